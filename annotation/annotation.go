@@ -0,0 +1,263 @@
+// Package annotation describes the annotations asyncapi-doc reads in Go
+// comments: their names, the objects they describe and the annotations
+// registered by other tools, whose values the parser hands to them.
+package annotation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Target is the AsyncAPI object an annotation describes.
+type Target string
+
+// Targets of the annotations.
+const (
+	TargetInfo       Target = "info"
+	TargetServer     Target = "server"
+	TargetOperation  Target = "operation"
+	TargetChannel    Target = "channel"
+	TargetMessage    Target = "message"
+	TargetComponents Target = "components"
+	TargetSchema     Target = "schema"
+)
+
+// Annotation describes an annotation read by the parser.
+type Annotation struct {
+	// Name is the annotation as written in comments, e.g.
+	// "@message.contentType". Annotations are matched case-insensitively.
+	Name string
+	// Prefix reports whether Name starts a family of annotations completed
+	// by a key, e.g. "@binding.kafka." for "@binding.kafka.partitions".
+	Prefix bool
+	// Target is the object the annotation describes.
+	Target Target
+	// Description tells what the annotation sets.
+	Description string
+	// Example is a complete annotation line.
+	Example string
+	// Parse handles the value of a registered annotation on an operation
+	// comment and returns the specification extensions it sets on the
+	// target, e.g. {"x-acme-team": "payments"}. It is nil for the built-in
+	// annotations.
+	Parse func(value string) (map[string]interface{}, error)
+}
+
+// Matches reports whether name is the annotation, or belongs to the family
+// it starts.
+func (a Annotation) Matches(name string) bool {
+	if a.Prefix {
+		rest, ok := CutPrefix(name, a.Name)
+		return ok && rest != ""
+	}
+	return strings.EqualFold(name, a.Name)
+}
+
+// builtin are the annotations of service-level, operation and type doc
+// comments, in the order of the README. The parser dispatches on the names
+// Canonical returns, so an annotation missing from this table is not read.
+var builtin = []Annotation{
+	{Name: Title, Target: TargetInfo, Description: "Title of the API", Example: "@title Order Service API"},
+	{Name: Version, Target: TargetInfo, Description: "Version of the API", Example: "@version 1.0.0"},
+	{Name: Description, Target: TargetInfo, Description: "Description of the API, which may continue on the following lines", Example: "@description Handles the order lifecycle"},
+	{Name: ID, Target: TargetInfo, Description: "Identifier of the application, a URI", Example: "@id urn:example:orders"},
+	{Name: TermsOfService, Target: TargetInfo, Description: "URL of the terms of service", Example: "@termsOfService https://example.com/terms"},
+	{Name: ContactName, Target: TargetInfo, Description: "Name of the API owner", Example: "@contact.name API Support Team"},
+	{Name: ContactURL, Target: TargetInfo, Description: "URL of the API owner", Example: "@contact.url https://example.com/support"},
+	{Name: ContactEmail, Target: TargetInfo, Description: "Email address of the API owner", Example: "@contact.email support@example.com"},
+	{Name: LicenseName, Target: TargetInfo, Description: "License of the API", Example: "@license.name Apache 2.0"},
+	{Name: LicenseURL, Target: TargetInfo, Description: "URL of the license", Example: "@license.url https://www.apache.org/licenses/LICENSE-2.0"},
+	{Name: Tag, Target: TargetInfo, Description: "Tag of the API, with an optional \" - description\"", Example: "@tag orders - Order events"},
+	{Name: ExternalDocsDescription, Target: TargetInfo, Description: "Description of the external documentation", Example: "@externalDocs.description Full guide"},
+	{Name: ExternalDocsURL, Target: TargetInfo, Description: "URL of the external documentation", Example: "@externalDocs.url https://docs.example.com"},
+	{Name: MessageCommonHeader, Target: TargetInfo, Description: "Header shared by every message: name, type and description", Example: "@message.commonHeader tenant-id string Tenant the message belongs to"},
+	{Name: InfoMarker, Target: TargetInfo, Description: "Marks a comment as the service-level block", Example: "@asyncapi:info"},
+	{Name: ExtensionPrefix, Prefix: true, Target: TargetInfo, Description: "Specification extension of the info object, in service-level comments", Example: "@x-team payments"},
+
+	{Name: Protocol, Target: TargetServer, Description: "Protocol of the server", Example: "@protocol nats"},
+	{Name: ProtocolVersion, Target: TargetServer, Description: "Version of the protocol", Example: "@protocolVersion 2.10"},
+	{Name: URL, Target: TargetServer, Description: "URL of the server", Example: "@url nats://localhost:4222"},
+	{Name: Host, Target: TargetServer, Description: "Host of the server", Example: "@host localhost:4222"},
+	{Name: Pathname, Target: TargetServer, Description: "Path of the server", Example: "@pathname /production"},
+	{Name: ServerName, Target: TargetServer, Description: "Key of the server", Example: "@server.name production"},
+	{Name: ServerPathname, Target: TargetServer, Description: "Path of the server", Example: "@server.pathname /production"},
+	{Name: ServerVhost, Target: TargetServer, Description: "AMQP virtual host, written as the pathname", Example: "@server.vhost /orders"},
+	{Name: ServerEnvironment, Target: TargetServer, Description: "Environment naming the server", Example: "@server.environment staging"},
+	{Name: ServerTitle, Target: TargetServer, Description: "Title of the server", Example: "@server.title Production NATS"},
+	{Name: ServerSummary, Target: TargetServer, Description: "Summary of the server", Example: "@server.summary Primary cluster"},
+	{Name: ServerDescription, Target: TargetServer, Description: "Description of the server", Example: "@server.description Production cluster"},
+	{Name: ServerTag, Target: TargetServer, Description: "Tag of the server", Example: "@server.tag production"},
+	{Name: ServerExternalDocsDescription, Target: TargetServer, Description: "Description of the external documentation of the server", Example: "@server.externalDocs.description Cluster guide"},
+	{Name: ServerExternalDocsURL, Target: TargetServer, Description: "URL of the external documentation of the server", Example: "@server.externalDocs.url https://docs.example.com/nats"},
+	{Name: ServerVariable, Target: TargetServer, Description: "Variable of the server URL: name and enum, default and description", Example: "@server.variable region enum=us,eu default=us description=Region"},
+	{Name: ServerSecurity, Target: TargetServer, Description: "Security scheme required by the server", Example: "@server.security userPassword"},
+	{Name: ServerBinding, Target: TargetServer, Description: "Binding of the server: protocol.property and value", Example: "@server.binding nats.queue production-queue"},
+	{Name: ServerExtensionPrefix, Prefix: true, Target: TargetServer, Description: "Specification extension of the server", Example: "@server.x-region us-east"},
+	{Name: ServerPrefix, Prefix: true, Target: TargetServer, Description: "Field of a named server, as @server.<name>.<field>", Example: "@server.staging.host nats://staging:4222"},
+	{Name: SecuritySchemePrefix, Prefix: true, Target: TargetComponents, Description: "Field of a security scheme, as @securityScheme.<name>.<field>", Example: "@securityScheme.userPassword.type userPassword"},
+	{Name: OperationTraitPrefix, Prefix: true, Target: TargetComponents, Description: "Field of an operation trait, as @operationTrait.<name>.<field>", Example: "@operationTrait.audited.tag audit - Operations recorded in the audit log"},
+	{Name: MessageTraitPrefix, Prefix: true, Target: TargetComponents, Description: "Field of a message trait, as @messageTrait.<name>.<field>", Example: "@messageTrait.jsonEvent.contentType application/json"},
+
+	{Name: Type, Target: TargetOperation, Description: "Operation type: pub or sub", Example: "@type pub"},
+	{Name: Name, Target: TargetOperation, Description: "Channel name, with {parameters}, or const: and a Go string constant", Example: "@name order.{orderId}.placed"},
+	{Name: Summary, Target: TargetOperation, Description: "Summary of the operation and its message", Example: "@summary Order placed"},
+	{Name: Description, Target: TargetOperation, Description: "Description of the operation and its message, which may continue on the following lines", Example: "@description Publishes when an order is placed"},
+	{Name: Payload, Target: TargetOperation, Description: "Go type of the message payload; repeated for alternative messages", Example: "@payload OrderPlacedEvent"},
+	{Name: PayloadAlt, Target: TargetOperation, Description: "Go type of an alternative message on the channel", Example: "@payload.alt OrderShippedEvent"},
+	{Name: PayloadDiscriminator, Target: TargetOperation, Description: "Property telling the payload subtypes apart", Example: "@payload.discriminator kind"},
+	{Name: PayloadSubtype, Target: TargetOperation, Description: "Discriminator values and Go types of the payload subtypes", Example: "@payload.subtype order:OrderEvent user:UserEvent"},
+	{Name: Response, Target: TargetOperation, Description: "Go type of the reply, making the operation a request", Example: "@response OrderResponse"},
+	{Name: ResponseError, Target: TargetOperation, Description: "Go type of an error reply, with an optional condition", Example: "@response.error ErrorPayload order does not exist"},
+	{Name: ResponseAddress, Target: TargetOperation, Description: "Runtime expression of a dynamic reply address", Example: "@response.address $message.header#/replyTo"},
+	{Name: Security, Target: TargetOperation, Description: "Security scheme required by the operation", Example: "@security userPassword"},
+	{Name: OperationID, Target: TargetOperation, Description: "Key of the operation", Example: "@operation.id placeOrder"},
+	{Name: OperationSummary, Target: TargetOperation, Description: "Summary of the operation only", Example: "@operation.summary Place an order"},
+	{Name: OperationDescription, Target: TargetOperation, Description: "Description of the operation only", Example: "@operation.description Places an order"},
+	{Name: OperationTag, Target: TargetOperation, Description: "Tag of the operation", Example: "@operation.tag orders"},
+	{Name: OperationExternalDocsDescription, Target: TargetOperation, Description: "Description of the external documentation of the operation", Example: "@operation.externalDocs.description Ordering guide"},
+	{Name: OperationExternalDocsURL, Target: TargetOperation, Description: "URL of the external documentation of the operation", Example: "@operation.externalDocs.url https://docs.example.com/orders"},
+	{Name: OperationTimeout, Target: TargetOperation, Description: "Time the caller waits for completion or a reply, written as x-timeout", Example: "@operation.timeout 5s"},
+	{Name: OperationQoS, Target: TargetOperation, Description: "Delivery quality of service (0, 1 or 2), written as the MQTT binding or x-qos", Example: "@operation.qos 1"},
+	{Name: OperationTrait, Target: TargetOperation, Description: "Operation trait applied to the operation", Example: "@operation.trait audited"},
+	{Name: Trait, Target: TargetOperation, Description: "Operation trait applied to the operation", Example: "@trait audited"},
+	{Name: Deprecated, Target: TargetOperation, Description: "Marks the operation as deprecated", Example: "@deprecated"},
+	{Name: ExtensionPrefix, Prefix: true, Target: TargetOperation, Description: "Specification extension of the operation, in operation comments", Example: "@x-owner payments"},
+	{Name: OperationExtensionPrefix, Prefix: true, Target: TargetOperation, Description: "Specification extension of the operation", Example: "@operation.x-owner payments"},
+	{Name: OperationBindingPrefix, Prefix: true, Target: TargetOperation, Description: "Operation binding property, as @operation.binding.<protocol>.<property>", Example: "@operation.binding.mqtt.retain true"},
+	{Name: BindingNATSQueue, Target: TargetOperation, Description: "NATS queue group of the subscription", Example: "@binding.nats.queue order-workers"},
+	{Name: BindingNATSDeliverPolicy, Target: TargetOperation, Description: "JetStream deliver policy of the consumer", Example: "@binding.nats.deliverPolicy all"},
+	{Name: BindingKafkaPrefix, Prefix: true, Target: TargetOperation, Description: "Kafka binding property", Example: "@binding.kafka.groupId order-service"},
+	{Name: BindingAMQPPrefix, Prefix: true, Target: TargetOperation, Description: "AMQP binding property", Example: "@binding.amqp.exchange.name orders"},
+	{Name: BindingWSPrefix, Prefix: true, Target: TargetOperation, Description: "WebSockets binding property", Example: "@binding.ws.method GET"},
+	{Name: BindingHTTPPrefix, Prefix: true, Target: TargetOperation, Description: "HTTP binding property", Example: "@binding.http.method POST"},
+	{Name: BindingRedisPrefix, Prefix: true, Target: TargetOperation, Description: "Redis binding property", Example: "@binding.redis.stream orders"},
+
+	{Name: ChannelTitle, Target: TargetChannel, Description: "Title of the channel", Example: "@channel.title Order events"},
+	{Name: ChannelSummary, Target: TargetChannel, Description: "Summary of the channel", Example: "@channel.summary Order lifecycle"},
+	{Name: ChannelDescription, Target: TargetChannel, Description: "Description of the channel", Example: "@channel.description Events of the order lifecycle"},
+	{Name: ChannelKey, Target: TargetChannel, Description: "Key of the channel", Example: "@channel.key orderEvents"},
+	{Name: ChannelServer, Target: TargetChannel, Description: "Server the channel is available on", Example: "@channel.server production"},
+	{Name: ChannelTag, Target: TargetChannel, Description: "Tag of the channel", Example: "@channel.tag orders"},
+	{Name: ChannelExternalDocsDescription, Target: TargetChannel, Description: "Description of the external documentation of the channel", Example: "@channel.externalDocs.description Subject naming"},
+	{Name: ChannelExternalDocsURL, Target: TargetChannel, Description: "URL of the external documentation of the channel", Example: "@channel.externalDocs.url https://docs.example.com/subjects"},
+	{Name: Parameter, Target: TargetChannel, Description: "Address parameter: name, then enum, default, examples, description and location", Example: "@parameter region enum=eu,us default=eu"},
+	{Name: ChannelExtensionPrefix, Prefix: true, Target: TargetChannel, Description: "Specification extension of the channel", Example: "@channel.x-slo {\"latency\": \"100ms\"}"},
+	{Name: ChannelBindingPrefix, Prefix: true, Target: TargetChannel, Description: "Channel binding property, as @channel.binding.<protocol>.<property>", Example: "@channel.binding.ibmmq.queue.maxMsgLength 4096"},
+
+	{Name: MessageName, Target: TargetMessage, Description: "Name of the message", Example: "@message.name OrderPlaced"},
+	{Name: MessageTitle, Target: TargetMessage, Description: "Title of the message", Example: "@message.title Order placed"},
+	{Name: MessageSummary, Target: TargetMessage, Description: "Summary of the message only", Example: "@message.summary An order was placed"},
+	{Name: MessageDescription, Target: TargetMessage, Description: "Description of the message only", Example: "@message.description Sent once per order"},
+	{Name: MessageContentType, Target: TargetMessage, Description: "Content type of the message", Example: "@message.contentType application/json"},
+	{Name: MessageTag, Target: TargetMessage, Description: "Tag of the message", Example: "@message.tag orders"},
+	{Name: MessageHeaders, Target: TargetMessage, Description: "Go type of the message headers", Example: "@message.headers OrderHeaders"},
+	{Name: MessageCorrelationID, Target: TargetMessage, Description: "Correlation ID: a header name or runtime expression, with an optional \" - description\"", Example: "@message.correlationId $message.header#/correlationId"},
+	{Name: MessageProto, Target: TargetMessage, Description: ".proto file describing a protobuf payload", Example: "@message.proto ./proto/orders.proto"},
+	{Name: MessageSchemaFormat, Target: TargetMessage, Description: "Schema format of the payload", Example: "@message.schemaFormat application/vnd.apache.avro;version=1.9.0"},
+	{Name: MessageRef, Target: TargetMessage, Description: "Reference to a message defined elsewhere", Example: "@message.ref https://catalog.example.com/messages/OrderPlaced.yaml"},
+	{Name: MessageKey, Target: TargetMessage, Description: "Payload field used as the message key", Example: "@message.key orderId"},
+	{Name: MessageExamples, Target: TargetMessage, Description: "Named example with JSON payload, headers and summary", Example: "@message.examples created {\"payload\": {\"id\": \"42\"}}"},
+	{Name: MessageTrait, Target: TargetMessage, Description: "Message trait applied to the message", Example: "@message.trait tenant"},
+	{Name: MessageExtensionPrefix, Prefix: true, Target: TargetMessage, Description: "Specification extension of the message", Example: "@message.x-retention 7d"},
+	{Name: MessageBindingPrefix, Prefix: true, Target: TargetMessage, Description: "Message binding property, as @message.binding.<protocol>.<property>", Example: "@message.binding.kafka.key string"},
+
+	{Name: Format, Target: TargetSchema, Description: "Format of a defined primitive type, in its doc comment", Example: "@format uuid"},
+	{Name: Validate, Target: TargetSchema, Description: "Validation rules of a defined primitive type, in its doc comment", Example: "@validate min=1,max=64"},
+	{Name: Example, Target: TargetSchema, Description: "Example of a defined primitive type, in its doc comment", Example: "@example ord_123"},
+}
+
+// registered are the annotations added with Register.
+var registered struct {
+	sync.RWMutex
+	list []Annotation
+}
+
+// All returns the annotations the parser reads: the built-in ones, grouped
+// by target, then the registered ones in registration order.
+func All() []Annotation {
+	registered.RLock()
+	defer registered.RUnlock()
+	return slices.Concat(builtin, registered.list)
+}
+
+// Register adds an annotation of operation comments, handled by its Parse
+// function. The target must be the operation, its channel or its message,
+// and the name must not be, or start with, a built-in annotation. Register
+// is typically called from an init function.
+func Register(annotation Annotation) error {
+	if !strings.HasPrefix(annotation.Name, "@") || strings.ContainsAny(annotation.Name, " \t") {
+		return fmt.Errorf("invalid annotation name %q: want @ and a name without spaces", annotation.Name)
+	}
+	if annotation.Parse == nil {
+		return fmt.Errorf("annotation %s has no Parse function", annotation.Name)
+	}
+	//nolint:exhaustive // Only annotations of operation comments are handled
+	switch annotation.Target {
+	case TargetOperation, TargetChannel, TargetMessage:
+	default:
+		return fmt.Errorf("annotation %s: target %q is not supported, want operation, channel or message", annotation.Name, annotation.Target)
+	}
+	annotation.Prefix = false
+
+	registered.Lock()
+	defer registered.Unlock()
+	if existing, ok := find(slices.Concat(builtin, registered.list), annotation.Name); ok {
+		return fmt.Errorf("annotation %s is already defined as %s", annotation.Name, existing.Name)
+	}
+	registered.list = append(registered.list, annotation)
+	return nil
+}
+
+// Lookup returns the annotations name is, or whose family it belongs to,
+// e.g. "@binding.kafka." for "@binding.kafka.partitions". Names read on
+// several objects, such as @description, have one entry per target.
+func Lookup(name string) []Annotation {
+	var found []Annotation
+	for _, annotation := range All() {
+		if annotation.Matches(name) {
+			found = append(found, annotation)
+		}
+	}
+	return found
+}
+
+// Registered returns the registered annotation name is.
+func Registered(name string) (Annotation, bool) {
+	registered.RLock()
+	defer registered.RUnlock()
+	return find(registered.list, name)
+}
+
+// Canonical returns the name of the built-in annotation name is, whatever
+// its case, e.g. "@message.contentType" for "@message.contenttype", and
+// name itself otherwise.
+func Canonical(name string) string {
+	for _, annotation := range builtin {
+		if !annotation.Prefix && strings.EqualFold(annotation.Name, name) {
+			return annotation.Name
+		}
+	}
+	return name
+}
+
+// CutPrefix returns name without prefix, matched case-insensitively, and
+// reports whether name starts with it.
+func CutPrefix(name, prefix string) (string, bool) {
+	if len(name) < len(prefix) || !strings.EqualFold(name[:len(prefix)], prefix) {
+		return name, false
+	}
+	return name[len(prefix):], true
+}
+
+// find returns the annotation of annotations that name is, or that starts
+// the family of name.
+func find(annotations []Annotation, name string) (Annotation, bool) {
+	for _, annotation := range annotations {
+		if annotation.Matches(name) {
+			return annotation, true
+		}
+	}
+	return Annotation{}, false
+}
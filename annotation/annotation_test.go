@@ -0,0 +1,137 @@
+package annotation
+
+import (
+	"errors"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinCoversNames(t *testing.T) {
+	f, err := goparser.ParseFile(token.NewFileSet(), "names.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, value := range spec.Values {
+			name, _ := strconv.Unquote(value.(*ast.BasicLit).Value)
+			if !slices.ContainsFunc(builtin, func(a Annotation) bool { return a.Name == name }) {
+				t.Errorf("%s (%s) is missing from builtin", spec.Names[i].Name, name)
+			}
+		}
+		return false
+	})
+}
+
+func TestBuiltin(t *testing.T) {
+	for _, annotation := range builtin {
+		if annotation.Description == "" || annotation.Target == "" {
+			t.Errorf("%s: description %q, target %q, want both", annotation.Name, annotation.Description, annotation.Target)
+		}
+		if annotation.Prefix != (strings.HasSuffix(annotation.Name, ".") || strings.HasSuffix(annotation.Name, "-")) {
+			t.Errorf("%s: prefix = %v, want names ending in . or - only", annotation.Name, annotation.Prefix)
+		}
+		attribute := strings.Fields(annotation.Example)[0]
+		if !annotation.Matches(attribute) {
+			t.Errorf("%s: example %q does not use the annotation", annotation.Name, annotation.Example)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "@message.contenttype", want: MessageContentType},
+		{name: "@TITLE", want: Title},
+		{name: "@binding.NATS.deliverpolicy", want: BindingNATSDeliverPolicy},
+		{name: "@binding.kafka.groupId", want: "@binding.kafka.groupId"},
+		{name: "@unknown", want: "@unknown"},
+	}
+	for _, tt := range tests {
+		if got := Canonical(tt.name); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCutPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+		wantOK bool
+	}{
+		{name: "@binding.Kafka.groupId", prefix: BindingKafkaPrefix, want: "groupId", wantOK: true},
+		{name: "@securityscheme.user.flow", prefix: SecuritySchemePrefix, want: "user.flow", wantOK: true},
+		{name: "@binding.kafka.", prefix: BindingKafkaPrefix, want: "", wantOK: true},
+		{name: "@binding", prefix: BindingKafkaPrefix, want: "@binding", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := CutPrefix(tt.name, tt.prefix)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("CutPrefix(%q, %q) = %q, %v, want %q, %v", tt.name, tt.prefix, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(func() { registered.list = nil })
+
+	team := Annotation{
+		Name:        "@acme.team",
+		Target:      TargetChannel,
+		Description: "Team owning the channel",
+		Example:     "@acme.team payments",
+		Parse: func(value string) (map[string]interface{}, error) {
+			if value == "" {
+				return nil, errors.New("missing team")
+			}
+			return map[string]interface{}{"x-acme-team": value}, nil
+		},
+	}
+	if err := Register(team); err != nil {
+		t.Fatalf("Register(%s) error = %v", team.Name, err)
+	}
+
+	invalid := []Annotation{
+		team,
+		{Name: "@ACME.TEAM", Target: TargetChannel, Parse: team.Parse},
+		{Name: Title, Target: TargetOperation, Parse: team.Parse},
+		{Name: "@binding.kafka.owner", Target: TargetOperation, Parse: team.Parse},
+		{Name: "acme.owner", Target: TargetOperation, Parse: team.Parse},
+		{Name: "@acme.owner", Target: TargetOperation},
+		{Name: "@acme.owner", Target: TargetInfo, Parse: team.Parse},
+	}
+	for _, annotation := range invalid {
+		if err := Register(annotation); err == nil {
+			t.Errorf("Register(%s, %s) error = nil, want an error", annotation.Name, annotation.Target)
+		}
+	}
+
+	annotations := All()
+	if got := annotations[len(annotations)-1]; got.Name != team.Name {
+		t.Errorf("All() last = %s, want %s", got.Name, team.Name)
+	}
+	if len(annotations) != len(builtin)+1 {
+		t.Errorf("All() = %d, want %d", len(annotations), len(builtin)+1)
+	}
+	if got, ok := Registered("@Acme.Team"); !ok || got.Name != team.Name {
+		t.Errorf("Registered(@Acme.Team) = %s, %v, want %s", got.Name, ok, team.Name)
+	}
+	if _, ok := Registered(Title); ok {
+		t.Errorf("Registered(%s) = true, want false for a built-in annotation", Title)
+	}
+	if got := Lookup("@description"); len(got) < 2 {
+		t.Errorf("Lookup(@description) = %d annotations, want one per target", len(got))
+	}
+}
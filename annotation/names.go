@@ -0,0 +1,120 @@
+package annotation
+
+// Names of the built-in annotations, as documented. Annotations are matched
+// case-insensitively; names ending in "." or "-" start a family of
+// annotations completed by a key.
+const (
+	// Service-level annotations, describing the API.
+	Title                   = "@title"
+	Version                 = "@version"
+	Description             = "@description"
+	ID                      = "@id"
+	TermsOfService          = "@termsOfService"
+	ContactName             = "@contact.name"
+	ContactURL              = "@contact.url"
+	ContactEmail            = "@contact.email"
+	LicenseName             = "@license.name"
+	LicenseURL              = "@license.url"
+	Tag                     = "@tag"
+	ExternalDocsDescription = "@externalDocs.description"
+	ExternalDocsURL         = "@externalDocs.url"
+	MessageCommonHeader     = "@message.commonHeader"
+	InfoMarker              = "@asyncapi:info"
+	ExtensionPrefix         = "@x-"
+
+	// Server annotations.
+	Protocol                      = "@protocol"
+	ProtocolVersion               = "@protocolVersion"
+	URL                           = "@url"
+	Host                          = "@host"
+	Pathname                      = "@pathname"
+	ServerName                    = "@server.name"
+	ServerPathname                = "@server.pathname"
+	ServerVhost                   = "@server.vhost"
+	ServerEnvironment             = "@server.environment"
+	ServerTitle                   = "@server.title"
+	ServerSummary                 = "@server.summary"
+	ServerDescription             = "@server.description"
+	ServerTag                     = "@server.tag"
+	ServerExternalDocsDescription = "@server.externalDocs.description"
+	ServerExternalDocsURL         = "@server.externalDocs.url"
+	ServerVariable                = "@server.variable"
+	ServerSecurity                = "@server.security"
+	ServerBinding                 = "@server.binding"
+	ServerExtensionPrefix         = "@server.x-"
+	ServerPrefix                  = "@server."
+
+	// Reusable component annotations.
+	SecuritySchemePrefix = "@securityScheme."
+	OperationTraitPrefix = "@operationTrait."
+	MessageTraitPrefix   = "@messageTrait."
+
+	// Operation annotations.
+	Type                             = "@type"
+	Name                             = "@name"
+	Summary                          = "@summary"
+	Payload                          = "@payload"
+	PayloadAlt                       = "@payload.alt"
+	PayloadDiscriminator             = "@payload.discriminator"
+	PayloadSubtype                   = "@payload.subtype"
+	Response                         = "@response"
+	ResponseError                    = "@response.error"
+	ResponseAddress                  = "@response.address"
+	Security                         = "@security"
+	OperationID                      = "@operation.id"
+	OperationSummary                 = "@operation.summary"
+	OperationDescription             = "@operation.description"
+	OperationTag                     = "@operation.tag"
+	OperationExternalDocsDescription = "@operation.externalDocs.description"
+	OperationExternalDocsURL         = "@operation.externalDocs.url"
+	OperationTimeout                 = "@operation.timeout"
+	OperationQoS                     = "@operation.qos"
+	OperationTrait                   = "@operation.trait"
+	Trait                            = "@trait"
+	Deprecated                       = "@deprecated"
+	OperationExtensionPrefix         = "@operation.x-"
+	OperationBindingPrefix           = "@operation.binding."
+	BindingNATSQueue                 = "@binding.nats.queue"
+	BindingNATSDeliverPolicy         = "@binding.nats.deliverPolicy"
+	BindingKafkaPrefix               = "@binding.kafka."
+	BindingAMQPPrefix                = "@binding.amqp."
+	BindingWSPrefix                  = "@binding.ws."
+	BindingHTTPPrefix                = "@binding.http."
+	BindingRedisPrefix               = "@binding.redis."
+
+	// Channel annotations.
+	ChannelTitle                   = "@channel.title"
+	ChannelSummary                 = "@channel.summary"
+	ChannelDescription             = "@channel.description"
+	ChannelKey                     = "@channel.key"
+	ChannelServer                  = "@channel.server"
+	ChannelTag                     = "@channel.tag"
+	ChannelExternalDocsDescription = "@channel.externalDocs.description"
+	ChannelExternalDocsURL         = "@channel.externalDocs.url"
+	Parameter                      = "@parameter"
+	ChannelExtensionPrefix         = "@channel.x-"
+	ChannelBindingPrefix           = "@channel.binding."
+
+	// Message annotations.
+	MessageName            = "@message.name"
+	MessageTitle           = "@message.title"
+	MessageSummary         = "@message.summary"
+	MessageDescription     = "@message.description"
+	MessageContentType     = "@message.contentType"
+	MessageTag             = "@message.tag"
+	MessageHeaders         = "@message.headers"
+	MessageCorrelationID   = "@message.correlationId"
+	MessageProto           = "@message.proto"
+	MessageSchemaFormat    = "@message.schemaFormat"
+	MessageRef             = "@message.ref"
+	MessageKey             = "@message.key"
+	MessageExamples        = "@message.examples"
+	MessageTrait           = "@message.trait"
+	MessageExtensionPrefix = "@message.x-"
+	MessageBindingPrefix   = "@message.binding."
+
+	// Annotations of the doc comment of a defined primitive type.
+	Format   = "@format"
+	Validate = "@validate"
+	Example  = "@example"
+)
@@ -0,0 +1,26 @@
+// Loader for asyncapi-doc.wasm. Go's wasm_exec.js (copied next to the .wasm
+// file by `make build-wasm`) must be loaded first; it defines globalThis.Go.
+//
+//   import { load } from "./asyncapi-doc.js";
+//   const asyncapiDoc = await load("asyncapi-doc.wasm");
+//   const yaml = asyncapiDoc.generate({ "main.go": source });
+
+export async function load(wasmURL = "asyncapi-doc.wasm") {
+  const go = new globalThis.Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(wasmURL), go.importObject);
+  // The Go program keeps running to serve calls; do not await it.
+  go.run(instance);
+
+  return {
+    // generate returns the spec for files, an object mapping paths such as
+    // "main.go" or "handlers/orders.go" to Go source. options.format is
+    // "yaml" (default) or "json"; options.exclude lists directories to skip.
+    generate(files, options = {}) {
+      const result = globalThis.asyncapiDocGenerate(files, options);
+      if (result.error) {
+        throw new Error(result.error);
+      }
+      return result.spec;
+    },
+  };
+}
@@ -0,0 +1,68 @@
+//go:build js && wasm
+
+// Command asyncapi-doc-wasm exposes the generator to JavaScript when compiled
+// with GOOS=js GOARCH=wasm. It registers a global asyncapiDocGenerate function
+// that takes a set of virtual Go files and returns the generated spec; see
+// asyncapi-doc.js for the loader.
+package main
+
+import (
+	"syscall/js"
+	"testing/fstest"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+)
+
+func main() {
+	js.Global().Set("asyncapiDocGenerate", js.FuncOf(generate))
+	select {}
+}
+
+// generate implements asyncapiDocGenerate(files, options). files maps
+// slash-separated paths to Go source; options may set format ("yaml" or
+// "json") and exclude. It returns {spec} on success and {error} otherwise.
+func generate(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeObject {
+		return result("", "asyncapiDocGenerate expects an object mapping file paths to source")
+	}
+
+	fsys := fstest.MapFS{}
+	keys := js.Global().Get("Object").Call("keys", args[0])
+	for i := 0; i < keys.Length(); i++ {
+		name := keys.Index(i).String()
+		fsys[name] = &fstest.MapFile{Data: []byte(args[0].Get(name).String())}
+	}
+
+	format, exclude := "yaml", ""
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		if v := args[1].Get("format"); v.Type() == js.TypeString {
+			format = v.String()
+		}
+		if v := args[1].Get("exclude"); v.Type() == js.TypeString {
+			exclude = v.String()
+		}
+	}
+
+	doc, err := asyncapi.ParseFS(fsys, asyncapi.Options{Recursive: true, ExcludeDirs: exclude})
+	if err != nil {
+		return result("", err.Error())
+	}
+
+	var data []byte
+	if format == "json" {
+		data, err = doc.MarshalJSONIndent()
+	} else {
+		data, err = doc.MarshalYAML()
+	}
+	if err != nil {
+		return result("", err.Error())
+	}
+	return result(string(data), "")
+}
+
+func result(spec, errMsg string) map[string]interface{} {
+	if errMsg != "" {
+		return map[string]interface{}{"error": errMsg}
+	}
+	return map[string]interface{}{"spec": spec}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+)
+
+func annotateCommand() {
+	fs := flag.NewFlagSet("annotate", flag.ExitOnError)
+	write := fs.Bool("write", false, "insert the annotation stubs into the source files instead of writing a patch")
+	output := fs.String("output", "", "patch file to write the annotation stubs to (default: standard output)")
+	discover := fs.String("discover", "", "comma-separated clients whose calls get annotation stubs: nats, kafka, watermill (default all)")
+	configFile := fs.String("config", "", "JSON configuration file (e.g., type_mappings for custom type schemas)")
+	exclude := fs.String("exclude", "", "comma-separated directory names or globs to exclude when parsing sub-directories (e.g., vendor,mocks,**/internal/test*)")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v\n", err)
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: asyncapi-doc annotate [options] <source-directory>\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	if *write && *output != "" {
+		log.Fatalf("-output cannot be used with -write\n")
+	}
+
+	cfg := loadConfig(*configFile)
+	opts := asyncapi.Options{
+		ExcludeDirs:        *exclude,
+		TypeMappings:       cfg.TypeMappings,
+		UntaggedFields:     cfg.UntaggedFields,
+		Naming:             cfg.Naming,
+		ServerNaming:       cfg.ServerNaming,
+		BindingVersions:    cfg.BindingVersions,
+		RegistrationTables: registrationTables(cfg.RegistrationTables),
+	}
+	if *discover != "" {
+		opts.Discover = strings.Split(*discover, ",")
+	}
+	files, err := asyncapi.Annotate(fs.Arg(0), opts)
+	if err != nil {
+		log.Fatalf("Failed to annotate sources: %v\n", err)
+	}
+
+	stubs := 0
+	var patch strings.Builder
+	wd, _ := os.Getwd()
+	for _, f := range files {
+		stubs += f.Stubs()
+		if *write {
+			if err := replaceFile(f.Path, f.Annotated()); err != nil {
+				log.Fatalf("Failed to write %s: %v\n", f.Path, err)
+			}
+			continue
+		}
+		patch.WriteString(f.Diff(patchPath(wd, f.Path)))
+	}
+
+	if !*write {
+		if *output == "" {
+			if _, err := os.Stdout.WriteString(patch.String()); err != nil {
+				log.Fatalf("Failed to write patch: %v\n", err)
+			}
+		} else if err := os.WriteFile(*output, []byte(patch.String()), 0o600); err != nil {
+			log.Fatalf("Failed to write patch file: %v\n", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "✓ %d annotation stub(s) in %d file(s)\n", stubs, len(files))
+}
+
+// patchPath names the file at path in patch headers: relative to the
+// working directory wd when it is inside it, else absolute.
+func patchPath(wd, path string) string {
+	if rel, err := filepath.Rel(wd, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
+
+// replaceFile replaces the content of the file at path with data, keeping its
+// permissions. The data is written to a temporary file renamed over path, so
+// an interrupted write leaves the original in place.
+func replaceFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPatchPath(t *testing.T) {
+	wd := filepath.FromSlash("/src/orders")
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/src/orders/handlers/orders.go", "handlers/orders.go"},
+		{"/src/orders/..generated/orders.go", "..generated/orders.go"},
+		{"/src/billing/invoices.go", "/src/billing/invoices.go"},
+	}
+	for _, tt := range tests {
+		if got := patchPath(wd, filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("patchPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "handlers.go")
+	if err := os.WriteFile(path, []byte("package handlers\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "// @type pub\npackage handlers\n"
+	if err := replaceFile(path, []byte(want)); err != nil {
+		t.Fatalf("replaceFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("content = %q, want %q", data, want)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o640 {
+		t.Errorf("permissions = %o, want 640", perm)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want the temporary file removed", len(entries))
+	}
+}
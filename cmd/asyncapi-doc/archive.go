@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fedanant/asyncapi-doc/internal/archive"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// archivePrefix marks a diff argument naming an archived snapshot, as in
+// "archive:<dir>@<ref>".
+const archivePrefix = "archive:"
+
+// archiveSpec stores the document as a new snapshot of the archive in dir.
+func archiveSpec(doc *spec3.AsyncAPI, dir, commit string, yamlOpts yamlOutput, verbose bool) {
+	data, err := doc.MarshalYAMLWith(yamlOpts.YAMLOptions)
+	if err != nil {
+		log.Fatalf("Failed to marshal YAML: %v\n", err)
+	}
+	snapshot, added, err := archive.Add(dir, data, ".yaml", archive.Snapshot{
+		Created: time.Now().UTC().Truncate(time.Second),
+		Commit:  commit,
+		Version: doc.Info.Version,
+	})
+	if err != nil {
+		log.Fatalf("Failed to archive specification: %v\n", err)
+	}
+	if verbose {
+		if added {
+			fmt.Printf("Archived specification as: %s\n", filepath.Join(dir, snapshot.File))
+		} else {
+			fmt.Printf("Specification unchanged since archived snapshot: %s\n", filepath.Join(dir, snapshot.File))
+		}
+	}
+}
+
+// sourceCommit returns the abbreviated commit checked out in the source
+// directory, or "" outside a git repository.
+func sourceCommit(srcDir string) string {
+	dir := strings.TrimSuffix(filepath.ToSlash(srcDir), "...")
+	if dir == "" {
+		dir = "."
+	}
+	//nolint:gosec // The source directory is provided by the user invoking the CLI
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// readSpecSource reads a spec file, or the snapshot named by an
+// "archive:<dir>[@<ref>]" argument.
+func readSpecSource(name string) ([]byte, error) {
+	source, ok := strings.CutPrefix(name, archivePrefix)
+	if !ok {
+		return os.ReadFile(name)
+	}
+	dir, ref, _ := strings.Cut(source, "@")
+	data, _, err := archive.Read(dir, ref)
+	return data, err
+}
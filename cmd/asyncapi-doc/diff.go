@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+	"github.com/fedanant/asyncapi-doc/internal/diff"
+)
+
+// exitBreaking is the exit code used when breaking changes are detected.
+const exitBreaking = 1
+
+func diffCommand() {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	against := fs.String("against", "", "compare the spec against a git revision or an archived snapshot instead of a second file (e.g., git:HEAD, git:main, archive:./specs@latest)")
+	breakingOnly := fs.Bool("breaking-only", false, "only report breaking changes")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v\n", err)
+	}
+
+	var oldData, newData []byte
+	var oldName, newName string
+	var err error
+
+	switch {
+	case *against != "" && fs.NArg() == 1:
+		newName = fs.Arg(0)
+		if strings.HasPrefix(*against, archivePrefix) {
+			oldName = *against
+			oldData, err = readSpecSource(oldName)
+		} else {
+			oldName = *against + ":" + newName
+			oldData, err = readRevision(*against, newName)
+		}
+		if err != nil {
+			log.Fatalf("Failed to read %s: %v\n", oldName, err)
+		}
+	case *against == "" && fs.NArg() == 2:
+		oldName, newName = fs.Arg(0), fs.Arg(1)
+		oldData, err = readSpecSource(oldName)
+		if err != nil {
+			log.Fatalf("Failed to read %s: %v\n", oldName, err)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: asyncapi-doc diff [options] <old-spec> <new-spec>\n")
+		fmt.Fprintf(os.Stderr, "       asyncapi-doc diff -against git:<rev> [options] <spec>\n")
+		fmt.Fprintf(os.Stderr, "       asyncapi-doc diff -against archive:<dir>[@<ref>] [options] <spec>\n\n")
+		fmt.Fprintf(os.Stderr, "Specs may be archived snapshots, written archive:<dir>[@<ref>].\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+
+	newData, err = readSpecSource(newName)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v\n", newName, err)
+	}
+
+	oldDoc, err := spec3.Parse(oldData)
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v\n", oldName, err)
+	}
+	newDoc, err := spec3.Parse(newData)
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v\n", newName, err)
+	}
+
+	report := diff.Compare(oldDoc, newDoc)
+	changes := report.Changes
+	if *breakingOnly {
+		changes = report.Breaking()
+	}
+
+	if len(changes) == 0 {
+		if len(report.Changes) > 0 {
+			fmt.Printf("✓ No breaking changes detected (%d non-breaking change(s))\n", len(report.Changes))
+			return
+		}
+		fmt.Println("✓ No changes detected")
+		return
+	}
+
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+
+	if report.HasBreaking() {
+		fmt.Fprintf(os.Stderr, "\n✗ %d breaking change(s) detected\n", len(report.Breaking()))
+		os.Exit(exitBreaking)
+	}
+}
+
+// readRevision loads the content of path at the given "git:<rev>" revision.
+func readRevision(revision, path string) ([]byte, error) {
+	rev, ok := strings.CutPrefix(revision, "git:")
+	if !ok || rev == "" {
+		return nil, fmt.Errorf("unsupported revision %q (expected git:<rev>)", revision)
+	}
+
+	//nolint:gosec // Revision and path are provided by the user invoking the CLI
+	cmd := exec.Command("git", "show", rev+":./"+filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
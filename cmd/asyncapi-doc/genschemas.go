@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+	"github.com/fedanant/asyncapi-doc/internal/codegen"
+)
+
+func genSchemasCommand() {
+	fs := flag.NewFlagSet("gen-schemas", flag.ExitOnError)
+	lang := fs.String("lang", "jsonschema", "output language (jsonschema, typescript)")
+	output := fs.String("output", "./schemas", "output directory for the generated files")
+	verbose := fs.Bool("verbose", false, "enable verbose output")
+	configFile := fs.String("config", "", "JSON configuration file (e.g., type_mappings for custom type schemas)")
+	exclude := fs.String("exclude", "", "comma-separated directory names or globs to exclude when parsing sub-directories (e.g., vendor,mocks,**/internal/test*)")
+	refNested := fs.Bool("ref-nested", false, "register nested structs once and reference them instead of inlining")
+	goTypes := fs.Bool("go-types", false, "add an x-go-type extension with the import path and name of the Go type to each struct schema")
+	untaggedFields := fs.String("untagged-fields", "", "how exported struct fields without a json tag are documented: skip or include (default skip, or the config's untagged_fields)")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v\n", err)
+	}
+
+	if fs.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "Error: source directory is required\n")
+		fmt.Fprintf(os.Stderr, "Usage: asyncapi-doc gen-schemas [options] <source-directory>...\n")
+		fmt.Fprintf(os.Stderr, "       asyncapi-doc gen-schemas [options] git+<repository-url>[@<ref>] [<path>...]\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if *lang != "jsonschema" && *lang != "typescript" {
+		log.Fatalf("Unsupported language %q (supported: jsonschema, typescript)\n", *lang)
+	}
+
+	cfg := loadConfig(*configFile)
+	if *untaggedFields != "" {
+		cfg.UntaggedFields = *untaggedFields
+	}
+
+	sources, cleanup, err := resolveSource(fs.Args(), *verbose)
+	if err != nil {
+		log.Fatalf("Failed to fetch source: %v\n", err)
+	}
+	doc, err := asyncapi.ParseFoldersDocument(sources, asyncapi.Options{
+		Verbose:        *verbose,
+		ExcludeDirs:    *exclude,
+		RefNested:      *refNested,
+		TypeMappings:   cfg.TypeMappings,
+		GoTypes:        *goTypes,
+		UntaggedFields: cfg.UntaggedFields,
+		Info:           cfg.Info,
+		Servers:        cfg.Servers,
+	})
+	cleanup()
+	if err != nil {
+		log.Fatalf("Failed to parse folder: %v\n", err)
+	}
+
+	var files map[string][]byte
+	if *lang == "typescript" {
+		data, tsErr := codegen.TypeScript(doc)
+		if tsErr != nil {
+			log.Fatalf("Failed to generate TypeScript: %v\n", tsErr)
+		}
+		files = map[string][]byte{codegen.TypeScriptFileName: data}
+	} else {
+		files, err = codegen.JSONSchemaFiles(doc)
+		if err != nil {
+			log.Fatalf("Failed to generate JSON Schema: %v\n", err)
+		}
+	}
+
+	if err := os.MkdirAll(*output, 0o750); err != nil {
+		log.Fatalf("Failed to create output directory: %v\n", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(*output, name)
+		if *verbose {
+			fmt.Printf("Writing output to: %s\n", path)
+		}
+		if err := os.WriteFile(path, files[name], 0o600); err != nil {
+			log.Fatalf("Failed to write output file: %v\n", err)
+		}
+	}
+
+	fmt.Printf("✓ Generated %d schema file(s) in %s\n", len(files), *output)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+)
+
+func indexCommand() {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	output := fs.String("output", "", "JSON file to write the annotation index to (default: standard output)")
+	configFile := fs.String("config", "", "JSON configuration file (e.g., type_mappings for custom type schemas)")
+	exclude := fs.String("exclude", "", "comma-separated directory names or globs to exclude when parsing sub-directories (e.g., vendor,mocks,**/internal/test*)")
+	verifySubjects := fs.String("verify-subjects", "", "check the constant subjects, topics and queues passed to NATS, Kafka and AMQP clients by annotated functions against their @name: warn or error")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v\n", err)
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: asyncapi-doc index [options] <source-directory>\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	cfg := loadConfig(*configFile)
+	opts := asyncapi.Options{
+		ExcludeDirs:        *exclude,
+		TypeMappings:       cfg.TypeMappings,
+		UntaggedFields:     cfg.UntaggedFields,
+		Naming:             cfg.Naming,
+		ServerNaming:       cfg.ServerNaming,
+		BindingVersions:    cfg.BindingVersions,
+		RegistrationTables: registrationTables(cfg.RegistrationTables),
+		VerifySubjects:     *verifySubjects,
+	}
+	index, err := asyncapi.BuildIndex(fs.Arg(0), opts)
+	if err != nil {
+		log.Fatalf("Failed to index annotations: %v\n", err)
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal index: %v\n", err)
+	}
+	data = append(data, '\n')
+	if *output == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			log.Fatalf("Failed to write index: %v\n", err)
+		}
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o600); err != nil {
+		log.Fatalf("Failed to write index file: %v\n", err)
+	}
+}
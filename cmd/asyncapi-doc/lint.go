@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+	"github.com/fedanant/asyncapi-doc/internal/lint"
+)
+
+// exitLintErrors is the exit code used when rules of the error severity fail.
+const exitLintErrors = 1
+
+func lintCommand() {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	rulesetFile := fs.String("ruleset", "", "YAML ruleset file with the rules to evaluate (required)")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v\n", err)
+	}
+
+	if *rulesetFile == "" || fs.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: asyncapi-doc lint -ruleset <ruleset> <spec>...\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+
+	ruleset, err := lint.LoadRuleset(*rulesetFile)
+	if err != nil {
+		log.Fatalf("Failed to load ruleset: %v\n", err)
+	}
+
+	failed := false
+	for _, file := range fs.Args() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("Failed to read %s: %v\n", file, err)
+		}
+		results, err := ruleset.CheckData(data)
+		if err != nil {
+			log.Fatalf("Failed to lint %s: %v\n", file, err)
+		}
+		printLintResults(file, results)
+		failed = failed || lint.HasErrors(results)
+	}
+
+	if failed {
+		os.Exit(exitLintErrors)
+	}
+}
+
+// lintSpec evaluates the ruleset against a generated document, prints the
+// results and returns them. With strict set, rules of the error severity that
+// fail stop the generation before anything is written.
+func lintSpec(doc *spec3.AsyncAPI, rulesetFile string, strict bool) []lint.Result {
+	ruleset, err := lint.LoadRuleset(rulesetFile)
+	if err != nil {
+		log.Fatalf("Failed to load ruleset: %v\n", err)
+	}
+	results, err := ruleset.CheckDocument(doc)
+	if err != nil {
+		log.Fatalf("Failed to lint specification: %v\n", err)
+	}
+	printLintResults("generated specification", results)
+	if strict && lint.HasErrors(results) {
+		os.Exit(exitLintErrors)
+	}
+	return results
+}
+
+// printLintResults prints the results of linting source, with a summary.
+func printLintResults(source string, results []lint.Result) {
+	if len(results) == 0 {
+		fmt.Printf("✓ %s: no problems found\n", source)
+		return
+	}
+	numErrors := 0
+	for _, result := range results {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", source, result)
+		if result.Severity == lint.Error {
+			numErrors++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "\n%d problem(s), %d error(s)\n", len(results), numErrors)
+}
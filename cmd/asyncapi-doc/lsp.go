@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+	"github.com/fedanant/asyncapi-doc/internal/lsp"
+)
+
+func lspCommand() {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	configFile := fs.String("config", "", "JSON configuration file (e.g., type_mappings for custom type schemas)")
+	debounce := fs.Duration("debounce", 300*time.Millisecond, "delay before the diagnostics of an edited file are refreshed (0 = on every change)")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v\n", err)
+	}
+
+	// Standard output carries the protocol, so everything else goes to stderr
+	log.SetOutput(os.Stderr)
+	cfg := loadConfig(*configFile)
+	server := lsp.NewServer(os.Stdin, os.Stdout, asyncapi.Options{
+		TypeMappings:       cfg.TypeMappings,
+		UntaggedFields:     cfg.UntaggedFields,
+		Naming:             cfg.Naming,
+		ServerNaming:       cfg.ServerNaming,
+		BindingVersions:    cfg.BindingVersions,
+		RegistrationTables: registrationTables(cfg.RegistrationTables),
+		VerifySubjects:     asyncapi.VerifySubjectsWarn,
+	})
+	server.Debounce = *debounce
+	if err := server.Serve(); err != nil {
+		log.Fatalf("Failed to serve: %v\n", err)
+	}
+}
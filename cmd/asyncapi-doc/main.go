@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+	"github.com/fedanant/asyncapi-doc/internal/config"
+	"github.com/fedanant/asyncapi-doc/internal/lint"
 )
 
 // Build information set via ldflags.
@@ -38,6 +42,20 @@ func main() {
 	switch command {
 	case "generate":
 		generate()
+	case "diff":
+		diffCommand()
+	case "lint":
+		lintCommand()
+	case "gen-schemas":
+		genSchemasCommand()
+	case "sign":
+		signCommand()
+	case "lsp":
+		lspCommand()
+	case "index":
+		indexCommand()
+	case "annotate":
+		annotateCommand()
 	case "version", "--version", "-v":
 		fmt.Printf("asyncapi-doc version %s\n", Version)
 		fmt.Printf("  Build time: %s\n", BuildTime)
@@ -51,48 +69,207 @@ func main() {
 
 func generate() {
 	fs := flag.NewFlagSet("generate", flag.ExitOnError)
-	output := fs.String("output", "./asyncapi.yaml", "output file for generated AsyncAPI specification")
+	var outputs stringList
+	fs.Var(&outputs, "output", "output file for generated AsyncAPI specification, repeatable; .json files are written as JSON (default "+defaultOutput+")")
+	formats := fs.String("formats", "", "comma-separated formats to write for each output (yaml, json)")
 	verbose := fs.Bool("verbose", false, "enable verbose output")
-	exclude := fs.String("exclude", "", "comma-separated list of directories to exclude (e.g., vendor,node_modules,.git)")
+	configFile := fs.String("config", "", "JSON configuration file (e.g., type_mappings for custom type schemas)")
+	exclude := fs.String("exclude", "", "comma-separated directory names or globs to exclude when parsing sub-directories (e.g., vendor,mocks,**/internal/test*)")
+	redact := fs.String("redact", "", "comma-separated classifications to redact in a public copy of the spec (e.g., pii)")
+	publicOutput := fs.String("public-output", "", "output file for the redacted public spec (default: <output>.public.<ext> for each output)")
+	maxDepth := fs.Int("max-schema-depth", 0, "maximum nesting depth of payload schemas; deeper levels are cut and marked x-truncated (0 = unlimited)")
+	maxProperties := fs.Int("max-properties", 0, "maximum number of properties per payload object; extra properties are cut and marked x-truncated (0 = unlimited)")
+	refNested := fs.Bool("ref-nested", false, "register nested structs once under components/schemas and reference them instead of inlining")
+	goTypes := fs.Bool("go-types", false, "add an x-go-type extension with the import path and name of the Go type to each struct schema")
+	archiveDir := fs.String("archive", "", "directory keeping a timestamped snapshot of each generated specification and an index.json, for diff -against archive:<dir>")
+	badgeFile := fs.String("badge", "", "shields.io endpoint JSON file to write a badge with the channel count, API version and validation status to")
+	reportFile := fs.String("report", "", "JSON file to write the run report to, with each distinct warning, its count and first location")
+	openAPINullable := fs.Bool("openapi-nullable", false, "describe pointer fields with OpenAPI-style nullable: true instead of a [type, null] type list")
+	noDescriptionFallback := fs.Bool("no-description-fallback", false, "keep only explicit @description and @channel.description values instead of falling back to function and payload type doc comments")
+	minimal := fs.Bool("minimal", false, "remove empty bindings, empty schema keywords, placeholder descriptions that repeat a name and operation messages lists naming the only message of their channel")
+	optimize := fs.String("optimize", "", "comma-separated optimizations of the generated specification: traits (hoist tags, bindings and security repeated by several operations into operation traits)")
+	keepOperationMessages := fs.Bool("keep-operation-messages", false, "with -minimal, keep the messages list of operations on single-message channels")
+	keepComments := fs.Bool("keep-comments", false, "keep the comments of existing YAML output files on the entries that are regenerated")
+	rulesetFile := fs.String("ruleset", "", "YAML ruleset file whose rules are evaluated against the generated specification")
+	strict := fs.Bool("strict", false, "with -ruleset, fail without writing any output when a rule of the error severity fails")
+	namingStrategy := fs.String("naming", "", "naming of generated channel, operation and message keys: camelCase, snake_case, kebab-case or go-type-name (default camelCase, or the config's naming)")
+	untaggedFields := fs.String("untagged-fields", "", "how exported struct fields without a json tag are documented: skip, or include (under the Go field name, as encoding/json writes them) (default skip, or the config's untagged_fields)")
+	merge := fs.Bool("merge", false, "treat each source directory as a separate service and list the services producing and consuming each channel and message as x-producers and x-consumers")
+	order := fs.String("order", asyncapi.OrderAlpha, "order of channels and operations: alpha (by key) or source (by first appearance in the code, file then line)")
+	allModules := fs.Bool("all-modules", false, "generate one specification per annotated module of the go.work workspace of the source directory, written to -modules-dir; with -merge, -output receives the merged workspace specification")
+	verifySubjects := fs.String("verify-subjects", "", "check the constant subjects, topics and queues passed to NATS, Kafka and AMQP clients by annotated functions against their @name: warn or error")
+	discover := fs.String("discover", "", "comma-separated clients whose calls in functions without annotations become draft operations: nats, kafka, watermill")
+	modulesDir := fs.String("modules-dir", ".", "with -all-modules, directory the specifications of the modules are written to, as <module path>.yaml")
 
 	if err := fs.Parse(os.Args[2:]); err != nil {
 		log.Fatalf("Failed to parse flags: %v\n", err)
 	}
 
-	if fs.NArg() < 1 {
+	if fs.NArg() < 1 && !*allModules {
 		fmt.Fprintf(os.Stderr, "Error: source directory is required\n")
-		fmt.Fprintf(os.Stderr, "Usage: asyncapi-doc generate [options] <source-directory>\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: asyncapi-doc generate [options] <source-directory>...\n")
+		fmt.Fprintf(os.Stderr, "       asyncapi-doc generate [options] git+<repository-url>[@<ref>] [<path>...]\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fs.PrintDefaults()
 		os.Exit(1)
 	}
 
-	codeFolder := fs.Arg(0)
+	files, err := outputFiles(outputs, *formats)
+	if err != nil {
+		log.Fatalf("Failed to resolve output files: %v\n", err)
+	}
+
+	optimizations := parseOptimizations(*optimize)
+	cfg := loadConfig(*configFile)
+	if *namingStrategy != "" {
+		cfg.Naming = *namingStrategy
+	}
+	if *untaggedFields != "" {
+		cfg.UntaggedFields = *untaggedFields
+	}
+
+	sources := fs.Args()
+	if len(sources) == 0 {
+		// -all-modules defaults to the workspace of the working directory
+		sources = []string{"."}
+	}
+	codeFolders, cleanup, err := resolveSource(sources, *verbose)
+	if err != nil {
+		log.Fatalf("Failed to fetch source: %v\n", err)
+	}
 
 	if *verbose {
-		fmt.Printf("Parsing source directory: %s\n", codeFolder)
-		fmt.Printf("Output file: %s\n", *output)
+		fmt.Printf("Parsing source directories: %s\n", strings.Join(codeFolders, ", "))
+		fmt.Printf("Output files: %s\n", strings.Join(files, ", "))
 		if *exclude != "" {
 			fmt.Printf("Excluding directories: %s\n", *exclude)
 		}
 	}
 
-	yaml, err := asyncapi.ParseFolder(codeFolder, *verbose, *exclude)
+	var commit string
+	if *archiveDir != "" {
+		commit = sourceCommit(codeFolders[0])
+	}
+
+	var report asyncapi.Report
+	opts := asyncapi.Options{
+		Verbose:     *verbose,
+		ExcludeDirs: *exclude,
+		SchemaLimits: asyncapi.SchemaLimits{
+			MaxDepth:      *maxDepth,
+			MaxProperties: *maxProperties,
+		},
+		RefNested:             *refNested,
+		TypeMappings:          cfg.TypeMappings,
+		GoTypes:               *goTypes,
+		UntaggedFields:        cfg.UntaggedFields,
+		OpenAPINullable:       *openAPINullable,
+		NoDescriptionFallback: *noDescriptionFallback,
+		ServerNaming:          cfg.ServerNaming,
+		Naming:                cfg.Naming,
+		BindingVersions:       cfg.BindingVersions,
+		RegistrationTables:    registrationTables(cfg.RegistrationTables),
+		Info:                  cfg.Info,
+		Servers:               cfg.Servers,
+		Order:                 *order,
+		Merge:                 *merge,
+		VerifySubjects:        *verifySubjects,
+		Report:                &report,
+	}
+	if *discover != "" {
+		opts.Discover = strings.Split(*discover, ",")
+	}
+
+	if *allModules {
+		checkAllModulesFlags(fs)
+		workspace, err := asyncapi.ParseWorkspace(codeFolders[0], opts)
+		cleanup()
+		if err != nil {
+			log.Fatalf("Failed to parse workspace: %v\n", err)
+		}
+		process := func(doc *spec3.AsyncAPI) {
+			if *minimal {
+				asyncapi.Minimize(doc, asyncapi.MinimizeOptions{KeepOperationMessages: *keepOperationMessages})
+			}
+			optimizeSpec(doc, optimizations, *verbose)
+		}
+		writeWorkspace(workspace, *modulesDir, *formats, files, process, yamlOptions(cfg, *keepComments), *verbose)
+		fmt.Printf("✓ AsyncAPI specifications of %d module(s) generated successfully!\n", len(workspace.Modules))
+		return
+	}
+
+	doc, err := asyncapi.ParseFoldersDocument(codeFolders, opts)
+	cleanup()
 	if err != nil {
 		log.Fatalf("Failed to parse folder: %v\n", err)
 	}
 
-	if *verbose {
-		fmt.Printf("Writing output to: %s\n", *output)
+	if *reportFile != "" {
+		writeReport(&report, *reportFile, *verbose)
 	}
 
-	if err := os.WriteFile(*output, yaml, 0o600); err != nil {
-		log.Fatalf("Failed to write output file: %v\n", err)
+	if *minimal {
+		asyncapi.Minimize(doc, asyncapi.MinimizeOptions{KeepOperationMessages: *keepOperationMessages})
+	}
+
+	optimizeSpec(doc, optimizations, *verbose)
+
+	var lintResults []lint.Result
+	if *rulesetFile != "" {
+		lintResults = lintSpec(doc, *rulesetFile, *strict)
+	} else if *strict {
+		log.Fatalf("-strict requires -ruleset\n")
+	}
+
+	yamlOpts := yamlOptions(cfg, *keepComments)
+	for _, file := range files {
+		writeSpec(doc, file, yamlOpts, *verbose)
+	}
+
+	if *archiveDir != "" {
+		archiveSpec(doc, *archiveDir, commit, yamlOpts, *verbose)
+	}
+
+	if *badgeFile != "" {
+		writeBadge(doc, &report, lintResults, *badgeFile, *verbose)
+	}
+
+	if *redact != "" {
+		// The internal spec is already written, so redaction can modify the document in place
+		asyncapi.Redact(doc, strings.Split(*redact, ","))
+		if *publicOutput != "" {
+			writeSpec(doc, *publicOutput, yamlOpts, *verbose)
+		} else {
+			for _, file := range files {
+				writeSpec(doc, publicOutputFile(file), yamlOpts, *verbose)
+			}
+		}
 	}
 
 	fmt.Println("✓ AsyncAPI specification generated successfully!")
 }
 
+// loadConfig reads the -config file, or returns the defaults when none is given.
+func loadConfig(path string) *config.Config {
+	if path == "" {
+		return config.DefaultConfig()
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v\n", err)
+	}
+	return cfg
+}
+
+// registrationTables converts the registration tables of the config file.
+func registrationTables(tables []config.RegistrationTable) []asyncapi.RegistrationTable {
+	converted := make([]asyncapi.RegistrationTable, len(tables))
+	for i, table := range tables {
+		converted[i] = asyncapi.RegistrationTable(table)
+	}
+	return converted
+}
+
 func printUsage() {
 	fmt.Printf(`asyncapi-doc - AsyncAPI Documentation Generator CLI Tool (v%s)
 
@@ -101,11 +278,28 @@ Usage:
 
 Available Commands:
   generate    Generate AsyncAPI specification from Go code
+  diff        Compare two specifications and detect breaking changes
+  lint        Check specifications against a governance ruleset
+  gen-schemas Generate standalone payload schemas (JSON Schema or TypeScript)
+  sign        Sign a specification with a cosign-compatible signature
+  lsp         Serve annotation diagnostics and hover to editors over LSP (stdio)
+  index       Write a JSON index of the annotations and their diagnostics
+  annotate    Insert annotation stubs for unannotated broker calls, or write them as a patch
   version     Print version information
   help        Show this help message
 
 Examples:
   asyncapi-doc generate -output ./asyncapi.yaml ./example/nats
+  asyncapi-doc generate -output ./asyncapi.yaml -output ./asyncapi.json ./example/nats
+  asyncapi-doc generate -output ./service.yaml git+https://github.com/org/service@main ./cmd/service
+  asyncapi-doc generate -all-modules -modules-dir ./api
+  asyncapi-doc diff old.yaml new.yaml
+  asyncapi-doc gen-schemas -lang typescript -output ./web/types ./example/nats
+  asyncapi-doc diff -against git:HEAD ./asyncapi.yaml
+  asyncapi-doc index -output ./asyncapi-index.json ./...
+  asyncapi-doc annotate -output ./annotations.patch ./...
+  asyncapi-doc lint -ruleset ./asyncapi-rules.yaml ./asyncapi.yaml
+  asyncapi-doc sign -key cosign.key -attestation ./asyncapi.intoto.json ./asyncapi.yaml
 
 Use "asyncapi-doc <command> -h" for more information about a command.
 `, Version)
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// singleSpecFlags are the generate flags that apply to a single
+// specification, which -all-modules does not support.
+var singleSpecFlags = []string{"archive", "badge", "redact", "public-output", "report", "ruleset", "strict"}
+
+// checkAllModulesFlags stops when a flag that -all-modules does not support
+// is set.
+func checkAllModulesFlags(fs *flag.FlagSet) {
+	fs.Visit(func(f *flag.Flag) {
+		for _, name := range singleSpecFlags {
+			if f.Name == name {
+				log.Fatalf("-%s cannot be used with -all-modules\n", name)
+			}
+		}
+	})
+}
+
+// writeWorkspace writes the specification of each module of the workspace
+// to dir, named after its module path, e.g. dir/github.com/acme/orders.yaml,
+// once per format. The merged specification, when there is one, is written
+// to the -output files.
+func writeWorkspace(workspace *asyncapi.Workspace, dir, formats string, outputs []string,
+	process func(*spec3.AsyncAPI), yamlOpts yamlOutput, verbose bool,
+) {
+	for _, module := range workspace.Modules {
+		files, err := outputFiles([]string{filepath.Join(dir, filepath.FromSlash(module.Path)+".yaml")}, formats)
+		if err != nil {
+			log.Fatalf("Failed to resolve output files: %v\n", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(files[0]), 0o750); err != nil {
+			log.Fatalf("Failed to create output directory: %v\n", err)
+		}
+		process(module.Document)
+		for _, file := range files {
+			writeSpec(module.Document, file, yamlOpts, verbose)
+		}
+	}
+
+	if workspace.Merged == nil {
+		return
+	}
+	process(workspace.Merged)
+	for _, file := range outputs {
+		writeSpec(workspace.Merged, file, yamlOpts, verbose)
+	}
+	if verbose {
+		fmt.Printf("Merged %d module(s) of %s\n", len(workspace.Modules), workspace.File)
+	}
+}
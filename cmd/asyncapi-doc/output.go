@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+	"github.com/fedanant/asyncapi-doc/internal/config"
+	"github.com/fedanant/asyncapi-doc/internal/lint"
+)
+
+// defaultOutput is written when no -output flag is given.
+const defaultOutput = "./asyncapi.yaml"
+
+// formatExtensions maps the supported -formats values to file extensions.
+var formatExtensions = map[string]string{
+	"yaml": ".yaml",
+	"yml":  ".yml",
+	"json": ".json",
+}
+
+// stringList collects the values of a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// outputFiles returns every file to write. With formats set, each output is
+// written once per format, replacing its extension.
+func outputFiles(outputs []string, formats string) ([]string, error) {
+	if len(outputs) == 0 {
+		outputs = []string{defaultOutput}
+	}
+	if formats == "" {
+		return outputs, nil
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, output := range outputs {
+		base := strings.TrimSuffix(output, filepath.Ext(output))
+		for _, format := range strings.Split(formats, ",") {
+			format = strings.ToLower(strings.TrimSpace(format))
+			ext, ok := formatExtensions[format]
+			if !ok {
+				return nil, fmt.Errorf("unsupported format %q (supported: yaml, json)", format)
+			}
+			if file := base + ext; !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
+
+// writeSpec writes the document to output, as JSON for .json files and YAML,
+// formatted with yamlOpts, otherwise.
+func writeSpec(doc *spec3.AsyncAPI, output string, yamlOpts yamlOutput, verbose bool) {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(output), ".json") {
+		data, err = doc.MarshalJSONIndent()
+		if err != nil {
+			log.Fatalf("Failed to marshal JSON: %v\n", err)
+		}
+	} else {
+		opts := yamlOpts.YAMLOptions
+		if yamlOpts.keepComments {
+			// A missing file has no comments to keep
+			if previous, err := os.ReadFile(output); err == nil {
+				opts.Previous = previous
+			}
+		}
+		data, err = doc.MarshalYAMLWith(opts)
+		if err != nil {
+			log.Fatalf("Failed to marshal YAML: %v\n", err)
+		}
+	}
+
+	if verbose {
+		fmt.Printf("Writing output to: %s\n", output)
+	}
+
+	if err := os.WriteFile(output, data, 0o600); err != nil {
+		log.Fatalf("Failed to write output file: %v\n", err)
+	}
+}
+
+// yamlOutput is how YAML output files are written.
+type yamlOutput struct {
+	spec3.YAMLOptions
+	// keepComments keeps the comments of the file being overwritten.
+	keepComments bool
+}
+
+// yamlOptions returns the YAML formatting of the configuration.
+func yamlOptions(cfg *config.Config, keepComments bool) yamlOutput {
+	return yamlOutput{
+		YAMLOptions: spec3.YAMLOptions{
+			Indent:      cfg.YAML.Indent,
+			LineWidth:   cfg.YAML.LineWidth,
+			FlowMaxKeys: cfg.YAML.FlowMaxKeys,
+		},
+		keepComments: keepComments,
+	}
+}
+
+// writeReport writes the report of the run to output as JSON.
+func writeReport(report *asyncapi.Report, output string, verbose bool) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal report: %v\n", err)
+	}
+
+	if verbose {
+		fmt.Printf("Writing report to: %s\n", output)
+	}
+
+	if err := os.WriteFile(output, append(data, '\n'), 0o600); err != nil {
+		log.Fatalf("Failed to write report file: %v\n", err)
+	}
+}
+
+// parseOptimizations splits and validates the -optimize value.
+func parseOptimizations(value string) []string {
+	if value == "" {
+		return nil
+	}
+	optimizations := strings.Split(value, ",")
+	for i := range optimizations {
+		optimizations[i] = strings.TrimSpace(optimizations[i])
+	}
+	if err := asyncapi.ValidOptimizations(optimizations); err != nil {
+		log.Fatalf("Failed to parse -optimize: %v\n", err)
+	}
+	return optimizations
+}
+
+// optimizeSpec applies the -optimize optimizations to the document.
+func optimizeSpec(doc *spec3.AsyncAPI, optimizations []string, verbose bool) {
+	for _, optimization := range optimizations {
+		if optimization == asyncapi.OptimizeTraits {
+			traits := asyncapi.HoistOperationTraits(doc)
+			if verbose {
+				fmt.Printf("Hoisted %d operation trait(s): %s\n", len(traits), strings.Join(traits, ", "))
+			}
+		}
+	}
+}
+
+// writeBadge writes the shields.io badge of the document to output. Lint
+// results of the error severity make the document invalid; other results
+// count as warnings, like the warnings of the run.
+func writeBadge(doc *spec3.AsyncAPI, report *asyncapi.Report, lintResults []lint.Result, output string, verbose bool) {
+	warnings, errors := report.WarningCount(), 0
+	for _, result := range lintResults {
+		switch result.Severity {
+		case lint.Error:
+			errors++
+		case lint.Warn:
+			warnings++
+		case lint.Info, lint.Off:
+		}
+	}
+
+	data, err := json.MarshalIndent(asyncapi.NewBadge(doc, warnings, errors), "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal badge: %v\n", err)
+	}
+
+	if verbose {
+		fmt.Printf("Writing badge to: %s\n", output)
+	}
+
+	if err := os.WriteFile(output, append(data, '\n'), 0o600); err != nil {
+		log.Fatalf("Failed to write badge file: %v\n", err)
+	}
+}
+
+// publicOutputFile returns the redacted counterpart of output, e.g. api.public.yaml.
+func publicOutputFile(output string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + ".public" + ext
+}
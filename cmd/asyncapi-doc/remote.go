@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// remoteSourcePrefix marks a git repository given as the source, e.g.
+// git+https://github.com/org/service@main.
+const remoteSourcePrefix = "git+"
+
+// parseRemoteSource splits "git+<url>[@<ref>]" into the clone URL and ref.
+// The ref is only taken from an "@" after the last slash, so user info such as
+// git@github.com stays part of the URL.
+//
+//nolint:gocritic // Named returns would reduce readability here
+func parseRemoteSource(source string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(source, remoteSourcePrefix)
+	if !ok || rest == "" {
+		return "", "", false
+	}
+	if idx := strings.LastIndex(rest, "@"); idx > strings.LastIndex(rest, "/") {
+		return rest[:idx], rest[idx+1:], true
+	}
+	return rest, "", true
+}
+
+// resolveSource returns the directories to parse for the positional
+// arguments: either local directories, or paths inside a shallow clone of a
+// remote repository given as the first argument. cleanup removes the clone and
+// must be called once parsing is done.
+func resolveSource(args []string, verbose bool) ([]string, func(), error) {
+	noop := func() {}
+	url, ref, remote := parseRemoteSource(args[0])
+	if !remote {
+		return args, noop, nil
+	}
+
+	subdirs, err := remoteSubdirs(args[1:])
+	if err != nil {
+		return nil, noop, err
+	}
+	cloneArgs, err := gitCloneArgs(url, ref)
+	if err != nil {
+		return nil, noop, err
+	}
+
+	dir, err := os.MkdirTemp("", "asyncapi-doc-")
+	if err != nil {
+		return nil, noop, fmt.Errorf("failed to create clone directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	cloneArgs = append(cloneArgs, dir)
+
+	if verbose {
+		fmt.Printf("Cloning %s into %s\n", args[0], dir)
+	}
+
+	//nolint:gosec // Repository URL and ref are provided by the user invoking the CLI
+	cmd := exec.Command("git", cloneArgs...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("git clone %s: %w: %s", url, err, strings.TrimSpace(stderr.String()))
+	}
+
+	dirs := make([]string, len(subdirs))
+	for i, subdir := range subdirs {
+		dirs[i] = filepath.Join(dir, subdir)
+	}
+	return dirs, cleanup, nil
+}
+
+// remoteSubdirs returns the paths to parse inside a cloned repository, the
+// repository root when there are none. Paths must stay inside the clone.
+func remoteSubdirs(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{"."}, nil
+	}
+	for _, path := range paths {
+		cleaned := filepath.Clean(path)
+		if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("path %q must be relative to the repository root", path)
+		}
+	}
+	return paths, nil
+}
+
+// gitCloneArgs returns the arguments of a shallow git clone of url at ref,
+// the clone directory excepted. A url or ref starting with "-" is rejected
+// rather than parsed as a git option.
+func gitCloneArgs(url, ref string) ([]string, error) {
+	if strings.HasPrefix(url, "-") {
+		return nil, fmt.Errorf("invalid repository URL %q", url)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid ref %q", ref)
+	}
+	args := []string{"clone", "--depth", "1", "--quiet"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	return append(args, "--", url), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemoteSubdirs(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "repository root", paths: nil, want: []string{"."}},
+		{name: "every package", paths: []string{"./..."}, want: []string{"./..."}},
+		{name: "dot-prefixed name", paths: []string{"..config", "services/orders"}, want: []string{"..config", "services/orders"}},
+		{name: "parent", paths: []string{".."}, wantErr: true},
+		{name: "outside", paths: []string{"services/../../etc"}, wantErr: true},
+		{name: "absolute", paths: []string{"/etc"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := remoteSubdirs(tt.paths)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("remoteSubdirs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("remoteSubdirs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitCloneArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "default branch",
+			source: "git+https://github.com/org/service",
+			want:   []string{"clone", "--depth", "1", "--quiet", "--", "https://github.com/org/service"},
+		},
+		{
+			name:   "ref",
+			source: "git+git@github.com:org/service@v1.2.0",
+			want:   []string{"clone", "--depth", "1", "--quiet", "--branch", "v1.2.0", "--", "git@github.com:org/service"},
+		},
+		{name: "option as url", source: "git+--upload-pack=touch /tmp/pwned", wantErr: true},
+		{name: "option as ref", source: "git+https://github.com/org/service@--config=core.sshCommand=sh", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, ref, ok := parseRemoteSource(tt.source)
+			if !ok {
+				t.Fatalf("parseRemoteSource(%q) is not remote", tt.source)
+			}
+			got, err := gitCloneArgs(url, ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gitCloneArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("gitCloneArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
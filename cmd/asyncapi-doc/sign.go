@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+	"github.com/fedanant/asyncapi-doc/internal/sign"
+)
+
+// cosignPasswordEnv holds the password of an encrypted cosign key, as in cosign.
+const cosignPasswordEnv = "COSIGN_PASSWORD"
+
+func signCommand() {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	key := fs.String("key", "", "PEM private key: a cosign key (password from "+cosignPasswordEnv+") or an unencrypted PKCS#8/EC key")
+	keyless := fs.Bool("keyless", false, "sign keyless with Sigstore by running cosign (requires cosign on PATH)")
+	output := fs.String("output", "", "output file for the signature (default: <spec>.sig, or <spec>.bundle with -keyless)")
+	attestation := fs.String("attestation", "", "also write a signed in-toto provenance attestation of the spec to this file")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v\n", err)
+	}
+
+	if fs.NArg() != 1 || (*key == "") != *keyless {
+		fmt.Fprintf(os.Stderr, "Error: a spec file and exactly one of -key or -keyless are required\n")
+		fmt.Fprintf(os.Stderr, "Usage: asyncapi-doc sign -key <cosign.key> [options] <spec>\n")
+		fmt.Fprintf(os.Stderr, "       asyncapi-doc sign -keyless [options] <spec>\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	specFile := fs.Arg(0)
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v\n", specFile, err)
+	}
+	doc, err := spec3.Parse(data)
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v\n", specFile, err)
+	}
+	predicate := sign.Predicate{
+		AsyncAPI:  doc.AsyncAPI,
+		ID:        doc.ID,
+		Title:     doc.Info.Title,
+		Version:   doc.Info.Version,
+		Generator: "asyncapi-doc " + Version,
+	}
+
+	if *keyless {
+		if *output == "" {
+			*output = specFile + ".bundle"
+		}
+		if err := signKeyless(specFile, *output, *attestation, predicate); err != nil {
+			log.Fatalf("Failed to sign %s: %v\n", specFile, err)
+		}
+		return
+	}
+
+	if *output == "" {
+		*output = specFile + ".sig"
+	}
+	keyData, err := os.ReadFile(*key)
+	if err != nil {
+		log.Fatalf("Failed to read key: %v\n", err)
+	}
+	signer, err := sign.LoadPrivateKey(keyData, []byte(os.Getenv(cosignPasswordEnv)))
+	if err != nil {
+		log.Fatalf("Failed to load key: %v\n", err)
+	}
+
+	sig, err := sign.Blob(signer, data)
+	if err != nil {
+		log.Fatalf("Failed to sign %s: %v\n", specFile, err)
+	}
+	if err := os.WriteFile(*output, sig, 0o600); err != nil {
+		log.Fatalf("Failed to write signature: %v\n", err)
+	}
+	fmt.Printf("✓ Signature written to %s\n", *output)
+
+	if *attestation != "" {
+		envelope, err := sign.Attestation(signer, filepath.Base(specFile), data, predicate)
+		if err != nil {
+			log.Fatalf("Failed to attest %s: %v\n", specFile, err)
+		}
+		if err := os.WriteFile(*attestation, envelope, 0o600); err != nil {
+			log.Fatalf("Failed to write attestation: %v\n", err)
+		}
+		fmt.Printf("✓ Attestation written to %s\n", *attestation)
+	}
+}
+
+// signKeyless delegates to cosign, which obtains a short-lived certificate
+// from Sigstore and records the signature in the transparency log.
+func signKeyless(specFile, output, attestation string, predicate sign.Predicate) error {
+	if err := runCosign("sign-blob", "--yes", "--bundle", output, specFile); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Signature bundle written to %s\n", output)
+
+	if attestation == "" {
+		return nil
+	}
+	predicateFile, err := os.CreateTemp("", "asyncapi-doc-predicate-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create predicate file: %w", err)
+	}
+	defer func() { _ = os.Remove(predicateFile.Name()) }()
+	if err := json.NewEncoder(predicateFile).Encode(predicate); err != nil {
+		_ = predicateFile.Close()
+		return fmt.Errorf("failed to write predicate file: %w", err)
+	}
+	if err := predicateFile.Close(); err != nil {
+		return fmt.Errorf("failed to write predicate file: %w", err)
+	}
+
+	if err := runCosign("attest-blob", "--yes", "--predicate", predicateFile.Name(), "--type", sign.PredicateType, "--bundle", attestation, specFile); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Attestation bundle written to %s\n", attestation)
+	return nil
+}
+
+func runCosign(args ...string) error {
+	//nolint:gosec // Arguments are provided by the user invoking the CLI
+	cmd := exec.Command("cosign", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cosign %s: %w", args[0], err)
+	}
+	return nil
+}
@@ -208,6 +208,8 @@ func (s *Service) SubscribeToOrderEvents(ctx context.Context) {
 // @name user.get
 // @summary Get User Request
 // @description Sends a request to retrieve user details by ID and waits for response
+// @message.summary Get User Request
+// @message.description Identifies the user whose details are requested
 // @payload GetUserRequest
 // @response GetUserResponse
 func (s *Service) RequestGetUser(userID string) (*GetUserResponse, error) {
@@ -243,6 +245,8 @@ func (s *Service) RequestGetUser(userID string) (*GetUserResponse, error) {
 // @name user.get
 // @summary Get User Handler
 // @description Handles requests to retrieve user details
+// @message.summary Get User Request
+// @message.description Identifies the user whose details are requested
 // @payload GetUserRequest
 // @response GetUserResponse
 func (s *Service) SubscribeToGetUser(ctx context.Context) {
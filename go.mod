@@ -4,6 +4,13 @@ go 1.24.0
 
 require (
 	github.com/modern-go/reflect2 v1.0.2
-	golang.org/x/text v0.32.0
+	golang.org/x/crypto v0.45.0
+	golang.org/x/mod v0.30.0
+	golang.org/x/tools v0.39.0
 	gopkg.in/yaml.v3 v3.0.1
 )
+
+require (
+	golang.org/x/sync v0.19.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+)
@@ -0,0 +1,159 @@
+// Package archive keeps timestamped snapshots of generated specifications in a
+// directory, with an index.json listing them, so specs can be compared with
+// any earlier run without external storage.
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IndexFile is the name of the index of an archive directory.
+const IndexFile = "index.json"
+
+// timeFormat formats the creation time in snapshot file names.
+const timeFormat = "20060102T150405Z"
+
+// Snapshot is an archived specification.
+type Snapshot struct {
+	// File is the name of the snapshot in the archive directory, e.g.
+	// "asyncapi-20261016T120000Z-1a2b3c4.yaml".
+	File    string    `json:"file"`
+	Created time.Time `json:"created"`
+	// Commit is the revision of the sources the spec was generated from.
+	Commit string `json:"commit,omitempty"`
+	// Version is the info.version of the spec.
+	Version string `json:"version,omitempty"`
+	SHA256  string `json:"sha256"`
+}
+
+// Index lists the snapshots of an archive, oldest first.
+type Index struct {
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// Load reads the index of the archive in dir. A missing index is empty.
+func Load(dir string) (*Index, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse archive index: %w", err)
+	}
+	return &index, nil
+}
+
+// Add stores data as a new snapshot in dir and records it in the index,
+// creating the directory when needed. The file name is built from the
+// creation time and commit of snapshot and the extension ext. It reports
+// false, storing nothing, when data is identical to the latest snapshot.
+func Add(dir string, data []byte, ext string, snapshot Snapshot) (Snapshot, bool, error) {
+	index, err := Load(dir)
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+	sum := sha256.Sum256(data)
+	snapshot.SHA256 = hex.EncodeToString(sum[:])
+	if n := len(index.Snapshots); n > 0 && index.Snapshots[n-1].SHA256 == snapshot.SHA256 {
+		return index.Snapshots[n-1], false, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return Snapshot{}, false, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	base := "asyncapi-" + snapshot.Created.UTC().Format(timeFormat)
+	if snapshot.Commit != "" {
+		base += "-" + snapshot.Commit
+	}
+	snapshot.File = base + ext
+	for i := 2; fileExists(filepath.Join(dir, snapshot.File)); i++ {
+		snapshot.File = base + "-" + strconv.Itoa(i) + ext
+	}
+	if err := os.WriteFile(filepath.Join(dir, snapshot.File), data, 0o600); err != nil {
+		return Snapshot{}, false, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	index.Snapshots = append(index.Snapshots, snapshot)
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("failed to marshal archive index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, IndexFile), append(indexData, '\n'), 0o600); err != nil {
+		return Snapshot{}, false, fmt.Errorf("failed to write archive index: %w", err)
+	}
+	return snapshot, true, nil
+}
+
+// Find returns the snapshot named by ref: "" or "latest" for the latest one,
+// "latest~N" for the Nth before it, or else the latest snapshot whose file
+// name is ref or whose commit or creation time (as in file names, e.g.
+// "20261016") starts with ref.
+func (idx *Index) Find(ref string) (Snapshot, error) {
+	n := len(idx.Snapshots)
+	if n == 0 {
+		return Snapshot{}, errors.New("archive has no snapshots")
+	}
+	if ref == "" || ref == "latest" {
+		return idx.Snapshots[n-1], nil
+	}
+	if back, ok := strings.CutPrefix(ref, "latest~"); ok {
+		i, err := strconv.Atoi(back)
+		if err != nil || i < 0 {
+			return Snapshot{}, fmt.Errorf("invalid snapshot reference %q", ref)
+		}
+		if i >= n {
+			return Snapshot{}, fmt.Errorf("snapshot %q not found: archive has %d snapshot(s)", ref, n)
+		}
+		return idx.Snapshots[n-1-i], nil
+	}
+	for i := n - 1; i >= 0; i-- {
+		snapshot := idx.Snapshots[i]
+		if snapshot.File == ref ||
+			(snapshot.Commit != "" && strings.HasPrefix(snapshot.Commit, ref)) ||
+			strings.HasPrefix(snapshot.Created.UTC().Format(timeFormat), ref) {
+			return snapshot, nil
+		}
+	}
+	return Snapshot{}, fmt.Errorf("snapshot %q not found", ref)
+}
+
+// Read returns the content of the snapshot of the archive in dir named by
+// ref, as resolved by Find.
+func Read(dir, ref string) ([]byte, Snapshot, error) {
+	index, err := Load(dir)
+	if err != nil {
+		return nil, Snapshot{}, err
+	}
+	snapshot, err := index.Find(ref)
+	if err != nil {
+		return nil, Snapshot{}, err
+	}
+	// The index may have been edited, so its file names must stay in dir
+	if f := snapshot.File; f == "" || f == "." || f == ".." || filepath.Base(f) != f {
+		return nil, Snapshot{}, fmt.Errorf("invalid snapshot file %q in archive index", f)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, snapshot.File))
+	if err != nil {
+		return nil, Snapshot{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return data, snapshot, nil
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
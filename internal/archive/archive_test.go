@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAddAndFind(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	day := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	adds := []struct {
+		data      string
+		snapshot  Snapshot
+		wantFile  string
+		wantAdded bool
+	}{
+		{"v1", Snapshot{Created: day, Commit: "1a2b3c4", Version: "1.0.0"}, "asyncapi-20261016T120000Z-1a2b3c4.yaml", true},
+		{"v1", Snapshot{Created: day.Add(time.Hour), Commit: "5d6e7f8"}, "asyncapi-20261016T120000Z-1a2b3c4.yaml", false},
+		{"v2", Snapshot{Created: day.Add(24 * time.Hour), Commit: "5d6e7f8"}, "asyncapi-20261017T120000Z-5d6e7f8.yaml", true},
+		{"v3", Snapshot{Created: day.Add(24 * time.Hour), Commit: "5d6e7f8"}, "asyncapi-20261017T120000Z-5d6e7f8-2.yaml", true},
+		{"v4", Snapshot{Created: day.Add(48 * time.Hour)}, "asyncapi-20261018T120000Z.yaml", true},
+	}
+	for _, add := range adds {
+		snapshot, added, err := Add(dir, []byte(add.data), ".yaml", add.snapshot)
+		if err != nil {
+			t.Fatalf("Add(%s) error = %v", add.data, err)
+		}
+		if snapshot.File != add.wantFile || added != add.wantAdded {
+			t.Errorf("Add(%s) = %s, %v, want %s, %v", add.data, snapshot.File, added, add.wantFile, add.wantAdded)
+		}
+	}
+
+	index, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(index.Snapshots) != 4 {
+		t.Fatalf("snapshots = %d, want 4", len(index.Snapshots))
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "", want: "v4"},
+		{ref: "latest", want: "v4"},
+		{ref: "latest~3", want: "v1"},
+		{ref: "1a2b", want: "v1"},
+		{ref: "5d6e7f8", want: "v3"},
+		{ref: "20261017", want: "v3"},
+		{ref: "asyncapi-20261017T120000Z-5d6e7f8.yaml", want: "v2"},
+		{ref: "latest~4", wantErr: true},
+		{ref: "latest~x", wantErr: true},
+		{ref: "ffff", wantErr: true},
+	}
+	for _, tt := range tests {
+		data, _, err := Read(dir, tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Read(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && string(data) != tt.want {
+			t.Errorf("Read(%q) = %q, want %q", tt.ref, data, tt.want)
+		}
+	}
+}
+
+func TestLoadEmpty(t *testing.T) {
+	dir := t.TempDir()
+	index, err := Load(dir)
+	if err != nil || len(index.Snapshots) != 0 {
+		t.Errorf("Load() = %v, %v, want an empty index", index, err)
+	}
+	if _, _, err := Read(dir, ""); err == nil {
+		t.Error("Read() of an empty archive error = nil, want error")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, IndexFile), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("Load() of an invalid index error = nil, want error")
+	}
+}
+
+func TestReadRejectsPathsOutsideArchive(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "archive")
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "secret.yaml"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "nested", "spec.yaml"), []byte("nested"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"../secret.yaml", "nested/spec.yaml", filepath.Join(root, "secret.yaml"), "..", ""} {
+		index := fmt.Sprintf(`{"snapshots": [{"file": %q, "created": "2026-10-16T12:00:00Z"}]}`, file)
+		if err := os.WriteFile(filepath.Join(dir, IndexFile), []byte(index), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if data, _, err := Read(dir, ""); err == nil {
+			t.Errorf("Read() of snapshot file %q = %q, want error", file, data)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package asyncapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// amqpValueKind is how an AMQP binding value is converted.
+type amqpValueKind int
+
+const (
+	amqpString amqpValueKind = iota
+	amqpBool
+	amqpInt
+	amqpList
+)
+
+// amqpBindingKeys maps the lowercase AMQP binding annotation keys to their
+// binding object, property path and value kind, as defined by the AMQP
+// bindings. "exchange" and "routingKey" keep their historical meaning: the
+// exchange name and the routing key of published messages.
+var amqpBindingKeys = map[string]struct {
+	level bindingLevel
+	path  []string
+	kind  amqpValueKind
+}{
+	"is":                  {channelBinding, []string{"is"}, amqpString},
+	"exchange":            {channelBinding, []string{"exchange", "name"}, amqpString},
+	"exchange.name":       {channelBinding, []string{"exchange", "name"}, amqpString},
+	"exchange.type":       {channelBinding, []string{"exchange", "type"}, amqpString},
+	"exchange.durable":    {channelBinding, []string{"exchange", "durable"}, amqpBool},
+	"exchange.autodelete": {channelBinding, []string{"exchange", "autoDelete"}, amqpBool},
+	"exchange.vhost":      {channelBinding, []string{"exchange", "vhost"}, amqpString},
+	"queue.name":          {channelBinding, []string{"queue", "name"}, amqpString},
+	"queue.durable":       {channelBinding, []string{"queue", "durable"}, amqpBool},
+	"queue.exclusive":     {channelBinding, []string{"queue", "exclusive"}, amqpBool},
+	"queue.autodelete":    {channelBinding, []string{"queue", "autoDelete"}, amqpBool},
+	"queue.vhost":         {channelBinding, []string{"queue", "vhost"}, amqpString},
+	"routingkey":          {operationBinding, []string{"cc"}, amqpList},
+	"cc":                  {operationBinding, []string{"cc"}, amqpList},
+	"bcc":                 {operationBinding, []string{"bcc"}, amqpList},
+	"deliverymode":        {operationBinding, []string{"deliveryMode"}, amqpInt},
+	"mandatory":           {operationBinding, []string{"mandatory"}, amqpBool},
+	"priority":            {operationBinding, []string{"priority"}, amqpInt},
+	"expiration":          {operationBinding, []string{"expiration"}, amqpInt},
+	"userid":              {operationBinding, []string{"userId"}, amqpString},
+	"timestamp":           {operationBinding, []string{"timestamp"}, amqpBool},
+	"ack":                 {operationBinding, []string{"ack"}, amqpBool},
+	"contentencoding":     {messageBinding, []string{"contentEncoding"}, amqpString},
+	"messagetype":         {messageBinding, []string{"messageType"}, amqpString},
+}
+
+// amqpExchangeTypes are the exchange types of the AMQP channel binding.
+var amqpExchangeTypes = map[string]bool{
+	"topic":   true,
+	"direct":  true,
+	"fanout":  true,
+	"default": true,
+	"headers": true,
+}
+
+// ParseBindingAMQP parses an AMQP 0-9-1 binding property into the channel,
+// operation or message binding it belongs to.
+func (operation *Operation) ParseBindingAMQP(key, value string) error {
+	value = strings.TrimSpace(value)
+	lowerKey := strings.ToLower(key)
+	if lowerKey == "bindingversion" {
+		operation.setBindingVersion("amqp", value)
+		return nil
+	}
+
+	property, ok := amqpBindingKeys[lowerKey]
+	if !ok {
+		return fmt.Errorf("unknown AMQP binding @binding.amqp.%s", key)
+	}
+	bindingValue, err := amqpBindingValue(lowerKey, property.kind, value)
+	if err != nil {
+		return fmt.Errorf("invalid @binding.amqp.%s %q: %w", key, value, err)
+	}
+
+	name := property.path[0]
+	if len(property.path) == 1 {
+		operation.setBinding(property.level, "amqp", name, bindingValue)
+		return nil
+	}
+	binding := bindingOf(&operation.ChannelBindings, "amqp")
+	object, _ := binding[name].(map[string]interface{})
+	if object == nil {
+		object = make(map[string]interface{})
+		binding[name] = object
+	}
+	object[property.path[1]] = bindingValue
+	return nil
+}
+
+// amqpBindingValue converts and checks an AMQP binding value.
+func amqpBindingValue(key string, kind amqpValueKind, value string) (interface{}, error) {
+	switch kind {
+	case amqpBool:
+		return strconv.ParseBool(value)
+	case amqpList:
+		return bindingList(value), nil
+	case amqpInt:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, errors.New("must be a non-negative integer")
+		}
+		if key == "deliverymode" && n != 1 && n != 2 {
+			return nil, errors.New("must be 1 (transient) or 2 (persistent)")
+		}
+		return n, nil
+	case amqpString:
+	}
+	switch key {
+	case "is":
+		if value != "queue" && value != "routingKey" {
+			return nil, errors.New("must be queue or routingKey")
+		}
+	case "exchange.type":
+		if !amqpExchangeTypes[value] {
+			return nil, errors.New("must be topic, direct, fanout, default or headers")
+		}
+	}
+	return value, nil
+}
+
+// applyAMQPChannelType sets the "is" of an AMQP channel binding that does not
+// annotate it: queue when only the queue is described, routingKey otherwise.
+func (operation *Operation) applyAMQPChannelType() {
+	binding, ok := operation.ChannelBindings["amqp"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if _, ok := binding["is"]; ok {
+		return
+	}
+	_, hasQueue := binding["queue"]
+	_, hasExchange := binding["exchange"]
+	if hasQueue && !hasExchange {
+		binding["is"] = "queue"
+	} else {
+		binding["is"] = "routingKey"
+	}
+}
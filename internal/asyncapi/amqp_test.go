@@ -0,0 +1,100 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBindingAMQP(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		level   bindingLevel
+		key     string
+		want    interface{}
+		wantErr bool
+	}{
+		{"exchange name", "@binding.amqp.exchange orders", channelBinding, "exchange", map[string]interface{}{"name": "orders"}, false},
+		{"exchange type", "@binding.amqp.exchange.type topic", channelBinding, "exchange", map[string]interface{}{"type": "topic"}, false},
+		{"invalid exchange type", "@binding.amqp.exchange.type broadcast", channelBinding, "", nil, true},
+		{"queue durable", "@binding.amqp.queue.durable true", channelBinding, "queue", map[string]interface{}{"durable": true}, false},
+		{"is", "@binding.amqp.is queue", channelBinding, "is", "queue", false},
+		{"invalid is", "@binding.amqp.is topic", channelBinding, "", nil, true},
+		{"routing key", "@binding.amqp.routingKey orders.placed", operationBinding, "cc", []interface{}{"orders.placed"}, false},
+		{"bcc", "@binding.amqp.bcc audit, archive", operationBinding, "bcc", []interface{}{"audit", "archive"}, false},
+		{"delivery mode", "@binding.amqp.deliveryMode 2", operationBinding, "deliveryMode", 2, false},
+		{"invalid delivery mode", "@binding.amqp.deliveryMode 3", operationBinding, "", nil, true},
+		{"expiration", "@binding.amqp.expiration 60000", operationBinding, "expiration", 60000, false},
+		{"invalid priority", "@binding.amqp.priority high", operationBinding, "", nil, true},
+		{"ack case", "@binding.AMQP.ack false", operationBinding, "ack", false, false},
+		{"message type", "@binding.amqp.messageType order.placed", messageBinding, "messageType", "order.placed", false},
+		{"unknown", "@binding.amqp.exchange.internal true", channelBinding, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := NewOperation()
+			err := operation.ParseComment(tt.comment, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			bindings := map[bindingLevel]map[string]interface{}{
+				channelBinding:   operation.ChannelBindings,
+				operationBinding: operation.Bindings,
+				messageBinding:   operation.MessageBindings,
+			}[tt.level]
+			amqp, _ := bindings["amqp"].(map[string]interface{})
+			if !reflect.DeepEqual(amqp[tt.key], tt.want) {
+				t.Errorf("%s = %#v, want %#v", tt.key, amqp[tt.key], tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOperationAMQPBindings(t *testing.T) {
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name orders.placed",
+		"@payload string",
+		"@binding.amqp.exchange.name orders",
+		"@binding.amqp.exchange.type topic",
+		"@binding.amqp.exchange.durable true",
+		"@binding.amqp.deliveryMode 2",
+		"@binding.amqp.bindingVersion 0.3.0",
+	}, nil)
+	parser.ParseOperation([]string{
+		"@type sub",
+		"@name billing.orders",
+		"@payload string",
+		"@binding.amqp.queue.name billing",
+		"@binding.amqp.queue.exclusive false",
+		"@binding.amqp.ack true",
+	}, nil)
+
+	exchange := parser.asyncAPI.Channels["ordersPlaced"].Bindings["amqp"]
+	want := map[string]interface{}{
+		"is":             "routingKey",
+		"exchange":       map[string]interface{}{"name": "orders", "type": "topic", "durable": true},
+		"bindingVersion": "0.3.0",
+	}
+	if !reflect.DeepEqual(exchange, want) {
+		t.Errorf("exchange channel binding = %v, want %v", exchange, want)
+	}
+	queue := parser.asyncAPI.Channels["billingOrders"].Bindings["amqp"]
+	want = map[string]interface{}{
+		"is":    "queue",
+		"queue": map[string]interface{}{"name": "billing", "exclusive": false},
+	}
+	if !reflect.DeepEqual(queue, want) {
+		t.Errorf("queue channel binding = %v, want %v", queue, want)
+	}
+
+	publish := parser.asyncAPI.Operations["publishOrdersPlaced"].Bindings["amqp"]
+	want = map[string]interface{}{"deliveryMode": 2, "bindingVersion": "0.3.0"}
+	if !reflect.DeepEqual(publish, want) {
+		t.Errorf("operation binding = %v, want %v", publish, want)
+	}
+}
@@ -0,0 +1,240 @@
+package asyncapi
+
+import (
+	"fmt"
+	"go/ast"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// annotatePlaceholder stands for the payload type of the stubs whose payload
+// could not be inferred, for the type checker to flag until it is filled in.
+const annotatePlaceholder = "TODO"
+
+// diffContext is the number of unchanged lines around the insertions of a
+// diff hunk.
+const diffContext = 3
+
+// AnnotatedFile is a source file with the annotation stubs Annotate inserts
+// into it.
+type AnnotatedFile struct {
+	// Path is the path of the file.
+	Path string
+	// Original is the content of the file.
+	Original   []byte
+	insertions []insertion
+}
+
+// insertion is a block of lines inserted before a line of a file.
+type insertion struct {
+	// line is the 1-based line the lines are inserted before.
+	line  int
+	lines []string
+}
+
+// Annotate finds the broker client calls that Options.Discover would
+// document as draft operations, for all clients when it is empty, and
+// returns the files of srcDir with annotation stubs for them, ordered by
+// path. A function holding a single operation gets @type, @name and
+// @payload annotations in its doc comment; other calls get call-site
+// directives on the line above them. Payload types that could not be
+// inferred are left as TODO.
+//
+// Calls registering several operations, such as a Watermill handler
+// consuming one topic and publishing to another, get a stub for the first
+// one; the others are reported as warnings.
+func Annotate(srcDir string, opts Options) ([]AnnotatedFile, error) {
+	if len(opts.Discover) == 0 {
+		opts.Discover = slices.Sorted(maps.Keys(discoverers))
+	}
+	p, err := configuredParser(opts)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := loadSourceDir(srcDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, src := range pkgs {
+		src.tc.configure(opts)
+		p.service = src.service
+		p.sourceDir = src.dir
+		parseComments(p, src.files, src.tc)
+	}
+
+	drafts := p.draftOperations()
+	perFunc := make(map[*ast.FuncDecl]int)
+	for _, d := range drafts {
+		perFunc[d.fn]++
+	}
+	files := make(map[string]*AnnotatedFile)
+	stubbed := make(map[ast.Node]bool)
+	for _, d := range drafts {
+		path := filepath.Join(d.sourceDir, d.file.name)
+		f, ok := files[path]
+		if !ok {
+			original, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			f = &AnnotatedFile{Path: path, Original: original}
+			files[path] = f
+		}
+
+		if perFunc[d.fn] == 1 && d.fn.Name.Name != "main" && d.fn.Name.Name != "init" {
+			f.insertions = append(f.insertions, insertion{
+				line:  d.tc.fset.Position(d.fn.Type.Func).Line,
+				lines: d.call.docStub(),
+			})
+			continue
+		}
+		if stubbed[d.node] {
+			p.warnings.location = d.location
+			p.warnings.warnf(warnDiscovery, "%s also registers an operation on %s; annotate it by hand", d.call.callee, d.call.subject)
+			continue
+		}
+		stubbed[d.node] = true
+		line := d.tc.fset.Position(d.node.Pos()).Line
+		f.insertions = append(f.insertions, insertion{
+			line:  line,
+			lines: d.call.directiveStub(lineIndent(f.Original, line)),
+		})
+	}
+	p.warnings.location = ""
+	if opts.Report != nil {
+		opts.Report.Warnings = p.warnings.list()
+	}
+
+	var annotated []AnnotatedFile
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		f := files[path]
+		sort.SliceStable(f.insertions, func(i, j int) bool { return f.insertions[i].line < f.insertions[j].line })
+		annotated = append(annotated, *f)
+	}
+	return annotated, nil
+}
+
+// docStub returns the annotations of the operation of d as the lines of a
+// doc comment.
+func (d discoveredCall) docStub() []string {
+	typeOperation := "sub"
+	if d.send {
+		typeOperation = "pub"
+	}
+	lines := []string{
+		"// " + annotation.Type + " " + typeOperation,
+		"// " + annotation.Name + " " + d.subject,
+		"// " + annotation.Payload + " " + d.stubPayload(),
+	}
+	if d.doc != "" {
+		lines = append(lines, "// "+annotation.Description+" "+strings.Join(strings.Fields(d.doc), " "))
+	}
+	for _, line := range d.annotations {
+		lines = append(lines, "// "+line)
+	}
+	return lines
+}
+
+// directiveStub returns the annotations of the operation of d as call-site
+// directives indented by indent.
+func (d discoveredCall) directiveStub(indent string) []string {
+	verb := "subscribe"
+	if d.send {
+		verb = "publish"
+	}
+	lines := []string{indent + directivePrefix + verb + " " + d.subject + " " + d.stubPayload()}
+	if d.doc != "" {
+		lines = append(lines, indent+directivePrefix+strings.TrimPrefix(annotation.Description, "@")+" "+strings.Join(strings.Fields(d.doc), " "))
+	}
+	for _, line := range d.annotations {
+		lines = append(lines, indent+directivePrefix+strings.TrimPrefix(line, "@"))
+	}
+	return lines
+}
+
+// stubPayload returns the payload type of d, or the placeholder.
+func (d discoveredCall) stubPayload() string {
+	if d.payload == "" {
+		return annotatePlaceholder
+	}
+	return d.payload
+}
+
+// lineIndent returns the leading whitespace of the 1-based line of src.
+func lineIndent(src []byte, line int) string {
+	lines := sourceLines(src)
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	text := lines[line-1]
+	return text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+}
+
+// sourceLines splits src into lines without their line breaks.
+func sourceLines(src []byte) []string {
+	return strings.Split(strings.TrimSuffix(string(src), "\n"), "\n")
+}
+
+// Stubs returns the number of annotation stubs inserted into the file.
+func (f AnnotatedFile) Stubs() int {
+	return len(f.insertions)
+}
+
+// Annotated returns the content of the file with the stubs inserted.
+func (f AnnotatedFile) Annotated() []byte {
+	lines := sourceLines(f.Original)
+	var b strings.Builder
+	next := 0
+	for i, line := range lines {
+		for next < len(f.insertions) && f.insertions[next].line == i+1 {
+			for _, inserted := range f.insertions[next].lines {
+				b.WriteString(inserted + "\n")
+			}
+			next++
+		}
+		b.WriteString(line + "\n")
+	}
+	return []byte(b.String())
+}
+
+// Diff returns the insertions as a unified diff of the file, named name in
+// its headers.
+func (f AnnotatedFile) Diff(name string) string {
+	lines := sourceLines(f.Original)
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+	offset := 0
+	for i := 0; i < len(f.insertions); {
+		// Insertions whose context lines overlap share a hunk
+		start := max(1, f.insertions[i].line-diffContext)
+		end := min(len(lines), f.insertions[i].line-1+diffContext)
+		j := i
+		for j+1 < len(f.insertions) && f.insertions[j+1].line-diffContext <= end+1 {
+			j++
+			end = min(len(lines), f.insertions[j].line-1+diffContext)
+		}
+
+		var hunk strings.Builder
+		added := 0
+		for line := start; line <= end; line++ {
+			for ; i <= j && f.insertions[i].line == line; i++ {
+				for _, inserted := range f.insertions[i].lines {
+					hunk.WriteString("+" + inserted + "\n")
+					added++
+				}
+			}
+			hunk.WriteString(" " + lines[line-1] + "\n")
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", start, end-start+1, start+offset, end-start+1+added)
+		b.WriteString(hunk.String())
+		offset += added
+		i = j + 1
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package asyncapi
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAnnotate(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, discoverSources)
+
+	files, err := Annotate(root, Options{})
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Stubs() != 5 {
+		t.Fatalf("Annotate() = %d files, want main.go with 5 stubs", len(files))
+	}
+	annotated := string(files[0].Annotated())
+	for _, want := range []string{
+		"\t//asyncapi:publish order.created Order\n\tnc.Publish(\"order.created\", data)\n",
+		"\t//asyncapi:publish order.{ID}.updated Order\n",
+		"// @type pub\n// @name order.get\n// @payload OrderQuery\n// @response Order\nfunc getOrder(",
+		"\t//asyncapi:subscribe payment.received Payment\n\t//asyncapi:binding.nats.queue billing\n\tnc.QueueSubscribe(",
+		"\t//asyncapi:subscribe order.*.shipped Order\n",
+	} {
+		if !strings.Contains(annotated, want) {
+			t.Errorf("annotated main.go lacks %q:\n%s", want, annotated)
+		}
+	}
+
+	// The stubs document the operations discovery found, so nothing is
+	// left to annotate
+	if err := os.WriteFile(files[0].Path, files[0].Annotated(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	files, err = Annotate(root, Options{})
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Annotate() after annotating = %d files, want none", len(files))
+	}
+	doc, err := ParseFS(DirFS(root), Options{})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if len(doc.Operations) != 7 {
+		t.Errorf("annotated operations = %d, want 7", len(doc.Operations))
+	}
+}
+
+func TestAnnotatedFileDiff(t *testing.T) {
+	f := AnnotatedFile{
+		Original: []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"),
+		insertions: []insertion{
+			{line: 2, lines: []string{"x"}},
+			{line: 4, lines: []string{"y", "z"}},
+			{line: 13, lines: []string{"w"}},
+		},
+	}
+	want := `--- a/f.go
++++ b/f.go
+@@ -1,6 +1,9 @@
+ a
++x
+ b
+ c
++y
++z
+ d
+ e
+ f
+@@ -10,4 +13,5 @@
+ j
+ k
+ l
++w
+ m
+`
+	if got := f.Diff("f.go"); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := string(f.Annotated()); got != "a\nx\nb\nc\ny\nz\nd\ne\nf\ng\nh\ni\nj\nk\nl\nw\nm\n" {
+		t.Errorf("Annotated() = %q", got)
+	}
+}
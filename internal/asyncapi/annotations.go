@@ -0,0 +1,40 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// parseRegisteredAnnotation hands the value of a registered annotation to its
+// Parse function and sets the extensions it returns on the operation, its
+// channel or its message. It reports whether the annotation is registered.
+func (operation *Operation) parseRegisteredAnnotation(attribute, value string) (bool, error) {
+	registered, ok := annotation.Registered(attribute)
+	if !ok {
+		return false, nil
+	}
+	extensions, err := registered.Parse(value)
+	if err != nil {
+		return true, fmt.Errorf("invalid %s: %w", registered.Name, err)
+	}
+	target := &operation.Extensions
+	//nolint:exhaustive // Registered annotations describe an operation, its channel or its message
+	switch registered.Target {
+	case annotation.TargetChannel:
+		target = &operation.ChannelExtensions
+	case annotation.TargetMessage:
+		target = &operation.MessageExtensions
+	}
+	for key, value := range extensions {
+		if !strings.HasPrefix(key, extensionPrefix) {
+			return true, fmt.Errorf("invalid %s: extension %s does not start with %s", registered.Name, key, extensionPrefix)
+		}
+		if *target == nil {
+			*target = make(map[string]interface{})
+		}
+		(*target)[key] = value
+	}
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package asyncapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+func TestParseRegisteredAnnotation(t *testing.T) {
+	registrations := []annotation.Annotation{
+		{
+			Name:        "@acme.team",
+			Target:      annotation.TargetChannel,
+			Description: "Team owning the channel",
+			Example:     "@acme.team payments",
+			Parse: func(value string) (map[string]interface{}, error) {
+				if value == "" {
+					return nil, errors.New("missing team")
+				}
+				return map[string]interface{}{"x-acme-team": value}, nil
+			},
+		},
+		{
+			Name:        "@acme.owner",
+			Target:      annotation.TargetOperation,
+			Description: "Owner of the operation",
+			Example:     "@acme.owner alice",
+			Parse: func(value string) (map[string]interface{}, error) {
+				return map[string]interface{}{"acme-owner": value}, nil
+			},
+		},
+	}
+	for _, registration := range registrations {
+		// The registry is global: a repeated test run finds them registered
+		if _, ok := annotation.Registered(registration.Name); ok {
+			continue
+		}
+		if err := annotation.Register(registration); err != nil {
+			t.Fatalf("Register(%s) error = %v", registration.Name, err)
+		}
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name order.placed", "@Acme.Team payments"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name order.shipped", "@acme.team"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name order.cancelled", "@acme.owner alice"}, nil)
+	if got := parser.asyncAPI.Channels["orderPlaced"].Extensions["x-acme-team"]; got != "payments" {
+		t.Errorf("x-acme-team = %v, want payments", got)
+	}
+	warnings := parser.warnings.list()
+	if len(warnings) != 2 ||
+		!strings.Contains(warnings[0].Message, "invalid @acme.team: missing team") ||
+		!strings.Contains(warnings[1].Message, "invalid @acme.owner: extension acme-owner does not start with x-") {
+		t.Errorf("warnings = %+v, want invalid @acme.team and @acme.owner", warnings)
+	}
+}
@@ -1,13 +1,20 @@
 package asyncapi
 
 import (
+	"go/types"
 	"time"
 )
 
 // TypeInfo holds information extracted from type checking.
 type TypeInfo struct {
 	Name   string
+	Doc    string
 	Fields []FieldInfo
+
+	obj *types.TypeName
+	// named is the type, an instance with its type arguments for generic
+	// types such as Page[Order].
+	named *types.Named
 }
 
 // FieldInfo holds information about a struct field.
@@ -15,11 +22,22 @@ type FieldInfo struct {
 	Name     string
 	Type     string
 	JSONTag  string
+	Tag      string
+	Doc      string
 	IsArray  bool
 	IsPtr    bool
 	ElemType string
 }
 
+// jsonName returns the name of the field in JSON: its json tag name, or the
+// Go field name.
+func (field FieldInfo) jsonName() string {
+	if field.JSONTag == "" {
+		return field.Name
+	}
+	return field.JSONTag
+}
+
 // CreateStructFromTypeInfo creates a struct instance based on TypeInfo.
 func CreateStructFromTypeInfo(typeInfo *TypeInfo) interface{} {
 	if typeInfo == nil {
@@ -30,8 +48,8 @@ func CreateStructFromTypeInfo(typeInfo *TypeInfo) interface{} {
 	result := make(map[string]interface{})
 
 	for _, field := range typeInfo.Fields {
-		jsonName := field.JSONTag
-		if jsonName == "" || jsonName == "-" {
+		jsonName := field.jsonName()
+		if jsonName == "-" {
 			continue
 		}
 
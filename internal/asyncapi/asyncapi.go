@@ -3,12 +3,17 @@ package asyncapi
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
-	"go/token"
+	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+	"golang.org/x/tools/go/packages"
 )
 
 type file struct {
@@ -49,161 +54,434 @@ func extractComment(cgrp *ast.CommentGroup) []string {
 
 func parseComments(p *Parser, files []file, tc *TypeChecker) {
 	for _, f := range files {
+		p.order.startFile()
+		if declaresMain(f.file) {
+			p.mainFiles = append(p.mainFiles, filepath.Join(p.sourceDir, f.name))
+		}
 		for _, c := range f.file.Comments {
+			p.warnings.location = commentLocation(f, c, tc)
+			p.order.at(c, tc)
 			comments := extractComment(c)
-			if isGeneralAPIComment(comments) {
+			if isGeneralAPIComment(comments) || isServiceDocComment(f.name, f.file, c, comments) {
 				p.ParseMain(comments)
 			} else {
+				if isOperationComment(comments) {
+					p.recordOperationFile(f)
+					p.verifyFunctionSubjects(f, c, comments, tc)
+					comments = withMarshalContentType(comments, documentedFunc(f, c), tc)
+				}
 				p.ParseOperation(comments, tc)
 			}
 		}
+		parseDirectives(p, f, tc)
+		parseRegistrationTables(p, f, tc)
+		discoverOperations(p, f, tc)
+	}
+	p.warnings.location = ""
+}
+
+// commentLocation returns the "file:line" position of a comment group or
+// other node, used to tell where a warning was first seen.
+func commentLocation(f file, c ast.Node, tc *TypeChecker) string {
+	if tc == nil || tc.fset == nil {
+		return f.name
 	}
+	return fmt.Sprintf("%s:%d", f.name, tc.fset.Position(c.Pos()).Line)
 }
 
 func isGeneralAPIComment(comments []string) bool {
 	for _, commentLine := range comments {
-		attribute := strings.ToLower(strings.Split(commentLine, " ")[0])
+		attribute := annotation.Canonical(strings.Split(commentLine, " ")[0])
 		switch attribute {
-		case titleAttr, versionAttr, protocolAttr, urlAttr, hostAttr:
+		case annotation.Title, annotation.Version, annotation.Protocol, annotation.URL, annotation.Host, annotation.MessageCommonHeader, annotation.InfoMarker:
 			return true
 		}
+		if _, _, ok := namedServerAttr(attribute); ok {
+			return true
+		}
+		for _, prefix := range []string{annotation.SecuritySchemePrefix, annotation.OperationTraitPrefix, annotation.MessageTraitPrefix} {
+			if _, ok := annotation.CutPrefix(attribute, prefix); ok {
+				return true
+			}
+		}
 	}
 	return false
 }
 
-//nolint:gocyclo // Complex folder parsing logic is intentionally centralized
-func ParseFolder(srcDir string, verbose bool, excludeDirs string) ([]byte, error) {
-	// Validate that the source directory exists
-	if _, err := os.Stat(srcDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("source directory does not exist: %s", srcDir)
-	}
-
-	pathExec, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get working directory: %w", err)
-	}
-	fset := token.NewFileSet()
+// recursiveSuffix marks a source path whose sub-directories are parsed as
+// well, as in "./...".
+const recursiveSuffix = "/..."
+
+// sourcePackage is a package loaded from one of the source directories.
+type sourcePackage struct {
+	dir   string
+	name  string
+	files []file
+	tc    *TypeChecker
+	// module is the path of the Go module holding the package, when known.
+	module string
+	// service is the merged service the package belongs to.
+	service string
+}
 
-	// Parse excluded directories list
-	excludeMap := make(map[string]bool)
-	if excludeDirs != "" {
-		for _, dir := range strings.Split(excludeDirs, ",") {
-			excludeMap[strings.TrimSpace(dir)] = true
+// loadMode loads the syntax and type information of the source packages.
+// Imported packages, including third-party ones, are type-checked from source
+// so payload types declared outside the annotated package resolve too. Source
+// is used rather than export data, which is tied to the installed toolchain.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+	packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo |
+	packages.NeedModule
+
+// loadPackages loads the packages in dirs with go/packages in module mode and
+// returns them ordered by directory, with the annotated files left out by
+// their build constraints. Directories without Go files are skipped.
+// overlay replaces the contents of files by their absolute path, as for
+// packages.Config.
+func loadPackages(root string, dirs []string, overlay map[string][]byte) ([]sourcePackage, []IgnoredFile, error) {
+	patterns := make([]string, len(dirs))
+	for i, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve directory %s: %w", dir, err)
 		}
+		patterns[i] = abs
 	}
 
-	// Create filter function to exclude directories
-	filter := func(info os.FileInfo) bool {
-		if info.IsDir() && excludeMap[info.Name()] {
-			if verbose {
-				fmt.Printf("Excluding directory: %s\n", info.Name())
-			}
-			return false
-		}
-		return true
+	cfg := &packages.Config{
+		Mode:    loadMode,
+		Dir:     root,
+		Overlay: overlay,
 	}
-
-	// Parse all files in the directory
-	pkgs, err := parser.ParseDir(fset, srcDir, filter, parser.ParseComments)
+	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse directory %s: %w", srcDir, err)
+		return nil, nil, fmt.Errorf("failed to load packages: %w", err)
 	}
 
-	// Collect all type checkers by package
-	typeCheckers := make(map[string]*TypeChecker)
+	var result []sourcePackage
+	var ignored []string
+	for _, pkg := range pkgs {
+		for _, name := range pkg.IgnoredFiles {
+			if !slices.Contains(ignored, name) {
+				ignored = append(ignored, name)
+			}
+		}
+		if len(pkg.Syntax) == 0 {
+			continue
+		}
+		fileNames := make(map[*ast.File]string)
+		for _, f := range pkg.Syntax {
+			fileNames[f] = pkg.Fset.Position(f.Package).Filename
+		}
+		src := sourcePackage{
+			dir:   filepath.Dir(fileNames[pkg.Syntax[0]]),
+			name:  pkg.Name,
+			files: sortedFiles(pkg.Syntax, fileNames),
+			tc:    newPackageTypeChecker(pkg),
+		}
+		if pkg.Module != nil {
+			src.module = pkg.Module.Path
+		}
+		result = append(result, src)
+	}
 
-	for pkgName, pkg := range pkgs {
-		// Convert ast.Package to []*ast.File
-		var files []*ast.File
-		for _, f := range pkg.Files {
-			files = append(files, f)
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].dir != result[j].dir {
+			return result[i].dir < result[j].dir
 		}
+		return result[i].name < result[j].name
+	})
+	return result, constrainedFiles(ignored), nil
+}
 
-		tc, err := NewTypeChecker(fset, files, pkgName)
+// sourceDirs returns "." and every directory below it in fsys that may hold
+// annotated sources, as slash-separated paths. Excluded directories are
+// skipped at every level and returned separately; the ones the go tool
+// ignores for "./..." patterns, vendor, testdata and names starting with "."
+// or "_", are skipped too.
+func sourceDirs(fsys fs.FS, exclude excludeList, verbose bool) (dirs, excluded []string, err error) {
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			if verbose {
-				fmt.Printf("Warning: failed to create type checker for package %s: %v\n", pkgName, err)
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != "." {
+			if exclude.matches(path) {
+				if verbose {
+					fmt.Printf("Excluding directory: %s\n", path)
+				}
+				excluded = append(excluded, path)
+				return fs.SkipDir
+			}
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return fs.SkipDir
 			}
-			continue
 		}
-		typeCheckers[pkgName] = tc
+		dirs = append(dirs, path)
+		return nil
+	})
+	return dirs, excluded, err
+}
+
+// Options configures ParseFS and ParseFolderDocument.
+type Options struct {
+	// Verbose prints progress information while parsing.
+	Verbose bool
+	// Recursive parses every sub-directory of the source root as well.
+	Recursive bool
+	// ExcludeDirs is a comma-separated list of directory names or globs
+	// skipped when parsing sub-directories.
+	ExcludeDirs string
+	// SchemaLimits bounds the size of generated payload schemas.
+	SchemaLimits SchemaLimits
+	// RefNested registers every nested struct once under components/schemas
+	// and references it, instead of inlining it into each parent schema.
+	RefNested bool
+	// TypeMappings replaces the generated schema of the named types, e.g.
+	// "uuid.UUID" -> {type: string, format: uuid}.
+	TypeMappings map[string]map[string]interface{}
+	// UntaggedFields is how exported struct fields without a json tag are
+	// documented: UntaggedFieldsSkip (the default), or UntaggedFieldsInclude,
+	// as encoding/json writes them.
+	UntaggedFields string
+	// GoTypes adds an x-go-type extension naming the Go type, e.g.
+	// "github.com/org/svc/events.UserCreated", to the schema of each named
+	// struct, for code generators that reuse the original types.
+	GoTypes bool
+	// OpenAPINullable describes pointer fields with the OpenAPI 3.0
+	// "nullable: true" instead of adding null to their JSON Schema type.
+	OpenAPINullable bool
+	// NoDescriptionFallback keeps only explicit @description and
+	// @channel.description values instead of falling back to the doc
+	// comments of the function and payload type.
+	NoDescriptionFallback bool
+	// ServerNaming is the strategy naming the server described without
+	// @server.name: ServerNamingAuto (the default) or ServerNamingTitle.
+	ServerNaming string
+	// Naming is the strategy naming the generated channel, operation and
+	// message keys: NamingCamelCase (the default), NamingSnakeCase,
+	// NamingKebabCase or NamingGoTypeName.
+	Naming string
+	// BindingVersions overrides the bindingVersion written on the binding
+	// objects of a protocol that have none, e.g. "kafka" -> "0.4.0". An empty
+	// version omits it. Unlisted protocols use the latest binding versions.
+	BindingVersions map[string]string
+	// Order is the order of channels and operations in the written
+	// document: OrderAlpha (the default) or OrderSource.
+	Order string
+	// Merge treats each source directory as a separate service, named after
+	// the directory, and lists the services sending and receiving each
+	// channel and message as x-producers and x-consumers.
+	Merge bool
+	// RegistrationTables lists the struct types whose literals register
+	// handlers; each literal becomes an operation without annotations.
+	RegistrationTables []RegistrationTable
+	// VerifySubjects checks the constant subjects, topics and queues passed
+	// to NATS, Kafka and AMQP clients in the body of annotated functions, and
+	// on the line below call-site directives, against their @name:
+	// VerifySubjectsWarn reports mismatches as warnings and
+	// VerifySubjectsError fails the generation. Empty skips the check.
+	VerifySubjects string
+	// Discover lists the clients, such as DiscoverNATS, whose calls in
+	// functions without annotations become draft operations, marked with
+	// x-discovered: the subject, the payload type marshaled or unmarshaled
+	// around the call and, for requests, the reply type are inferred from
+	// the code. Annotated operations on the same channel and action take
+	// precedence over the calls.
+	Discover []string
+	// Info and Servers describe the service when the sources lack the
+	// service-level annotations: Info fills the info fields they leave
+	// empty, and Servers adds the servers they do not declare.
+	Info    *spec3.Info
+	Servers map[string]spec3.Server
+	// Report, when set, receives the warnings of the run.
+	Report *Report
+}
+
+// ParseFolder parses the Go sources in srcDir and returns the generated
+// AsyncAPI document serialized as YAML.
+func ParseFolder(srcDir string, verbose bool, excludeDirs string) ([]byte, error) {
+	doc, err := ParseFolderDocument(srcDir, Options{Verbose: verbose, ExcludeDirs: excludeDirs})
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse additional dependency packages
-	packagesFile, err := listPackages(srcDir, nil, "-deps")
+	yaml, err := doc.MarshalYAML()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list packages: %w", err)
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
 	}
 
-	for _, pkgInfo := range packagesFile {
-		filename := pkgInfo.Dir
-		if strings.HasPrefix(filename, pathExec) && typeCheckers[pkgInfo.Name] == nil {
-			packages, err := parser.ParseDir(fset, filename, nil, parser.ParseComments)
-			if err != nil {
-				if verbose {
-					fmt.Printf("Warning: failed to parse package directory %s: %v\n", filename, err)
-				}
-				continue
-			}
+	return yaml, nil
+}
 
-			for pkgName, pkg := range packages {
-				var files []*ast.File
-				for _, f := range pkg.Files {
-					files = append(files, f)
-				}
+// ParseFolderDocument parses the Go sources in srcDir and returns the generated
+// AsyncAPI document, so callers can post-process it before serializing. When
+// srcDir ends in "/..." its sub-directories are parsed too.
+func ParseFolderDocument(srcDir string, opts Options) (*spec3.AsyncAPI, error) {
+	return ParseFoldersDocument([]string{srcDir}, opts)
+}
 
-				tc, err := NewTypeChecker(fset, files, pkgName)
-				if err != nil {
-					if verbose {
-						fmt.Printf("Warning: failed to create type checker for package %s: %v\n", pkgName, err)
-					}
-					continue
-				}
-				typeCheckers[pkgName] = tc
+// ParseFoldersDocument parses the Go sources in each of srcDirs and combines
+// their annotations into one document, so annotations may be spread over
+// directories that share no common root worth parsing. Each directory may end
+// in "/..." to parse its sub-directories too; a package reached from several
+// of them is parsed once.
+func ParseFoldersDocument(srcDirs []string, opts Options) (*spec3.AsyncAPI, error) {
+	var pkgs []sourcePackage
+	var ignored []IgnoredFile
+	seen := make(map[string]bool)
+	for _, srcDir := range srcDirs {
+		root, recursive := strings.CutSuffix(filepath.ToSlash(srcDir), recursiveSuffix)
+		if recursive && root == "" {
+			root = "."
+		} else if !recursive {
+			root = srcDir
+		}
+
+		// Validate that the source directory exists
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			return nil, fmt.Errorf("source directory does not exist: %s", root)
+		}
+
+		dirOpts := opts
+		dirOpts.Recursive = opts.Recursive || recursive
+		dirPkgs, dirIgnored, err := loadFS(DirFS(root), dirOpts)
+		if err != nil {
+			return nil, err
+		}
+		ignored = append(ignored, dirIgnored...)
+		for _, pkg := range dirPkgs {
+			if opts.Merge {
+				pkg.service = serviceName(root)
+			}
+			if key := pkg.dir + "\x00" + pkg.name; !seen[key] {
+				seen[key] = true
+				pkgs = append(pkgs, pkg)
 			}
 		}
 	}
+	return buildDocument(pkgs, ignored, opts)
+}
 
+// configuredParser returns a parser set up with the options that shape how
+// annotations are parsed, after validating them.
+func configuredParser(opts Options) (*Parser, error) {
 	p := NewParser()
+	p.schemaLimits = opts.SchemaLimits
+	p.openAPINullable = opts.OpenAPINullable
+	p.noDescriptionFallback = opts.NoDescriptionFallback
+	if err := validServerNaming(opts.ServerNaming); err != nil {
+		return nil, err
+	}
+	p.serverNaming = opts.ServerNaming
+	if err := validNaming(opts.Naming); err != nil {
+		return nil, err
+	}
+	p.naming = naming(opts.Naming)
+	p.bindingVersions = bindingVersionsWith(opts.BindingVersions)
+	if err := validUntaggedFields(opts.UntaggedFields); err != nil {
+		return nil, err
+	}
+	if err := validRegistrationTables(opts.RegistrationTables); err != nil {
+		return nil, err
+	}
+	p.registrationTables = opts.RegistrationTables
+	if err := validOrder(opts.Order); err != nil {
+		return nil, err
+	}
+	if err := validVerifySubjects(opts.VerifySubjects); err != nil {
+		return nil, err
+	}
+	p.verifySubjects = opts.VerifySubjects
+	if err := validDiscover(opts.Discover); err != nil {
+		return nil, err
+	}
+	p.discover = opts.Discover
+	return p, nil
+}
+
+// buildDocument parses the annotations of the loaded packages into a
+// validated document. The annotated files that were not loaded are listed
+// in a notice.
+func buildDocument(pkgs []sourcePackage, ignored []IgnoredFile, opts Options) (*spec3.AsyncAPI, error) {
+	verbose := opts.Verbose
+	p, err := configuredParser(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	if verbose {
 		fmt.Printf("Parsing %d package(s)...\n", len(pkgs))
 	}
 
-	// Parse comments from main packages
-	for pkgName, pkg := range pkgs {
+	// Parse comments from the source packages
+	for _, src := range pkgs {
 		if verbose {
-			fmt.Printf("  - Parsing package: %s\n", pkgName)
-		}
-
-		tc := typeCheckers[pkgName]
-		if tc == nil {
-			if verbose {
-				fmt.Printf("Warning: no type checker for package %s\n", pkgName)
+			fmt.Printf("  - Parsing package: %s (%s)\n", src.name, src.dir)
+			for _, err := range src.tc.typeErrors {
+				fmt.Printf("    type error: %v\n", err)
 			}
-			continue
 		}
-
-		// Create file list with names
-		var files []*ast.File
-		fileNames := make(map[*ast.File]string)
-		for name, f := range pkg.Files {
-			files = append(files, f)
-			fileNames[f] = name
+		src.tc.configure(opts)
+		p.service = src.service
+		p.sourceDir = src.dir
+		parseComments(p, src.files, src.tc)
+	}
+	p.addDiscoveredOperations()
+	if opts.VerifySubjects == VerifySubjectsError {
+		if err := subjectMismatchError(p.warnings.occurrences); err != nil {
+			return nil, err
 		}
+	}
 
-		sortedFileList := sortedFiles(files, fileNames)
-		parseComments(p, sortedFileList, tc)
+	p.applyConfiguredInfo(opts.Info, opts.Servers)
+	p.Finalize()
+	if opts.Order == OrderSource {
+		p.applySourceOrder()
+	}
+
+	// Documents without @id are identified by their Go module
+	if p.asyncAPI.ID == "" {
+		for _, src := range pkgs {
+			if src.module != "" {
+				p.asyncAPI.ID = "urn:" + src.module
+				break
+			}
+		}
 	}
 
 	// Validate that we found required API information
 	if err := p.Validate(); err != nil {
+		if !p.hasServiceInfo && len(p.operationFiles) > 0 {
+			return nil, p.missingServiceInfoError()
+		}
+		if !p.hasServiceInfo {
+			return nil, unannotatedError{err}
+		}
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	yaml, err := p.MarshalYAML()
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	for _, name := range p.undefinedSecuritySchemes() {
+		p.warnings.warnf(warnUndefinedName, "security scheme %q is referenced but not defined (use @securityScheme.%s)", name, name)
+	}
+	for _, name := range p.undefinedChannelServers() {
+		p.warnings.warnf(warnUndefinedName, "server %q is referenced by @channel.server but not defined (use @server.%s.host)", name, name)
+	}
+	for _, name := range p.undefinedTraits() {
+		p.warnings.warnf(warnUndefinedName, "trait %q is referenced but not declared", name)
+	}
+
+	if summary := p.warnings.summary(); summary != "" {
+		log.Print(summary)
+	}
+	if notice := ignoredNotice(ignored); notice != "" {
+		log.Print(notice)
+	}
+	if opts.Report != nil {
+		opts.Report.Warnings = p.warnings.list()
+		opts.Report.Ignored = ignored
 	}
 
 	if verbose {
@@ -211,7 +489,7 @@ func ParseFolder(srcDir string, verbose bool, excludeDirs string) ([]byte, error
 			len(p.asyncAPI.Channels), len(p.asyncAPI.Operations))
 	}
 
-	return yaml, nil
+	return p.asyncAPI, nil
 }
 
 func Gen(filename, outFile string) error {
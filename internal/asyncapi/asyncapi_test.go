@@ -0,0 +1,890 @@
+package asyncapi
+
+import (
+	"fmt"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSourceDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"handlers/orders",
+		"consumers",
+		"vendor/example.com/lib",
+		"testdata",
+		".git/objects",
+		"_build",
+		"mocks",
+		"consumers/mocks",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dirs, excluded, err := sourceDirs(os.DirFS(root), parseExcludeList("mocks"), false)
+	if err != nil {
+		t.Fatalf("sourceDirs() error = %v", err)
+	}
+	want := []string{".", "consumers", "handlers", "handlers/orders"}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Errorf("sourceDirs() = %v, want %v", dirs, want)
+	}
+	wantExcluded := []string{"consumers/mocks", "mocks"}
+	if !reflect.DeepEqual(excluded, wantExcluded) {
+		t.Errorf("sourceDirs() excluded = %v, want %v", excluded, wantExcluded)
+	}
+}
+
+func TestParseFolderDocumentRecursive(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/svc\n\ngo 1.21\n",
+		"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+func main() {}
+`,
+		"handlers/orders.go": `package handlers
+
+// @type pub
+// @name order.created
+func PublishOrderCreated() {}
+`,
+		"internal/legacy/legacy.go": `package legacy
+
+// @type pub
+// @name order.legacy
+func PublishLegacy() {}
+`,
+	})
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	flat, err := ParseFolderDocument(".", Options{})
+	if err != nil {
+		t.Fatalf("ParseFolderDocument(.) error = %v", err)
+	}
+	if len(flat.Operations) != 0 {
+		t.Errorf("ParseFolderDocument(.) operations = %d, want 0", len(flat.Operations))
+	}
+
+	doc, err := ParseFolderDocument("./...", Options{ExcludeDirs: "legacy"})
+	if err != nil {
+		t.Fatalf("ParseFolderDocument(./...) error = %v", err)
+	}
+	if _, ok := doc.Operations["publishOrderCreated"]; !ok {
+		t.Errorf("operations = %v, want publishOrderCreated from handlers/", doc.Operations)
+	}
+	if len(doc.Operations) != 1 {
+		t.Errorf("operations = %d, want 1 (legacy is excluded)", len(doc.Operations))
+	}
+}
+
+func TestParseFoldersDocument(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/svc\n\ngo 1.21\n",
+		"cmd/api/main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+func main() {}
+`,
+		"internal/consumers/orders.go": `package consumers
+
+// @type sub
+// @name order.created
+func OnOrderCreated() {}
+`,
+		"pkg/events/billing/billing.go": `package billing
+
+// @type pub
+// @name invoice.issued
+func PublishInvoiceIssued() {}
+`,
+	})
+
+	dirs := []string{
+		filepath.Join(root, "cmd", "api"),
+		filepath.Join(root, "internal", "consumers"),
+		filepath.Join(root, "pkg") + "/...",
+		filepath.Join(root, "pkg", "events", "billing"),
+	}
+	doc, err := ParseFoldersDocument(dirs, Options{})
+	if err != nil {
+		t.Fatalf("ParseFoldersDocument() error = %v", err)
+	}
+	if doc.Info.Title != "Orders API" {
+		t.Errorf("title = %q, want Orders API", doc.Info.Title)
+	}
+	for _, name := range []string{"subscribeOrderCreated", "publishInvoiceIssued"} {
+		if _, ok := doc.Operations[name]; !ok {
+			t.Errorf("operations = %v, want %s", doc.Operations, name)
+		}
+	}
+	if len(doc.Operations) != 2 {
+		t.Errorf("operations = %d, want 2 (billing is parsed once)", len(doc.Operations))
+	}
+
+	if _, err := ParseFoldersDocument([]string{filepath.Join(root, "cmd", "api"), filepath.Join(root, "missing")}, Options{}); err == nil {
+		t.Error("ParseFoldersDocument() with a missing directory error = nil, want error")
+	}
+}
+
+func TestParseFoldersDocumentMerge(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/mesh\n\ngo 1.21\n",
+		"orders/main.go": `// @title Event Mesh
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+type OrderCreated struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// @type pub
+// @name order.created
+// @payload OrderCreated
+func PublishOrderCreated() {}
+
+func main() {}
+`,
+		"billing/billing.go": `package billing
+
+type OrderCreated struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// @type sub
+// @name order.created
+// @payload OrderCreated
+func OnOrderCreated() {}
+`,
+		"shipping/shipping.go": `package shipping
+
+type OrderCreated struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// @type sub
+// @name order.created
+// @payload OrderCreated
+func OnOrderCreated() {}
+`,
+	})
+
+	dirs := []string{filepath.Join(root, "orders"), filepath.Join(root, "billing"), filepath.Join(root, "shipping")}
+	doc, err := ParseFoldersDocument(dirs, Options{Merge: true})
+	if err != nil {
+		t.Fatalf("ParseFoldersDocument() error = %v", err)
+	}
+
+	channel := doc.Channels["orderCreated"]
+	if want := []string{"orders"}; !reflect.DeepEqual(channel.Producers, want) {
+		t.Errorf("channel x-producers = %v, want %v", channel.Producers, want)
+	}
+	if want := []string{"billing", "shipping"}; !reflect.DeepEqual(channel.Consumers, want) {
+		t.Errorf("channel x-consumers = %v, want %v", channel.Consumers, want)
+	}
+	message := doc.Components.Messages["orderCreatedMessage"]
+	if want := []string{"orders"}; !reflect.DeepEqual(message.Producers, want) {
+		t.Errorf("message x-producers = %v, want %v", message.Producers, want)
+	}
+	if want := []string{"billing", "shipping"}; !reflect.DeepEqual(message.Consumers, want) {
+		t.Errorf("message x-consumers = %v, want %v", message.Consumers, want)
+	}
+
+	doc, err = ParseFoldersDocument(dirs, Options{})
+	if err != nil {
+		t.Fatalf("ParseFoldersDocument() error = %v", err)
+	}
+	if channel := doc.Channels["orderCreated"]; channel.Producers != nil || channel.Consumers != nil {
+		t.Errorf("channel without merge = %+v, want no x-producers or x-consumers", channel)
+	}
+}
+
+func TestParseFolderDocumentResolvesImportedTypes(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/svc\n\ngo 1.21\n",
+		"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+import "example.com/svc/events"
+
+// @type pub
+// @name order.placed
+// @payload events.OrderPlaced
+func PublishOrderPlaced(events.OrderPlaced) {}
+
+func main() {}
+`,
+		"events/events.go": `package events
+
+type OrderPlaced struct {
+	OrderID string ` + "`json:\"orderId\"`" + `
+	Items   []Item ` + "`json:\"items\"`" + `
+}
+
+type Item struct {
+	SKU string ` + "`json:\"sku\"`" + `
+}
+`,
+	})
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	doc, err := ParseFolderDocument(".", Options{})
+	if err != nil {
+		t.Fatalf("ParseFolderDocument() error = %v", err)
+	}
+
+	schema, ok := doc.Components.Schemas["OrderPlaced"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("schemas = %v, want OrderPlaced", doc.Components.Schemas)
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	items, _ := properties["items"].(map[string]interface{})
+	itemSchema, _ := items["items"].(map[string]interface{})
+	itemProperties, _ := itemSchema["properties"].(map[string]interface{})
+	if _, ok := properties["orderId"]; !ok {
+		t.Errorf("OrderPlaced properties = %v, want orderId", properties)
+	}
+	if _, ok := itemProperties["sku"]; !ok {
+		t.Errorf("items schema = %v, want Item properties", items)
+	}
+}
+
+func TestParseFolderDocumentRefNestedSameName(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/svc\n\ngo 1.21\n",
+		"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+import (
+	"example.com/svc/billing"
+	"example.com/svc/events"
+)
+
+// @type pub
+// @name invoice.addressed
+// @payload billing.Address
+func PublishInvoiceAddressed(billing.Address) {}
+
+// @type pub
+// @name order.placed
+// @payload events.OrderPlaced
+func PublishOrderPlaced(events.OrderPlaced) {}
+
+func main() {}
+`,
+		"events/events.go": `package events
+
+import (
+	"example.com/svc/billing"
+	"example.com/svc/shipping"
+)
+
+type OrderPlaced struct {
+	Billing  billing.Address  ` + "`json:\"billing\"`" + `
+	Shipping shipping.Address ` + "`json:\"shipping\"`" + `
+}
+`,
+		"billing/billing.go": `package billing
+
+type Address struct {
+	Company string ` + "`json:\"company\"`" + `
+}
+`,
+		"shipping/shipping.go": `package shipping
+
+type Address struct {
+	Street string ` + "`json:\"street\"`" + `
+}
+`,
+	})
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	var report Report
+	doc, err := ParseFolderDocument(".", Options{RefNested: true, Report: &report})
+	if err != nil {
+		t.Fatalf("ParseFolderDocument() error = %v", err)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", report.Warnings)
+	}
+
+	schema, ok := doc.Components.Schemas["OrderPlaced"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("schemas = %v, want OrderPlaced", doc.Components.Schemas)
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for field, want := range map[string]string{"billing": "company", "shipping": "street"} {
+		property, _ := properties[field].(map[string]interface{})
+		ref, _ := property["$ref"].(string)
+		name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+		if !ok {
+			t.Errorf("%s = %v, want a $ref to a component schema", field, property)
+			continue
+		}
+		target, _ := doc.Components.Schemas[name].(map[string]interface{})
+		targetProperties, _ := target["properties"].(map[string]interface{})
+		if _, ok := targetProperties[want]; !ok {
+			t.Errorf("%s refers to %s = %v, want a schema with %s", field, name, target, want)
+		}
+	}
+	if billing := properties["billing"].(map[string]interface{})["$ref"]; billing != "#/components/schemas/Address" {
+		t.Errorf("billing $ref = %v, want the Address payload schema", billing)
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go": {Data: []byte(`// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+func main() {}
+`)},
+		"handlers/orders.go": {Data: []byte(`package handlers
+
+type OrderCreated struct {
+	OrderID string ` + "`json:\"orderId\"`" + `
+}
+
+// @type pub
+// @name order.created
+// @payload OrderCreated
+func PublishOrderCreated() {}
+`)},
+		"handlers/orders_test.go": {Data: []byte(`package handlers
+
+// @type pub
+// @name order.test
+func PublishTest() {}
+`)},
+		"mocks/mocks.go": {Data: []byte(`package mocks
+
+// @type pub
+// @name order.mock
+func PublishMock() {}
+`)},
+	}
+
+	flat, err := ParseFS(fsys, Options{})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if len(flat.Operations) != 0 {
+		t.Errorf("non-recursive Operations = %v, want none", flat.Operations)
+	}
+
+	doc, err := ParseFS(fsys, Options{Recursive: true, ExcludeDirs: "mocks"})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if doc.Info.Title != "Orders API" {
+		t.Errorf("Info.Title = %q, want %q", doc.Info.Title, "Orders API")
+	}
+	if len(doc.Operations) != 1 {
+		t.Fatalf("Operations = %v, want only publishOrderCreated", doc.Operations)
+	}
+	schema, _ := doc.Components.Schemas["OrderCreated"].(map[string]interface{})
+	properties, _ := schema["properties"].(map[string]interface{})
+	if _, ok := properties["orderId"]; !ok {
+		t.Errorf("OrderCreated schema = %v, want orderId property", schema)
+	}
+}
+
+func TestParseFSReport(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go": {Data: []byte(`// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+// @type pub
+// @name order.created
+// @payload MissingEvent
+func PublishOrderCreated() {}
+
+// @type pub
+// @name order.updated
+// @payload MissingEvent
+func PublishOrderUpdated() {}
+
+func main() {}
+`)},
+	}
+
+	var report Report
+	if _, err := ParseFS(fsys, Options{Report: &report}); err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	want := Warning{
+		Kind:     warnTypeNotFound,
+		Message:  "type 'MissingEvent' not found, using empty struct",
+		Count:    2,
+		Location: "main.go:7",
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0] != want {
+		t.Errorf("Report.Warnings = %+v, want [%+v]", report.Warnings, want)
+	}
+}
+
+func TestParseFSReportTypeErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go": {Data: []byte(`// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+// @type pub
+// @name order.created
+// @payload OrderCreated
+func PublishOrderCreated() {}
+
+type OrderCreated = Missing
+
+func main() {}
+`)},
+	}
+
+	var report Report
+	if _, err := ParseFS(fsys, Options{Report: &report}); err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if len(report.Warnings) != 1 {
+		t.Fatalf("Report.Warnings = %+v, want one warning", report.Warnings)
+	}
+	if message := report.Warnings[0].Message; !strings.Contains(message, "package main has type errors") || !strings.Contains(message, "undefined: Missing") {
+		t.Errorf("Message = %q, want the type error of Missing", message)
+	}
+}
+
+func TestParseFSSourceOrder(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go": {Data: []byte(`// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+// @type sub
+// @name order.shipped
+// @payload string
+func OnOrderShipped() {}
+
+// @type pub
+// @name order.placed
+// @payload string
+func PublishOrderPlaced() {}
+
+// @type sub
+// @name order.placed
+// @payload string
+func OnOrderPlaced() {}
+
+func main() {}
+`)},
+		"audit.go": {Data: []byte(`package main
+
+// @type pub
+// @name audit.log
+// @payload string
+func PublishAuditLog() {}
+`)},
+	}
+
+	tests := []struct {
+		order          string
+		wantChannels   []string
+		wantOperations []string
+	}{
+		{"", nil, nil},
+		{OrderAlpha, nil, nil},
+		{
+			OrderSource,
+			[]string{"orderShipped", "orderPlaced", "auditLog"},
+			[]string{"subscribeOrderShipped", "publishOrderPlaced", "subscribeOrderPlaced", "publishAuditLog"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			doc, err := ParseFS(fsys, Options{Order: tt.order})
+			if err != nil {
+				t.Fatalf("ParseFS() error = %v", err)
+			}
+			if !reflect.DeepEqual(doc.ChannelOrder, tt.wantChannels) {
+				t.Errorf("ChannelOrder = %v, want %v", doc.ChannelOrder, tt.wantChannels)
+			}
+			if !reflect.DeepEqual(doc.OperationOrder, tt.wantOperations) {
+				t.Errorf("OperationOrder = %v, want %v", doc.OperationOrder, tt.wantOperations)
+			}
+		})
+	}
+
+	if _, err := ParseFS(fsys, Options{Order: "random"}); err == nil {
+		t.Errorf("ParseFS(order random) error = nil, want error")
+	}
+}
+
+func TestParseFSModuleID(t *testing.T) {
+	mainSrc := `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+%s
+package main
+
+func main() {}
+`
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		want  string
+	}{
+		{
+			name: "module path",
+			files: fstest.MapFS{
+				"go.mod":  {Data: []byte("module example.com/orders\n\ngo 1.24\n")},
+				"main.go": {Data: []byte(fmt.Sprintf(mainSrc, ""))},
+			},
+			want: "urn:example.com/orders",
+		},
+		{
+			name: "explicit id",
+			files: fstest.MapFS{
+				"go.mod":  {Data: []byte("module example.com/orders\n\ngo 1.24\n")},
+				"main.go": {Data: []byte(fmt.Sprintf(mainSrc, "// @id urn:com:example:orders"))},
+			},
+			want: "urn:com:example:orders",
+		},
+		{
+			name:  "no go.mod",
+			files: fstest.MapFS{"main.go": {Data: []byte(fmt.Sprintf(mainSrc, ""))}},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseFS(tt.files, Options{})
+			if err != nil {
+				t.Fatalf("ParseFS() error = %v", err)
+			}
+			if doc.ID != tt.want {
+				t.Errorf("ID = %q, want %q", doc.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFSGoTypes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/orders\n\ngo 1.24\n")},
+		"main.go": {Data: []byte(`// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+
+type OrderPlaced struct {
+	ID      string  ` + "`json:\"id\"`" + `
+	Address Address ` + "`json:\"address\"`" + `
+}
+
+// @type pub
+// @name order.placed
+// @payload OrderPlaced
+func PublishOrderPlaced() {}
+
+func main() {}
+`)},
+	}
+
+	tests := []struct {
+		goTypes     bool
+		wantOrder   interface{}
+		wantAddress interface{}
+	}{
+		{false, nil, nil},
+		{true, "example.com/orders.OrderPlaced", "example.com/orders.Address"},
+	}
+	for _, tt := range tests {
+		doc, err := ParseFS(fsys, Options{GoTypes: tt.goTypes})
+		if err != nil {
+			t.Fatalf("ParseFS() error = %v", err)
+		}
+		schema, _ := doc.Components.Schemas["OrderPlaced"].(map[string]interface{})
+		if got := schema[goTypeExtension]; got != tt.wantOrder {
+			t.Errorf("GoTypes %v: OrderPlaced x-go-type = %v, want %v", tt.goTypes, got, tt.wantOrder)
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		address, _ := properties["address"].(map[string]interface{})
+		if got := address[goTypeExtension]; got != tt.wantAddress {
+			t.Errorf("GoTypes %v: address x-go-type = %v, want %v", tt.goTypes, got, tt.wantAddress)
+		}
+	}
+}
+
+func TestParseFSMessageKey(t *testing.T) {
+	src := `// @title Users API
+// @version 1.0.0
+// @protocol %s
+// @url %s://localhost:9092
+package main
+
+type Account struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type UserCreated struct {
+	UserID  string  ` + "`json:\"userId\"`" + `
+	Account Account ` + "`json:\"account\"`" + `
+}
+
+// @type pub
+// @name user.created
+// @payload UserCreated
+// @message.key %s
+func PublishUserCreated() {}
+
+func main() {}
+`
+	tests := []struct {
+		name     string
+		protocol string
+		key      string
+		nested   bool
+		wantRef  string
+		wantKey  string
+		warnings int
+	}{
+		{"kafka", "kafka", "userId", false, "#/components/schemas/UserCreated/properties/userId", "", 0},
+		{"kafka nested", "kafka", "account.id", false, "#/components/schemas/UserCreated/properties/account/properties/id", "", 0},
+		{"kafka nested component", "kafka", "account.id", true, "#/components/schemas/Account/properties/id", "", 0},
+		{"nats", "nats", "userId", false, "", "userId", 0},
+		{"unknown field", "nats", "tenantId", false, "", "tenantId", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report Report
+			fsys := fstest.MapFS{"main.go": {Data: []byte(fmt.Sprintf(src, tt.protocol, tt.protocol, tt.key))}}
+			doc, err := ParseFS(fsys, Options{RefNested: tt.nested, Report: &report})
+			if err != nil {
+				t.Fatalf("ParseFS() error = %v", err)
+			}
+			message := doc.Components.Messages["userCreatedMessage"]
+			if message.PartitionKey != tt.wantKey {
+				t.Errorf("x-partition-key = %q, want %q", message.PartitionKey, tt.wantKey)
+			}
+			kafka, _ := message.Bindings["kafka"].(map[string]interface{})
+			key, _ := kafka["key"].(map[string]interface{})
+			if got, _ := key["$ref"].(string); got != tt.wantRef {
+				t.Errorf("kafka key $ref = %q, want %q", got, tt.wantRef)
+			}
+			if len(report.Warnings) != tt.warnings {
+				t.Errorf("warnings = %v, want %d", report.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseFSMissingServiceInfo(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cmd/api/main.go": {Data: []byte("package main\n\nfunc main() {}\n")},
+		"events/users.go": {Data: []byte(`package events
+
+type UserCreated struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// @type pub
+// @name user.created
+// @payload UserCreated
+func PublishUserCreated() {}
+`)},
+	}
+
+	_, err := ParseFS(fsys, Options{Recursive: true})
+	if err == nil {
+		t.Fatal("ParseFS() error = nil, want missing service-level annotations")
+	}
+	for _, want := range []string{"events/users.go", "func main in cmd/api/main.go", "-config"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseFS() error = %q, want it to mention %q", err, want)
+		}
+	}
+
+	doc, err := ParseFS(fsys, Options{
+		Recursive: true,
+		Info:      &spec3.Info{Title: "Users", Version: "1.0.0"},
+		Servers:   map[string]spec3.Server{"production": {Host: "nats.example.com:4222", Protocol: "nats"}},
+	})
+	if err != nil {
+		t.Fatalf("ParseFS() with configured info error = %v", err)
+	}
+	if doc.Info.Title != "Users" || doc.Servers["production"].Protocol != "nats" {
+		t.Errorf("info, servers = %+v, %+v, want them from the options", doc.Info, doc.Servers)
+	}
+	if _, ok := doc.Operations["publishUserCreated"]; !ok {
+		t.Errorf("operations = %v, want publishUserCreated", doc.Operations)
+	}
+}
+
+func TestParseFSServiceInfoOutsideMain(t *testing.T) {
+	events := `package events
+
+type UserCreated struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// @type pub
+// @name user.created
+// @payload UserCreated
+func PublishUserCreated() {}
+`
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "doc.go package comment",
+			fsys: fstest.MapFS{
+				"events/doc.go": {Data: []byte(`// Package events publishes user lifecycle events.
+//
+// @description User lifecycle events
+// @contact.name Identity Team
+package events
+`)},
+				"events/users.go": {Data: []byte(events)},
+			},
+		},
+		{
+			name: "marker",
+			fsys: fstest.MapFS{
+				"events/users.go": {Data: []byte(events + `
+// @asyncapi:info
+// @description User lifecycle events
+// @contact.name Identity Team
+var _ = 0
+`)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseFS(tt.fsys, Options{
+				Recursive: true,
+				Info:      &spec3.Info{Title: "Users", Version: "1.0.0"},
+				Servers:   map[string]spec3.Server{"production": {Host: "nats.example.com:4222", Protocol: "nats"}},
+			})
+			if err != nil {
+				t.Fatalf("ParseFS() error = %v", err)
+			}
+			if doc.Info.Description != "User lifecycle events" {
+				t.Errorf("Info.Description = %q, want %q", doc.Info.Description, "User lifecycle events")
+			}
+			if doc.Info.Contact == nil || doc.Info.Contact.Name != "Identity Team" {
+				t.Errorf("Info.Contact = %+v, want Identity Team", doc.Info.Contact)
+			}
+			if _, ok := doc.Operations["publishUserCreated"]; !ok {
+				t.Errorf("operations = %v, want publishUserCreated", doc.Operations)
+			}
+		})
+	}
+}
+
+func TestIsServiceDocComment(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{"doc.go", "// @description Users\npackage events\n", true},
+		{"asyncapi.go", "// @contact.name Identity Team\npackage events\n", true},
+		{"users.go", "// @description Users\npackage events\n", false},
+		{"doc.go", "// Package events has no annotations.\npackage events\n", false},
+		{"doc.go", "// @type pub\n// @name user.created\npackage events\n", false},
+		{"doc.go", "package events\n\n// @description Users\nvar _ = 0\n", false},
+	}
+	for _, tt := range tests {
+		f, err := goparser.ParseFile(token.NewFileSet(), tt.name, tt.src, goparser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := f.Comments[0]
+		if got := isServiceDocComment(tt.name, f, c, extractComment(c)); got != tt.want {
+			t.Errorf("isServiceDocComment(%s, %q) = %v, want %v", tt.name, tt.src, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package asyncapi
+
+import (
+	"fmt"
+	"go/types"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// avroSchemaFormat is the AsyncAPI schema format of Avro payloads.
+const avroSchemaFormat = "application/vnd.apache.avro;version=1.9.0"
+
+// avroSchemaFile is the extension of Avro schema files, which @payload can
+// reference instead of a Go type.
+const avroSchemaFile = ".avsc"
+
+// isAvroSchemaFormat reports whether format is an Avro schema format, with or
+// without its version and encoding parameters.
+func isAvroSchemaFormat(format string) bool {
+	return strings.HasPrefix(strings.ToLower(format), "application/vnd.apache.avro")
+}
+
+// avroFileTypeName names the message of an .avsc payload after the file,
+// e.g. "schemas/order_placed.avsc" -> "order_placed".
+func avroFileTypeName(file string) string {
+	return strings.TrimSuffix(path.Base(file), path.Ext(file))
+}
+
+// isAvroStruct reports whether the named type is a struct with avro field
+// tags, as used by Avro codecs such as hamba/avro.
+func (tc *TypeChecker) isAvroStruct(typeName string) bool {
+	if tc == nil || tc.pkg == nil {
+		return false
+	}
+	obj := tc.lookupType(typeName)
+	if obj == nil {
+		return false
+	}
+	structType, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		if _, ok := reflect.StructTag(structType.Tag(i)).Lookup("avro"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// avroSchema returns the Avro record schema of the named struct type.
+func (tc *TypeChecker) avroSchema(typeName string) (map[string]interface{}, error) {
+	if tc == nil || tc.pkg == nil {
+		return nil, fmt.Errorf("no type information for Avro schema of %s", typeName)
+	}
+	obj, ok := tc.lookupType(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type not found for Avro schema: %s", typeName)
+	}
+	if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+		return nil, fmt.Errorf("Avro payload %s is not a struct", typeName)
+	}
+	b := &avroBuilder{tc: tc, defined: make(map[*types.TypeName]bool)}
+	schema, _ := b.schema(obj.Type()).(map[string]interface{})
+	return schema, nil
+}
+
+// avroBuilder converts Go types to Avro schemas. Named types are defined
+// once as records; later uses, including recursive ones, refer to the name.
+type avroBuilder struct {
+	tc      *TypeChecker
+	defined map[*types.TypeName]bool
+}
+
+func (b *avroBuilder) schema(typ types.Type) interface{} {
+	switch t := typ.(type) {
+	case *types.Alias:
+		return b.schema(types.Unalias(t))
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return map[string]interface{}{"type": "long", "logicalType": "timestamp-millis"}
+		}
+		structType, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return b.schema(t.Underlying())
+		}
+		if b.defined[obj] {
+			return obj.Name()
+		}
+		b.defined[obj] = true
+		return b.record(obj, structType)
+	case *types.Pointer:
+		return []interface{}{"null", b.schema(t.Elem())}
+	case *types.Slice:
+		if basic, ok := t.Elem().(*types.Basic); ok && basic.Kind() == types.Byte {
+			return "bytes"
+		}
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case *types.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case *types.Map:
+		// Avro map keys are always strings
+		return map[string]interface{}{"type": "map", "values": b.schema(t.Elem())}
+	case *types.Basic:
+		return avroPrimitive(t)
+	}
+	return "string"
+}
+
+// record builds the Avro record of a named struct, with the doc comments of
+// the type and its fields.
+func (b *avroBuilder) record(obj *types.TypeName, structType *types.Struct) map[string]interface{} {
+	docs := b.tc.fieldDocs(obj)
+	fields := []interface{}{}
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		name := avroFieldName(field.Name(), reflect.StructTag(structType.Tag(i)))
+		if name == "" {
+			continue
+		}
+		avroField := map[string]interface{}{
+			"name": name,
+			"type": b.schema(field.Type()),
+		}
+		if _, ok := field.Type().(*types.Pointer); ok {
+			avroField["default"] = nil
+		}
+		if doc := docs[field.Pos()]; doc != "" {
+			avroField["doc"] = doc
+		}
+		fields = append(fields, avroField)
+	}
+
+	record := map[string]interface{}{
+		"type":   "record",
+		"name":   obj.Name(),
+		"fields": fields,
+	}
+	if obj.Pkg() != nil {
+		record["namespace"] = obj.Pkg().Name()
+	}
+	if doc := docDescription(b.tc.typeDoc(obj)); doc != "" {
+		record["doc"] = doc
+	}
+	return record
+}
+
+// avroFieldName returns the name of a field in its Avro record: the avro
+// tag, then the json tag, then the Go name. It returns "" for skipped fields.
+func avroFieldName(goName string, tag reflect.StructTag) string {
+	for _, key := range []string{"avro", "json"} {
+		if value, ok := tag.Lookup(key); ok {
+			name, _, _ := strings.Cut(value, ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+	}
+	return goName
+}
+
+// avroPrimitive maps a Go basic type to the Avro primitive of its range.
+func avroPrimitive(basic *types.Basic) string {
+	//nolint:exhaustive // Untyped and complex kinds never appear in payload fields
+	switch basic.Kind() {
+	case types.Bool:
+		return "boolean"
+	case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16:
+		return "int"
+	case types.Int, types.Int64, types.Uint, types.Uint32, types.Uint64:
+		return "long"
+	case types.Float32:
+		return "float"
+	case types.Float64:
+		return "double"
+	}
+	return "string"
+}
+
+// resolveSchemaFormats applies @message.schemaFormat to the operation's
+// payload and generates the Avro schemas of its Avro messages. Messages whose
+// type has no Avro form fall back to JSON Schema.
+func (p *Parser) resolveSchemaFormats(operation *Operation, tc *TypeChecker) {
+	if operation.MessageSchemaFormat != "" {
+		operation.Message.SchemaFormat = operation.MessageSchemaFormat
+	}
+
+	messages := append([]*MessageInfo{operation.Message, operation.MessageResponse}, operation.AltMessages...)
+	messages = append(messages, operation.ResponseErrors...)
+	for _, msg := range messages {
+		if !isAvroSchemaFormat(msg.SchemaFormat) || msg.Schema != nil || msg.MessageSample == nil {
+			continue
+		}
+		schema, err := tc.avroSchema(msg.TypeName)
+		if err != nil {
+			p.warnings.warnf(warnSchema, "%v, using JSON Schema", err)
+			msg.SchemaFormat = ""
+			continue
+		}
+		msg.Schema = schema
+	}
+}
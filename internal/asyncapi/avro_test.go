@@ -0,0 +1,159 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+const avroTestSource = `
+package events
+
+import "time"
+
+// OrderPlaced is published when a customer places an order.
+type OrderPlaced struct {
+	OrderID  string            ` + "`avro:\"order_id\"`" + `
+	Quantity int32             ` + "`avro:\"quantity\"`" + `
+	Total    float64           ` + "`avro:\"total\"`" + `
+	// Coupon applied at checkout.
+	Coupon   *string           ` + "`avro:\"coupon\"`" + `
+	Lines    []Line            ` + "`avro:\"lines\"`" + `
+	Labels   map[string]string ` + "`avro:\"labels\"`" + `
+	PlacedAt time.Time         ` + "`avro:\"placed_at\"`" + `
+	Parent   *OrderPlaced      ` + "`avro:\"parent\"`" + `
+	Internal string            ` + "`avro:\"-\"`" + `
+}
+
+type Line struct {
+	Sku  string ` + "`avro:\"sku\"`" + `
+	Data []byte ` + "`avro:\"data\"`" + `
+}
+
+type Plain struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+`
+
+func newAvroTestChecker(t *testing.T) *TypeChecker {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "events.go", avroTestSource, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "events")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+	return tc
+}
+
+func TestAvroSchema(t *testing.T) {
+	tc := newAvroTestChecker(t)
+	if !tc.isAvroStruct("OrderPlaced") {
+		t.Errorf("isAvroStruct(OrderPlaced) = false, want true")
+	}
+	if tc.isAvroStruct("Plain") {
+		t.Errorf("isAvroStruct(Plain) = true, want false")
+	}
+
+	schema, err := tc.avroSchema("OrderPlaced")
+	if err != nil {
+		t.Fatalf("avroSchema() error = %v", err)
+	}
+	if schema["type"] != "record" || schema["name"] != "OrderPlaced" || schema["namespace"] != "events" {
+		t.Errorf("record = %v, want OrderPlaced record in events", schema)
+	}
+	if want := "OrderPlaced is published when a customer places an order."; schema["doc"] != want {
+		t.Errorf("doc = %v, want %q", schema["doc"], want)
+	}
+
+	fields := map[string]map[string]interface{}{}
+	for _, field := range schema["fields"].([]interface{}) {
+		f := field.(map[string]interface{})
+		fields[f["name"].(string)] = f
+	}
+	tests := []struct {
+		name string
+		want interface{}
+	}{
+		{"order_id", "string"},
+		{"quantity", "int"},
+		{"total", "double"},
+		{"coupon", []interface{}{"null", "string"}},
+		{"labels", map[string]interface{}{"type": "map", "values": "string"}},
+		{"placed_at", map[string]interface{}{"type": "long", "logicalType": "timestamp-millis"}},
+		{"parent", []interface{}{"null", "OrderPlaced"}},
+		{"lines", map[string]interface{}{"type": "array", "items": map[string]interface{}{
+			"type":      "record",
+			"name":      "Line",
+			"namespace": "events",
+			"fields": []interface{}{
+				map[string]interface{}{"name": "sku", "type": "string"},
+				map[string]interface{}{"name": "data", "type": "bytes"},
+			},
+		}}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(fields[tt.name]["type"], tt.want) {
+			t.Errorf("%s type = %#v, want %#v", tt.name, fields[tt.name]["type"], tt.want)
+		}
+	}
+	if coupon := fields["coupon"]; coupon["doc"] != "Coupon applied at checkout." || coupon["default"] != nil {
+		t.Errorf("coupon = %v, want doc and null default", coupon)
+	}
+	if _, ok := fields["Internal"]; ok {
+		t.Errorf("fields = %v, want no skipped field", fields)
+	}
+	if _, err := tc.avroSchema("Missing"); err == nil {
+		t.Errorf("avroSchema(Missing) error = nil, want error")
+	}
+}
+
+func TestParseOperationAvro(t *testing.T) {
+	tc := newAvroTestChecker(t)
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name order.placed", "@payload OrderPlaced"}, tc)
+	parser.ParseOperation([]string{"@type pub", "@name order.shipped", "@payload schemas/order_shipped.avsc"}, tc)
+	parser.ParseOperation([]string{"@type pub", "@name plain.avro", "@payload Plain", "@message.schemaFormat application/vnd.apache.avro+json;version=1.9.0"}, tc)
+	parser.ParseOperation([]string{"@type pub", "@name plain.json", "@payload Plain", "@message.schemaFormat application/schema+json;version=draft-07"}, tc)
+
+	messages := parser.asyncAPI.Components.Messages
+	placed := messages["orderPlacedMessage"].Payload.(map[string]interface{})
+	if placed["schemaFormat"] != avroSchemaFormat {
+		t.Errorf("schemaFormat = %v, want %v", placed["schemaFormat"], avroSchemaFormat)
+	}
+	if record := placed["schema"].(map[string]interface{}); record["name"] != "OrderPlaced" {
+		t.Errorf("schema = %v, want OrderPlaced record", record)
+	}
+
+	shipped := messages["orderShippedMessage"].Payload
+	want := map[string]interface{}{
+		"schemaFormat": avroSchemaFormat,
+		"schema":       map[string]interface{}{"$ref": "schemas/order_shipped.avsc"},
+	}
+	if !reflect.DeepEqual(shipped, want) {
+		t.Errorf(".avsc payload = %v, want %v", shipped, want)
+	}
+
+	plainAvro := messages["plainAvroMessage"].Payload.(map[string]interface{})
+	if plainAvro["schemaFormat"] != "application/vnd.apache.avro+json;version=1.9.0" {
+		t.Errorf("schemaFormat = %v, want explicit Avro format", plainAvro["schemaFormat"])
+	}
+	if record := plainAvro["schema"].(map[string]interface{}); record["type"] != "record" {
+		t.Errorf("schema = %v, want Avro record", record)
+	}
+
+	plainJSON := messages["plainJsonMessage"].Payload
+	want = map[string]interface{}{
+		"schemaFormat": "application/schema+json;version=draft-07",
+		"schema":       map[string]interface{}{"$ref": "#/components/schemas/Plain"},
+	}
+	if !reflect.DeepEqual(plainJSON, want) {
+		t.Errorf("JSON Schema payload = %v, want %v", plainJSON, want)
+	}
+}
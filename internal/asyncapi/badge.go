@@ -0,0 +1,70 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// Badge colors of the validation statuses.
+const (
+	badgeColorValid    = "brightgreen"
+	badgeColorWarnings = "yellow"
+	badgeColorInvalid  = "red"
+)
+
+// Badge is a shields.io endpoint badge, e.g. "AsyncAPI | 14 channels / 1.2.0 / valid",
+// served from a JSON file with https://img.shields.io/endpoint?url=<file URL>.
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// NewBadge describes the document with its channel count, API version and
+// validation status: invalid when errors is positive, the number of warnings
+// when there are some, valid otherwise.
+func NewBadge(doc *spec3.AsyncAPI, warnings, errors int) Badge {
+	parts := []string{plural(len(doc.Channels), "channel")}
+	if doc.Info.Version != "" {
+		parts = append(parts, doc.Info.Version)
+	}
+
+	color := badgeColorValid
+	switch {
+	case errors > 0:
+		parts = append(parts, "invalid")
+		color = badgeColorInvalid
+	case warnings > 0:
+		parts = append(parts, plural(warnings, "warning"))
+		color = badgeColorWarnings
+	default:
+		parts = append(parts, "valid")
+	}
+
+	return Badge{
+		SchemaVersion: 1,
+		Label:         "AsyncAPI",
+		Message:       strings.Join(parts, " / "),
+		Color:         color,
+	}
+}
+
+// plural formats a count of nouns, e.g. "1 channel" or "14 channels".
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// WarningCount returns the number of warnings of the run, repeats included.
+func (r *Report) WarningCount() int {
+	count := 0
+	for _, warning := range r.Warnings {
+		count += warning.Count
+	}
+	return count
+}
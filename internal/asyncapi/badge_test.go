@@ -0,0 +1,48 @@
+package asyncapi
+
+import (
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func TestNewBadge(t *testing.T) {
+	doc := spec3.NewAsyncAPI()
+	doc.Info.Version = "1.2.0"
+	doc.Channels["userCreated"] = spec3.Channel{Address: "user.created"}
+	doc.Channels["userDeleted"] = spec3.Channel{Address: "user.deleted"}
+
+	tests := []struct {
+		name        string
+		warnings    int
+		errors      int
+		wantMessage string
+		wantColor   string
+	}{
+		{name: "valid", wantMessage: "2 channels / 1.2.0 / valid", wantColor: "brightgreen"},
+		{name: "one warning", warnings: 1, wantMessage: "2 channels / 1.2.0 / 1 warning", wantColor: "yellow"},
+		{name: "warnings", warnings: 3, wantMessage: "2 channels / 1.2.0 / 3 warnings", wantColor: "yellow"},
+		{name: "errors", warnings: 3, errors: 1, wantMessage: "2 channels / 1.2.0 / invalid", wantColor: "red"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			badge := NewBadge(doc, tt.warnings, tt.errors)
+			if badge.SchemaVersion != 1 || badge.Label != "AsyncAPI" {
+				t.Errorf("NewBadge() = %+v, want schemaVersion 1 and label AsyncAPI", badge)
+			}
+			if badge.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", badge.Message, tt.wantMessage)
+			}
+			if badge.Color != tt.wantColor {
+				t.Errorf("Color = %q, want %q", badge.Color, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestReportWarningCount(t *testing.T) {
+	report := Report{Warnings: []Warning{{Count: 2}, {Count: 1}}}
+	if got := report.WarningCount(); got != 3 {
+		t.Errorf("WarningCount() = %d, want 3", got)
+	}
+}
@@ -0,0 +1,245 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// bindingLevel is the AsyncAPI object a binding property belongs to.
+type bindingLevel int
+
+const (
+	channelBinding bindingLevel = iota
+	operationBinding
+	messageBinding
+)
+
+// bindingTargetPrefixes start the annotations naming the binding object a
+// property belongs to, e.g. "@channel.binding.amqp.exchange.name orders".
+var bindingTargetPrefixes = []struct {
+	prefix string
+	level  bindingLevel
+}{
+	{annotation.ChannelBindingPrefix, channelBinding},
+	{annotation.OperationBindingPrefix, operationBinding},
+	{annotation.MessageBindingPrefix, messageBinding},
+}
+
+// bindingsAt returns the bindings of the operation at the given level.
+func (operation *Operation) bindingsAt(level bindingLevel) *map[string]interface{} {
+	switch level {
+	case channelBinding:
+		return &operation.ChannelBindings
+	case messageBinding:
+		return &operation.MessageBindings
+	case operationBinding:
+	}
+	return &operation.Bindings
+}
+
+// setBinding sets a property of the protocol binding at the given level.
+func (operation *Operation) setBinding(level bindingLevel, protocol, name string, value interface{}) {
+	bindingOf(operation.bindingsAt(level), protocol)[name] = value
+}
+
+// ParseTargetBinding sets a property of any protocol binding in the binding
+// object at level. key is "<protocol>.<property>", where a dotted property
+// sets a nested one, e.g. "amqp.exchange.name". Values starting with {, [ or
+// a double quote are JSON; others become booleans, numbers or strings.
+func (operation *Operation) ParseTargetBinding(level bindingLevel, key, value string) error {
+	protocol, property, ok := strings.Cut(key, ".")
+	if !ok || protocol == "" || property == "" {
+		return fmt.Errorf("invalid binding %q: expected <protocol>.<property>", key)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fmt.Errorf("missing value for binding %s", key)
+	}
+	parsed, err := parseBindingValue(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for binding %s: %w", key, err)
+	}
+
+	binding := bindingOf(operation.bindingsAt(level), strings.ToLower(protocol))
+	path := strings.Split(property, ".")
+	for _, name := range path[:len(path)-1] {
+		child, ok := binding[name].(map[string]interface{})
+		if !ok {
+			if binding[name] != nil {
+				return fmt.Errorf("invalid binding %s: %s is not an object", key, name)
+			}
+			child = make(map[string]interface{})
+			binding[name] = child
+		}
+		binding = child
+	}
+	binding[path[len(path)-1]] = parsed
+	return nil
+}
+
+// parseBindingValue converts a binding annotation value to the value of the
+// binding property.
+func parseBindingValue(value string) (interface{}, error) {
+	switch value[0] {
+	case '{', '[', '"':
+		var parsed interface{}
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.UseNumber()
+		if err := decoder.Decode(&parsed); err != nil {
+			return nil, err
+		}
+		return normalizeNumbers(parsed), nil
+	}
+	return parseScalar(value), nil
+}
+
+// setBindingVersion records the @binding.<protocol>.bindingVersion of the
+// operation.
+func (operation *Operation) setBindingVersion(protocol, version string) {
+	if operation.BindingVersions == nil {
+		operation.BindingVersions = make(map[string]string)
+	}
+	operation.BindingVersions[protocol] = version
+}
+
+// applyBindingVersions sets the annotated bindingVersion on each binding of
+// its protocol in the operation.
+func (operation *Operation) applyBindingVersions() {
+	for protocol, version := range operation.BindingVersions {
+		for _, bindings := range []map[string]interface{}{operation.ChannelBindings, operation.Bindings, operation.MessageBindings} {
+			if binding, ok := bindings[protocol].(map[string]interface{}); ok {
+				binding["bindingVersion"] = version
+			}
+		}
+	}
+}
+
+// bindingOf returns the binding of protocol in *bindings, creating the map
+// and the binding as needed.
+func bindingOf(bindings *map[string]interface{}, protocol string) map[string]interface{} {
+	if *bindings == nil {
+		*bindings = make(map[string]interface{})
+	}
+	binding, ok := (*bindings)[protocol].(map[string]interface{})
+	if !ok {
+		binding = make(map[string]interface{})
+		(*bindings)[protocol] = binding
+	}
+	return binding
+}
+
+// mergeBindings adds the properties of the bindings in src to dst, per
+// protocol, and returns dst.
+func mergeBindings(dst, src map[string]interface{}) map[string]interface{} {
+	for protocol, binding := range src {
+		srcBinding, ok := binding.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		dstBinding := bindingOf(&dst, protocol)
+		for key, value := range srcBinding {
+			dstBinding[key] = value
+		}
+	}
+	return dst
+}
+
+// bindingList splits a comma-separated binding value into its items.
+func bindingList(value string) []interface{} {
+	var items []interface{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// defaultBindingVersions are the latest versions of the AsyncAPI protocol
+// bindings, written as the bindingVersion of each binding object that has
+// none, since strict validators reject binding objects without it.
+var defaultBindingVersions = map[string]string{
+	"amqp":         "0.3.0",
+	"amqp1":        "0.1.0",
+	"anypointmq":   "0.1.0",
+	"googlepubsub": "0.2.0",
+	"http":         "0.3.0",
+	"ibmmq":        "0.1.0",
+	"jms":          "0.0.1",
+	"kafka":        "0.5.0",
+	"mercure":      "0.1.0",
+	"mqtt":         "0.2.0",
+	"nats":         "0.1.0",
+	"pulsar":       "0.1.0",
+	"redis":        "0.1.0",
+	"sns":          "0.1.0",
+	"solace":       "0.4.0",
+	"sqs":          "0.2.0",
+	"stomp":        "0.1.0",
+	"ws":           "0.1.0",
+}
+
+// bindingVersionsWith returns the default binding versions overridden by
+// versions. An empty version leaves the bindings of its protocol without one.
+func bindingVersionsWith(versions map[string]string) map[string]string {
+	merged := make(map[string]string, len(defaultBindingVersions)+len(versions))
+	for protocol, version := range defaultBindingVersions {
+		merged[protocol] = version
+	}
+	for protocol, version := range versions {
+		merged[strings.ToLower(protocol)] = version
+	}
+	return merged
+}
+
+// applyDefaultBindingVersions sets the bindingVersion of every binding object
+// of the document that has none to the version of its protocol.
+func (p *Parser) applyDefaultBindingVersions() {
+	doc := p.asyncAPI
+	var all []map[string]interface{}
+	for _, server := range doc.Servers {
+		all = append(all, server.Bindings)
+	}
+	for _, channel := range doc.Channels {
+		all = append(all, channel.Bindings)
+	}
+	for _, operation := range doc.Operations {
+		all = append(all, operation.Bindings)
+	}
+	if components := doc.Components; components != nil {
+		for _, message := range components.Messages {
+			all = append(all, message.Bindings)
+		}
+		for _, trait := range components.OperationTraits {
+			all = append(all, trait.Bindings)
+		}
+		for _, trait := range components.MessageTraits {
+			all = append(all, trait.Bindings)
+		}
+		for _, named := range []map[string]interface{}{
+			components.ServerBindings, components.ChannelBindings,
+			components.OperationBindings, components.MessageBindings,
+		} {
+			for _, bindings := range named {
+				if bindings, ok := bindings.(map[string]interface{}); ok {
+					all = append(all, bindings)
+				}
+			}
+		}
+	}
+
+	for _, bindings := range all {
+		for protocol, binding := range bindings {
+			binding, ok := binding.(map[string]interface{})
+			if !ok || binding["bindingVersion"] != nil {
+				continue
+			}
+			if version := p.bindingVersions[protocol]; version != "" {
+				binding["bindingVersion"] = version
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTargetBinding(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		level   bindingLevel
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "channel nested",
+			comment: "@channel.binding.amqp.exchange.name orders",
+			level:   channelBinding,
+			want:    map[string]interface{}{"amqp": map[string]interface{}{"exchange": map[string]interface{}{"name": "orders"}}},
+		},
+		{
+			name:    "operation number",
+			comment: "@operation.binding.mqtt.qos 1",
+			level:   operationBinding,
+			want:    map[string]interface{}{"mqtt": map[string]interface{}{"qos": int64(1)}},
+		},
+		{
+			name:    "message JSON",
+			comment: `@Message.Binding.Kafka.key {"type": "string", "maxLength": 36}`,
+			level:   messageBinding,
+			want:    map[string]interface{}{"kafka": map[string]interface{}{"key": map[string]interface{}{"type": "string", "maxLength": int64(36)}}},
+		},
+		{
+			name:    "boolean",
+			comment: "@channel.binding.ibmmq.queue.isPartitioned true",
+			level:   channelBinding,
+			want:    map[string]interface{}{"ibmmq": map[string]interface{}{"queue": map[string]interface{}{"isPartitioned": true}}},
+		},
+		{name: "missing property", comment: "@channel.binding.amqp value", wantErr: true},
+		{name: "missing value", comment: "@operation.binding.amqp.ack", wantErr: true},
+		{name: "invalid JSON", comment: "@message.binding.kafka.key {type", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := NewOperation()
+			err := operation.ParseComment(tt.comment, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := *operation.bindingsAt(tt.level); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bindings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	operation := NewOperation()
+	if err := operation.ParseComment("@channel.binding.amqp.exchange orders", nil); err != nil {
+		t.Fatalf("ParseComment() error = %v", err)
+	}
+	if err := operation.ParseComment("@channel.binding.amqp.exchange.type topic", nil); err == nil {
+		t.Errorf("ParseComment() on a non-object property error = nil, want error")
+	}
+}
+
+func TestApplyDefaultBindingVersions(t *testing.T) {
+	parser := NewParser()
+	parser.bindingVersions = bindingVersionsWith(map[string]string{"Kafka": "0.4.0", "nats": ""})
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name orders.placed",
+		"@payload string",
+		"@binding.kafka.topic orders",
+		"@binding.kafka.clientId checkout",
+		"@binding.amqp.exchange.name orders",
+		"@binding.amqp.bindingVersion 0.2.0",
+		"@binding.nats.queue workers",
+		"@binding.redis.stream orders",
+	}, nil)
+	parser.Finalize()
+
+	channel := parser.asyncAPI.Channels["ordersPlaced"].Bindings
+	operation := parser.asyncAPI.Operations["publishOrdersPlaced"].Bindings
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"kafka channel", channel["kafka"].(map[string]interface{})["bindingVersion"], "0.4.0"},
+		{"kafka operation", operation["kafka"].(map[string]interface{})["bindingVersion"], "0.4.0"},
+		{"annotated amqp", channel["amqp"].(map[string]interface{})["bindingVersion"], "0.2.0"},
+		{"disabled nats", operation["nats"].(map[string]interface{})["bindingVersion"], nil},
+		{"extension", channel[redisBindingKey].(map[string]interface{})["bindingVersion"], nil},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s bindingVersion = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
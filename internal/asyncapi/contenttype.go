@@ -0,0 +1,182 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// msgpackContentType is the content type of messages encoded with
+// MessagePack.
+const msgpackContentType = "application/msgpack"
+
+// Import paths of the serialization packages whose Marshal and Unmarshal
+// calls tell the content type of the messages of an operation.
+var (
+	protoPackages   = []string{"google.golang.org/protobuf/proto", "github.com/golang/protobuf/proto"}
+	msgpackPackages = []string{
+		"github.com/vmihailenco/msgpack/v5",
+		"github.com/vmihailenco/msgpack/v4",
+		"github.com/vmihailenco/msgpack",
+		"github.com/shamaton/msgpack/v2",
+	}
+)
+
+// marshalContentTypes are the content types of the serialization packages.
+// Protobuf messages get application/x-protobuf rather than
+// application/protobuf, as the messages referencing a .proto file, so both
+// ways of documenting a protobuf payload agree.
+var marshalContentTypes = []struct {
+	packages    []string
+	contentType string
+}{
+	{protoPackages, protobufContentType},
+	{msgpackPackages, msgpackContentType},
+}
+
+// marshalContentType returns the content type of the first protobuf or
+// MessagePack call named method, Marshal or Unmarshal, below node and after
+// the position after, or "" when there is none, as for encoding/json.
+func marshalContentType(node ast.Node, method string, after token.Pos, tc *TypeChecker) string {
+	if node == nil || tc == nil || tc.info == nil {
+		return ""
+	}
+	contentType := ""
+	ast.Inspect(node, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || contentType != "" {
+			return contentType == ""
+		}
+		fn := calledFunc(call, tc)
+		if fn == nil || fn.Name() != method || call.Pos() <= after {
+			return true
+		}
+		for _, serialization := range marshalContentTypes {
+			if funcOf(fn, serialization.packages) {
+				contentType = serialization.contentType
+				break
+			}
+		}
+		return true
+	})
+	return contentType
+}
+
+// callContentType returns the content type of the messages sent or received
+// in body by node, a client call or message literal: the one of the Marshal
+// producing its values, following the variables they are assigned from, or
+// of the Unmarshal of its handler, or of body after node when the call
+// returns the message instead.
+func callContentType(node ast.Node, body *ast.BlockStmt, send bool, tc *TypeChecker) string {
+	var values []ast.Expr
+	switch n := node.(type) {
+	case *ast.CallExpr:
+		values = n.Args
+	case *ast.CompositeLit:
+		values = n.Elts
+	}
+	if !send {
+		for _, value := range values {
+			if handler, _ := handlerFunc(value, tc); handler != nil {
+				return marshalContentType(handler, "Unmarshal", token.NoPos, tc)
+			}
+		}
+		return marshalContentType(body, "Unmarshal", node.End(), tc)
+	}
+
+	seen := make(map[types.Object]bool)
+	var produced func(node ast.Node) string
+	produced = func(node ast.Node) string {
+		contentType := ""
+		if node == nil {
+			return contentType
+		}
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				contentType = marshalContentType(n, "Marshal", token.NoPos, tc)
+			case *ast.Ident:
+				if obj := tc.info.ObjectOf(n); obj != nil && !seen[obj] {
+					seen[obj] = true
+					contentType = produced(assignedValue(n, body, tc))
+				}
+			}
+			return contentType == ""
+		})
+		return contentType
+	}
+	for _, value := range values {
+		if contentType := produced(value); contentType != "" {
+			return contentType
+		}
+	}
+	return ""
+}
+
+// withMarshalContentType appends to the annotations of an operation without
+// @message.contenttype the content type of the serialization of its
+// messages: the one of the Marshal calls of fn when it sends them, or of its
+// Unmarshal calls when it receives them.
+func withMarshalContentType(annotations []string, fn *ast.FuncDecl, tc *TypeChecker) []string {
+	if fn == nil || fn.Body == nil || hasAnnotation(annotations, annotation.MessageContentType) {
+		return annotations
+	}
+	method := "Unmarshal"
+	if sendsMessages(annotations) {
+		method = "Marshal"
+	}
+	return withContentType(annotations, marshalContentType(fn.Body, method, token.NoPos, tc))
+}
+
+// withCallContentType appends to the annotations of the operation of node, a
+// client call or message literal found in body, without @message.contenttype
+// the content type of the serialization of its messages.
+func withCallContentType(annotations []string, node ast.Node, body *ast.BlockStmt, send bool, tc *TypeChecker) []string {
+	if hasAnnotation(annotations, annotation.MessageContentType) {
+		return annotations
+	}
+	return withContentType(annotations, callContentType(node, body, send, tc))
+}
+
+// withContentType appends contentType to annotations without
+// @message.contenttype.
+func withContentType(annotations []string, contentType string) []string {
+	if contentType == "" || hasAnnotation(annotations, annotation.MessageContentType) {
+		return annotations
+	}
+	return append(slices.Clip(annotations), annotation.MessageContentType+" "+contentType)
+}
+
+// sendsMessages reports whether the @type of annotations is pub.
+func sendsMessages(annotations []string) bool {
+	for _, line := range annotations {
+		if fields := strings.Fields(line); len(fields) > 1 && strings.EqualFold(fields[0], annotation.Type) {
+			return strings.EqualFold(fields[1], "pub")
+		}
+	}
+	return false
+}
+
+// documentedFunc returns the function of f documented by c, or nil.
+func documentedFunc(f file, c *ast.CommentGroup) *ast.FuncDecl {
+	for _, decl := range f.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Doc == c {
+			return fn
+		}
+	}
+	return nil
+}
+
+// enclosingFunc returns the function of f holding pos, or nil.
+func enclosingFunc(f file, pos token.Pos) *ast.FuncDecl {
+	for _, decl := range f.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Pos() <= pos && pos < fn.End() {
+			return fn
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package asyncapi
+
+import (
+	"testing"
+)
+
+func TestParseFSMarshalContentType(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": `module example.com/svc
+
+go 1.21
+
+require (
+	github.com/vmihailenco/msgpack/v5 v5.0.0
+	google.golang.org/protobuf v1.0.0
+)
+
+replace github.com/vmihailenco/msgpack/v5 => ./stub/msgpack
+
+replace google.golang.org/protobuf => ./stub/protobuf
+`,
+		"stub/msgpack/go.mod":          "module github.com/vmihailenco/msgpack/v5\n\ngo 1.21\n",
+		"stub/msgpack/msgpack.go":      "package msgpack\n\nfunc Marshal(v any) ([]byte, error) { return nil, nil }\n\nfunc Unmarshal(data []byte, v any) error { return nil }\n",
+		"stub/protobuf/go.mod":         "module google.golang.org/protobuf\n\ngo 1.21\n",
+		"stub/protobuf/proto/proto.go": "package proto\n\ntype Message interface{}\n\nfunc Marshal(m Message) ([]byte, error) { return nil, nil }\n",
+		"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+type Order struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Payment struct {
+	Amount int ` + "`json:\"amount\"`" + `
+}
+
+type Refund struct {
+	Amount int ` + "`json:\"amount\"`" + `
+}
+
+type Shipment struct {
+	Carrier string ` + "`json:\"carrier\"`" + `
+}
+
+type Invoice struct {
+	Total int ` + "`json:\"total\"`" + `
+}
+
+type Receipt struct {
+	Number string ` + "`json:\"number\"`" + `
+}
+
+type Audit struct {
+	Actor string ` + "`json:\"actor\"`" + `
+}
+
+// @type pub
+// @name audit.logged
+// @payload Audit
+func publishAudit(audit Audit, state []byte) {
+	var cached Audit
+	msgpack.Unmarshal(state, &cached)
+	json.Marshal(audit)
+}
+
+// @type sub
+// @name audit.replayed
+// @payload Audit
+func onAuditReplay(data []byte) {
+	var audit Audit
+	json.Unmarshal(data, &audit)
+	msgpack.Marshal(audit)
+}
+
+// @type pub
+// @name order.created
+// @payload Order
+func publishOrder(order Order) {
+	proto.Marshal(order)
+}
+
+// @type sub
+// @name payment.received
+// @payload Payment
+func onPayment(data []byte) {
+	var payment Payment
+	msgpack.Unmarshal(data, &payment)
+}
+
+// @type pub
+// @name refund.issued
+// @payload Refund
+// @message.contentType application/vnd.refund+msgpack
+func publishRefund(refund Refund) {
+	msgpack.Marshal(refund)
+}
+
+// @type pub
+// @name shipment.sent
+// @payload Shipment
+func publishShipment(shipment Shipment) {
+	json.Marshal(shipment)
+}
+
+func send(subject string, data []byte) {}
+
+func main() {
+	data, _ := msgpack.Marshal(Invoice{})
+	//asyncapi:publish invoice.sent Invoice
+	send("invoice.sent", data)
+	receipt, _ := json.Marshal(Receipt{})
+	//asyncapi:publish receipt.sent Receipt
+	send("receipt.sent", receipt)
+}
+`,
+	})
+
+	doc, err := ParseFS(DirFS(root), Options{})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	want := map[string]string{
+		"order.created":    protobufContentType,
+		"payment.received": msgpackContentType,
+		"refund.issued":    "application/vnd.refund+msgpack",
+		"shipment.sent":    "",
+		"invoice.sent":     msgpackContentType,
+		"receipt.sent":     "",
+		"audit.logged":     "",
+		"audit.replayed":   "",
+	}
+	for _, channel := range doc.Channels {
+		wantContentType, ok := want[channel.Address]
+		if !ok {
+			t.Errorf("unexpected channel %s", channel.Address)
+			continue
+		}
+		delete(want, channel.Address)
+		for _, ref := range channel.Messages {
+			message := doc.Components.Messages[ref.Ref[len("#/components/messages/"):]]
+			if message.ContentType != wantContentType {
+				t.Errorf("contentType of %s = %q, want %q", channel.Address, message.ContentType, wantContentType)
+			}
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing channels %v", want)
+	}
+}
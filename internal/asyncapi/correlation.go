@@ -0,0 +1,75 @@
+package asyncapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+const (
+	// correlationIDHeaderLocation prefixes header names given without a
+	// runtime expression.
+	correlationIDHeaderLocation = "$message.header#/"
+	correlationIDRefPrefix      = "#/components/correlationIds/"
+	defaultCorrelationIDName    = "correlationId"
+)
+
+// parseCorrelationID parses the value of @message.correlationid: a runtime
+// expression such as "$message.payload#/meta/requestId", or a header name
+// standing for "$message.header#/<name>", optionally followed by
+// " - <description>".
+func parseCorrelationID(value string) spec3.CorrelationID {
+	location, description, _ := strings.Cut(value, " - ")
+	location = strings.TrimSpace(location)
+	if !strings.HasPrefix(location, "$") {
+		location = correlationIDHeaderLocation + location
+	}
+	return spec3.CorrelationID{
+		Location:    location,
+		Description: strings.TrimSpace(description),
+	}
+}
+
+// registerCorrelationID stores the correlation ID under components/correlationIds,
+// named after the last segment of its location, and returns a reference to it.
+// A name already holding a different definition gets a numbered variant.
+func (p *Parser) registerCorrelationID(correlationID spec3.CorrelationID) *spec3.CorrelationID {
+	components := p.asyncAPI.Components
+	if components.CorrelationIDs == nil {
+		components.CorrelationIDs = make(map[string]spec3.CorrelationID)
+	}
+	name := correlationIDName(correlationID.Location)
+	candidate := name
+	for i := 2; ; i++ {
+		existing, taken := components.CorrelationIDs[candidate]
+		if !taken {
+			components.CorrelationIDs[candidate] = correlationID
+			break
+		}
+		if reflect.DeepEqual(existing, correlationID) {
+			break
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+	return &spec3.CorrelationID{Ref: correlationIDRefPrefix + candidate}
+}
+
+// correlationIDName returns the last segment of the JSON pointer of a
+// location as a component key, e.g. "requestId" for
+// "$message.payload#/meta/requestId". Characters not allowed in component
+// keys split the segment into words joined in camelCase, e.g. "requestId"
+// for "request id".
+func correlationIDName(location string) string {
+	_, pointer, _ := strings.Cut(location, "#")
+	segment := pointer[strings.LastIndex(pointer, "/")+1:]
+	segment = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+	words := strings.FieldsFunc(segment, func(r rune) bool {
+		return !componentKeyPattern.MatchString(string(r))
+	})
+	if len(words) == 0 {
+		return defaultCorrelationIDName
+	}
+	return naming(NamingCamelCase).join(words...)
+}
@@ -0,0 +1,95 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func TestParseCorrelationID(t *testing.T) {
+	tests := []struct {
+		value string
+		want  spec3.CorrelationID
+	}{
+		{
+			value: "correlationId",
+			want:  spec3.CorrelationID{Location: "$message.header#/correlationId"},
+		},
+		{
+			value: "$message.header#/correlationId - Correlation id header",
+			want:  spec3.CorrelationID{Location: "$message.header#/correlationId", Description: "Correlation id header"},
+		},
+		{
+			value: "$message.payload#/meta/requestId",
+			want:  spec3.CorrelationID{Location: "$message.payload#/meta/requestId"},
+		},
+	}
+	for _, tt := range tests {
+		if got := parseCorrelationID(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCorrelationID(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterCorrelationID(t *testing.T) {
+	parser := NewParser()
+	for _, comments := range [][]string{
+		{"@type pub", "@name order.created", "@message.correlationid $message.header#/correlationId - Correlation id header"},
+		{"@type pub", "@name order.shipped", "@message.correlationid $message.header#/correlationId - Correlation id header"},
+		{"@type pub", "@name order.cancelled", "@message.correlationid $message.payload#/correlationId"},
+		{"@type pub", "@name order.returned", "@message.correlationid correlation id"},
+	} {
+		operation := NewOperation()
+		for _, comment := range comments {
+			if err := operation.ParseComment(comment, nil); err != nil {
+				t.Fatalf("ParseComment(%q) error = %v", comment, err)
+			}
+		}
+		parser.proccessOperation(operation)
+	}
+
+	wantIDs := map[string]spec3.CorrelationID{
+		"correlationId":  {Location: "$message.header#/correlationId", Description: "Correlation id header"},
+		"correlationId2": {Location: "$message.payload#/correlationId"},
+		"correlationId3": {Location: "$message.header#/correlation id"},
+	}
+	if got := parser.asyncAPI.Components.CorrelationIDs; !reflect.DeepEqual(got, wantIDs) {
+		t.Errorf("CorrelationIDs = %+v, want %+v", got, wantIDs)
+	}
+
+	for message, want := range map[string]string{
+		"orderCreatedMessage":   "#/components/correlationIds/correlationId",
+		"orderCancelledMessage": "#/components/correlationIds/correlationId2",
+	} {
+		got := parser.asyncAPI.Components.Messages[message].CorrelationID
+		if got == nil || got.Ref != want {
+			t.Errorf("%s CorrelationID = %+v, want $ref %s", message, got, want)
+		}
+	}
+}
+
+func TestCorrelationIDName(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{"$message.header#/correlationId", "correlationId"},
+		{"$message.payload#/meta/requestId", "requestId"},
+		{"$message.header#/x-request-id", "x-request-id"},
+		{"$message.header#/request id", "requestId"},
+		{"$message.payload#/meta/trace~1span", "traceSpan"},
+		{"$message.header#/$id", "id"},
+		{"$message.header#/", defaultCorrelationIDName},
+		{"$message.header#/@@", defaultCorrelationIDName},
+	}
+	for _, tt := range tests {
+		got := correlationIDName(tt.location)
+		if got != tt.want {
+			t.Errorf("correlationIDName(%q) = %q, want %q", tt.location, got, tt.want)
+		}
+		if !componentKeyPattern.MatchString(got) {
+			t.Errorf("correlationIDName(%q) = %q, not a valid component key", tt.location, got)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// Diagnostic is a problem found in the annotations of a source file, as
+// reported to an editor.
+type Diagnostic struct {
+	// File is the path of the source file.
+	File string `json:"file"`
+	// Line is the 1-based line of the problem, and Column and EndColumn the
+	// 1-based byte columns of the text it concerns.
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndColumn int    `json:"endColumn"`
+	Kind      string `json:"kind"`
+	Message   string `json:"message"`
+}
+
+// Diagnose parses the annotations of the package in dir and returns the
+// problems found in them, ordered by file and position: the warnings of a
+// generation run, such as unresolved payload types and invalid values, and
+// annotations no built-in or registered annotation matches, most likely
+// typos. overlay replaces the contents of files by their absolute path, e.g.
+// with the unsaved buffers of an editor.
+//
+// Only the package in dir is parsed, so problems that depend on the rest of
+// the service, such as references to security schemes declared in main, are
+// not reported.
+func Diagnose(dir string, overlay map[string][]byte, opts Options) ([]Diagnostic, error) {
+	p, err := configuredParser(opts)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, _, err := loadPackages(dir, []string{dir}, overlay)
+	if err != nil {
+		return nil, err
+	}
+	return diagnosePackages(p, pkgs, opts), nil
+}
+
+// diagnosePackages parses the annotations of pkgs with p and returns the
+// problems found in them, as described by Diagnose.
+func diagnosePackages(p *Parser, pkgs []sourcePackage, opts Options) []Diagnostic {
+	annotations := annotation.All()
+	var diagnostics []Diagnostic
+	for _, src := range pkgs {
+		src.tc.configure(opts)
+		p.sourceDir = src.dir
+		seen := len(p.warnings.occurrences)
+		parseComments(p, src.files, src.tc)
+		for _, f := range src.files {
+			diagnostics = append(diagnostics, unknownAnnotations(src.dir, f, src.tc.fset, annotations)...)
+		}
+		for _, warning := range p.warnings.occurrences[seen:] {
+			if diagnostic, ok := src.warningDiagnostic(warning); ok {
+				diagnostics = append(diagnostics, diagnostic)
+			}
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return diagnostics
+}
+
+// annotationLine is a line comment starting with an annotation.
+type annotationLine struct {
+	name string
+	// text is the annotation with its value.
+	text string
+	pos  token.Position
+}
+
+// annotationLines returns the line comments of c that start with an
+// annotation, positioned at the annotation.
+func annotationLines(fset *token.FileSet, c *ast.CommentGroup) []annotationLine {
+	var lines []annotationLine
+	for _, comment := range c.List {
+		body, ok := strings.CutPrefix(comment.Text, "//")
+		if !ok {
+			continue
+		}
+		text := strings.TrimLeft(body, " \t")
+		if !strings.HasPrefix(text, "@") {
+			continue
+		}
+		pos := fset.Position(comment.Slash)
+		pos.Column += len("//") + len(body) - len(text)
+		text = strings.TrimRight(text, " \t\r")
+		lines = append(lines, annotationLine{name: strings.Fields(text)[0], text: text, pos: pos})
+	}
+	return lines
+}
+
+// unknownAnnotations reports the annotations of the service-level and
+// operation comments of f that none of annotations matches.
+func unknownAnnotations(dir string, f file, fset *token.FileSet, annotations []annotation.Annotation) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, c := range f.file.Comments {
+		comments := extractComment(c)
+		if !isOperationComment(comments) && !isGeneralAPIComment(comments) && !isServiceDocComment(f.name, f.file, c, comments) {
+			continue
+		}
+		for _, line := range annotationLines(fset, c) {
+			if slices.ContainsFunc(annotations, func(candidate annotation.Annotation) bool { return candidate.Matches(line.name) }) {
+				continue
+			}
+			message := "unknown annotation " + line.name
+			if suggestion := closestAnnotation(annotations, line.name); suggestion != "" {
+				message += ", did you mean " + suggestion + "?"
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				File:      filepath.Join(dir, f.name),
+				Line:      line.pos.Line,
+				Column:    line.pos.Column,
+				EndColumn: line.pos.Column + len(line.name),
+				Kind:      warnUnknownAnnotation,
+				Message:   message,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// maxSuggestionDistance is the largest number of edits between an unknown
+// annotation and the annotation suggested for it.
+const maxSuggestionDistance = 2
+
+// closestAnnotation returns the name of the annotation closest to name, or ""
+// when none is within maxSuggestionDistance edits.
+func closestAnnotation(annotations []annotation.Annotation, name string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, candidate := range annotations {
+		if candidate.Prefix {
+			continue
+		}
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate.Name)); distance < bestDistance {
+			best, bestDistance = candidate.Name, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// mentionPattern matches the annotations and quoted values named by warning
+// messages.
+var mentionPattern = regexp.MustCompile(`@[\w.:-]+|'[^']+'|"[^"]+"`)
+
+// warningDiagnostic places a warning reported while parsing src on the
+// annotation it concerns: the line of the comment it was reported for whose
+// annotation or value the message names, or else the first annotation of
+// that comment. Warnings without a location are left out.
+func (src sourcePackage) warningDiagnostic(warning Warning) (Diagnostic, bool) {
+	i := strings.LastIndexByte(warning.Location, ':')
+	if i < 0 {
+		return Diagnostic{}, false
+	}
+	name := warning.Location[:i]
+	line, err := strconv.Atoi(warning.Location[i+1:])
+	if err != nil {
+		return Diagnostic{}, false
+	}
+
+	for _, f := range src.files {
+		if f.name != name {
+			continue
+		}
+		diagnostic := Diagnostic{
+			File:      filepath.Join(src.dir, name),
+			Line:      line,
+			Column:    1,
+			EndColumn: 1,
+			Kind:      warning.Kind,
+			Message:   warning.Message,
+		}
+		for _, c := range f.file.Comments {
+			if src.tc.fset.Position(c.Pos()).Line != line {
+				continue
+			}
+			lines := annotationLines(src.tc.fset, c)
+			if len(lines) == 0 {
+				break
+			}
+			match := lines[0]
+			for _, l := range lines {
+				if l.mentionedBy(warning.Message) {
+					match = l
+					break
+				}
+			}
+			diagnostic.Line = match.pos.Line
+			diagnostic.Column = match.pos.Column
+			diagnostic.EndColumn = match.pos.Column + len(match.text)
+			break
+		}
+		return diagnostic, true
+	}
+	return Diagnostic{}, false
+}
+
+// mentionedBy reports whether message names the annotation of l or quotes a
+// part of its value.
+func (l annotationLine) mentionedBy(message string) bool {
+	for _, mention := range mentionPattern.FindAllString(message, -1) {
+		if strings.HasPrefix(mention, "@") {
+			if strings.EqualFold(strings.TrimRight(mention, ".:"), l.name) {
+				return true
+			}
+			continue
+		}
+		if strings.Contains(l.text[len(l.name):], strings.Trim(mention, `'"`)) {
+			return true
+		}
+	}
+	return false
+}
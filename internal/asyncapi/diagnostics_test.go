@@ -0,0 +1,76 @@
+package asyncapi
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+func TestDiagnose(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/svc\n\ngo 1.21\n",
+		"main.go": `// @title Orders API
+// @version 1.0.0
+package main
+
+func main() {}
+`,
+	})
+
+	// The unsaved buffer replaces main.go on disk
+	mainFile := filepath.Join(root, "main.go")
+	overlay := map[string][]byte{mainFile: []byte(`// @title Orders API
+// @version 1.0.0
+// @protocl nats
+package main
+
+type OrderPlaced struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// PublishOrderPlaced publishes an order.
+// @type pub
+// @name order.placed
+//   @payload OrderMissing
+// @binding.kafka.bogus 1
+func PublishOrderPlaced() {}
+
+// Helper mentions @someone in an ordinary comment.
+func Helper() {}
+
+func main() {}
+`)}
+
+	diagnostics, err := Diagnose(root, overlay, Options{})
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	want := []Diagnostic{
+		{File: mainFile, Line: 3, Column: 4, EndColumn: 12, Kind: warnUnknownAnnotation, Message: "unknown annotation @protocl, did you mean @protocol?"},
+		{File: mainFile, Line: 13, Column: 6, EndColumn: 27, Kind: warnTypeNotFound, Message: "type 'OrderMissing' not found, using empty struct"},
+		{File: mainFile, Line: 14, Column: 4, EndColumn: 26, Kind: warnAnnotation, Message: "unknown Kafka binding @binding.kafka.bogus"},
+	}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("Diagnose() =\n%+v\nwant\n%+v", diagnostics, want)
+	}
+}
+
+func TestClosestAnnotation(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"@tpye", "@type"},
+		{"@PAYLOD", "@payload"},
+		{"@channel.descripton", "@channel.description"},
+		{"@someone", ""},
+	}
+	for _, tt := range tests {
+		if got := closestAnnotation(annotation.All(), tt.name); got != tt.want {
+			t.Errorf("closestAnnotation(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
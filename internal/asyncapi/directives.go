@@ -0,0 +1,177 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// directivePrefix starts a statement-level annotation. Services often publish
+// from deep within business logic or through a shared helper such as
+// Publish[T any](subject, payload T), where only the call site knows the
+// event and a function-level annotation would be misleading:
+//
+//	//asyncapi:publish user.created UserCreatedEvent
+//	//asyncapi:summary User registered
+//	events.Publish(ctx, "user.created", event)
+//
+// The consecutive directive lines of a comment group describe one operation.
+// "publish" and "subscribe" take the channel name and an optional payload
+// type; any other "//asyncapi:<annotation> value" line is read as
+// "@<annotation> value". Without a payload, the type of the last argument of
+// the call on the next line is used, and without a channel name, its first
+// string constant argument, such as a subject constant.
+const directivePrefix = "//asyncapi:"
+
+// directiveTypes maps directive verbs to operation types.
+var directiveTypes = map[string]string{
+	"publish":   "pub",
+	"subscribe": "sub",
+}
+
+// parseDirectives processes the directive annotations of a file. Go treats
+// "//asyncapi:" lines as directives, so they never reach the comment text
+// handled by parseComments.
+func parseDirectives(p *Parser, f file, tc *TypeChecker) {
+	for _, group := range f.file.Comments {
+		annotations := directiveAnnotations(group)
+		if len(annotations) == 0 {
+			continue
+		}
+		p.warnings.location = commentLocation(f, group, tc)
+		p.order.at(group, tc)
+		call := nextLineCall(f.file, group.End(), tc)
+		if !hasAnnotation(annotations, annotation.Name) {
+			subject, ok := callSubject(call, tc)
+			if !ok {
+				p.warnings.warnf(warnDirective, "%s directives in %s are ignored without a channel name (e.g. %spublish <channel>)",
+					directivePrefix, f.name, directivePrefix)
+				continue
+			}
+			annotations = append(annotations, annotation.Name+" "+subject)
+		}
+		if !hasAnnotation(annotations, annotation.Payload) {
+			if payload := callPayloadType(call, tc); payload != "" {
+				annotations = append(annotations, annotation.Payload+" "+payload)
+			} else {
+				p.warnings.warnf(warnDirective, "%s directives in %s have no payload type and none could be inferred from the call below them",
+					directivePrefix, f.name)
+			}
+		}
+		if call != nil {
+			p.verifyCallSubjects(f, call, annotations, tc)
+			if fn := enclosingFunc(f, call.Pos()); fn != nil {
+				annotations = withCallContentType(annotations, call, fn.Body, sendsMessages(annotations), tc)
+			}
+		}
+		p.recordOperationFile(f)
+		p.ParseOperation(annotations, tc)
+	}
+}
+
+// directiveAnnotations translates the directive lines of a comment group into
+// annotation lines.
+func directiveAnnotations(group *ast.CommentGroup) []string {
+	var annotations []string
+	for _, comment := range group.List {
+		directive, ok := strings.CutPrefix(comment.Text, directivePrefix)
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		typeOperation, ok := directiveTypes[fields[0]]
+		if !ok {
+			annotations = append(annotations, "@"+strings.TrimSpace(directive))
+			continue
+		}
+		annotations = append(annotations, annotation.Type+" "+typeOperation)
+		if len(fields) > 1 {
+			annotations = append(annotations, annotation.Name+" "+fields[1])
+		}
+		if len(fields) > 2 {
+			annotations = append(annotations, annotation.Payload+" "+fields[2])
+		}
+	}
+	return annotations
+}
+
+// hasAnnotation reports whether annotations contain the given attribute.
+func hasAnnotation(annotations []string, attribute string) bool {
+	for _, line := range annotations {
+		if fields := strings.Fields(line); len(fields) > 0 && strings.EqualFold(fields[0], attribute) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextLineCall returns the first call starting on the line after pos, the
+// call annotated by the directives ending at pos, or nil.
+func nextLineCall(f *ast.File, pos token.Pos, tc *TypeChecker) *ast.CallExpr {
+	if tc == nil || tc.info == nil {
+		return nil
+	}
+	line := tc.fset.Position(pos).Line + 1
+
+	var call *ast.CallExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		if call != nil || n == nil || n.End() < pos {
+			return false
+		}
+		if c, ok := n.(*ast.CallExpr); ok && c.Pos() > pos && tc.fset.Position(c.Pos()).Line == line {
+			call = c
+			return false
+		}
+		return true
+	})
+	return call
+}
+
+// callPayloadType returns the type name of the last argument of call, the
+// payload of publish helpers, or "" when it is unknown.
+func callPayloadType(call *ast.CallExpr, tc *TypeChecker) string {
+	if call == nil || len(call.Args) == 0 {
+		return ""
+	}
+	return payloadTypeName(tc.info.TypeOf(call.Args[len(call.Args)-1]), tc)
+}
+
+// callSubject returns the first string constant argument of call before
+// its payload, the subject of nc.Publish(SubjectUserCreated, data) and
+// similar calls.
+func callSubject(call *ast.CallExpr, tc *TypeChecker) (string, bool) {
+	if call == nil || len(call.Args) < 2 {
+		return "", false
+	}
+	for _, arg := range call.Args[:len(call.Args)-1] {
+		if subject, ok := constantString(arg, tc); ok && subject != "" {
+			return subject, true
+		}
+	}
+	return "", false
+}
+
+// payloadTypeName returns the type name of a payload value of type typ, or ""
+// for untyped constants and interfaces, whose payload type is unknown.
+func payloadTypeName(typ types.Type, tc *TypeChecker) string {
+	if typ == nil {
+		return ""
+	}
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	if _, ok := typ.Underlying().(*types.Interface); ok {
+		return ""
+	}
+	if basic, ok := typ.(*types.Basic); ok && basic.Info()&types.IsUntyped != 0 {
+		return ""
+	}
+	name, _, _, _ := tc.extractFieldTypeInfo(typ)
+	return name
+}
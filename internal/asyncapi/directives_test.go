@@ -0,0 +1,194 @@
+package asyncapi
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestParseDirectives(t *testing.T) {
+	src := `
+package testpkg
+
+type UserCreated struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type OrderPlaced struct {
+	OrderID string ` + "`json:\"orderId\"`" + `
+}
+
+type OrderCancelled struct {
+	Reason string ` + "`json:\"reason\"`" + `
+}
+
+func Publish[T any](subject string, payload T) error { return nil }
+
+func Subscribe[T any](subject string, handler func(T)) {}
+
+const (
+	SubjectOrderShipped = "order.shipped"
+	SubjectOrderPrefix  = "order."
+)
+
+var dynamicSubject = "order.dynamic"
+
+type OrderReturned struct {
+	Reason string ` + "`json:\"reason\"`" + `
+}
+
+type OrderShipped struct {
+	OrderID string ` + "`json:\"orderId\"`" + `
+}
+
+func Handle() {
+	//asyncapi:publish user.created UserCreated
+	_ = Publish("user.created", UserCreated{})
+
+	// Order events use the payload type of the call.
+	//asyncapi:publish order.placed
+	_ = Publish("order.placed", OrderPlaced{})
+
+	//asyncapi:publish order.cancelled
+	if err := Publish("order.cancelled", &OrderCancelled{}); err != nil {
+		return
+	}
+
+	//asyncapi:subscribe user.created UserCreated
+	Subscribe("user.created", func(UserCreated) {})
+
+	//asyncapi:publish audit.logged
+	_ = Publish[any]("audit.logged", nil)
+
+	// The channel is the subject constant of the call.
+	//asyncapi:publish
+	_ = Publish(SubjectOrderShipped, OrderShipped{})
+
+	//asyncapi:subscribe
+	//asyncapi:payload OrderReturned
+	Subscribe(SubjectOrderPrefix+"returned", func(OrderReturned) {})
+
+	//asyncapi:publish
+	_ = Publish(dynamicSubject, "a payload, not a subject")
+
+	//asyncapi:unknown ignored
+}
+`
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{f}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parseComments(parser, []file{{file: f, name: "test.go"}}, tc)
+
+	tests := []struct {
+		operation string
+		payload   string
+	}{
+		{"publishUserCreated", "UserCreated"},
+		{"subscribeUserCreated", "UserCreated"},
+		{"publishOrderPlaced", "OrderPlaced"},
+		{"publishOrderCancelled", "OrderCancelled"},
+		{"publishAuditLogged", ""},
+		{"publishOrderShipped", "OrderShipped"},
+		{"subscribeOrderReturned", "OrderReturned"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			op, ok := parser.asyncAPI.Operations[tt.operation]
+			if !ok {
+				t.Fatalf("Operations = %v, want %s", parser.asyncAPI.Operations, tt.operation)
+			}
+			msg := parser.asyncAPI.Components.Messages[op.Channel.Ref[len("#/channels/"):]+"Message"]
+			payload, _ := msg.Payload.(map[string]interface{})
+			want := interface{}(nil)
+			if tt.payload != "" {
+				want = "#/components/schemas/" + tt.payload
+			}
+			if payload["$ref"] != want {
+				t.Errorf("payload = %v, want $ref %v", msg.Payload, want)
+			}
+		})
+	}
+
+	if len(parser.asyncAPI.Operations) != len(tests) {
+		t.Errorf("Operations = %d, want %d", len(parser.asyncAPI.Operations), len(tests))
+	}
+}
+
+func TestParseDirectivesAnnotations(t *testing.T) {
+	src := `
+package testpkg
+
+type Service struct{}
+
+type PaymentFailed struct {
+	OrderID string ` + "`json:\"orderId\"`" + `
+}
+
+type RefundRequest struct {
+	OrderID string ` + "`json:\"orderId\"`" + `
+}
+
+type RefundResult struct {
+	Approved bool ` + "`json:\"approved\"`" + `
+}
+
+func (s *Service) send(subject string, payload interface{}) {}
+
+// Charge charges an order. A doc comment here would not describe every event
+// sent from the function body.
+func (s *Service) Charge(orderID string, ok bool) {
+	if !ok {
+		//asyncapi:publish payment.failed PaymentFailed
+		//asyncapi:summary Payment failed
+		//asyncapi:description Sent when the card is declined.
+		//asyncapi:message.contentType application/json
+		s.send("payment.failed", PaymentFailed{OrderID: orderID})
+		return
+	}
+
+	//asyncapi:type sub
+	//asyncapi:name refund.requested
+	//asyncapi:payload RefundRequest
+	//asyncapi:response RefundResult
+	s.send("refund.requested", RefundRequest{OrderID: orderID})
+
+	//asyncapi:summary No channel name
+}
+`
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{f}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parseComments(parser, []file{{file: f, name: "test.go"}}, tc)
+
+	if len(parser.asyncAPI.Operations) != 2 {
+		t.Fatalf("Operations = %v, want publishPaymentFailed and the refund request", parser.asyncAPI.Operations)
+	}
+	msg := parser.asyncAPI.Components.Messages["paymentFailedMessage"]
+	if msg.Summary != "Payment failed" || msg.Description != "Sent when the card is declined." {
+		t.Errorf("message summary, description = %q, %q", msg.Summary, msg.Description)
+	}
+	if msg.ContentType != "application/json" {
+		t.Errorf("message ContentType = %q, want %q", msg.ContentType, "application/json")
+	}
+	if _, ok := parser.asyncAPI.Components.Schemas["RefundResult"]; !ok {
+		t.Errorf("schemas = %v, want RefundResult from the directive @response", parser.asyncAPI.Components.Schemas)
+	}
+}
@@ -0,0 +1,568 @@
+package asyncapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// Clients of Options.Discover, whose calls become operations without
+// annotations.
+const (
+	// DiscoverNATS infers operations from the Publish, Request and Subscribe
+	// calls of nats.go connections.
+	DiscoverNATS = "nats"
+	// DiscoverKafka infers operations from the writers and readers of
+	// segmentio/kafka-go and the producer messages and consumers of sarama.
+	DiscoverKafka = "kafka"
+	// DiscoverWatermill infers operations from the publishers, subscribers
+	// and router handlers of Watermill.
+	DiscoverWatermill = "watermill"
+)
+
+// discoveredExtension marks the operations inferred from client calls, with
+// the call and the file they come from, so drafts are easy to find and
+// annotate. The line is left out to keep the document stable as code moves.
+const discoveredExtension = "x-discovered"
+
+// discoverer returns the operations of n when n is a call or literal of its
+// client, one per channel. body is the function holding n, where payloads
+// are traced.
+type discoverer func(n ast.Node, body *ast.BlockStmt, tc *TypeChecker) []discoveredCall
+
+// discoverers find the operations of the clients of Options.Discover.
+var discoverers = map[string]discoverer{
+	DiscoverNATS:      discoverNATS,
+	DiscoverKafka:     discoverKafka,
+	DiscoverWatermill: discoverWatermill,
+}
+
+// validDiscover reports an error for a client that cannot be discovered.
+func validDiscover(clients []string) error {
+	for _, client := range clients {
+		if _, ok := discoverers[client]; !ok {
+			return fmt.Errorf("unknown client %q to discover (want %s)", client, strings.Join(slices.Sorted(maps.Keys(discoverers)), ", "))
+		}
+	}
+	return nil
+}
+
+// discoveredCall is a client call sending or receiving messages.
+type discoveredCall struct {
+	// callee names the call, e.g. "nats.Publish".
+	callee string
+	// subject is the channel name, "" when it is not built from constants.
+	subject string
+	send    bool
+	payload string
+	// annotations are further annotations of the operation, e.g. @response.
+	annotations []string
+	// doc is the prose describing the operation, e.g. the doc comment of its
+	// handler.
+	doc string
+}
+
+// discoveredOperation is a discovered call waiting for all annotations to be
+// parsed, with the parsing state it was found in.
+type discoveredOperation struct {
+	call      discoveredCall
+	tc        *TypeChecker
+	file      file
+	sourceDir string
+	service   string
+	location  string
+	pos       sourcePosition
+	// fn is the function holding node, the call or literal discovered.
+	fn   *ast.FuncDecl
+	node ast.Node
+}
+
+// discoverOperations records the client calls in the functions of f that
+// carry no operation annotations. Calls below call-site directives are left
+// to the directives.
+func discoverOperations(p *Parser, f file, tc *TypeChecker) {
+	if len(p.discover) == 0 || tc == nil || tc.info == nil {
+		return
+	}
+	directed := make(map[int]bool)
+	for _, group := range f.file.Comments {
+		if len(directiveAnnotations(group)) > 0 {
+			directed[tc.fset.Position(group.End()).Line+1] = true
+		}
+	}
+
+	for _, decl := range f.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || (fn.Doc != nil && isOperationComment(extractComment(fn.Doc))) {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if n == nil || directed[tc.fset.Position(n.Pos()).Line] {
+				return true
+			}
+			for _, client := range p.discover {
+				calls := discoverers[client](n, fn.Body, tc)
+				if len(calls) == 0 {
+					continue
+				}
+				p.order.at(n, tc)
+				for _, call := range calls {
+					call.annotations = withCallContentType(call.annotations, n, fn.Body, call.send, tc)
+					p.discovered = append(p.discovered, discoveredOperation{
+						call:      call,
+						tc:        tc,
+						file:      f,
+						sourceDir: p.sourceDir,
+						service:   p.service,
+						location:  commentLocation(f, n, tc),
+						pos:       p.order.pos,
+						fn:        fn,
+						node:      n,
+					})
+				}
+				break
+			}
+			return true
+		})
+	}
+}
+
+// addDiscoveredOperations parses the draft operations of the discovered
+// calls once all annotations are parsed, marked with x-discovered.
+func (p *Parser) addDiscoveredOperations() {
+	for _, draft := range p.draftOperations() {
+		d := draft.discoveredOperation
+		p.order.pos = d.pos
+		p.service = d.service
+		p.sourceDir = d.sourceDir
+		p.warnings.location = d.location
+		p.recordOperationFile(d.file)
+		p.ParseOperation(append(draft.annotations, "@"+discoveredExtension+" "+d.call.callee+" in "+d.file.name), d.tc)
+	}
+	p.warnings.location = ""
+}
+
+// draftOperation is a discovered call to document, with the annotations of
+// its operation.
+type draftOperation struct {
+	discoveredOperation
+	annotations []string
+}
+
+// draftOperations returns the discovered calls to document. Annotations take
+// precedence: calls on a channel an annotated operation already sends or
+// receives on are left out, as are repeated calls and calls whose subject is
+// unknown.
+func (p *Parser) draftOperations() []draftOperation {
+	annotated := p.operationChannels()
+	seen := make(map[string]bool)
+	var drafts []draftOperation
+	for _, d := range p.discovered {
+		p.warnings.location = d.location
+		call := d.call
+		if call.subject == "" {
+			p.warnings.warnf(warnDiscovery, "%s is not documented: its subject is not built from constants", call.callee)
+			continue
+		}
+		if slices.ContainsFunc(annotated, func(c operationChannel) bool {
+			return c.send == call.send && subjectMatches(c.address, call.subject)
+		}) {
+			continue
+		}
+
+		typeOperation := "sub"
+		if call.send {
+			typeOperation = "pub"
+		}
+		annotations := []string{annotation.Type + " " + typeOperation, annotation.Name + " " + call.subject}
+		if call.payload != "" {
+			annotations = append(annotations, annotation.Payload+" "+call.payload)
+		}
+		annotations = append(annotations, call.annotations...)
+		if call.doc != "" {
+			annotations = append([]string{call.doc}, annotations...)
+		}
+		key := strings.Join(annotations, "\n")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if call.payload == "" {
+			p.warnings.warnf(warnDiscovery, "no payload type could be inferred for %s on %s", call.callee, call.subject)
+		}
+		drafts = append(drafts, draftOperation{d, annotations})
+	}
+	p.warnings.location = ""
+	return drafts
+}
+
+// operationChannel is the channel address and direction of an operation.
+type operationChannel struct {
+	address string
+	send    bool
+}
+
+// operationChannels returns the channels the operations of the document send
+// and receive on.
+func (p *Parser) operationChannels() []operationChannel {
+	var channels []operationChannel
+	for _, operation := range p.asyncAPI.Operations {
+		channel, ok := p.asyncAPI.Channels[strings.TrimPrefix(operation.Channel.Ref, "#/channels/")]
+		if ok {
+			channels = append(channels, operationChannel{address: channel.Address, send: operation.Action == spec3.ActionSend})
+		}
+	}
+	return channels
+}
+
+// natsMethod locates the arguments of a nats.go method: the subject, the data
+// published, the handler receiving messages and the queue group, or -1.
+type natsMethod struct {
+	send    bool
+	subject int
+	data    int
+	handler int
+	queue   int
+}
+
+var natsMethods = map[string]natsMethod{
+	"Publish":            {send: true, subject: 0, data: 1, handler: -1, queue: -1},
+	"Request":            {send: true, subject: 0, data: 1, handler: -1, queue: -1},
+	"RequestWithContext": {send: true, subject: 1, data: 2, handler: -1, queue: -1},
+	"Subscribe":          {subject: 0, data: -1, handler: 1, queue: -1},
+	"SubscribeSync":      {subject: 0, data: -1, handler: -1, queue: -1},
+	"ChanSubscribe":      {subject: 0, data: -1, handler: -1, queue: -1},
+	"QueueSubscribe":     {subject: 0, data: -1, handler: 2, queue: 1},
+	"QueueSubscribeSync": {subject: 0, data: -1, handler: -1, queue: 1},
+	"ChanQueueSubscribe": {subject: 0, data: -1, handler: -1, queue: 1},
+}
+
+// discoverNATS infers the operation of a call of a nats.go connection: its
+// subject, the type marshaled into the published data or unmarshaled by the
+// subscription handler, the reply type of requests and the queue group.
+func discoverNATS(n ast.Node, body *ast.BlockStmt, tc *TypeChecker) []discoveredCall {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	fn := calledFunc(call, tc)
+	if !funcOf(fn, natsPackages) {
+		return nil
+	}
+	method, ok := natsMethods[fn.Name()]
+	if !ok || method.subject >= len(call.Args) {
+		return nil
+	}
+
+	d := discoveredCall{callee: fn.Pkg().Name() + "." + fn.Name(), send: method.send}
+	d.subject = subjectPattern(call.Args[method.subject], tc)
+	if method.data >= 0 && method.data < len(call.Args) {
+		d.payload = marshaledPayload(call.Args[method.data], body, tc)
+		if reply := replyPayload(call, body, tc); reply != "" {
+			d.annotations = append(d.annotations, annotation.Response+" "+reply)
+		}
+	}
+	if method.handler >= 0 && method.handler < len(call.Args) {
+		d.payload = handlerPayload(call.Args[method.handler], tc)
+	}
+	if method.queue >= 0 && method.queue < len(call.Args) {
+		if queue, ok := constantString(call.Args[method.queue], tc); ok && queue != "" {
+			d.annotations = append(d.annotations, annotation.BindingNATSQueue+" "+queue)
+		}
+	}
+	return []discoveredCall{d}
+}
+
+// calledFunc returns the function or method called by call, or nil.
+func calledFunc(call *ast.CallExpr, tc *TypeChecker) *types.Func {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := tc.info.Uses[ident].(*types.Func)
+	return fn
+}
+
+// funcOf reports whether fn is a function or method of one of packages.
+func funcOf(fn *types.Func, packages []string) bool {
+	return fn != nil && fn.Pkg() != nil && slices.Contains(packages, fn.Pkg().Path())
+}
+
+// subjectPattern returns the channel name of a subject expression: the value
+// of a constant, or a parameterized name for subjects built from constants
+// and variables, "orders.{id}.created" for
+// fmt.Sprintf("orders.%s.created", id) or "orders." + id + ".created".
+// It returns "" for other subjects.
+func subjectPattern(expr ast.Expr, tc *TypeChecker) string {
+	if subject, ok := constantString(expr, tc); ok {
+		return subject
+	}
+	params := make(map[string]int)
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return ""
+		}
+		return concatPattern(e, tc, params)
+	case *ast.CallExpr:
+		fn := calledFunc(e, tc)
+		if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "fmt" || fn.Name() != "Sprintf" || len(e.Args) == 0 {
+			return ""
+		}
+		format, ok := constantString(e.Args[0], tc)
+		if !ok {
+			return ""
+		}
+		return sprintfPattern(format, e.Args[1:], params)
+	}
+	return ""
+}
+
+// concatPattern returns the channel name of a string concatenation, with a
+// parameter for each operand that is not a constant.
+func concatPattern(expr ast.Expr, tc *TypeChecker, params map[string]int) string {
+	if value, ok := constantString(expr, tc); ok {
+		return value
+	}
+	if e, ok := ast.Unparen(expr).(*ast.BinaryExpr); ok && e.Op == token.ADD {
+		return concatPattern(e.X, tc, params) + concatPattern(e.Y, tc, params)
+	}
+	return "{" + paramName(expr, params) + "}"
+}
+
+// sprintfPattern returns the channel name of a Sprintf format, with a
+// parameter for each verb.
+func sprintfPattern(format string, args []ast.Expr, params map[string]int) string {
+	var b strings.Builder
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			b.WriteByte('%')
+			continue
+		}
+		// Skip flags, width and precision up to the verb
+		for i < len(format) && !('a' <= format[i] && format[i] <= 'z' || 'A' <= format[i] && format[i] <= 'Z') {
+			i++
+		}
+		if i >= len(format) || arg >= len(args) {
+			return ""
+		}
+		b.WriteString("{" + paramName(args[arg], params) + "}")
+		arg++
+	}
+	return b.String()
+}
+
+// paramName names the channel parameter of a subject part after the
+// variable or field it comes from, numbering repeated names.
+func paramName(expr ast.Expr, params map[string]int) string {
+	name := "param"
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	case *ast.CallExpr:
+		// id.String()
+		if sel, ok := ast.Unparen(e.Fun).(*ast.SelectorExpr); ok {
+			if x, ok := ast.Unparen(sel.X).(*ast.Ident); ok {
+				name = x.Name
+			}
+		}
+	}
+	params[name]++
+	if params[name] > 1 {
+		name += strconv.Itoa(params[name])
+	}
+	return name
+}
+
+// marshaledPayload returns the payload type of the data argument of a publish
+// call: its own type unless it is []byte, else the type of the value it was
+// marshaled from in body, as in
+//
+//	data, err := json.Marshal(event)
+//	nc.Publish("user.created", data)
+//
+// or "" when it cannot be told.
+func marshaledPayload(expr ast.Expr, body *ast.BlockStmt, tc *TypeChecker) string {
+	if expr == nil {
+		return ""
+	}
+	expr = ast.Unparen(expr)
+	typ := tc.info.TypeOf(expr)
+	if !isByteSlice(typ) {
+		return payloadTypeName(typ, tc)
+	}
+	if call, ok := expr.(*ast.CallExpr); ok {
+		return marshaledType(call, tc)
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	obj := tc.info.ObjectOf(ident)
+	payload := ""
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || payload != "" || len(assign.Rhs) != 1 {
+			return payload == ""
+		}
+		if lhs, ok := assign.Lhs[0].(*ast.Ident); ok && tc.info.ObjectOf(lhs) == obj {
+			if call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr); ok {
+				payload = marshaledType(call, tc)
+			}
+		}
+		return true
+	})
+	return payload
+}
+
+// marshaledType returns the type of the value marshaled by call, a
+// json.Marshal-like function or a MarshalJSON-like method, or "".
+func marshaledType(call *ast.CallExpr, tc *TypeChecker) string {
+	fn := calledFunc(call, tc)
+	if fn == nil {
+		return ""
+	}
+	switch name := fn.Name(); {
+	case (name == "Marshal" || name == "MarshalIndent") && len(call.Args) > 0:
+		return payloadTypeName(tc.info.TypeOf(call.Args[0]), tc)
+	case strings.HasPrefix(name, "Marshal") && len(call.Args) == 0:
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+			return payloadTypeName(tc.info.TypeOf(sel.X), tc)
+		}
+	}
+	return ""
+}
+
+// replyPayload returns the type the reply of a request call is unmarshaled
+// into in body, as in
+//
+//	msg, err := nc.Request("order.get", data, time.Second)
+//	json.Unmarshal(msg.Data, &order)
+//
+// or "".
+func replyPayload(call *ast.CallExpr, body *ast.BlockStmt, tc *TypeChecker) string {
+	var reply types.Object
+	ast.Inspect(body, func(n ast.Node) bool {
+		if assign, ok := n.(*ast.AssignStmt); ok && len(assign.Rhs) == 1 && ast.Unparen(assign.Rhs[0]) == call {
+			if lhs, ok := assign.Lhs[0].(*ast.Ident); ok {
+				reply = tc.info.ObjectOf(lhs)
+			}
+		}
+		return reply == nil
+	})
+	if reply == nil {
+		return ""
+	}
+	return unmarshaledPayload(body, tc, func(data ast.Expr) bool {
+		sel, ok := ast.Unparen(data).(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		x, ok := ast.Unparen(sel.X).(*ast.Ident)
+		return ok && tc.info.ObjectOf(x) == reply
+	})
+}
+
+// handlerPayload returns the payload type of a subscription handler: the
+// type of its parameter for typed handlers, else the type the message data is
+// unmarshaled into in its body, or "". Handlers are function literals or
+// functions and methods of the package.
+func handlerPayload(expr ast.Expr, tc *TypeChecker) string {
+	if sig, ok := tc.info.TypeOf(expr).(*types.Signature); ok && sig.Params().Len() == 1 && !namedOf(sig.Params().At(0).Type(), natsPackages, "Msg") {
+		return payloadTypeName(sig.Params().At(0).Type(), tc)
+	}
+
+	body, _ := handlerFunc(expr, tc)
+	if body == nil {
+		return ""
+	}
+	return unmarshaledPayload(body, tc, func(ast.Expr) bool { return true })
+}
+
+// handlerFunc returns the body of a handler, a function literal or a
+// function or method of the package, and the declaration of the latter.
+func handlerFunc(expr ast.Expr, tc *TypeChecker) (*ast.BlockStmt, *ast.FuncDecl) {
+	var ident *ast.Ident
+	switch h := ast.Unparen(expr).(type) {
+	case *ast.FuncLit:
+		return h.Body, nil
+	case *ast.Ident:
+		ident = h
+	case *ast.SelectorExpr:
+		ident = h.Sel
+	default:
+		return nil, nil
+	}
+	fn, ok := tc.info.Uses[ident].(*types.Func)
+	if !ok {
+		return nil, nil
+	}
+	decl := tc.funcDecl(fn)
+	if decl == nil {
+		return nil, nil
+	}
+	return decl.Body, decl
+}
+
+// unmarshaledPayload returns the type of the value the first Unmarshal call
+// of body whose data argument satisfies fromData decodes into, or "".
+func unmarshaledPayload(body *ast.BlockStmt, tc *TypeChecker, fromData func(ast.Expr) bool) string {
+	payload := ""
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || payload != "" {
+			return payload == ""
+		}
+		if fn := calledFunc(call, tc); fn != nil && fn.Name() == "Unmarshal" && len(call.Args) >= 2 && fromData(call.Args[0]) {
+			payload = payloadTypeName(tc.info.TypeOf(call.Args[1]), tc)
+		}
+		return true
+	})
+	return payload
+}
+
+// funcDecl returns the declaration of a function or method of the package.
+func (tc *TypeChecker) funcDecl(fn *types.Func) *ast.FuncDecl {
+	for _, f := range tc.files[tc.pkg] {
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && tc.info.Defs[fd.Name] == fn {
+				return fd
+			}
+		}
+	}
+	return nil
+}
+
+// isByteSlice reports whether typ is []byte.
+func isByteSlice(typ types.Type) bool {
+	if typ == nil {
+		return false
+	}
+	slice, ok := typ.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	basic, ok := slice.Elem().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
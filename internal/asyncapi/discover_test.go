@@ -0,0 +1,225 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// discoverSources is a module using a stand-in for the NATS client without
+// annotating its operations.
+var discoverSources = map[string]string{
+	"go.mod": `module example.com/svc
+
+go 1.21
+
+require github.com/nats-io/nats.go v1.0.0
+
+replace github.com/nats-io/nats.go => ./stub/nats
+`,
+	"stub/nats/go.mod": "module github.com/nats-io/nats.go\n\ngo 1.21\n",
+	"stub/nats/nats.go": `package nats
+
+import "time"
+
+type Conn struct{}
+
+type Msg struct {
+	Subject string
+	Data    []byte
+}
+
+type Subscription struct{}
+
+type MsgHandler func(msg *Msg)
+
+func (c *Conn) Publish(subj string, data []byte) error { return nil }
+
+func (c *Conn) Request(subj string, data []byte, timeout time.Duration) (*Msg, error) { return nil, nil }
+
+func (c *Conn) Subscribe(subj string, cb MsgHandler) (*Subscription, error) { return nil, nil }
+
+func (c *Conn) QueueSubscribe(subj, queue string, cb MsgHandler) (*Subscription, error) { return nil, nil }
+`,
+	"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type Order struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Payment struct {
+	Amount int ` + "`json:\"amount\"`" + `
+}
+
+type OrderQuery struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+func publishCreated(nc *nats.Conn, order Order) {
+	data, _ := json.Marshal(order)
+	nc.Publish("order.created", data)
+	nc.Publish(fmt.Sprintf("order.%s.updated", order.ID), data)
+}
+
+func getOrder(nc *nats.Conn, id string) Order {
+	data, _ := json.Marshal(OrderQuery{ID: id})
+	msg, _ := nc.Request("order.get", data, time.Second)
+	var order Order
+	json.Unmarshal(msg.Data, &order)
+	return order
+}
+
+func onPayment(msg *nats.Msg) {
+	var payment Payment
+	json.Unmarshal(msg.Data, &payment)
+}
+
+// @type pub
+// @name order.cancelled
+// @description Annotated
+// @payload Order
+func publishCancelled(nc *nats.Conn, data []byte) {
+	nc.Publish("order.cancelled", data)
+}
+
+func main() {
+	var nc *nats.Conn
+	nc.QueueSubscribe("payment.received", "billing", onPayment)
+	nc.Subscribe("order.*.shipped", func(msg *nats.Msg) {
+		var order Order
+		json.Unmarshal(msg.Data, &order)
+	})
+	nc.Publish("order.cancelled", nil)
+	nc.Publish(subject(), nil)
+	//asyncapi:publish order.paid Payment
+	nc.Publish("order.payed", nil)
+}
+
+func subject() string { return "order.archived" }
+`,
+}
+
+func TestParseFSDiscover(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, discoverSources)
+
+	var report Report
+	doc, err := ParseFS(DirFS(root), Options{Discover: []string{DiscoverNATS}, Report: &report})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+
+	type operation struct {
+		action  spec3.OperationAction
+		payload string
+		reply   bool
+	}
+	want := map[string]operation{
+		"order.created":      {spec3.ActionSend, "Order", false},
+		"order.{ID}.updated": {spec3.ActionSend, "Order", false},
+		"order.get":          {spec3.ActionSend, "OrderQuery", true},
+		"payment.received":   {spec3.ActionReceive, "Payment", false},
+		"order.*.shipped":    {spec3.ActionReceive, "Order", false},
+		"order.cancelled":    {spec3.ActionSend, "Order", false},
+		"order.paid":         {spec3.ActionSend, "Payment", false},
+	}
+	got := make(map[string]operation)
+	discovered := 0
+	for _, op := range doc.Operations {
+		channel := doc.Channels[strings.TrimPrefix(op.Channel.Ref, "#/channels/")]
+		payload := ""
+		if len(op.Messages) > 0 {
+			ref := op.Messages[0].Ref
+			message := doc.Components.Messages[ref[strings.LastIndex(ref, "/")+1:]]
+			schema, _ := message.Payload.(map[string]interface{})
+			ref, _ = schema["$ref"].(string)
+			payload = strings.TrimPrefix(ref, "#/components/schemas/")
+		}
+		got[channel.Address] = operation{op.Action, payload, op.Reply != nil}
+		if marker, ok := op.Extensions[discoveredExtension]; ok {
+			discovered++
+			if marker, _ := marker.(string); !strings.HasSuffix(marker, " in main.go") {
+				t.Errorf("%s on %s = %q, want the call and its file", discoveredExtension, channel.Address, marker)
+			}
+			if channel.Address == "order.cancelled" || channel.Address == "order.paid" {
+				t.Errorf("annotated operation on %s is marked %s", channel.Address, discoveredExtension)
+			}
+		}
+	}
+	for address, w := range want {
+		if got[address] != w {
+			t.Errorf("operation on %s = %+v, want %+v", address, got[address], w)
+		}
+	}
+	if len(got) != len(want) || discovered != len(want)-2 {
+		t.Errorf("operations = %+v (%d discovered), want %d with %d discovered", got, discovered, len(want), len(want)-2)
+	}
+
+	queue := false
+	for _, op := range doc.Operations {
+		if nats, ok := op.Bindings["nats"].(map[string]interface{}); ok {
+			queue = queue || nats["queue"] == "billing"
+		}
+	}
+	if !queue {
+		t.Error("no operation has the billing queue binding")
+	}
+
+	skipped := false
+	for _, warning := range report.Warnings {
+		if warning.Kind == warnDiscovery && warning.Message == "nats.Publish is not documented: its subject is not built from constants" {
+			skipped = true
+		}
+	}
+	if !skipped {
+		t.Errorf("warnings = %+v, want the call with a computed subject", report.Warnings)
+	}
+
+	doc, err = ParseFS(DirFS(root), Options{})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if len(doc.Operations) != 2 {
+		t.Errorf("operations without Discover = %d, want 2", len(doc.Operations))
+	}
+
+	if _, err := ParseFS(DirFS(root), Options{Discover: []string{"mqtt"}}); err == nil {
+		t.Error("ParseFS(unknown client) error = nil, want an error")
+	}
+}
+
+func TestSubjectPattern(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"order.%s.created", "order.{id}.created"},
+		{"order.%s.%d", "order.{id}.{id2}"},
+		{"%v-%05d", "{id}-{id2}"},
+		{"100%%.%s", "100%.{id}"},
+		{"order.%s.%s", ""},
+	}
+	for _, tt := range tests {
+		args := []ast.Expr{ast.NewIdent("id"), ast.NewIdent("id")}
+		if tt.want == "" {
+			args = args[:1]
+		}
+		if got := sprintfPattern(tt.format, args, make(map[string]int)); got != tt.want {
+			t.Errorf("sprintfPattern(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// discoverKafka infers the operations of Kafka clients:
+//
+//   - kafka-go Writer.WriteMessages publishes to the Topic of each message,
+//     or else of the writer, the type marshaled into the message Value;
+//   - kafka-go NewReader consumes the Topic of its ReaderConfig, in its
+//     GroupID;
+//   - a sarama ProducerMessage literal publishes to its Topic the type
+//     marshaled into its Value;
+//   - sarama ConsumerGroup.Consume consumes each of its topics in the group
+//     the consumer group was created with, the type its handler's
+//     ConsumeClaim unmarshals the message values into;
+//   - sarama Consumer.ConsumePartition consumes its topic.
+//
+// Readers and partition consumers take their payload type from the message
+// Value unmarshaled in the function that creates them.
+func discoverKafka(n ast.Node, body *ast.BlockStmt, tc *TypeChecker) []discoveredCall {
+	switch n := n.(type) {
+	case *ast.CompositeLit:
+		if !namedOf(tc.info.TypeOf(n), saramaPackages, "ProducerMessage") {
+			return nil
+		}
+		return []discoveredCall{{
+			callee:  "sarama.ProducerMessage",
+			subject: subjectPattern(literalField(n, "Topic"), tc),
+			send:    true,
+			payload: encodedPayload(literalField(n, "Value"), body, tc),
+		}}
+	case *ast.CallExpr:
+		fn := calledFunc(n, tc)
+		switch {
+		case funcOf(fn, kafkaGoPackages) && fn.Name() == "WriteMessages":
+			return discoverKafkaWrite(n, body, tc)
+		case funcOf(fn, kafkaGoPackages) && fn.Name() == "NewReader" && len(n.Args) == 1:
+			config := literalOf(n.Args[0], body, tc)
+			d := discoveredCall{callee: "kafka.NewReader", payload: valuePayload(body, tc)}
+			if config != nil {
+				d.subject = subjectPattern(literalField(config, "Topic"), tc)
+				d.annotations = groupIDAnnotation(literalField(config, "GroupID"), tc)
+			}
+			return []discoveredCall{d}
+		case funcOf(fn, saramaPackages) && fn.Name() == "Consume" && len(n.Args) == 3:
+			return discoverSaramaGroup(n, body, tc)
+		case funcOf(fn, saramaPackages) && fn.Name() == "ConsumePartition" && len(n.Args) > 0:
+			return []discoveredCall{{
+				callee:  "sarama.ConsumePartition",
+				subject: subjectPattern(n.Args[0], tc),
+				payload: valuePayload(body, tc),
+			}}
+		}
+	}
+	return nil
+}
+
+// discoverKafkaWrite returns an operation per message written by a call of
+// kafka-go Writer.WriteMessages.
+func discoverKafkaWrite(call *ast.CallExpr, body *ast.BlockStmt, tc *TypeChecker) []discoveredCall {
+	if len(call.Args) < 1 {
+		return nil
+	}
+	var topic ast.Expr
+	if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+		if writer := literalOf(sel.X, body, tc); writer != nil {
+			topic = literalField(writer, "Topic")
+		}
+	}
+
+	var calls []discoveredCall
+	for _, arg := range call.Args[1:] {
+		d := discoveredCall{callee: "kafka.WriteMessages", send: true, subject: subjectPattern(topic, tc)}
+		if message := literalOf(arg, body, tc); message != nil && namedOf(tc.info.TypeOf(message), kafkaGoPackages, "Message") {
+			if messageTopic := literalField(message, "Topic"); messageTopic != nil {
+				d.subject = subjectPattern(messageTopic, tc)
+			}
+			d.payload = marshaledPayload(literalField(message, "Value"), body, tc)
+		}
+		calls = append(calls, d)
+	}
+	return calls
+}
+
+// discoverSaramaGroup returns an operation per topic consumed by a call of
+// sarama ConsumerGroup.Consume.
+func discoverSaramaGroup(call *ast.CallExpr, body *ast.BlockStmt, tc *TypeChecker) []discoveredCall {
+	var groupID []string
+	if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+		if create, ok := assignedValue(sel.X, body, tc).(*ast.CallExpr); ok {
+			switch fn := calledFunc(create, tc); {
+			case funcOf(fn, saramaPackages) && fn.Name() == "NewConsumerGroup" && len(create.Args) > 1:
+				groupID = groupIDAnnotation(create.Args[1], tc)
+			case funcOf(fn, saramaPackages) && fn.Name() == "NewConsumerGroupFromClient" && len(create.Args) > 0:
+				groupID = groupIDAnnotation(create.Args[0], tc)
+			}
+		}
+	}
+
+	payload := ""
+	if handler := tc.info.TypeOf(call.Args[2]); handler != nil {
+		obj, _, _ := types.LookupFieldOrMethod(handler, true, tc.pkg, "ConsumeClaim")
+		if fn, ok := obj.(*types.Func); ok {
+			if decl := tc.funcDecl(fn); decl != nil && decl.Body != nil {
+				payload = valuePayload(decl.Body, tc)
+			}
+		}
+	}
+
+	topics := []ast.Expr{nil}
+	if list := literalOf(call.Args[1], body, tc); list != nil {
+		topics = list.Elts
+	}
+	var calls []discoveredCall
+	for _, topic := range topics {
+		calls = append(calls, discoveredCall{
+			callee:      "sarama.Consume",
+			subject:     subjectPattern(topic, tc),
+			payload:     payload,
+			annotations: groupID,
+		})
+	}
+	return calls
+}
+
+// groupIDAnnotation returns the Kafka groupId binding of a constant consumer
+// group, if any.
+func groupIDAnnotation(expr ast.Expr, tc *TypeChecker) []string {
+	if expr == nil {
+		return nil
+	}
+	if group, ok := constantString(expr, tc); ok && group != "" {
+		return []string{annotation.BindingKafkaPrefix + "groupId " + group}
+	}
+	return nil
+}
+
+// valuePayload returns the type the Value of a Kafka message is unmarshaled
+// into in body, or "".
+func valuePayload(body *ast.BlockStmt, tc *TypeChecker) string {
+	return unmarshaledPayload(body, tc, func(data ast.Expr) bool {
+		sel, ok := ast.Unparen(data).(*ast.SelectorExpr)
+		return ok && sel.Sel.Name == "Value"
+	})
+}
+
+// encodedPayload returns the payload type of the Value of a sarama producer
+// message, looking through encoder conversions such as
+// sarama.ByteEncoder(data).
+func encodedPayload(expr ast.Expr, body *ast.BlockStmt, tc *TypeChecker) string {
+	if expr == nil {
+		return ""
+	}
+	if call, ok := ast.Unparen(expr).(*ast.CallExpr); ok && len(call.Args) == 1 && tc.info.Types[call.Fun].IsType() {
+		expr = call.Args[0]
+	}
+	return marshaledPayload(expr, body, tc)
+}
+
+// literalField returns the value of the named field of a keyed struct
+// literal, or nil.
+func literalField(lit *ast.CompositeLit, name string) ast.Expr {
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == name {
+				return kv.Value
+			}
+		}
+	}
+	return nil
+}
+
+// literalOf returns the composite literal expr is, points to or, for a
+// variable, was assigned in body, or nil.
+func literalOf(expr ast.Expr, body *ast.BlockStmt, tc *TypeChecker) *ast.CompositeLit {
+	expr = ast.Unparen(expr)
+	if ident, ok := expr.(*ast.Ident); ok {
+		expr = ast.Unparen(assignedValue(ident, body, tc))
+	}
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = ast.Unparen(unary.X)
+	}
+	lit, _ := expr.(*ast.CompositeLit)
+	return lit
+}
+
+// assignedValue returns the expression the variable expr was first assigned
+// or declared with in body, the call for variables assigned a result of a
+// multi-value call, or nil.
+func assignedValue(expr ast.Expr, body *ast.BlockStmt, tc *TypeChecker) ast.Expr {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	obj := tc.info.ObjectOf(ident)
+	if obj == nil {
+		return nil
+	}
+	var value ast.Expr
+	assigned := func(names []ast.Expr, values []ast.Expr) {
+		i := slices.IndexFunc(names, func(name ast.Expr) bool {
+			id, ok := name.(*ast.Ident)
+			return ok && tc.info.ObjectOf(id) == obj
+		})
+		switch {
+		case i < 0:
+		case len(values) == len(names):
+			value = values[i]
+		case len(values) == 1:
+			value = values[0]
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			assigned(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			names := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				names[i] = name
+			}
+			assigned(names, n.Values)
+		}
+		return value == nil
+	})
+	return value
+}
+
+// namedOf reports whether typ, or the type it points to, is the named type
+// name of one of packages.
+func namedOf(typ types.Type, packages []string, name string) bool {
+	named, ok := derefType(typ).(*types.Named)
+	return ok && named.Obj().Name() == name && named.Obj().Pkg() != nil && slices.Contains(packages, named.Obj().Pkg().Path())
+}
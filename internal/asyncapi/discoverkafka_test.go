@@ -0,0 +1,244 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// discoverKafkaSources is a module using stand-ins for kafka-go and sarama
+// without annotating its operations.
+var discoverKafkaSources = map[string]string{
+	"go.mod": `module example.com/svc
+
+go 1.21
+
+require (
+	github.com/IBM/sarama v1.0.0
+	github.com/segmentio/kafka-go v1.0.0
+)
+
+replace github.com/segmentio/kafka-go => ./stub/kafka
+
+replace github.com/IBM/sarama => ./stub/sarama
+`,
+	"stub/kafka/go.mod": "module github.com/segmentio/kafka-go\n\ngo 1.21\n",
+	"stub/kafka/kafka.go": `package kafka
+
+import "context"
+
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+type Writer struct {
+	Addr  string
+	Topic string
+}
+
+func (w *Writer) WriteMessages(ctx context.Context, msgs ...Message) error { return nil }
+
+type ReaderConfig struct {
+	Brokers []string
+	Topic   string
+	GroupID string
+}
+
+type Reader struct{}
+
+func NewReader(config ReaderConfig) *Reader { return nil }
+
+func (r *Reader) ReadMessage(ctx context.Context) (Message, error) { return Message{}, nil }
+`,
+	"stub/sarama/go.mod": "module github.com/IBM/sarama\n\ngo 1.21\n",
+	"stub/sarama/sarama.go": `package sarama
+
+import "context"
+
+type Encoder interface {
+	Encode() ([]byte, error)
+}
+
+type ByteEncoder []byte
+
+func (b ByteEncoder) Encode() ([]byte, error) { return b, nil }
+
+type ProducerMessage struct {
+	Topic string
+	Value Encoder
+}
+
+type SyncProducer interface {
+	SendMessage(msg *ProducerMessage) (int32, int64, error)
+}
+
+type ConsumerMessage struct {
+	Topic string
+	Value []byte
+}
+
+type ConsumerGroupSession interface{}
+
+type ConsumerGroupClaim interface {
+	Messages() <-chan *ConsumerMessage
+}
+
+type ConsumerGroupHandler interface {
+	ConsumeClaim(ConsumerGroupSession, ConsumerGroupClaim) error
+}
+
+type ConsumerGroup interface {
+	Consume(ctx context.Context, topics []string, handler ConsumerGroupHandler) error
+}
+
+type Config struct{}
+
+func NewConsumerGroup(addrs []string, groupID string, config *Config) (ConsumerGroup, error) {
+	return nil, nil
+}
+`,
+	"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol kafka
+// @url localhost:9092
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/IBM/sarama"
+	"github.com/segmentio/kafka-go"
+)
+
+type Order struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Payment struct {
+	Amount int ` + "`json:\"amount\"`" + `
+}
+
+type Shipment struct {
+	Carrier string ` + "`json:\"carrier\"`" + `
+}
+
+func publishOrder(ctx context.Context, order Order) {
+	w := &kafka.Writer{Addr: "localhost:9092", Topic: "orders"}
+	data, _ := json.Marshal(order)
+	w.WriteMessages(ctx, kafka.Message{Value: data})
+}
+
+func publishPayment(producer sarama.SyncProducer, payment Payment) {
+	data, _ := json.Marshal(payment)
+	producer.SendMessage(&sarama.ProducerMessage{Topic: "payments", Value: sarama.ByteEncoder(data)})
+}
+
+func readShipments(ctx context.Context) {
+	r := kafka.NewReader(kafka.ReaderConfig{Topic: "shipments", GroupID: "tracking"})
+	m, _ := r.ReadMessage(ctx)
+	var shipment Shipment
+	json.Unmarshal(m.Value, &shipment)
+}
+
+type paymentHandler struct{}
+
+func (paymentHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var payment Payment
+		json.Unmarshal(msg.Value, &payment)
+	}
+	return nil
+}
+
+// @type sub
+// @name orders
+// @description Annotated
+// @payload Order
+func consumeOrders() {}
+
+func main() {
+	ctx := context.Background()
+	group, _ := sarama.NewConsumerGroup(nil, "billing", nil)
+	group.Consume(ctx, []string{"payments", "orders"}, paymentHandler{})
+}
+`,
+}
+
+func TestParseFSDiscoverKafka(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, discoverKafkaSources)
+
+	doc, err := ParseFS(DirFS(root), Options{Discover: []string{DiscoverKafka}})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+
+	type operation struct {
+		address string
+		action  spec3.OperationAction
+		payload string
+		groupID string
+	}
+	want := []operation{
+		{"orders", spec3.ActionReceive, "Order", ""},
+		{"orders", spec3.ActionSend, "Order", ""},
+		{"payments", spec3.ActionReceive, "Payment", "[billing]"},
+		{"payments", spec3.ActionSend, "Payment", ""},
+		{"shipments", spec3.ActionReceive, "Shipment", "[tracking]"},
+	}
+	var got []operation
+	for _, op := range doc.Operations {
+		o := operation{address: doc.Channels[strings.TrimPrefix(op.Channel.Ref, "#/channels/")].Address, action: op.Action}
+		if len(op.Messages) > 0 {
+			ref := op.Messages[0].Ref
+			message := doc.Components.Messages[ref[strings.LastIndex(ref, "/")+1:]]
+			schema, _ := message.Payload.(map[string]interface{})
+			ref, _ = schema["$ref"].(string)
+			o.payload = strings.TrimPrefix(ref, "#/components/schemas/")
+		}
+		if kafka, ok := op.Bindings["kafka"].(map[string]interface{}); ok {
+			if schema, ok := kafka["groupId"].(map[string]interface{}); ok {
+				o.groupID = fmt.Sprint(schema["enum"])
+			}
+		}
+		got = append(got, o)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("operations = %+v, want %+v", got, want)
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			found = found || g == w
+		}
+		if !found {
+			t.Errorf("operations = %+v, want %+v among them", got, w)
+		}
+	}
+}
+
+func TestParseFSDiscoverKafkaWriteWithoutArguments(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, discoverKafkaSources)
+	writeFiles(t, root, map[string]string{"flush.go": `package main
+
+import "github.com/segmentio/kafka-go"
+
+func flush(w *kafka.Writer) {
+	w.WriteMessages()
+}
+`})
+
+	doc, err := ParseFS(DirFS(root), Options{Discover: []string{DiscoverKafka}})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if len(doc.Operations) != 5 {
+		t.Errorf("operations = %d, want 5", len(doc.Operations))
+	}
+}
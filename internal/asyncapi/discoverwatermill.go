@@ -0,0 +1,132 @@
+package asyncapi
+
+import (
+	"go/ast"
+)
+
+// watermillPackages are the import paths of the Watermill message package.
+var watermillPackages = []string{"github.com/ThreeDotsLabs/watermill/message"}
+
+// discoverWatermill infers the operations of Watermill:
+//
+//   - Publisher.Publish publishes to its topic the type marshaled into the
+//     payload of its messages, created with message.NewMessage;
+//   - Subscriber.Subscribe consumes its topic, the type the message Payload
+//     is unmarshaled into in the same function;
+//   - Router.AddHandler consumes its subscribe topic and publishes to its
+//     publish topic, and Router.AddNoPublisherHandler and
+//     AddConsumerHandler consume their topic. The handler tells the
+//     payloads: the type it unmarshals the message Payload into and the type
+//     marshaled into the messages it returns. The doc comment of a handler
+//     function describes its operations.
+func discoverWatermill(n ast.Node, body *ast.BlockStmt, tc *TypeChecker) []discoveredCall {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	fn := calledFunc(call, tc)
+	if !funcOf(fn, watermillPackages) {
+		return nil
+	}
+	args := call.Args
+	switch fn.Name() {
+	case "Publish":
+		if len(args) == 0 {
+			return nil
+		}
+		var calls []discoveredCall
+		for _, msg := range args[1:] {
+			calls = append(calls, discoveredCall{
+				callee:  "message.Publish",
+				subject: subjectPattern(args[0], tc),
+				send:    true,
+				payload: newMessagePayload(msg, body, tc),
+			})
+		}
+		if len(calls) == 0 {
+			calls = append(calls, discoveredCall{callee: "message.Publish", subject: subjectPattern(args[0], tc), send: true})
+		}
+		return calls
+	case "Subscribe":
+		if len(args) != 2 {
+			return nil
+		}
+		return []discoveredCall{{
+			callee:  "message.Subscribe",
+			subject: subjectPattern(args[1], tc),
+			payload: watermillPayload(body, tc),
+		}}
+	case "AddHandler":
+		if len(args) != 6 {
+			return nil
+		}
+		handlerBody, decl := handlerFunc(args[5], tc)
+		doc := ""
+		if decl != nil {
+			doc = docDescription(decl.Doc)
+		}
+		consume := discoveredCall{callee: "message.AddHandler", subject: subjectPattern(args[1], tc), doc: doc}
+		publish := discoveredCall{callee: "message.AddHandler", subject: subjectPattern(args[3], tc), send: true, doc: doc}
+		if handlerBody != nil {
+			consume.payload = watermillPayload(handlerBody, tc)
+			publish.payload = producedPayload(handlerBody, tc)
+		}
+		return []discoveredCall{consume, publish}
+	case "AddNoPublisherHandler", "AddConsumerHandler":
+		if len(args) != 4 {
+			return nil
+		}
+		handlerBody, decl := handlerFunc(args[3], tc)
+		consume := discoveredCall{callee: "message." + fn.Name(), subject: subjectPattern(args[1], tc)}
+		if decl != nil {
+			consume.doc = docDescription(decl.Doc)
+		}
+		if handlerBody != nil {
+			consume.payload = watermillPayload(handlerBody, tc)
+		}
+		return []discoveredCall{consume}
+	}
+	return nil
+}
+
+// newMessagePayload returns the type marshaled into the payload of the
+// Watermill message msg, created in body with message.NewMessage, or "".
+func newMessagePayload(msg ast.Expr, body *ast.BlockStmt, tc *TypeChecker) string {
+	create, ok := ast.Unparen(msg).(*ast.CallExpr)
+	if !ok {
+		create, ok = ast.Unparen(assignedValue(msg, body, tc)).(*ast.CallExpr)
+	}
+	if !ok {
+		return ""
+	}
+	if fn := calledFunc(create, tc); !funcOf(fn, watermillPackages) || fn.Name() != "NewMessage" || len(create.Args) != 2 {
+		return ""
+	}
+	return marshaledPayload(create.Args[1], body, tc)
+}
+
+// producedPayload returns the type marshaled into the first message a
+// handler creates with message.NewMessage, or "".
+func producedPayload(body *ast.BlockStmt, tc *TypeChecker) string {
+	payload := ""
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || payload != "" {
+			return payload == ""
+		}
+		if fn := calledFunc(call, tc); funcOf(fn, watermillPackages) && fn.Name() == "NewMessage" && len(call.Args) == 2 {
+			payload = marshaledPayload(call.Args[1], body, tc)
+		}
+		return true
+	})
+	return payload
+}
+
+// watermillPayload returns the type the Payload of a Watermill message is
+// unmarshaled into in body, or "".
+func watermillPayload(body *ast.BlockStmt, tc *TypeChecker) string {
+	return unmarshaledPayload(body, tc, func(data ast.Expr) bool {
+		sel, ok := ast.Unparen(data).(*ast.SelectorExpr)
+		return ok && sel.Sel.Name == "Payload"
+	})
+}
@@ -0,0 +1,151 @@
+package asyncapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func TestParseFSDiscoverWatermill(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": `module example.com/svc
+
+go 1.21
+
+require github.com/ThreeDotsLabs/watermill v1.0.0
+
+replace github.com/ThreeDotsLabs/watermill => ./stub/watermill
+`,
+		"stub/watermill/go.mod": "module github.com/ThreeDotsLabs/watermill\n\ngo 1.21\n",
+		"stub/watermill/message/message.go": `package message
+
+import "context"
+
+type Payload []byte
+
+type Message struct {
+	UUID    string
+	Payload Payload
+}
+
+func NewMessage(uuid string, payload Payload) *Message { return &Message{UUID: uuid, Payload: payload} }
+
+type Publisher interface {
+	Publish(topic string, messages ...*Message) error
+}
+
+type Subscriber interface {
+	Subscribe(ctx context.Context, topic string) (<-chan *Message, error)
+}
+
+type HandlerFunc func(msg *Message) ([]*Message, error)
+
+type NoPublishHandlerFunc func(msg *Message) error
+
+type Router struct{}
+
+type Handler struct{}
+
+func (r *Router) AddHandler(handlerName, subscribeTopic string, subscriber Subscriber, publishTopic string, publisher Publisher, handlerFunc HandlerFunc) *Handler {
+	return nil
+}
+
+func (r *Router) AddNoPublisherHandler(handlerName, subscribeTopic string, subscriber Subscriber, handlerFunc NoPublishHandlerFunc) *Handler {
+	return nil
+}
+`,
+		"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol kafka
+// @url localhost:9092
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type Order struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Invoice struct {
+	Total int ` + "`json:\"total\"`" + `
+}
+
+type Shipment struct {
+	Carrier string ` + "`json:\"carrier\"`" + `
+}
+
+func publishOrder(publisher message.Publisher, order Order) {
+	payload, _ := json.Marshal(order)
+	msg := message.NewMessage("1", payload)
+	publisher.Publish("orders", msg)
+}
+
+// invoiceOrder bills placed orders.
+func invoiceOrder(msg *message.Message) ([]*message.Message, error) {
+	var order Order
+	json.Unmarshal(msg.Payload, &order)
+	payload, _ := json.Marshal(Invoice{})
+	return []*message.Message{message.NewMessage("2", payload)}, nil
+}
+
+func main() {
+	var router *message.Router
+	var subscriber message.Subscriber
+	var publisher message.Publisher
+	router.AddHandler("invoicing", "orders", subscriber, "invoices", publisher, invoiceOrder)
+	router.AddNoPublisherHandler("shipping", "shipments", subscriber, func(msg *message.Message) error {
+		var shipment Shipment
+		return json.Unmarshal(msg.Payload, &shipment)
+	})
+}
+`,
+	})
+
+	doc, err := ParseFS(DirFS(root), Options{Discover: []string{DiscoverWatermill}})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+
+	type operation struct {
+		address     string
+		action      spec3.OperationAction
+		payload     string
+		description string
+	}
+	want := []operation{
+		{"orders", spec3.ActionSend, "Order", ""},
+		{"orders", spec3.ActionReceive, "Order", "invoiceOrder bills placed orders."},
+		{"invoices", spec3.ActionSend, "Invoice", "invoiceOrder bills placed orders."},
+		{"shipments", spec3.ActionReceive, "Shipment", ""},
+	}
+	var got []operation
+	for _, op := range doc.Operations {
+		o := operation{address: doc.Channels[strings.TrimPrefix(op.Channel.Ref, "#/channels/")].Address, action: op.Action, description: op.Description}
+		if len(op.Messages) > 0 {
+			ref := op.Messages[0].Ref
+			message := doc.Components.Messages[ref[strings.LastIndex(ref, "/")+1:]]
+			schema, _ := message.Payload.(map[string]interface{})
+			ref, _ = schema["$ref"].(string)
+			o.payload = strings.TrimPrefix(ref, "#/components/schemas/")
+		}
+		got = append(got, o)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("operations = %+v, want %+v", got, want)
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			found = found || g == w
+		}
+		if !found {
+			t.Errorf("operations = %+v, want %+v among them", got, w)
+		}
+	}
+}
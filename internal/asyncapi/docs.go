@@ -0,0 +1,146 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// typeSpec returns the declaration of a named type, when the syntax of its
+// package is available.
+func (tc *TypeChecker) typeSpec(obj *types.TypeName) (*ast.GenDecl, *ast.TypeSpec) {
+	for _, f := range tc.files[obj.Pkg()] {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Pos() > obj.Pos() || gen.End() < obj.Pos() {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok && typeSpec.Name.Pos() == obj.Pos() {
+					return gen, typeSpec
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// typeDoc returns the doc comment of a named type. A type declared alone,
+// "type X struct{...}", has its comment on the declaration rather than on
+// the type spec.
+func (tc *TypeChecker) typeDoc(obj *types.TypeName) *ast.CommentGroup {
+	gen, typeSpec := tc.typeSpec(obj)
+	if typeSpec == nil {
+		return nil
+	}
+	if typeSpec.Doc == nil && !gen.Lparen.IsValid() {
+		return gen.Doc
+	}
+	return typeSpec.Doc
+}
+
+// fieldDocs returns the doc comments of the fields of a struct type, keyed
+// by the position of the field names.
+func (tc *TypeChecker) fieldDocs(obj *types.TypeName) map[token.Pos]string {
+	_, typeSpec := tc.typeSpec(obj)
+	if typeSpec == nil {
+		return nil
+	}
+	structType, ok := typeSpec.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	docs := make(map[token.Pos]string)
+	for _, field := range structType.Fields.List {
+		doc := docDescription(field.Doc)
+		if doc == "" {
+			continue
+		}
+		for _, name := range field.Names {
+			docs[name.Pos()] = doc
+		}
+	}
+	return docs
+}
+
+// docDescription turns a doc comment into a schema description: annotation
+// lines such as "@format uuid" are dropped, the lines of a paragraph are
+// joined and paragraphs are separated by a blank line.
+func docDescription(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	return docText(strings.Split(doc.Text(), "\n"))
+}
+
+// docText joins the prose lines of a comment the way docDescription does.
+func docText(comments []string) string {
+	var paragraphs []string
+	var lines []string
+	for _, line := range comments {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "@") {
+			continue
+		}
+		if line == "" {
+			if len(lines) > 0 {
+				paragraphs = append(paragraphs, strings.Join(lines, " "))
+				lines = nil
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, " "))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// typeDescription returns the doc text of the named type, or "" when the
+// type is unknown.
+func (tc *TypeChecker) typeDescription(typeName string) string {
+	if tc == nil || tc.pkg == nil {
+		return ""
+	}
+	obj, ok := tc.lookupType(strings.TrimLeft(typeName, "[]*")).(*types.TypeName)
+	if !ok {
+		return ""
+	}
+	return docDescription(tc.typeDoc(obj))
+}
+
+// isDescriptionAttr reports whether the canonical annotation takes a
+// description, which may continue on the following lines.
+func isDescriptionAttr(attr string) bool {
+	switch attr {
+	case annotation.Description, annotation.OperationDescription, annotation.ChannelDescription, annotation.MessageDescription, annotation.ServerDescription:
+		return true
+	}
+	_, field, ok := namedServerAttr(attr)
+	return ok && field == "description"
+}
+
+// joinDescriptions appends the lines following a description annotation, up
+// to the next annotation, to the annotation line. They are joined with
+// newlines, so paragraphs, lists and other Markdown keep their layout.
+func joinDescriptions(comments []string) []string {
+	joined := make([]string, 0, len(comments))
+	continued := false
+	for _, line := range comments {
+		if fields := strings.Fields(line); len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+			continued = isDescriptionAttr(annotation.Canonical(fields[0]))
+			joined = append(joined, line)
+			continue
+		}
+		if continued {
+			joined[len(joined)-1] += "\n" + line
+			continue
+		}
+		joined = append(joined, line)
+	}
+	return joined
+}
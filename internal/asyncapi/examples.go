@@ -0,0 +1,152 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// exampleBody is the JSON accepted by @message.examples.
+type exampleBody struct {
+	Summary string                 `json:"summary"`
+	Headers map[string]interface{} `json:"headers"`
+	Payload interface{}            `json:"payload"`
+}
+
+// ParseMessageExample parses a named message example in format:
+// "name {"payload": {...}, "headers": {...}, "summary": "..."}".
+// The name is optional; the annotation can be repeated to add several examples.
+func (operation *Operation) ParseMessageExample(value string) error {
+	value = strings.TrimSpace(value)
+	name := ""
+	if idx := strings.Index(value, "{"); idx > 0 {
+		name = strings.TrimSpace(value[:idx])
+		value = value[idx:]
+	}
+
+	var body exampleBody
+	if err := json.Unmarshal([]byte(value), &body); err != nil {
+		return fmt.Errorf("invalid @message.examples JSON for %q: %w", name, err)
+	}
+	if body.Payload == nil && len(body.Headers) == 0 {
+		return fmt.Errorf("@message.examples %q must define payload or headers", name)
+	}
+
+	operation.Message.Examples = append(operation.Message.Examples, spec3.MessageExample{
+		Name:    name,
+		Summary: body.Summary,
+		Headers: body.Headers,
+		Payload: body.Payload,
+	})
+	return nil
+}
+
+// validateExample checks a decoded JSON value against a generated schema and
+// returns one problem per mismatch. Only the keywords produced by
+// GenerateJSONSchema are checked: type, properties, required, items and enum.
+func validateExample(schema map[string]interface{}, value interface{}, path string) []string {
+	if schema == nil {
+		return nil
+	}
+
+	var problems []string
+	schemaType, nullable := nullableType(schema)
+	if value == nil && nullable {
+		return nil
+	}
+	if schemaType != "" && !matchesSchemaType(schemaType, value) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, schemaType, jsonTypeName(value))}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", path, value, enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]string); ok {
+			for _, name := range required {
+				if _, has := v[name]; !has {
+					problems = append(problems, fmt.Sprintf("%s: missing required property %q", path, name))
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propSchema, ok := properties[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			problems = append(problems, validateExample(propSchema, v[key], path+"."+key)...)
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				problems = append(problems, validateExample(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return problems
+}
+
+func matchesSchemaType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == float64(int64(f))
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
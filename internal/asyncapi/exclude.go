@@ -0,0 +1,65 @@
+package asyncapi
+
+import (
+	"path"
+	"strings"
+)
+
+// excludeList holds the directory patterns given to -exclude. Patterns without
+// a slash match a directory name at any depth ("vendor", "test*"). Patterns
+// with a slash match the path relative to the source root, where "**" stands
+// for any number of directories ("**/internal/test*").
+type excludeList []string
+
+// parseExcludeList splits a comma-separated list of exclude patterns.
+func parseExcludeList(value string) excludeList {
+	var list excludeList
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if pattern != "" {
+			list = append(list, strings.TrimPrefix(pattern, "./"))
+		}
+	}
+	return list
+}
+
+// matches reports whether the directory at rel, a slash-separated path
+// relative to the source root, is excluded.
+func (l excludeList) matches(rel string) bool {
+	segments := strings.Split(rel, "/")
+	for _, pattern := range l {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, segments[len(segments)-1]); ok {
+				return true
+			}
+			continue
+		}
+		if matchSegments(strings.Split(pattern, "/"), segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where a "**"
+// segment matches zero or more path segments.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,30 @@
+package asyncapi
+
+import "testing"
+
+func TestExcludeListMatches(t *testing.T) {
+	exclude := parseExcludeList("vendor, mocks/ ,test*,**/internal/gen*,./tools/scripts")
+
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"vendor", true},
+		{"pkg/vendor", true},
+		{"mocks", true},
+		{"testutil", true},
+		{"handlers/testing", true},
+		{"internal/generated", true},
+		{"svc/internal/gen", true},
+		{"svc/internal/events", false},
+		{"tools/scripts", true},
+		{"cmd/tools/scripts", false},
+		{"handlers", false},
+	}
+
+	for _, tt := range tests {
+		if got := exclude.matches(tt.rel); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// extensionPrefix starts the key of specification extensions, written with
+// the @x-<key> annotations, e.g. "@x-team payments" or
+// "@channel.x-slo {"latency": "100ms"}".
+const extensionPrefix = "x-"
+
+// generatedExtensions are the extensions written from other annotations,
+// which @x- annotations cannot set.
+var generatedExtensions = []string{"x-producers", "x-consumers", "x-timeout", "x-qos", "x-error", "x-partition-key"}
+
+// extensionKey returns the extension key of an annotation made of prefix
+// and the key, e.g. "x-team" for "@channel.x-team" with the "@channel."
+// prefix. The prefix is matched case-insensitively; the key keeps its case.
+func extensionKey(attribute, prefix string) (string, bool) {
+	start := prefix + extensionPrefix
+	if len(attribute) <= len(start) || !strings.EqualFold(attribute[:len(start)], start) {
+		return "", false
+	}
+	return attribute[len(prefix):], true
+}
+
+// setExtension sets the extension key to value, decoded when it is JSON,
+// e.g. {"latency": "100ms"} or 99.9, and kept as a string otherwise.
+func setExtension(extensions *map[string]interface{}, key, value string) error {
+	if slices.Contains(generatedExtensions, strings.ToLower(key)) {
+		return fmt.Errorf("invalid @%s: the extension is generated from other annotations", key)
+	}
+	if value == "" {
+		return fmt.Errorf("invalid @%s: missing value", key)
+	}
+	var decoded interface{} = value
+	if json.Valid([]byte(value)) {
+		if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+			return fmt.Errorf("invalid @%s: %w", key, err)
+		}
+	}
+	if *extensions == nil {
+		*extensions = make(map[string]interface{})
+	}
+	(*extensions)[key] = decoded
+	return nil
+}
+
+// extensionTarget returns the extension key of the @x-<key> (or
+// @operation.x-<key>), @channel.x-<key> and @message.x-<key> annotations of an
+// operation, with the extensions it sets.
+func (operation *Operation) extensionTarget(attribute string) (string, *map[string]interface{}, bool) {
+	targets := []struct {
+		prefix     string
+		extensions *map[string]interface{}
+	}{
+		{"@", &operation.Extensions},
+		{"@operation.", &operation.Extensions},
+		{"@channel.", &operation.ChannelExtensions},
+		{"@message.", &operation.MessageExtensions},
+	}
+	for _, target := range targets {
+		if key, ok := extensionKey(attribute, target.prefix); ok {
+			return key, target.extensions, true
+		}
+	}
+	return "", nil, false
+}
+
+// mergeExtensions adds the extensions to those of a channel shared by
+// several operations; a later operation replaces the value of a key.
+func mergeExtensions(existing, extensions map[string]interface{}) map[string]interface{} {
+	if len(extensions) == 0 {
+		return existing
+	}
+	if existing == nil {
+		existing = make(map[string]interface{}, len(extensions))
+	}
+	maps.Copy(existing, extensions)
+	return existing
+}
@@ -0,0 +1,162 @@
+package asyncapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+	"golang.org/x/mod/modfile"
+)
+
+// dirFS is an fs.FS backed by an OS directory. ParseFS loads it with the go
+// tool instead of parsing it in memory.
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+// DirFS returns a file system for the OS directory dir. Sources read through
+// it are loaded with the go tool, so payload types declared in other packages
+// and modules resolve.
+func DirFS(dir string) fs.FS {
+	return dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+// ParseFS parses the Go sources in fsys and returns the generated document.
+// Only the root directory is parsed unless opts.Recursive is set.
+//
+// File systems from DirFS are loaded with the go tool. Any other fs.FS, such
+// as an in-memory file set, an embedded fixture or an extracted archive, is
+// parsed without touching the OS filesystem, which also works under
+// WebAssembly; there only types declared in the annotated package itself
+// resolve, and payload types from other packages fall back to an empty object.
+func ParseFS(fsys fs.FS, opts Options) (*spec3.AsyncAPI, error) {
+	pkgs, ignored, err := loadFS(fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+	return buildDocument(pkgs, ignored, opts)
+}
+
+// loadFS loads the source packages of fsys as described by ParseFS, with the
+// annotated files that are excluded or left out by their build constraints.
+func loadFS(fsys fs.FS, opts Options) ([]sourcePackage, []IgnoredFile, error) {
+	dirs := []string{"."}
+	var ignored []IgnoredFile
+	if opts.Recursive {
+		var excluded []string
+		var err error
+		dirs, excluded, err = sourceDirs(fsys, parseExcludeList(opts.ExcludeDirs), opts.Verbose)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to walk sources: %w", err)
+		}
+		if ignored, err = excludedFiles(fsys, excluded); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if d, ok := fsys.(dirFS); ok {
+		osDirs := make([]string, len(dirs))
+		for i, dir := range dirs {
+			osDirs[i] = filepath.Join(d.dir, filepath.FromSlash(dir))
+		}
+		pkgs, constrained, err := loadPackages(d.dir, osDirs, nil)
+		if abs, absErr := filepath.Abs(d.dir); absErr == nil {
+			// Name the files like the excluded ones, below the root as given
+			for i, file := range constrained {
+				if rel, relErr := filepath.Rel(abs, file.File); relErr == nil {
+					constrained[i].File = filepath.Join(d.dir, rel)
+				}
+			}
+		}
+		return pkgs, append(ignored, constrained...), err
+	}
+
+	fset := token.NewFileSet()
+	module := fsModulePath(fsys)
+	var pkgs []sourcePackage
+	for _, dir := range dirs {
+		dirPkgs, err := parseFSDir(fset, fsys, dir, module)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range dirPkgs {
+			dirPkgs[i].module = module
+		}
+		pkgs = append(pkgs, dirPkgs...)
+	}
+	return pkgs, ignored, nil
+}
+
+// fsModulePath returns the module path declared by the go.mod file at the
+// root of fsys, or "" when there is none.
+func fsModulePath(fsys fs.FS) string {
+	data, err := fs.ReadFile(fsys, "go.mod")
+	if err != nil {
+		return ""
+	}
+	return modfile.ModulePath(data)
+}
+
+// parseFSDir parses the non-test Go files in dir and type-checks them, one
+// package per package clause. Packages are given their import path within
+// module, or their name when the module is unknown.
+func parseFSDir(fset *token.FileSet, fsys fs.FS, dir, module string) ([]sourcePackage, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	files := make(map[string][]*ast.File)
+	fileNames := make(map[*ast.File]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		filename := path.Join(dir, name)
+		src, err := fs.ReadFile(fsys, filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		files[f.Name.Name] = append(files[f.Name.Name], f)
+		fileNames[f] = filename
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pkgs := make([]sourcePackage, 0, len(names))
+	for _, name := range names {
+		pkgPath := name
+		if module != "" {
+			pkgPath = path.Join(module, dir)
+		}
+		tc, err := NewTypeChecker(fset, files[name], pkgPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to type-check package %s: %w", name, err)
+		}
+		pkgs = append(pkgs, sourcePackage{
+			dir:   dir,
+			name:  name,
+			files: sortedFiles(files[name], fileNames),
+			tc:    tc,
+		})
+	}
+	return pkgs, nil
+}
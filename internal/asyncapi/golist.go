@@ -1,47 +0,0 @@
-package asyncapi
-
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"go/build"
-	"os/exec"
-)
-
-func listPackages(dir string, env []string, args ...string) (pkgs []*build.Package, finalErr error) {
-	//nolint:gosec // Command arguments are controlled and validated by the caller
-	cmd := exec.Command("go", append([]string{"list", "-json", "-e"}, args...)...)
-	cmd.Env = env
-	cmd.Dir = dir
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, err
-	}
-	var stderrBuf bytes.Buffer
-	cmd.Stderr = &stderrBuf
-	defer func() {
-		if stderrBuf.Len() > 0 {
-			finalErr = fmt.Errorf("%v\n%s", finalErr, stderrBuf.Bytes())
-		}
-	}()
-
-	err = cmd.Start()
-	if err != nil {
-		return nil, err
-	}
-	dec := json.NewDecoder(stdout)
-	for dec.More() {
-		var pkg build.Package
-		err = dec.Decode(&pkg)
-		if err != nil {
-			return nil, err
-		}
-		pkgs = append(pkgs, &pkg)
-	}
-	err = cmd.Wait()
-	if err != nil {
-		return nil, err
-	}
-	return pkgs, nil
-}
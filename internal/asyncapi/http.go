@@ -0,0 +1,58 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// httpMethods are the methods allowed in the HTTP operation binding.
+var httpMethods = map[string]bool{
+	"GET":     true,
+	"PUT":     true,
+	"POST":    true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"HEAD":    true,
+	"OPTIONS": true,
+	"CONNECT": true,
+	"TRACE":   true,
+}
+
+// ParseBindingHTTP parses an HTTP binding property, for webhooks and other
+// HTTP callbacks: the method and the Go type of the query parameters go to the
+// operation binding, the status code and the Go type of the headers to the
+// message binding.
+func (operation *Operation) ParseBindingHTTP(key, value string, tc *TypeChecker) error {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(key) {
+	case "bindingversion":
+		operation.setBindingVersion("http", value)
+	case "method":
+		method := strings.ToUpper(value)
+		if !httpMethods[method] {
+			return fmt.Errorf("invalid @binding.http.method %q", value)
+		}
+		operation.setBinding(operationBinding, "http", "method", method)
+	case "statuscode":
+		code, err := strconv.Atoi(value)
+		if err != nil || code < 100 || code > 599 {
+			return fmt.Errorf("invalid @binding.http.statusCode %q: must be an HTTP status code", value)
+		}
+		operation.setBinding(messageBinding, "http", "statusCode", code)
+	case "query", "headers":
+		name := strings.ToLower(key)
+		schema := GenerateJSONSchema(GetByNameType(value, tc))
+		if schema["type"] != "object" {
+			return fmt.Errorf("invalid @binding.http.%s %q: must be a struct type", key, value)
+		}
+		if name == "query" {
+			operation.setBinding(operationBinding, "http", name, schema)
+		} else {
+			operation.setBinding(messageBinding, "http", name, schema)
+		}
+	default:
+		return fmt.Errorf("unknown HTTP binding @binding.http.%s", key)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package asyncapi
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestParseBindingHTTP(t *testing.T) {
+	src := `
+package testpkg
+
+type CallbackQuery struct {
+	Signature string ` + "`json:\"signature\"`" + `
+}
+
+type CallbackHeaders struct {
+	Event string ` + "`json:\"X-Event\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		comment string
+		wantErr bool
+	}{
+		{"method", "@binding.http.method post", false},
+		{"invalid method", "@binding.http.method SEND", true},
+		{"status code", "@binding.http.statusCode 202", false},
+		{"invalid status code", "@binding.http.statusCode 42", true},
+		{"query", "@binding.http.query CallbackQuery", false},
+		{"headers case", "@binding.HTTP.headers CallbackHeaders", false},
+		{"non-struct query", "@binding.http.query int", true},
+		{"unknown", "@binding.http.path /hooks", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := NewOperation()
+			err := operation.ParseComment(tt.comment, tc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name hooks.order",
+		"@payload string",
+		"@binding.http.method POST",
+		"@binding.http.query CallbackQuery",
+		"@binding.http.statusCode 202",
+		"@binding.http.headers CallbackHeaders",
+		"@binding.http.bindingVersion 0.3.0",
+	}, tc)
+	operation, _ := parser.asyncAPI.Operations["publishHooksOrder"].Bindings["http"].(map[string]interface{})
+	if operation["method"] != "POST" || operation["bindingVersion"] != "0.3.0" {
+		t.Errorf("http operation binding = %v, want POST method and bindingVersion", operation)
+	}
+	if query, _ := operation["query"].(map[string]interface{}); query["type"] != "object" {
+		t.Errorf("query = %v, want object schema", query)
+	}
+	if _, ok := parser.asyncAPI.Channels["hooksOrder"].Bindings["http"]; ok {
+		t.Errorf("channel bindings = %v, want no http binding", parser.asyncAPI.Channels["hooksOrder"].Bindings)
+	}
+	if len(parser.asyncAPI.Components.Messages) == 0 {
+		t.Fatal("no component messages")
+	}
+	for name, message := range parser.asyncAPI.Components.Messages {
+		binding, _ := message.Bindings["http"].(map[string]interface{})
+		if binding["statusCode"] != 202 || binding["headers"] == nil {
+			t.Errorf("message %s http binding = %v, want statusCode and headers", name, binding)
+		}
+	}
+}
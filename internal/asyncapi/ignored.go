@@ -0,0 +1,121 @@
+package asyncapi
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Reasons why a source file was not parsed.
+const (
+	IgnoredExcluded        = "excluded directory"
+	IgnoredBuildConstraint = "build constraints"
+)
+
+// IgnoredFile is a source file holding annotations that were not parsed,
+// because its directory is excluded or its build constraints do not match
+// the current platform and build tags.
+type IgnoredFile struct {
+	File        string `json:"file"`
+	Reason      string `json:"reason"`
+	Annotations int    `json:"annotations"`
+}
+
+// annotatedComments returns the number of comment groups of the Go source
+// holding service-level, operation or directive annotations.
+func annotatedComments(filename string, src []byte) int {
+	f, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, c := range f.Comments {
+		comments := extractComment(c)
+		if isGeneralAPIComment(comments) || isServiceDocComment(filename, f, c, comments) ||
+			isOperationComment(comments) || len(directiveAnnotations(c)) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// excludedFiles returns the annotated Go files in the excluded directories
+// of fsys and below them. Test files are left out, as they never are parsed.
+func excludedFiles(fsys fs.FS, excluded []string) ([]IgnoredFile, error) {
+	var ignored []IgnoredFile
+	for _, dir := range excluded {
+		err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !isSourceFile(name) {
+				return nil
+			}
+			src, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+			if count := annotatedComments(name, src); count > 0 {
+				ignored = append(ignored, IgnoredFile{File: fsysPath(fsys, name), Reason: IgnoredExcluded, Annotations: count})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan excluded directory %s: %w", dir, err)
+		}
+	}
+	return ignored, nil
+}
+
+// constrainedFiles returns the annotated ones of the files left out by their
+// build constraints.
+func constrainedFiles(names []string) []IgnoredFile {
+	var ignored []IgnoredFile
+	for _, name := range names {
+		if !isSourceFile(name) {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		if count := annotatedComments(name, src); count > 0 {
+			ignored = append(ignored, IgnoredFile{File: name, Reason: IgnoredBuildConstraint, Annotations: count})
+		}
+	}
+	return ignored
+}
+
+// isSourceFile reports whether name is a Go file other than a test file.
+func isSourceFile(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// fsysPath returns the path of a file of fsys as the user knows it: on the
+// OS filesystem for DirFS, or within fsys otherwise.
+func fsysPath(fsys fs.FS, name string) string {
+	if d, ok := fsys.(dirFS); ok {
+		return filepath.Join(d.dir, filepath.FromSlash(name))
+	}
+	return path.Clean(name)
+}
+
+// ignoredNotice lists the annotated files that were not parsed, or returns
+// "" when there are none, so a channel missing from the output can be traced
+// to them.
+func ignoredNotice(ignored []IgnoredFile) string {
+	if len(ignored) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Notice: annotations in %d file(s) were not parsed:\n", len(ignored))
+	for _, file := range ignored {
+		fmt.Fprintf(&b, "  %s: %d annotated comment(s), %s\n", file.File, file.Annotations, file.Reason)
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package asyncapi
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseFSIgnoredExcludedFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go": {Data: []byte(`// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+// @type pub
+// @name order.created
+func PublishOrderCreated() {}
+
+func main() {}
+`)},
+		"legacy/legacy.go": {Data: []byte(`package legacy
+
+// @type pub
+// @name order.legacy
+func PublishLegacy() {}
+
+// @type sub
+// @name order.archived
+func OnOrderArchived() {}
+`)},
+		"legacy/plain.go":        {Data: []byte("package legacy\n\n// Helper is not annotated.\nfunc Helper() {}\n")},
+		"legacy/legacy_test.go":  {Data: []byte("package legacy\n\n// @type pub\n// @name order.test\nfunc PublishTest() {}\n")},
+		"mocks/orders/orders.go": {Data: []byte("package orders\n\n// @type sub\n// @name order.mock\nfunc OnMock() {}\n")},
+	}
+
+	var report Report
+	doc, err := ParseFS(fsys, Options{Recursive: true, ExcludeDirs: "legacy,mocks", Report: &report})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if len(doc.Operations) != 1 {
+		t.Errorf("operations = %d, want 1", len(doc.Operations))
+	}
+	want := []IgnoredFile{
+		{File: "legacy/legacy.go", Reason: IgnoredExcluded, Annotations: 2},
+		{File: "mocks/orders/orders.go", Reason: IgnoredExcluded, Annotations: 1},
+	}
+	if !reflect.DeepEqual(report.Ignored, want) {
+		t.Errorf("Report.Ignored = %+v, want %+v", report.Ignored, want)
+	}
+}
+
+func TestParseFSIgnoredBuildConstraints(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/svc\n\ngo 1.21\n",
+		"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+// @type pub
+// @name order.created
+func PublishOrderCreated() {}
+
+func main() {}
+`,
+		"enterprise.go": `//go:build enterprise
+
+package main
+
+// @type pub
+// @name order.audited
+func PublishOrderAudited() {}
+`,
+		"service_windows.go": `package main
+
+// Unannotated is only built on Windows.
+func Unannotated() {}
+`,
+	})
+
+	var report Report
+	doc, err := ParseFS(DirFS(root), Options{Report: &report})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if len(doc.Operations) != 1 {
+		t.Errorf("operations = %d, want 1", len(doc.Operations))
+	}
+	want := []IgnoredFile{
+		{File: filepath.Join(root, "enterprise.go"), Reason: IgnoredBuildConstraint, Annotations: 1},
+	}
+	if !reflect.DeepEqual(report.Ignored, want) {
+		t.Errorf("Report.Ignored = %+v, want %+v", report.Ignored, want)
+	}
+}
+
+func TestIgnoredNotice(t *testing.T) {
+	if got := ignoredNotice(nil); got != "" {
+		t.Errorf("ignoredNotice(nil) = %q, want empty", got)
+	}
+	got := ignoredNotice([]IgnoredFile{
+		{File: "legacy/legacy.go", Reason: IgnoredExcluded, Annotations: 2},
+		{File: "enterprise.go", Reason: IgnoredBuildConstraint, Annotations: 1},
+	})
+	for _, want := range []string{
+		"annotations in 2 file(s) were not parsed",
+		"legacy/legacy.go: 2 annotated comment(s), excluded directory",
+		"enterprise.go: 1 annotated comment(s), build constraints",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ignoredNotice() = %q, want it to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package asyncapi
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// Index lists the annotations of Go sources as the parser reads them, with
+// the problems found in them, for editor plugins that do not run the
+// language server.
+type Index struct {
+	Annotations []IndexEntry `json:"annotations"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// IndexEntry is an annotation line of a comment.
+type IndexEntry struct {
+	File string `json:"file"`
+	// Line and Column are the 1-based position of the annotation.
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value,omitempty"`
+	// Resolved is the value the parser reads when it differs from Value:
+	// the string of a "const:" channel name, or the import path and name of
+	// a payload, response or header type.
+	Resolved string `json:"resolved,omitempty"`
+	// Definition is the "file:line:column" position of the constant or type
+	// Resolved names.
+	Definition string `json:"definition,omitempty"`
+	// Target and Description come from the annotation registry; both are
+	// empty for unknown annotations.
+	Target      annotation.Target `json:"target,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// typeValuedAttrs are the annotations whose first value is a Go type.
+var typeValuedAttrs = []string{annotation.Payload, annotation.PayloadAlt, annotation.Response, annotation.ResponseError, annotation.MessageHeaders}
+
+// BuildIndex parses the Go sources in srcDir, whose sub-directories are
+// parsed too when it ends in "/..." or opts.Recursive is set, and indexes
+// their annotations: every line of the service-level and operation comments
+// starting with an annotation, and the known annotations of other comments,
+// such as the ones of payload types. The diagnostics are the ones of
+// Diagnose. In the VerifySubjectsError mode, subject mismatches fail the
+// indexing.
+func BuildIndex(srcDir string, opts Options) (*Index, error) {
+	p, err := configuredParser(opts)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := loadSourceDir(srcDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{
+		Annotations: []IndexEntry{},
+		Diagnostics: diagnosePackages(p, pkgs, opts),
+	}
+	if index.Diagnostics == nil {
+		index.Diagnostics = []Diagnostic{}
+	}
+	if opts.VerifySubjects == VerifySubjectsError {
+		if err := subjectMismatchError(p.warnings.occurrences); err != nil {
+			return nil, err
+		}
+	}
+	annotations := annotation.All()
+	for _, src := range pkgs {
+		for _, f := range src.files {
+			index.Annotations = append(index.Annotations, indexFile(src.dir, f, src.tc, annotations)...)
+		}
+	}
+	return index, nil
+}
+
+// loadSourceDir loads the packages of srcDir, and of its sub-directories
+// when it ends in "/...".
+func loadSourceDir(srcDir string, opts Options) ([]sourcePackage, error) {
+	root, recursive := strings.CutSuffix(filepath.ToSlash(srcDir), recursiveSuffix)
+	if recursive && root == "" {
+		root = "."
+	} else if !recursive {
+		root = srcDir
+	}
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, fmt.Errorf("source directory does not exist: %s", root)
+	}
+	opts.Recursive = opts.Recursive || recursive
+	pkgs, _, err := loadFS(DirFS(root), opts)
+	return pkgs, err
+}
+
+// indexFile returns the index entries of the comments of f.
+func indexFile(dir string, f file, tc *TypeChecker, annotations []annotation.Annotation) []IndexEntry {
+	var entries []IndexEntry
+	for _, c := range f.file.Comments {
+		comments := extractComment(c)
+		service := isGeneralAPIComment(comments) || isServiceDocComment(f.name, f.file, c, comments)
+		annotated := service || isOperationComment(comments)
+		for _, line := range annotationLines(tc.fset, c) {
+			found, known := annotationFor(annotations, line.name, service)
+			if !known && !annotated {
+				continue
+			}
+			entry := IndexEntry{
+				File:        filepath.Join(dir, f.name),
+				Line:        line.pos.Line,
+				Column:      line.pos.Column,
+				Attribute:   line.name,
+				Value:       strings.TrimSpace(line.text[len(line.name):]),
+				Target:      found.Target,
+				Description: found.Description,
+			}
+			entry.Resolved, entry.Definition = resolveIndexValue(line.name, entry.Value, tc)
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// annotationFor returns the annotation name is. Names read on several
+// objects, such as @description, resolve to the service-level one in service
+// comments and to the operation one elsewhere.
+func annotationFor(annotations []annotation.Annotation, name string, service bool) (annotation.Annotation, bool) {
+	var found []annotation.Annotation
+	for _, candidate := range annotations {
+		if candidate.Matches(name) {
+			found = append(found, candidate)
+		}
+	}
+	if len(found) == 0 {
+		return annotation.Annotation{}, false
+	}
+	for _, candidate := range found {
+		//nolint:exhaustive // Only tells service-level targets from the others
+		switch candidate.Target {
+		case annotation.TargetInfo, annotation.TargetServer, annotation.TargetComponents:
+			if service {
+				return candidate, true
+			}
+		default:
+			if !service {
+				return candidate, true
+			}
+		}
+	}
+	return found[0], true
+}
+
+// resolveIndexValue returns the value the parser reads for the value of an
+// annotation, when it differs, and the position of its declaration.
+func resolveIndexValue(attribute, value string, tc *TypeChecker) (resolved, definition string) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || tc == nil || tc.pkg == nil {
+		return "", ""
+	}
+	var obj types.Object
+	switch {
+	case strings.EqualFold(attribute, annotation.Name) && strings.HasPrefix(value, constPrefix):
+		name, err := tc.resolveConst(fields[0])
+		if err != nil {
+			return "", ""
+		}
+		resolved = name
+		obj = tc.lookupType(strings.TrimPrefix(fields[0], constPrefix))
+	case slices.ContainsFunc(typeValuedAttrs, func(attr string) bool { return strings.EqualFold(attr, attribute) }):
+		obj = tc.lookupType(baseTypeName(fields[0]))
+		if obj == nil || obj.Pkg() == nil {
+			return "", ""
+		}
+		resolved = obj.Pkg().Path() + "." + obj.Name()
+	}
+	if obj != nil && obj.Pos().IsValid() {
+		definition = tc.fset.Position(obj.Pos()).String()
+	}
+	return resolved, definition
+}
+
+// baseTypeName returns the named type of a type expression such as
+// "[]*events.Order", "map[string]Order" or "Page[Order]".
+func baseTypeName(typeName string) string {
+	for {
+		switch {
+		case strings.HasPrefix(typeName, "[]"):
+			typeName = typeName[len("[]"):]
+		case strings.HasPrefix(typeName, "*"):
+			typeName = typeName[len("*"):]
+		case strings.HasPrefix(typeName, "map["):
+			end := closingBracket(typeName, len("map"))
+			if end < 0 {
+				return typeName
+			}
+			typeName = typeName[end+1:]
+		default:
+			name, _, _ := strings.Cut(typeName, "[")
+			return name
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package asyncapi
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+func TestBuildIndex(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/svc\n\ngo 1.21\n",
+		"main.go": `// @title Orders API
+// @version 1.0.0
+// @description Order events
+package main
+
+import "example.com/svc/events"
+
+// @type pub
+// @name const:events.SubjectPlaced
+// @description Order placed
+// @payload []events.Order
+// @sumary Typo
+func PublishOrderPlaced(order events.Order) {}
+
+func main() {}
+`,
+		"events/events.go": `package events
+
+const SubjectPlaced = "order.placed"
+
+// OrderID identifies an order.
+// @format uuid
+type OrderID string
+
+type Order struct {
+	ID OrderID ` + "`json:\"id\"`" + `
+}
+`,
+	})
+
+	index, err := BuildIndex(root+"/...", Options{})
+	if err != nil {
+		t.Fatalf("BuildIndex() error = %v", err)
+	}
+
+	mainFile := filepath.Join(root, "main.go")
+	eventsFile := filepath.Join(root, "events", "events.go")
+	want := []IndexEntry{
+		{File: mainFile, Line: 1, Column: 4, Attribute: "@title", Value: "Orders API", Target: annotation.TargetInfo, Description: "Title of the API"},
+		{File: mainFile, Line: 2, Column: 4, Attribute: "@version", Value: "1.0.0", Target: annotation.TargetInfo, Description: "Version of the API"},
+		{File: mainFile, Line: 3, Column: 4, Attribute: "@description", Value: "Order events", Target: annotation.TargetInfo,
+			Description: "Description of the API, which may continue on the following lines"},
+		{File: mainFile, Line: 8, Column: 4, Attribute: "@type", Value: "pub", Target: annotation.TargetOperation,
+			Description: "Operation type: pub or sub"},
+		{File: mainFile, Line: 9, Column: 4, Attribute: "@name", Value: "const:events.SubjectPlaced", Target: annotation.TargetOperation,
+			Description: "Channel name, with {parameters}, or const: and a Go string constant",
+			Resolved:    "order.placed", Definition: eventsFile + ":3:7"},
+		{File: mainFile, Line: 10, Column: 4, Attribute: "@description", Value: "Order placed", Target: annotation.TargetOperation,
+			Description: "Description of the operation and its message, which may continue on the following lines"},
+		{File: mainFile, Line: 11, Column: 4, Attribute: "@payload", Value: "[]events.Order", Target: annotation.TargetOperation,
+			Description: "Go type of the message payload; repeated for alternative messages",
+			Resolved:    "example.com/svc/events.Order", Definition: eventsFile + ":9:6"},
+		{File: mainFile, Line: 12, Column: 4, Attribute: "@sumary", Value: "Typo"},
+		{File: eventsFile, Line: 6, Column: 4, Attribute: "@format", Value: "uuid", Target: annotation.TargetSchema,
+			Description: "Format of a defined primitive type, in its doc comment"},
+	}
+	if len(index.Annotations) != len(want) {
+		t.Fatalf("Annotations = %+v, want %d entries", index.Annotations, len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(index.Annotations[i], want[i]) {
+			t.Errorf("Annotations[%d] =\n%+v\nwant\n%+v", i, index.Annotations[i], want[i])
+		}
+	}
+
+	if len(index.Diagnostics) != 1 || index.Diagnostics[0].Message != "unknown annotation @sumary, did you mean @summary?" {
+		t.Errorf("Diagnostics = %+v, want @sumary", index.Diagnostics)
+	}
+}
+
+func TestBaseTypeName(t *testing.T) {
+	tests := map[string]string{
+		"Order":                   "Order",
+		"[]*events.Order":         "events.Order",
+		"map[string][]Order":      "Order",
+		"Page[events.Order]":      "Page",
+		"*map[[2]int]events.Item": "events.Item",
+	}
+	for typeName, want := range tests {
+		if got := baseTypeName(typeName); got != want {
+			t.Errorf("baseTypeName(%q) = %q, want %q", typeName, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// kafkaTopicConfigurationPrefix starts the topic configuration keys of the
+// Kafka channel binding, e.g. "topicConfiguration.retention.ms".
+const kafkaTopicConfigurationPrefix = "topicconfiguration."
+
+// kafkaBindingKeys maps the lowercase Kafka binding annotation keys to their
+// binding object and property name, as defined by the Kafka bindings.
+var kafkaBindingKeys = map[string]struct {
+	level bindingLevel
+	name  string
+}{
+	"topic":                   {channelBinding, "topic"},
+	"partitions":              {channelBinding, "partitions"},
+	"replicas":                {channelBinding, "replicas"},
+	"groupid":                 {operationBinding, "groupId"},
+	"clientid":                {operationBinding, "clientId"},
+	"key":                     {messageBinding, "key"},
+	"schemaidlocation":        {messageBinding, "schemaIdLocation"},
+	"schemaidpayloadencoding": {messageBinding, "schemaIdPayloadEncoding"},
+	"schemalookupstrategy":    {messageBinding, "schemaLookupStrategy"},
+}
+
+// kafkaTopicConfiguration holds the topic configuration properties of the
+// Kafka channel binding.
+var kafkaTopicConfiguration = map[string]bool{
+	"cleanup.policy":                        true,
+	"retention.ms":                          true,
+	"retention.bytes":                       true,
+	"delete.retention.ms":                   true,
+	"max.message.bytes":                     true,
+	"confluent.key.schema.validation":       true,
+	"confluent.key.subject.name.strategy":   true,
+	"confluent.value.schema.validation":     true,
+	"confluent.value.subject.name.strategy": true,
+}
+
+// ParseBindingKafka parses a Kafka binding property into the channel,
+// operation or message binding it belongs to. groupId and clientId become
+// schemas of their single value and key the schema of the named type.
+func (operation *Operation) ParseBindingKafka(key, value string, tc *TypeChecker) error {
+	value = strings.TrimSpace(value)
+	lowerKey := strings.ToLower(key)
+	if lowerKey == "bindingversion" {
+		operation.setBindingVersion("kafka", value)
+		return nil
+	}
+
+	if name, ok := strings.CutPrefix(lowerKey, kafkaTopicConfigurationPrefix); ok {
+		if !kafkaTopicConfiguration[name] {
+			return fmt.Errorf("unknown Kafka topic configuration @binding.kafka.%s", key)
+		}
+		binding := bindingOf(&operation.ChannelBindings, "kafka")
+		config, _ := binding["topicConfiguration"].(map[string]interface{})
+		if config == nil {
+			config = make(map[string]interface{})
+			binding["topicConfiguration"] = config
+		}
+		configValue, err := kafkaTopicConfigurationValue(name, value)
+		if err != nil {
+			return fmt.Errorf("invalid @binding.kafka.%s: %w", key, err)
+		}
+		config[name] = configValue
+		return nil
+	}
+
+	property, ok := kafkaBindingKeys[lowerKey]
+	if !ok {
+		return fmt.Errorf("unknown Kafka binding @binding.kafka.%s", key)
+	}
+	var bindingValue interface{} = value
+	switch property.name {
+	case "partitions", "replicas":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid @binding.kafka.%s %q: must be a positive integer", key, value)
+		}
+		bindingValue = n
+	case "groupId", "clientId":
+		bindingValue = map[string]interface{}{"type": "string", "enum": []interface{}{value}}
+	case "key":
+		bindingValue = GenerateJSONSchema(GetByNameType(value, tc))
+	}
+
+	operation.setBinding(property.level, "kafka", property.name, bindingValue)
+	return nil
+}
+
+// kafkaTopicConfigurationValue converts a topic configuration value to its
+// type: cleanup.policy is a list, sizes and durations are integers and the
+// schema validation flags are booleans.
+func kafkaTopicConfigurationValue(name, value string) (interface{}, error) {
+	switch {
+	case name == "cleanup.policy":
+		return bindingList(value), nil
+	case strings.HasSuffix(name, ".ms") || strings.HasSuffix(name, ".bytes"):
+		return strconv.ParseInt(value, 10, 64)
+	case strings.HasSuffix(name, ".validation"):
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
@@ -0,0 +1,151 @@
+package asyncapi
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestParseBindingKafka(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		level   bindingLevel
+		key     string
+		want    interface{}
+		wantErr bool
+	}{
+		{"topic", "@binding.kafka.topic orders", channelBinding, "topic", "orders", false},
+		{"partitions", "@binding.kafka.partitions 3", channelBinding, "partitions", 3, false},
+		{"invalid partitions", "@binding.kafka.partitions many", channelBinding, "", nil, true},
+		{"groupId", "@binding.kafka.groupId billing", operationBinding, "groupId", map[string]interface{}{"type": "string", "enum": []interface{}{"billing"}}, false},
+		{"clientId case", "@binding.Kafka.clientid billing-1", operationBinding, "clientId", map[string]interface{}{"type": "string", "enum": []interface{}{"billing-1"}}, false},
+		{"key", "@binding.kafka.key string", messageBinding, "key", map[string]interface{}{"type": "string"}, false},
+		{"schemaIdLocation", "@binding.kafka.schemaIdLocation payload", messageBinding, "schemaIdLocation", "payload", false},
+		{"unknown", "@binding.kafka.compression gzip", channelBinding, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := NewOperation()
+			err := operation.ParseComment(tt.comment, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			bindings := map[bindingLevel]map[string]interface{}{
+				channelBinding:   operation.ChannelBindings,
+				operationBinding: operation.Bindings,
+				messageBinding:   operation.MessageBindings,
+			}[tt.level]
+			kafka, _ := bindings["kafka"].(map[string]interface{})
+			if !reflect.DeepEqual(kafka[tt.key], tt.want) {
+				t.Errorf("%s = %#v, want %#v", tt.key, kafka[tt.key], tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBindingKafkaTopicConfiguration(t *testing.T) {
+	operation := NewOperation()
+	for _, comment := range []string{
+		"@binding.kafka.topicConfiguration.cleanup.policy delete, compact",
+		"@binding.kafka.topicConfiguration.retention.ms 604800000",
+		"@binding.kafka.topicConfiguration.confluent.value.schema.validation true",
+	} {
+		if err := operation.ParseComment(comment, nil); err != nil {
+			t.Fatalf("ParseComment(%q) error = %v", comment, err)
+		}
+	}
+	want := map[string]interface{}{
+		"cleanup.policy":                    []interface{}{"delete", "compact"},
+		"retention.ms":                      int64(604800000),
+		"confluent.value.schema.validation": true,
+	}
+	config := operation.ChannelBindings["kafka"].(map[string]interface{})["topicConfiguration"]
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("topicConfiguration = %v, want %v", config, want)
+	}
+
+	if err := operation.ParseComment("@binding.kafka.topicConfiguration.retention.ms forever", nil); err == nil {
+		t.Errorf("ParseComment(invalid retention.ms) error = nil, want error")
+	}
+}
+
+func TestParseOperationKafkaBindings(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderKey struct {
+	TenantID string ` + "`json:\"tenantId\"`" + `
+}
+
+type OrderPlaced struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type OrderAck struct {
+	OK bool ` + "`json:\"ok\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name orders",
+		"@payload OrderPlaced",
+		"@response OrderAck",
+		"@binding.kafka.topic orders",
+		"@binding.kafka.partitions 6",
+		"@binding.kafka.clientId order-service",
+		"@binding.kafka.key OrderKey",
+		"@binding.kafka.bindingVersion 0.5.0",
+	}, tc)
+	parser.ParseOperation([]string{
+		"@type sub",
+		"@name orders",
+		"@payload OrderPlaced",
+		"@binding.kafka.groupId billing",
+		"@binding.kafka.replicas 3",
+	}, tc)
+
+	channel := parser.asyncAPI.Channels["orders"]
+	wantChannel := map[string]interface{}{
+		"kafka": map[string]interface{}{"topic": "orders", "partitions": 6, "replicas": 3, "bindingVersion": "0.5.0"},
+	}
+	if !reflect.DeepEqual(channel.Bindings, wantChannel) {
+		t.Errorf("channel bindings = %v, want %v", channel.Bindings, wantChannel)
+	}
+	if reply := parser.asyncAPI.Channels["ordersReply"]; reply.Bindings != nil {
+		t.Errorf("reply channel bindings = %v, want none", reply.Bindings)
+	}
+
+	publish := parser.asyncAPI.Operations["requestOrders"].Bindings["kafka"].(map[string]interface{})
+	if _, ok := publish["topic"]; ok {
+		t.Errorf("operation bindings = %v, want no channel properties", publish)
+	}
+	if publish["bindingVersion"] != "0.5.0" || publish["clientId"] == nil {
+		t.Errorf("operation bindings = %v, want clientId and bindingVersion", publish)
+	}
+
+	message := parser.asyncAPI.Components.Messages["ordersMessage"]
+	key := message.Bindings["kafka"].(map[string]interface{})["key"].(map[string]interface{})
+	if _, ok := key["properties"].(map[string]interface{})["tenantId"]; !ok {
+		t.Errorf("message key = %v, want OrderKey schema", key)
+	}
+	if reply := parser.asyncAPI.Components.Messages["ordersReplyMessage"]; reply.Bindings != nil {
+		t.Errorf("reply message bindings = %v, want none", reply.Bindings)
+	}
+}
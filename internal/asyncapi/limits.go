@@ -0,0 +1,88 @@
+package asyncapi
+
+import "sort"
+
+// truncatedExtension marks schemas whose content was cut by SchemaLimits.
+const truncatedExtension = "x-truncated"
+
+// SchemaLimits bounds the size of generated payload schemas, so deeply nested
+// or very wide structs do not blow up the document. Zero disables a limit.
+type SchemaLimits struct {
+	// MaxDepth is the number of nested levels (object properties, array items
+	// and map values) kept below the root schema.
+	MaxDepth int
+	// MaxProperties is the number of properties kept per object.
+	MaxProperties int
+}
+
+// truncate applies the limits to schema in place and reports whether anything
+// was removed. Truncated schemas are marked with x-truncated.
+func (l SchemaLimits) truncate(schema map[string]interface{}) bool {
+	if l.MaxDepth <= 0 && l.MaxProperties <= 0 {
+		return false
+	}
+	return l.truncateAt(schema, 0)
+}
+
+func (l SchemaLimits) truncateAt(schema map[string]interface{}, depth int) bool {
+	if l.MaxDepth > 0 && depth >= l.MaxDepth {
+		truncated := false
+		for _, key := range []string{"properties", "required", "items", "additionalProperties"} {
+			if _, ok := schema[key]; ok {
+				delete(schema, key)
+				truncated = true
+			}
+		}
+		if truncated {
+			schema[truncatedExtension] = true
+		}
+		return truncated
+	}
+
+	truncated := false
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		if l.MaxProperties > 0 && len(properties) > l.MaxProperties {
+			l.dropProperties(schema, properties)
+			truncated = true
+		}
+		for _, property := range properties {
+			if child, ok := property.(map[string]interface{}); ok && l.truncateAt(child, depth+1) {
+				truncated = true
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties"} {
+		if child, ok := schema[key].(map[string]interface{}); ok && l.truncateAt(child, depth+1) {
+			truncated = true
+		}
+	}
+	return truncated
+}
+
+// dropProperties keeps the first MaxProperties properties in name order and
+// removes the rest, including from the required list.
+func (l SchemaLimits) dropProperties(schema, properties map[string]interface{}) {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names[l.MaxProperties:] {
+		delete(properties, name)
+	}
+
+	if required, ok := schema["required"].([]string); ok {
+		kept := required[:0]
+		for _, name := range required {
+			if _, ok := properties[name]; ok {
+				kept = append(kept, name)
+			}
+		}
+		if len(kept) == 0 {
+			delete(schema, "required")
+		} else {
+			schema["required"] = kept
+		}
+	}
+	schema[truncatedExtension] = true
+}
@@ -0,0 +1,94 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func nestedSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":    map[string]interface{}{"type": "string"},
+			"name":  map[string]interface{}{"type": "string"},
+			"email": map[string]interface{}{"type": "string"},
+			"address": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string"},
+				},
+			},
+			"tags": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+		},
+		"required": []string{"id", "name", "email"},
+	}
+}
+
+func TestSchemaLimitsTruncate(t *testing.T) {
+	tests := []struct {
+		name       string
+		limits     SchemaLimits
+		wantResult bool
+		check      func(t *testing.T, schema map[string]interface{})
+	}{
+		{
+			name:       "no limits",
+			limits:     SchemaLimits{},
+			wantResult: false,
+			check: func(t *testing.T, schema map[string]interface{}) {
+				if !reflect.DeepEqual(schema, nestedSchema()) {
+					t.Errorf("schema changed without limits: %v", schema)
+				}
+			},
+		},
+		{
+			name:       "max depth",
+			limits:     SchemaLimits{MaxDepth: 1},
+			wantResult: true,
+			check: func(t *testing.T, schema map[string]interface{}) {
+				properties := schema["properties"].(map[string]interface{})
+				address := properties["address"].(map[string]interface{})
+				if _, ok := address["properties"]; ok || address[truncatedExtension] != true {
+					t.Errorf("address = %v, want properties removed and %s", address, truncatedExtension)
+				}
+				tags := properties["tags"].(map[string]interface{})
+				if _, ok := tags["items"]; ok || tags[truncatedExtension] != true {
+					t.Errorf("tags = %v, want items removed and %s", tags, truncatedExtension)
+				}
+				if _, ok := properties["id"].(map[string]interface{})[truncatedExtension]; ok {
+					t.Error("scalar property marked as truncated")
+				}
+			},
+		},
+		{
+			name:       "max properties",
+			limits:     SchemaLimits{MaxProperties: 2},
+			wantResult: true,
+			check: func(t *testing.T, schema map[string]interface{}) {
+				properties := schema["properties"].(map[string]interface{})
+				if len(properties) != 2 || properties["address"] == nil || properties["email"] == nil {
+					t.Errorf("properties = %v, want address and email", properties)
+				}
+				if !reflect.DeepEqual(schema["required"], []string{"email"}) {
+					t.Errorf("required = %v, want [email]", schema["required"])
+				}
+				if schema[truncatedExtension] != true {
+					t.Errorf("root schema missing %s", truncatedExtension)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := nestedSchema()
+			if got := tt.limits.truncate(schema); got != tt.wantResult {
+				t.Errorf("truncate() = %v, want %v", got, tt.wantResult)
+			}
+			tt.check(t, schema)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package asyncapi
+
+import (
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// componentSchemaPrefix starts the references to component schemas.
+const componentSchemaPrefix = "#/components/schemas/"
+
+// checkMessageKey warns when the @message.key path does not name a field of
+// the payload schema. Fields behind a $ref are checked once the referenced
+// schemas are known, by applyMessageKeys.
+func (p *Parser) checkMessageKey(messageName, key string, schema map[string]interface{}) {
+	for _, field := range strings.Split(key, ".") {
+		if _, ok := schema["$ref"]; ok {
+			return
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		child, ok := properties[field].(map[string]interface{})
+		if !ok {
+			p.warnings.warnf(warnAnnotation, "@message.key %q of message %s is not a field of its payload", key, messageName)
+			return
+		}
+		schema = child
+	}
+}
+
+// applyMessageKeys documents the @message.key of each message. When the API
+// is served over Kafka the key becomes the key schema of the Kafka message
+// binding, a reference to the payload field; other protocols keep the
+// x-partition-key extension. An explicit @binding.kafka.key takes precedence.
+func (p *Parser) applyMessageKeys() {
+	kafka := false
+	for _, server := range p.asyncAPI.Servers {
+		if protocol := strings.ToLower(server.Protocol); protocol == "kafka" || protocol == "kafka-secure" {
+			kafka = true
+		}
+	}
+	if !kafka {
+		return
+	}
+
+	for name, message := range p.asyncAPI.Components.Messages {
+		if message.PartitionKey == "" {
+			continue
+		}
+		if binding, ok := message.Bindings["kafka"].(map[string]interface{}); ok && binding["key"] != nil {
+			continue
+		}
+		ref, ok := p.payloadFieldRef(message, message.PartitionKey)
+		if !ok {
+			continue
+		}
+		// Message bindings may be shared with the other messages of the
+		// operation, so they are copied before the key is added
+		bindings := make(map[string]interface{}, len(message.Bindings)+1)
+		for protocol, binding := range message.Bindings {
+			bindings[protocol] = binding
+		}
+		kafkaBinding := map[string]interface{}{}
+		if binding, ok := bindings["kafka"].(map[string]interface{}); ok {
+			for key, value := range binding {
+				kafkaBinding[key] = value
+			}
+		}
+		kafkaBinding["key"] = map[string]interface{}{"$ref": ref}
+		bindings["kafka"] = kafkaBinding
+		message.Bindings = bindings
+		message.PartitionKey = ""
+		p.asyncAPI.Components.Messages[name] = message
+	}
+}
+
+// payloadFieldRef returns a reference to the schema of the payload field at
+// the dotted path, following the component schemas it goes through. ok is
+// false when the payload is not a component schema or has no such field.
+func (p *Parser) payloadFieldRef(message spec3.Message, path string) (string, bool) {
+	payload, _ := message.Payload.(map[string]interface{})
+	ref, _ := payload["$ref"].(string)
+	var schema map[string]interface{}
+	for _, field := range strings.Split(path, ".") {
+		if schema == nil {
+			name, ok := strings.CutPrefix(ref, componentSchemaPrefix)
+			if !ok {
+				return "", false
+			}
+			if schema, ok = p.asyncAPI.Components.Schemas[name].(map[string]interface{}); !ok {
+				return "", false
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		child, ok := properties[field].(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if next, ok := child["$ref"].(string); ok {
+			// The field is a component schema of its own
+			ref, schema = next, nil
+			continue
+		}
+		ref += "/properties/" + jsonPointerEscape(field)
+		schema = child
+	}
+	return ref, true
+}
+
+// jsonPointerEscape escapes a JSON Pointer reference token.
+func jsonPointerEscape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,169 @@
+package asyncapi
+
+import (
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// emptySchemaKeys are the schema keywords dropped when empty: an empty
+// properties or required list says nothing about the object.
+var emptySchemaKeys = []string{"properties", "required", "patternProperties"}
+
+// MinimizeOptions configures Minimize.
+type MinimizeOptions struct {
+	// KeepOperationMessages keeps the messages list of operations that send
+	// or receive the only message of their channel.
+	KeepOperationMessages bool
+}
+
+// Minimize removes what carries no information from the document: empty
+// bindings, empty schema keywords, the placeholder descriptions generated
+// from names, such as a parameter described by its own name, and the
+// messages list of operations on single-message channels, which defaults to
+// every message of the channel. The document is modified in place.
+func Minimize(doc *spec3.AsyncAPI, opts MinimizeOptions) {
+	for name, server := range doc.Servers {
+		server.Bindings = minimizeBindings(server.Bindings)
+		doc.Servers[name] = server
+	}
+	for name, channel := range doc.Channels {
+		minimizeParameters(channel.Parameters)
+		channel.Bindings = minimizeBindings(channel.Bindings)
+		doc.Channels[name] = channel
+	}
+	for name, operation := range doc.Operations {
+		operation.Bindings = minimizeBindings(operation.Bindings)
+		if !opts.KeepOperationMessages {
+			operation.Messages = channelDefaultMessages(doc, operation.Channel, operation.Messages)
+			if reply := operation.Reply; reply != nil && reply.Channel != nil {
+				reply.Messages = channelDefaultMessages(doc, *reply.Channel, reply.Messages)
+			}
+		}
+		doc.Operations[name] = operation
+	}
+	if doc.Components == nil {
+		return
+	}
+	minimizeParameters(doc.Components.Parameters)
+	for _, schema := range doc.Components.Schemas {
+		minimizeSchema(schema)
+	}
+	for name, message := range doc.Components.Messages {
+		minimizeSchema(message.Payload)
+		minimizeSchema(message.Headers)
+		message.Bindings = minimizeBindings(message.Bindings)
+		doc.Components.Messages[name] = message
+	}
+}
+
+// channelDefaultMessages returns nil when messages only lists the single
+// message of the referenced channel, which an omitted list stands for, and
+// messages otherwise.
+func channelDefaultMessages(doc *spec3.AsyncAPI, channelRef spec3.Reference, messages []spec3.Reference) []spec3.Reference {
+	key, ok := strings.CutPrefix(channelRef.Ref, "#/channels/")
+	if !ok || len(messages) != 1 {
+		return messages
+	}
+	channel, ok := doc.Channels[key]
+	if !ok || len(channel.Messages) != 1 {
+		return messages
+	}
+	for name := range channel.Messages {
+		if messages[0].Ref != channelRef.Ref+"/messages/"+name {
+			return messages
+		}
+	}
+	return nil
+}
+
+// minimizeParameters drops parameter descriptions that only repeat the
+// parameter name.
+func minimizeParameters(params map[string]spec3.Parameter) {
+	for name, param := range params {
+		if strings.EqualFold(param.Description, name) {
+			param.Description = ""
+			params[name] = param
+		}
+	}
+}
+
+// minimizeSchema drops empty schema keywords and property descriptions that
+// only repeat the property name, recursively.
+func minimizeSchema(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if properties, ok := v["properties"].(map[string]interface{}); ok {
+			for name, property := range properties {
+				if property, ok := property.(map[string]interface{}); ok {
+					if description, ok := property["description"].(string); ok && strings.EqualFold(description, name) {
+						delete(property, "description")
+					}
+				}
+			}
+		}
+		for _, key := range emptySchemaKeys {
+			if isEmptyValue(v[key]) {
+				delete(v, key)
+			}
+		}
+		for key, child := range v {
+			switch key {
+			case "default", "const", "enum", "example", "examples":
+				// Default and example values are data, not schemas
+			case "properties", "patternProperties", "$defs", "definitions":
+				// Maps of names to schemas, whose names may be keywords
+				if schemas, ok := child.(map[string]interface{}); ok {
+					for _, schema := range schemas {
+						minimizeSchema(schema)
+					}
+				}
+			default:
+				minimizeSchema(child)
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			minimizeSchema(child)
+		}
+	}
+}
+
+// minimizeBindings drops empty binding properties and the protocols left
+// without any, returning nil when no binding remains. A bindingVersion alone
+// describes nothing, so its binding is dropped too.
+func minimizeBindings(bindings map[string]interface{}) map[string]interface{} {
+	for protocol, binding := range bindings {
+		if properties, ok := binding.(map[string]interface{}); ok {
+			for key, value := range properties {
+				if isEmptyValue(value) {
+					delete(properties, key)
+				}
+			}
+			if _, ok := properties["bindingVersion"]; ok && len(properties) == 1 {
+				delete(bindings, protocol)
+				continue
+			}
+		}
+		if isEmptyValue(binding) {
+			delete(bindings, protocol)
+		}
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+	return bindings
+}
+
+// isEmptyValue reports whether value is an empty map or list.
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func TestMinimize(t *testing.T) {
+	doc := spec3.NewAsyncAPI()
+	doc.Channels["orderPlaced"] = spec3.Channel{
+		Address: "order.{orderId}.placed",
+		Parameters: map[string]spec3.Parameter{
+			"orderId": {Description: "orderId"},
+			"region":  {Description: "Sales region"},
+		},
+		Bindings: map[string]interface{}{
+			"nats": map[string]interface{}{},
+			"amqp": map[string]interface{}{"is": "routingKey", "cc": []interface{}{}},
+		},
+	}
+	doc.Operations["publishOrderPlaced"] = spec3.Operation{
+		Bindings: map[string]interface{}{
+			"nats":  map[string]interface{}{},
+			"kafka": map[string]interface{}{"bindingVersion": "0.5.0", "clientId": map[string]interface{}{}},
+		},
+	}
+	doc.Components.Schemas["Order"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":       map[string]interface{}{"type": "string", "description": "id"},
+			"required": map[string]interface{}{},
+			"meta": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+				"required":   []interface{}{},
+				"default":    map[string]interface{}{"properties": map[string]interface{}{}},
+			},
+		},
+		"required": []string{},
+	}
+
+	Minimize(doc, MinimizeOptions{})
+
+	channel := doc.Channels["orderPlaced"]
+	wantParams := map[string]spec3.Parameter{"orderId": {}, "region": {Description: "Sales region"}}
+	if !reflect.DeepEqual(channel.Parameters, wantParams) {
+		t.Errorf("Parameters = %v, want %v", channel.Parameters, wantParams)
+	}
+	wantBindings := map[string]interface{}{"amqp": map[string]interface{}{"is": "routingKey"}}
+	if !reflect.DeepEqual(channel.Bindings, wantBindings) {
+		t.Errorf("channel Bindings = %v, want %v", channel.Bindings, wantBindings)
+	}
+	if bindings := doc.Operations["publishOrderPlaced"].Bindings; bindings != nil {
+		t.Errorf("operation Bindings = %v, want nil", bindings)
+	}
+
+	want := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":       map[string]interface{}{"type": "string"},
+			"required": map[string]interface{}{},
+			"meta": map[string]interface{}{
+				"type":    "object",
+				"default": map[string]interface{}{"properties": map[string]interface{}{}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(doc.Components.Schemas["Order"], want) {
+		t.Errorf("Order = %v, want %v", doc.Components.Schemas["Order"], want)
+	}
+}
+
+func TestMinimizeOperationMessages(t *testing.T) {
+	newDoc := func() *spec3.AsyncAPI {
+		doc := spec3.NewAsyncAPI()
+		doc.Channels["orders"] = spec3.Channel{Messages: map[string]spec3.MessageRef{
+			"ordersMessage": {Ref: "#/components/messages/ordersMessage"},
+		}}
+		doc.Channels["ordersReply"] = spec3.Channel{Messages: map[string]spec3.MessageRef{
+			"ordersReplyMessage": {Ref: "#/components/messages/ordersReplyMessage"},
+			"ordersError":        {Ref: "#/components/messages/ordersError"},
+		}}
+		doc.Operations["requestOrders"] = spec3.Operation{
+			Channel:  spec3.Reference{Ref: "#/channels/orders"},
+			Messages: []spec3.Reference{{Ref: "#/channels/orders/messages/ordersMessage"}},
+			Reply: &spec3.OperationReply{
+				Channel:  &spec3.Reference{Ref: "#/channels/ordersReply"},
+				Messages: []spec3.Reference{{Ref: "#/channels/ordersReply/messages/ordersReplyMessage"}},
+			},
+		}
+		return doc
+	}
+
+	doc := newDoc()
+	Minimize(doc, MinimizeOptions{})
+	operation := doc.Operations["requestOrders"]
+	if operation.Messages != nil {
+		t.Errorf("Messages = %v, want nil for the only message of the channel", operation.Messages)
+	}
+	if len(operation.Reply.Messages) != 1 {
+		t.Errorf("Reply.Messages = %v, want the reply message of a two-message channel", operation.Reply.Messages)
+	}
+
+	doc = newDoc()
+	Minimize(doc, MinimizeOptions{KeepOperationMessages: true})
+	if operation := doc.Operations["requestOrders"]; len(operation.Messages) != 1 {
+		t.Errorf("Messages = %v, want kept with KeepOperationMessages", operation.Messages)
+	}
+}
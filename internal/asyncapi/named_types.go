@@ -0,0 +1,131 @@
+package asyncapi
+
+import (
+	"fmt"
+	"go/constant"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// constPrefix marks an annotation value naming a Go string constant rather
+// than giving the value itself, e.g. "@name const:SubjectUserCreated" or
+// "@name const:events.SubjectUserCreated".
+const constPrefix = "const:"
+
+// resolveConst returns value, or the value of the string constant it names
+// with constPrefix.
+func (tc *TypeChecker) resolveConst(value string) (string, error) {
+	name, ok := strings.CutPrefix(value, constPrefix)
+	if !ok {
+		return value, nil
+	}
+	if tc == nil || tc.pkg == nil {
+		return "", fmt.Errorf("constant %s cannot be resolved without type information", name)
+	}
+	c, ok := tc.lookupType(name).(*types.Const)
+	if !ok {
+		return "", fmt.Errorf("constant not found: %s", name)
+	}
+	if c.Val().Kind() != constant.String {
+		return "", fmt.Errorf("constant %s is not a string", name)
+	}
+	return constant.StringVal(c.Val()), nil
+}
+
+// namedPrimitiveSchema returns the schema of a defined primitive type such as
+// "type UserID string", or of an alias of a primitive: the primitive's schema
+// titled with the type name, an enum of the constants declared with the type,
+// and the description and @format, @validate and @example annotations of its
+// doc comment:
+//
+//	// @validate min=1,max=64
+//	type Status string
+//
+//	const (
+//		StatusOpen   Status = "open"
+//		StatusClosed Status = "closed"
+//	)
+func (tc *TypeChecker) namedPrimitiveSchema(typeName string) (map[string]interface{}, bool) {
+	obj, ok := tc.lookupType(typeName).(*types.TypeName)
+	if !ok {
+		return nil, false
+	}
+	basic, ok := types.Unalias(obj.Type()).Underlying().(*types.Basic)
+	if !ok || basic.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) == 0 {
+		return nil, false
+	}
+
+	var schema map[string]interface{}
+	if names := tc.protoEnumNames(obj); names != nil {
+		// protojson writes protobuf enums as their value names
+		schema = map[string]interface{}{"type": "string", "enum": names}
+	} else {
+		schema = generateSchemaForType(tc.getReflectTypeFromString(types.Typ[basic.Kind()].Name()))
+		if enum := tc.enumValues(obj); len(enum) > 0 {
+			schema["enum"] = enum
+		}
+	}
+	schema["title"] = obj.Name()
+
+	if doc := tc.typeDoc(obj); doc != nil {
+		if description := docDescription(doc); description != "" {
+			schema["description"] = description
+		}
+		for _, line := range strings.Split(doc.Text(), "\n") {
+			attribute, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+			value = strings.TrimSpace(value)
+			switch annotation.Canonical(attribute) {
+			case annotation.Format:
+				schema["format"] = value
+			case annotation.Validate:
+				applyValidationRules(schema, value)
+			case annotation.Example:
+				schema["example"] = parseExampleValue(value, schema)
+			}
+		}
+	}
+	return schema, true
+}
+
+// enumValues returns the values of the constants declared with the named
+// type, in declaration order. Constants of standard library types, such as
+// time.Hour, are units rather than the only allowed values and are skipped.
+func (tc *TypeChecker) enumValues(obj *types.TypeName) []interface{} {
+	pkg := obj.Pkg()
+	if pkg == nil || (pkg != tc.pkg && !strings.Contains(strings.Split(pkg.Path(), "/")[0], ".")) {
+		return nil
+	}
+
+	var consts []*types.Const
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(c.Type(), obj.Type()) {
+			consts = append(consts, c)
+		}
+	}
+	sort.Slice(consts, func(i, j int) bool {
+		return consts[i].Pos() < consts[j].Pos()
+	})
+
+	values := make([]interface{}, 0, len(consts))
+	for _, c := range consts {
+		//nolint:exhaustive // Typed constants of primitive types are never unknown or complex
+		switch c.Val().Kind() {
+		case constant.String:
+			values = append(values, constant.StringVal(c.Val()))
+		case constant.Bool:
+			values = append(values, constant.BoolVal(c.Val()))
+		case constant.Int:
+			if v, exact := constant.Int64Val(c.Val()); exact {
+				values = append(values, v)
+			}
+		case constant.Float:
+			v, _ := constant.Float64Val(c.Val())
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,161 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Strategies naming the generated channel, operation and message keys.
+const (
+	// NamingCamelCase joins words in camelCase, e.g. "publishUserCreated",
+	// the default.
+	NamingCamelCase = "camelCase"
+	// NamingSnakeCase joins lowercase words with underscores, e.g.
+	// "publish_user_created".
+	NamingSnakeCase = "snake_case"
+	// NamingKebabCase joins lowercase words with dashes, e.g.
+	// "publish-user-created".
+	NamingKebabCase = "kebab-case"
+	// NamingGoTypeName names messages after their Go payload type, e.g.
+	// "UserCreatedEvent", and joins the words of other keys like Go
+	// exported identifiers, e.g. "PublishUserCreated".
+	NamingGoTypeName = "go-type-name"
+)
+
+// validNaming reports an error for an unknown naming strategy.
+func validNaming(strategy string) error {
+	switch strategy {
+	case "", NamingCamelCase, NamingSnakeCase, NamingKebabCase, NamingGoTypeName:
+		return nil
+	}
+	return fmt.Errorf("unknown naming strategy %q (want %s, %s, %s or %s)",
+		strategy, NamingCamelCase, NamingSnakeCase, NamingKebabCase, NamingGoTypeName)
+}
+
+// naming builds generated keys following a naming strategy; the zero value
+// is NamingCamelCase.
+type naming string
+
+// channelKey returns the key of the channel at address, e.g. "userIdUpdated"
+// or "user_id_updated" for "user.{id}.updated".
+func (n naming) channelKey(address string) string {
+	switch n {
+	case NamingSnakeCase, NamingKebabCase:
+		return n.join(address)
+	case NamingGoTypeName:
+		return upperFirst(toChannelName(address))
+	default:
+		return toChannelName(address)
+	}
+}
+
+// join joins the parts of a key, each being a key of the strategy or a
+// word, e.g. ("publish", "userCreated") -> "publishUserCreated". Empty parts
+// add nothing.
+func (n naming) join(parts ...string) string {
+	switch n {
+	case NamingSnakeCase, NamingKebabCase:
+		var words []string
+		for _, part := range parts {
+			for _, word := range splitWords(part) {
+				words = append(words, strings.ToLower(word))
+			}
+		}
+		if n == NamingSnakeCase {
+			return strings.Join(words, "_")
+		}
+		return strings.Join(words, "-")
+	case NamingGoTypeName:
+		return upperFirst(naming(NamingCamelCase).join(parts...))
+	default:
+		var b strings.Builder
+		for i, part := range parts {
+			if i > 0 {
+				part = upperFirst(part)
+			}
+			b.WriteString(part)
+		}
+		return b.String()
+	}
+}
+
+// messageName names the message with the Go type typeName on a channel:
+// after the channel, e.g. "userCreatedMessage", or with NamingGoTypeName
+// after the Go type when there is one.
+func (n naming) messageName(channelName, typeName string) string {
+	if n == NamingGoTypeName {
+		if name := goTypeBaseName(typeName); name != "" {
+			return upperFirst(name)
+		}
+	}
+	return n.join(channelName, "Message")
+}
+
+// altMessageName names an additional payload message after its Go type,
+// e.g. ("orderEvents", "events.OrderShipped") -> "orderEventsOrderShippedMessage".
+func (n naming) altMessageName(channelName, typeName string) string {
+	return n.join(channelName, goTypeBaseName(typeName), "Message")
+}
+
+// messageBase strips the "Message" word that ends generated message names.
+func (n naming) messageBase(name string) string {
+	switch n {
+	case NamingSnakeCase:
+		return strings.TrimSuffix(name, "_message")
+	case NamingKebabCase:
+		return strings.TrimSuffix(name, "-message")
+	default:
+		return strings.TrimSuffix(name, "Message")
+	}
+}
+
+// goTypeBaseName returns the name of a Go type without its package and
+// slice prefix, e.g. "OrderShipped" for "[]events.OrderShipped".
+func goTypeBaseName(typeName string) string {
+	typeName = strings.TrimPrefix(typeName, "[]")
+	if idx := strings.LastIndex(typeName, "."); idx != -1 {
+		typeName = typeName[idx+1:]
+	}
+	return typeName
+}
+
+// splitWords splits a key or address into words at separators and case
+// changes, e.g. "user.{id}.HTTPUpdated" -> [user id HTTP Updated].
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// upperFirst uppercases the first letter of s.
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
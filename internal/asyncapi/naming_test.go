@@ -0,0 +1,93 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNamingKeys(t *testing.T) {
+	tests := []struct {
+		strategy    string
+		channel     string
+		operation   string
+		message     string
+		typeMessage string
+		altMessage  string
+	}{
+		{NamingCamelCase, "userIdUpdated", "publishUserIdUpdated", "userIdUpdatedMessage", "userIdUpdatedMessage", "userIdUpdatedOrderShippedMessage"},
+		{"", "userIdUpdated", "publishUserIdUpdated", "userIdUpdatedMessage", "userIdUpdatedMessage", "userIdUpdatedOrderShippedMessage"},
+		{NamingSnakeCase, "user_id_updated", "publish_user_id_updated", "user_id_updated_message", "user_id_updated_message", "user_id_updated_order_shipped_message"},
+		{NamingKebabCase, "user-id-updated", "publish-user-id-updated", "user-id-updated-message", "user-id-updated-message", "user-id-updated-order-shipped-message"},
+		{NamingGoTypeName, "UserIdUpdated", "PublishUserIdUpdated", "UserIdUpdatedMessage", "UserUpdated", "UserIdUpdatedOrderShippedMessage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			n := naming(tt.strategy)
+			channel := n.channelKey("user.{id}.updated")
+			if channel != tt.channel {
+				t.Errorf("channelKey() = %q, want %q", channel, tt.channel)
+			}
+			if got := n.join("publish", channel); got != tt.operation {
+				t.Errorf("join(publish) = %q, want %q", got, tt.operation)
+			}
+			if got := n.messageName(channel, ""); got != tt.message {
+				t.Errorf("messageName() = %q, want %q", got, tt.message)
+			}
+			if got := n.messageName(channel, "events.UserUpdated"); got != tt.typeMessage {
+				t.Errorf("messageName(events.UserUpdated) = %q, want %q", got, tt.typeMessage)
+			}
+			if got := n.altMessageName(channel, "[]events.OrderShipped"); got != tt.altMessage {
+				t.Errorf("altMessageName() = %q, want %q", got, tt.altMessage)
+			}
+			if got := n.messageBase(tt.message); got != channel {
+				t.Errorf("messageBase(%q) = %q, want %q", tt.message, got, channel)
+			}
+		})
+	}
+
+	if err := validNaming("PascalCase"); err == nil {
+		t.Error("validNaming(PascalCase) error = nil, want error")
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"user.created", []string{"user", "created"}},
+		{"orders.{orderId}.placed", []string{"orders", "order", "Id", "placed"}},
+		{"publishHTTPRequest", []string{"publish", "HTTP", "Request"}},
+		{"v2Events", []string{"v2", "Events"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := splitWords(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestProcessOperationNaming(t *testing.T) {
+	parser := NewParser()
+	parser.naming = NamingSnakeCase
+	parser.ParseOperation([]string{"@type pub", "@name user.created"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name user-created"}, nil)
+
+	for key, address := range map[string]string{
+		"user_created":  "user.created",
+		"user_created2": "user-created",
+	} {
+		if got := parser.asyncAPI.Channels[key].Address; got != address {
+			t.Errorf("channel %s address = %q, want %q", key, got, address)
+		}
+	}
+	for _, name := range []string{"publish_user_created", "publish_user_created2"} {
+		if _, ok := parser.asyncAPI.Operations[name]; !ok {
+			t.Errorf("operation %s missing, have %v", name, parser.asyncAPI.Operations)
+		}
+	}
+	if _, ok := parser.asyncAPI.Components.Messages["user_created_message"]; !ok {
+		t.Errorf("message user_created_message missing, have %v", parser.asyncAPI.Components.Messages)
+	}
+}
@@ -0,0 +1,87 @@
+package asyncapi
+
+// nullType is the schema of a JSON null.
+const nullType = "null"
+
+// nullableSchema makes schema also accept null, as a pointer field does. A
+// typed schema gets null added to its type and enum; other schemas, such as
+// $ref, are wrapped in a oneOf with the null type.
+func nullableSchema(schema map[string]interface{}) map[string]interface{} {
+	schemaType, ok := schema["type"].(string)
+	if !ok {
+		return map[string]interface{}{
+			"oneOf": []interface{}{schema, map[string]interface{}{"type": nullType}},
+		}
+	}
+	schema["type"] = []interface{}{schemaType, nullType}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		schema["enum"] = append(enum, nil)
+	}
+	return schema
+}
+
+// nullableType returns the type of a schema and whether it accepts null, for
+// "type: string", "type: [string, null]" and "nullable: true".
+func nullableType(schema map[string]interface{}) (typ string, nullable bool) {
+	switch t := schema["type"].(type) {
+	case string:
+		typ, nullable = t, t == nullType
+	case []interface{}:
+		for _, item := range t {
+			if name, _ := item.(string); name == nullType {
+				nullable = true
+			} else if typ == "" {
+				typ = name
+			}
+		}
+	}
+	return typ, nullable || schema["nullable"] == true
+}
+
+// openAPINullable rewrites the nullable schemas produced by nullableSchema in
+// place into the OpenAPI 3.0 form, "nullable: true", for tools that do not
+// understand type arrays.
+func openAPINullable(schema map[string]interface{}) {
+	if typ, nullable := nullableType(schema); nullable && typ != "" {
+		schema["type"] = typ
+		schema["nullable"] = true
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			kept := enum[:0]
+			for _, value := range enum {
+				if value != nil {
+					kept = append(kept, value)
+				}
+			}
+			schema["enum"] = kept
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok && len(oneOf) == 2 {
+		if null, ok := oneOf[1].(map[string]interface{}); ok && len(null) == 1 && null["type"] == nullType {
+			delete(schema, "oneOf")
+			schema["allOf"] = oneOf[:1]
+			schema["nullable"] = true
+		}
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for _, property := range properties {
+			if child, ok := property.(map[string]interface{}); ok {
+				openAPINullable(child)
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties"} {
+		if child, ok := schema[key].(map[string]interface{}); ok {
+			openAPINullable(child)
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if children, ok := schema[key].([]interface{}); ok {
+			for _, child := range children {
+				if child, ok := child.(map[string]interface{}); ok {
+					openAPINullable(child)
+				}
+			}
+		}
+	}
+}
@@ -2,11 +2,14 @@ package asyncapi
 
 import (
 	"fmt"
-	"log"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/fedanant/asyncapi-doc/annotation"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
 	"github.com/modern-go/reflect2"
 )
 
@@ -22,9 +25,16 @@ type MsgResponse struct {
 // MessageInfo holds message metadata for AsyncAPI 3.0 operations.
 // Replaces the swaggest asyncapi.MessageSample for 3.0 compatibility.
 type MessageInfo struct {
+	TypeName      string
 	Summary       string
 	Description   string
 	MessageSample interface{}
+	Examples      []spec3.MessageExample // @message.examples
+	Error         *spec3.MessageError    // set for @response.error variants
+	Protobuf      bool                   // the type is a protobuf-generated message
+	SchemaFormat  string                 // schemaFormat of the payload when not the default
+	Schema        interface{}            // payload schema in SchemaFormat, e.g. an Avro record
+	Doc           string                 // doc comment of the payload type
 }
 
 // ParameterInfo holds parameter metadata for AsyncAPI 3.0 channels.
@@ -36,29 +46,64 @@ type ParameterInfo struct {
 // Operation represents a parsed AsyncAPI operation from Go comments.
 // Updated for AsyncAPI 3.0 compatibility with extended annotations support.
 type Operation struct {
-	TypeOperation   string
-	Name            string
-	Message         *MessageInfo
-	MessageResponse *MessageInfo
-	Parameters      map[string]ParameterInfo
+	TypeOperation     string
+	Name              string
+	Message           *MessageInfo
+	MessageResponse   *MessageInfo
+	AltMessages       []*MessageInfo               // additional @payload types on the same channel
+	ResponseErrors    []*MessageInfo               // @response.error variants on the reply channel
+	ResponseAddress   *spec3.OperationReplyAddress // @response.address, for replies to a runtime address
+	Parameters        map[string]ParameterInfo
+	ParameterMetadata map[string]spec3.Parameter // @parameter, by parameter name
+	Doc               string                     // prose of the annotated comment, without annotation lines
+
+	// Payload polymorphism
+	PayloadDiscriminator string           // @payload.discriminator (property name)
+	PayloadSubtypes      []PayloadSubtype // @payload.subtype variants
 
 	// Extended operation fields
-	Security      []string               // @security
-	OperationTags []string               // @operation.tag
-	Deprecated    bool                   // @deprecated
-	ExternalDocs  *ExternalDocsInfo      // @operation.externaldocs.*
-	Bindings      map[string]interface{} // @binding.*
+	Security             []string               // @security
+	OperationTags        []string               // @operation.tag
+	Deprecated           bool                   // @deprecated
+	OperationID          string                 // @operation.id
+	OperationSummary     string                 // @operation.summary
+	OperationDescription string                 // @operation.description
+	Timeout              string                 // @operation.timeout (Go duration)
+	QoS                  *int                   // @operation.qos
+	ExternalDocs         *ExternalDocsInfo      // @operation.externaldocs.*
+	Bindings             map[string]interface{} // @binding.* (operation bindings)
+	ChannelBindings      map[string]interface{} // channel-level @binding.* properties
+	MessageBindings      map[string]interface{} // message-level @binding.* properties
+	OperationTraits      []string               // @operation.trait (or @trait)
+	MessageTraits        []string               // @message.trait
+	Extensions           map[string]interface{} // @x-<key> (or @operation.x-<key>)
 
 	// Channel metadata
-	ChannelTitle       string // @channel.title
-	ChannelDescription string // @channel.description
+	ChannelKey          string                 // @channel.key
+	ChannelTitle        string                 // @channel.title
+	ChannelSummary      string                 // @channel.summary
+	ChannelDescription  string                 // @channel.description
+	ChannelTags         []string               // @channel.tag
+	ChannelServers      []string               // @channel.server
+	ChannelExternalDocs *ExternalDocsInfo      // @channel.externaldocs.*
+	ChannelExtensions   map[string]interface{} // @channel.x-<key>
 
 	// Message metadata
-	MessageContentType   string   // @message.contenttype
-	MessageTitle         string   // @message.title
-	MessageTags          []string // @message.tag
-	MessageHeaders       string   // @message.headers (type name)
-	MessageCorrelationID string   // @message.correlationid
+	MessageContentType   string                 // @message.contenttype
+	MessageName          string                 // @message.name
+	MessageSummary       string                 // @message.summary
+	MessageDescription   string                 // @message.description
+	MessageTitle         string                 // @message.title
+	MessageTags          []string               // @message.tag
+	MessageHeaders       string                 // @message.headers (type name)
+	MessageHeadersSample interface{}            // @message.headers (resolved type the schema is generated from)
+	MessageCorrelationID string                 // @message.correlationid
+	MessageProto         string                 // @message.proto (path of the .proto file)
+	MessageSchemaFormat  string                 // @message.schemaFormat
+	MessageRef           string                 // @message.ref
+	MessageKey           string                 // @message.key (payload field path)
+	MessageExtensions    map[string]interface{} // @message.x-<key>
+	BindingVersions      map[string]string      // @binding.<protocol>.bindingVersion
 }
 
 // ExternalDocsInfo holds external documentation metadata.
@@ -91,70 +136,192 @@ func (operation *Operation) ParseComment(comment string, tc *TypeChecker) error
 	}
 
 	attribute := strings.Fields(commentLine)[0]
-	lineRemainder, lowerAttribute := strings.TrimSpace(commentLine[len(attribute):]), strings.ToLower(attribute)
-	switch lowerAttribute {
-	case typeAttr:
+	lineRemainder := strings.TrimSpace(commentLine[len(attribute):])
+	switch annotation.Canonical(attribute) {
+	case annotation.Type:
 		operation.ParseType(lineRemainder)
-	case nameAttr:
-		operation.ParseName(lineRemainder)
-	case descriptionAttr:
+	case annotation.Name:
+		name, err := tc.resolveConst(lineRemainder)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", annotation.Name, err)
+		}
+		operation.ParseName(name)
+	case annotation.Description:
 		operation.ParseDescription(lineRemainder)
-	case summaryAttr:
+	case annotation.Summary:
 		operation.ParseSummary(lineRemainder)
-	case payloadAttr:
+	case annotation.Payload, annotation.PayloadAlt:
 		if err := operation.ParsePayload(lineRemainder, tc); err != nil {
-			log.Printf("Warning: %v", err)
+			return err
+		}
+	case annotation.PayloadDiscriminator:
+		operation.PayloadDiscriminator = lineRemainder
+	case annotation.PayloadSubtype:
+		if err := operation.ParsePayloadSubtypes(lineRemainder, tc); err != nil {
+			return err
 		}
-	case responseAttr:
+	case annotation.Response:
 		if err := operation.ParseResponse(lineRemainder, tc); err != nil {
-			log.Printf("Warning: %v", err)
+			return err
+		}
+	case annotation.ResponseError:
+		if err := operation.ParseResponseError(lineRemainder, tc); err != nil {
+			return err
+		}
+	case annotation.ResponseAddress:
+		if err := operation.ParseResponseAddress(lineRemainder); err != nil {
+			return err
 		}
 	// Extended operation annotations
-	case securityAttr:
+	case annotation.Security:
 		operation.ParseSecurity(lineRemainder)
-	case operationTagAttr:
+	case annotation.OperationTag:
 		operation.ParseOperationTag(lineRemainder)
-	case deprecatedAttr:
+	case annotation.Deprecated:
 		operation.ParseDeprecated(lineRemainder)
-	case operationExternalDocsDescAttr:
+	case annotation.OperationTimeout:
+		if err := operation.ParseTimeout(lineRemainder); err != nil {
+			return err
+		}
+	case annotation.OperationQoS:
+		if err := operation.ParseQoS(lineRemainder); err != nil {
+			return err
+		}
+	case annotation.OperationID:
+		id, err := parseComponentKey(annotation.OperationID, lineRemainder)
+		if err != nil {
+			return err
+		}
+		operation.OperationID = id
+	case annotation.MessageName:
+		name, err := parseComponentKey(annotation.MessageName, lineRemainder)
+		if err != nil {
+			return err
+		}
+		operation.MessageName = name
+	case annotation.OperationSummary:
+		operation.OperationSummary = lineRemainder
+	case annotation.OperationDescription:
+		operation.OperationDescription = lineRemainder
+	case annotation.MessageSummary:
+		operation.MessageSummary = lineRemainder
+	case annotation.MessageDescription:
+		operation.MessageDescription = lineRemainder
+	case annotation.OperationTrait, annotation.Trait:
+		operation.OperationTraits = appendNames(operation.OperationTraits, lineRemainder)
+	case annotation.MessageTrait:
+		operation.MessageTraits = appendNames(operation.MessageTraits, lineRemainder)
+	case annotation.OperationExternalDocsDescription:
 		operation.ParseOperationExternalDocsDesc(lineRemainder)
-	case operationExternalDocsURLAttr:
+	case annotation.OperationExternalDocsURL:
 		operation.ParseOperationExternalDocsURL(lineRemainder)
 	// Message annotations
-	case messageContentTypeAttr:
+	case annotation.MessageContentType:
 		operation.MessageContentType = lineRemainder
-	case messageTitleAttr:
+	case annotation.MessageTitle:
 		operation.MessageTitle = lineRemainder
-	case messageTagAttr:
+	case annotation.MessageTag:
 		operation.ParseMessageTag(lineRemainder)
-	case messageHeadersAttr:
-		operation.MessageHeaders = lineRemainder
-	case messageCorrelationIDAttr:
+	case annotation.MessageHeaders:
+		if err := operation.ParseMessageHeaders(lineRemainder, tc); err != nil {
+			return err
+		}
+	case annotation.MessageProto:
+		operation.MessageProto = lineRemainder
+	case annotation.MessageSchemaFormat:
+		operation.MessageSchemaFormat = lineRemainder
+	case annotation.MessageRef:
+		operation.MessageRef = lineRemainder
+	case annotation.MessageKey:
+		operation.MessageKey = strings.TrimSpace(lineRemainder)
+	case annotation.MessageCorrelationID:
 		operation.MessageCorrelationID = lineRemainder
+	case annotation.MessageExamples:
+		if err := operation.ParseMessageExample(lineRemainder); err != nil {
+			return err
+		}
 	// Channel annotations
-	case channelTitleAttr:
+	case annotation.ChannelKey:
+		operation.ChannelKey = lineRemainder
+	case annotation.ChannelTitle:
 		operation.ChannelTitle = lineRemainder
-	case channelDescriptionAttr:
+	case annotation.ChannelSummary:
+		operation.ChannelSummary = lineRemainder
+	case annotation.ChannelDescription:
 		operation.ChannelDescription = lineRemainder
+	case annotation.ChannelServer:
+		operation.ChannelServers = appendNames(operation.ChannelServers, lineRemainder)
+	case annotation.Parameter:
+		if err := operation.ParseParameter(lineRemainder); err != nil {
+			return err
+		}
+	case annotation.ChannelTag:
+		operation.ParseChannelTag(lineRemainder)
+	case annotation.ChannelExternalDocsDescription:
+		operation.ParseChannelExternalDocsDesc(lineRemainder)
+	case annotation.ChannelExternalDocsURL:
+		operation.ParseChannelExternalDocsURL(lineRemainder)
 	// Binding annotations
-	case bindingNATSQueueAttr:
+	case annotation.BindingNATSQueue:
 		operation.ParseBindingNATS("queue", lineRemainder)
-	case bindingNATSDeliverPolicyAttr:
+	case annotation.BindingNATSDeliverPolicy:
 		operation.ParseBindingNATS("deliverPolicy", lineRemainder)
-	case bindingAMQPExchangeAttr:
-		operation.ParseBindingAMQP("exchange", lineRemainder)
-	case bindingAMQPRoutingKeyAttr:
-		operation.ParseBindingAMQP("routingKey", lineRemainder)
-	case bindingKafkaTopicAttr:
-		operation.ParseBindingKafka("topic", lineRemainder)
-	case bindingKafkaPartitionsAttr:
-		operation.ParseBindingKafka("partitions", lineRemainder)
-	case bindingKafkaReplicasAttr:
-		operation.ParseBindingKafka("replicas", lineRemainder)
+	default:
+		if ok, err := operation.parseRegisteredAnnotation(attribute, lineRemainder); ok {
+			return err
+		}
+		if key, extensions, ok := operation.extensionTarget(attribute); ok {
+			return setExtension(extensions, key, lineRemainder)
+		}
+		if key, ok := annotation.CutPrefix(attribute, annotation.BindingKafkaPrefix); ok && key != "" {
+			return operation.ParseBindingKafka(key, lineRemainder, tc)
+		}
+		if key, ok := annotation.CutPrefix(attribute, annotation.BindingAMQPPrefix); ok && key != "" {
+			return operation.ParseBindingAMQP(key, lineRemainder)
+		}
+		if key, ok := annotation.CutPrefix(attribute, annotation.BindingWSPrefix); ok && key != "" {
+			return operation.ParseBindingWS(key, lineRemainder, tc)
+		}
+		if key, ok := annotation.CutPrefix(attribute, annotation.BindingHTTPPrefix); ok && key != "" {
+			return operation.ParseBindingHTTP(key, lineRemainder, tc)
+		}
+		if key, ok := annotation.CutPrefix(attribute, annotation.BindingRedisPrefix); ok && key != "" {
+			return operation.ParseBindingRedis(key, lineRemainder)
+		}
+		for _, target := range bindingTargetPrefixes {
+			if key, ok := annotation.CutPrefix(attribute, target.prefix); ok && key != "" {
+				return operation.ParseTargetBinding(target.level, key, lineRemainder)
+			}
+		}
 	}
 	return nil
 }
 
+// Summary returns the summary of the operation: @operation.summary, then
+// @summary.
+func (operation *Operation) Summary() string {
+	if operation.OperationSummary != "" {
+		return operation.OperationSummary
+	}
+	return operation.Message.Summary
+}
+
+// Description returns the description of the operation: @operation.description,
+// then @description, then the prose of its doc comment, then the doc comment
+// of its payload type.
+func (operation *Operation) Description() string {
+	if operation.OperationDescription != "" {
+		return operation.OperationDescription
+	}
+	if operation.Message.Description != "" {
+		return operation.Message.Description
+	}
+	if operation.Doc != "" {
+		return operation.Doc
+	}
+	return operation.Message.Doc
+}
+
 func (operation *Operation) ParseType(typeOperation string) {
 	operation.TypeOperation = typeOperation
 }
@@ -181,28 +348,121 @@ func (operation *Operation) ParseSummary(summary string) {
 	operation.Message.Summary = summary
 }
 
+// ParsePayload sets the message payload type. When the operation already has
+// a payload, the type is added as an alternative message on the same channel.
+// A path to an .avsc file references an Avro schema instead of a Go type.
 func (operation *Operation) ParsePayload(name string, tc *TypeChecker) error {
-	typeSpec := GetByNameType(name, tc)
-	if typeSpec != nil {
-		operation.Message.MessageSample = Msg{
+	payload := &MessageInfo{TypeName: name}
+	if strings.HasSuffix(strings.ToLower(name), avroSchemaFile) {
+		payload.TypeName = avroFileTypeName(name)
+		payload.SchemaFormat = avroSchemaFormat
+		payload.Schema = map[string]interface{}{"$ref": name}
+	} else {
+		typeSpec := GetByNameType(name, tc)
+		if typeSpec == nil {
+			return fmt.Errorf("payload type not found: %s", name)
+		}
+		payload.MessageSample = Msg{
 			Data: typeSpec,
 		}
+		payload.Protobuf = tc.isProtoMessage(name)
+		payload.Doc = tc.typeDescription(name)
+		if tc.isAvroStruct(name) {
+			payload.SchemaFormat = avroSchemaFormat
+		}
+	}
+
+	if operation.Message.MessageSample == nil && operation.Message.Schema == nil {
+		operation.Message.TypeName = payload.TypeName
+		operation.Message.MessageSample = payload.MessageSample
+		operation.Message.Protobuf = payload.Protobuf
+		operation.Message.SchemaFormat = payload.SchemaFormat
+		operation.Message.Schema = payload.Schema
+		operation.Message.Doc = payload.Doc
 		return nil
 	}
-	return fmt.Errorf("payload type not found: %s", name)
+	operation.AltMessages = append(operation.AltMessages, payload)
+	return nil
 }
 
 func (operation *Operation) ParseResponse(name string, tc *TypeChecker) error {
 	typeSpec := GetByNameType(name, tc)
 	if typeSpec != nil {
+		operation.MessageResponse.TypeName = name
 		operation.MessageResponse.MessageSample = MsgResponse{
 			Response: typeSpec,
 		}
+		operation.MessageResponse.Protobuf = tc.isProtoMessage(name)
+		if tc.isAvroStruct(name) {
+			operation.MessageResponse.SchemaFormat = avroSchemaFormat
+		}
 		return nil
 	}
 	return fmt.Errorf("response type not found: %s", name)
 }
 
+// ParseMessageHeaders resolves the headers type so its schema can be
+// registered in components alongside the payload schemas.
+func (operation *Operation) ParseMessageHeaders(name string, tc *TypeChecker) error {
+	operation.MessageHeaders = name
+	if tc == nil {
+		return fmt.Errorf("headers type not found: %s", name)
+	}
+	operation.MessageHeadersSample = Msg{
+		Data: GetByNameType(name, tc),
+	}
+	return nil
+}
+
+// ParseResponseError adds an error reply variant in format:
+// "ErrorType [condition]", e.g. "NotFoundError user does not exist".
+func (operation *Operation) ParseResponseError(value string, tc *TypeChecker) error {
+	parts := strings.SplitN(strings.TrimSpace(value), " ", 2)
+	name := parts[0]
+	if name == "" {
+		return fmt.Errorf("@response.error requires a type")
+	}
+	typeSpec := GetByNameType(name, tc)
+	if typeSpec == nil {
+		return fmt.Errorf("response error type not found: %s", name)
+	}
+
+	errInfo := &spec3.MessageError{Type: name}
+	if len(parts) > 1 {
+		errInfo.Condition = strings.TrimSpace(parts[1])
+	}
+	errMessage := &MessageInfo{
+		TypeName:    name,
+		Description: errInfo.Condition,
+		MessageSample: MsgResponse{
+			Response: typeSpec,
+		},
+		Error:    errInfo,
+		Protobuf: tc.isProtoMessage(name),
+	}
+	if tc.isAvroStruct(name) {
+		errMessage.SchemaFormat = avroSchemaFormat
+	}
+	operation.ResponseErrors = append(operation.ResponseErrors, errMessage)
+	return nil
+}
+
+// ParseResponseAddress sets the runtime expression locating the reply
+// address in the request, optionally followed by " - <description>", e.g.
+// "$message.header#/replyTo - Inbox of the requester".
+func (operation *Operation) ParseResponseAddress(value string) error {
+	location, description, _ := strings.Cut(value, " - ")
+	location = strings.TrimSpace(location)
+	if !strings.HasPrefix(location, "$message.header#") && !strings.HasPrefix(location, "$message.payload#") {
+		return fmt.Errorf("invalid @response.address %q: must be a $message.header# or $message.payload# runtime expression", value)
+	}
+	operation.ResponseAddress = &spec3.OperationReplyAddress{
+		Location:    location,
+		Description: strings.TrimSpace(description),
+	}
+	return nil
+}
+
 func GetByNameType(typeName string, tc *TypeChecker) interface{} {
 	hasArray := false
 	originalTypeName := typeName
@@ -246,7 +506,11 @@ func GetByNameType(typeName string, tc *TypeChecker) interface{} {
 		return refType.New()
 	}
 
-	log.Printf("warning: type '%s' not found, using empty struct", originalTypeName)
+	if err := tc.typeErrorFor(typeName); err != nil {
+		tc.warnings.warnf(warnTypeNotFound, "type '%s' not found, using empty struct: package %s has type errors: %v", originalTypeName, tc.pkg.Name(), err)
+		return struct{}{}
+	}
+	tc.warnings.warnf(warnTypeNotFound, "type '%s' not found, using empty struct", originalTypeName)
 	return struct{}{}
 }
 
@@ -261,6 +525,16 @@ func (operation *Operation) ParseSecurity(value string) {
 	}
 }
 
+// appendNames appends the comma-separated names in value to names.
+func appendNames(names []string, value string) []string {
+	for _, name := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(name); trimmed != "" {
+			names = append(names, trimmed)
+		}
+	}
+	return names
+}
+
 // ParseOperationTag adds an operation tag.
 func (operation *Operation) ParseOperationTag(value string) {
 	trimmed := strings.TrimSpace(value)
@@ -275,6 +549,39 @@ func (operation *Operation) ParseDeprecated(value string) {
 	operation.Deprecated = trimmed == "true" || trimmed == ""
 }
 
+// ParseTimeout sets the operation timeout, e.g. "5s" or "1m30s".
+func (operation *Operation) ParseTimeout(value string) error {
+	value = strings.TrimSpace(value)
+	if _, err := time.ParseDuration(value); err != nil {
+		return fmt.Errorf("invalid @operation.timeout %q: %w", value, err)
+	}
+	operation.Timeout = value
+	return nil
+}
+
+// ParseQoS sets the delivery quality of service level (0, 1 or 2).
+func (operation *Operation) ParseQoS(value string) error {
+	qos, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || qos < 0 || qos > 2 {
+		return fmt.Errorf("invalid @operation.qos %q: must be 0, 1 or 2", value)
+	}
+	operation.QoS = &qos
+	return nil
+}
+
+// componentKeyPattern matches the keys allowed for components and the
+// operations and channels maps.
+var componentKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+
+// parseComponentKey validates a key chosen with attribute.
+func parseComponentKey(attribute, value string) (string, error) {
+	key := strings.TrimSpace(value)
+	if !componentKeyPattern.MatchString(key) {
+		return "", fmt.Errorf("invalid %s %q: only letters, digits, '.', '-' and '_' are allowed", attribute, value)
+	}
+	return key, nil
+}
+
 // ParseOperationExternalDocsDesc sets the external docs description.
 func (operation *Operation) ParseOperationExternalDocsDesc(value string) {
 	if operation.ExternalDocs == nil {
@@ -291,6 +598,30 @@ func (operation *Operation) ParseOperationExternalDocsURL(value string) {
 	operation.ExternalDocs.URL = strings.TrimSpace(value)
 }
 
+// ParseChannelTag adds a channel tag.
+func (operation *Operation) ParseChannelTag(value string) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed != "" {
+		operation.ChannelTags = append(operation.ChannelTags, trimmed)
+	}
+}
+
+// ParseChannelExternalDocsDesc sets the channel external docs description.
+func (operation *Operation) ParseChannelExternalDocsDesc(value string) {
+	if operation.ChannelExternalDocs == nil {
+		operation.ChannelExternalDocs = &ExternalDocsInfo{}
+	}
+	operation.ChannelExternalDocs.Description = strings.TrimSpace(value)
+}
+
+// ParseChannelExternalDocsURL sets the channel external docs URL.
+func (operation *Operation) ParseChannelExternalDocsURL(value string) {
+	if operation.ChannelExternalDocs == nil {
+		operation.ChannelExternalDocs = &ExternalDocsInfo{}
+	}
+	operation.ChannelExternalDocs.URL = strings.TrimSpace(value)
+}
+
 // ParseMessageTag adds a message tag.
 func (operation *Operation) ParseMessageTag(value string) {
 	trimmed := strings.TrimSpace(value)
@@ -311,38 +642,6 @@ func (operation *Operation) ParseBindingNATS(key, value string) {
 	natsBinding[key] = strings.TrimSpace(value)
 }
 
-// ParseBindingAMQP parses AMQP-specific binding properties.
-func (operation *Operation) ParseBindingAMQP(key, value string) {
-	if operation.Bindings["amqp"] == nil {
-		operation.Bindings["amqp"] = make(map[string]interface{})
-	}
-	amqpBinding, ok := operation.Bindings["amqp"].(map[string]interface{})
-	if !ok {
-		return
-	}
-	amqpBinding[key] = strings.TrimSpace(value)
-}
-
-// ParseBindingKafka parses Kafka-specific binding properties.
-func (operation *Operation) ParseBindingKafka(key, value string) {
-	if operation.Bindings["kafka"] == nil {
-		operation.Bindings["kafka"] = make(map[string]interface{})
-	}
-	kafkaBinding, ok := operation.Bindings["kafka"].(map[string]interface{})
-	if !ok {
-		return
-	}
-
-	// Handle numeric fields
-	trimmed := strings.TrimSpace(value)
-	switch key {
-	case "partitions", "replicas":
-		kafkaBinding[key] = trimmed // Store as string, can be converted later if needed
-	default:
-		kafkaBinding[key] = trimmed
-	}
-}
-
 func TransToReflectType(typeName string) interface{} {
 	switch typeName {
 	case "uint", "int", "uint8", "int8", "uint16", "int16", "byte", "uint32", "int32", "rune", "uint64", "int64":
@@ -1,6 +1,7 @@
 package asyncapi
 
 import (
+	"encoding/json"
 	"go/ast"
 	"go/parser"
 	"go/token"
@@ -267,6 +268,61 @@ func TestParseNameWithParameters(t *testing.T) {
 	}
 }
 
+func TestParseNameConst(t *testing.T) {
+	src := `
+package testpkg
+
+const (
+	SubjectUserCreated = "user.created"
+	SubjectUserOrders  = "user.{userId}.orders"
+	MaxRetries         = 3
+)
+
+var subjectDynamic = "user.dynamic"
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	tests := []struct {
+		value      string
+		tc         *TypeChecker
+		wantName   string
+		wantParams int
+		wantErr    bool
+	}{
+		{"const:SubjectUserCreated", tc, "user.created", 0, false},
+		{"const:testpkg.SubjectUserCreated", tc, "user.created", 0, false},
+		{"const:SubjectUserOrders", tc, "user.{userId}.orders", 1, false},
+		{"user.created", nil, "user.created", 0, false},
+		{"const:SubjectMissing", tc, "", 0, true},
+		{"const:MaxRetries", tc, "", 0, true},
+		{"const:subjectDynamic", tc, "", 0, true},
+		{"const:SubjectUserCreated", nil, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			op := NewOperation()
+			err := op.ParseComment("@name "+tt.value, tt.tc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseComment(@name %s) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if op.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", op.Name, tt.wantName)
+			}
+			if len(op.Parameters) != tt.wantParams {
+				t.Errorf("Parameters = %v, want %d", op.Parameters, tt.wantParams)
+			}
+		})
+	}
+}
+
 func TestParsePayloadWithInvalidType(t *testing.T) {
 	op := NewOperation()
 
@@ -317,3 +373,145 @@ func TestParseResponseWithInvalidType(t *testing.T) {
 		t.Error("MessageSample should be set even for unknown types")
 	}
 }
+
+func TestParseMessageExample(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantErr     bool
+		wantName    string
+		wantSummary string
+		wantHeaders int
+	}{
+		{
+			name:        "named example with payload and headers",
+			input:       `orderCreated {"summary": "A new order", "payload": {"id": "42"}, "headers": {"trace-id": "abc"}}`,
+			wantName:    "orderCreated",
+			wantSummary: "A new order",
+			wantHeaders: 1,
+		},
+		{
+			name:  "unnamed example",
+			input: `{"payload": {"id": "42"}}`,
+		},
+		{
+			name:    "invalid json",
+			input:   `broken {"payload": }`,
+			wantErr: true,
+		},
+		{
+			name:    "empty example",
+			input:   `empty {}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := NewOperation()
+			err := op.ParseMessageExample(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMessageExample() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(op.Message.Examples) != 0 {
+					t.Errorf("Examples = %v, want none", op.Message.Examples)
+				}
+				return
+			}
+
+			if len(op.Message.Examples) != 1 {
+				t.Fatalf("Examples count = %d, want 1", len(op.Message.Examples))
+			}
+			example := op.Message.Examples[0]
+			if example.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", example.Name, tt.wantName)
+			}
+			if example.Summary != tt.wantSummary {
+				t.Errorf("Summary = %q, want %q", example.Summary, tt.wantSummary)
+			}
+			if len(example.Headers) != tt.wantHeaders {
+				t.Errorf("Headers = %v, want %d entries", example.Headers, tt.wantHeaders)
+			}
+			if example.Payload == nil {
+				t.Error("Payload should not be nil")
+			}
+		})
+	}
+}
+
+func TestValidateExample(t *testing.T) {
+	type Item struct {
+		SKU string `json:"sku"`
+	}
+	type Order struct {
+		ID     int     `json:"id"`
+		Status string  `json:"status" validate:"oneof=new|paid"`
+		Items  []Item  `json:"items"`
+		Note   *string `json:"note"`
+	}
+	schema := GenerateJSONSchema(Order{})
+
+	tests := []struct {
+		name    string
+		payload string
+		want    int
+	}{
+		{"valid", `{"id": 1, "status": "new", "items": [{"sku": "A1"}]}`, 0},
+		{"wrong type", `{"id": "one", "status": "new", "items": []}`, 1},
+		{"missing required", `{"id": 1, "status": "new"}`, 1},
+		{"not in enum", `{"id": 1, "status": "shipped", "items": []}`, 1},
+		{"nested item", `{"id": 1, "status": "paid", "items": [{"sku": 7}]}`, 1},
+		{"null pointer", `{"id": 1, "status": "new", "items": [], "note": null}`, 0},
+		{"wrong pointer type", `{"id": 1, "status": "new", "items": [], "note": 7}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload interface{}
+			if err := json.Unmarshal([]byte(tt.payload), &payload); err != nil {
+				t.Fatal(err)
+			}
+			problems := validateExample(schema, payload, "payload")
+			if len(problems) != tt.want {
+				t.Errorf("validateExample() = %v, want %d problem(s)", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeoutAndQoS(t *testing.T) {
+	tests := []struct {
+		name        string
+		parse       func(*Operation, string) error
+		value       string
+		wantErr     bool
+		wantTimeout string
+		wantQoS     int
+	}{
+		{name: "timeout", parse: (*Operation).ParseTimeout, value: "5s", wantTimeout: "5s", wantQoS: -1},
+		{name: "compound timeout", parse: (*Operation).ParseTimeout, value: "1m30s", wantTimeout: "1m30s", wantQoS: -1},
+		{name: "invalid timeout", parse: (*Operation).ParseTimeout, value: "soon", wantErr: true, wantQoS: -1},
+		{name: "qos", parse: (*Operation).ParseQoS, value: "1", wantQoS: 1},
+		{name: "qos out of range", parse: (*Operation).ParseQoS, value: "3", wantErr: true, wantQoS: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := NewOperation()
+			if err := tt.parse(op, tt.value); (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if op.Timeout != tt.wantTimeout {
+				t.Errorf("Timeout = %q, want %q", op.Timeout, tt.wantTimeout)
+			}
+			switch {
+			case tt.wantQoS < 0 && op.QoS != nil:
+				t.Errorf("QoS = %d, want unset", *op.QoS)
+			case tt.wantQoS >= 0 && (op.QoS == nil || *op.QoS != tt.wantQoS):
+				t.Errorf("QoS = %v, want %d", op.QoS, tt.wantQoS)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// Optimizations accepted by -optimize.
+const (
+	// OptimizeTraits hoists repeated operation metadata into traits.
+	OptimizeTraits = "traits"
+)
+
+// ValidOptimizations reports an error for an unknown optimization.
+func ValidOptimizations(optimizations []string) error {
+	for _, optimization := range optimizations {
+		if optimization != OptimizeTraits {
+			return fmt.Errorf("unknown optimization %q (want %s)", optimization, OptimizeTraits)
+		}
+	}
+	return nil
+}
+
+// minTraitUses is the number of operations that must share the same tags,
+// bindings and security before they are hoisted into a trait.
+const minTraitUses = 3
+
+// operationShared is the part of an operation that HoistOperationTraits
+// moves into a trait.
+type operationShared struct {
+	Tags     []spec3.Tag            `json:"tags,omitempty"`
+	Bindings map[string]interface{} `json:"bindings,omitempty"`
+	Security []spec3.Reference      `json:"security,omitempty"`
+}
+
+// HoistOperationTraits finds operations sharing the same combination of tags,
+// bindings and security, and replaces the combination repeated by at least
+// minTraitUses operations with a reference to a new operation trait in
+// components/operationTraits. It returns the names of the created traits. The
+// document is modified in place.
+func HoistOperationTraits(doc *spec3.AsyncAPI) []string {
+	names := make([]string, 0, len(doc.Operations))
+	for name := range doc.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	groups := make(map[string][]string)
+	var keys []string
+	for _, name := range names {
+		op := doc.Operations[name]
+		if len(op.Tags) == 0 && len(op.Bindings) == 0 && len(op.Security) == 0 {
+			continue
+		}
+		data, err := json.Marshal(operationShared{Tags: op.Tags, Bindings: op.Bindings, Security: op.Security})
+		if err != nil {
+			continue
+		}
+		key := string(data)
+		if groups[key] == nil {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+
+	var traits []string
+	for _, key := range keys {
+		members := groups[key]
+		if len(members) < minTraitUses {
+			continue
+		}
+		if doc.Components == nil {
+			doc.Components = &spec3.Components{}
+		}
+		if doc.Components.OperationTraits == nil {
+			doc.Components.OperationTraits = make(map[string]spec3.OperationTrait)
+		}
+
+		first := doc.Operations[members[0]]
+		traitName := uniqueTraitName(doc.Components.OperationTraits, operationTraitName(first))
+		doc.Components.OperationTraits[traitName] = spec3.OperationTrait{
+			Tags:     first.Tags,
+			Bindings: first.Bindings,
+			Security: first.Security,
+		}
+		for _, name := range members {
+			op := doc.Operations[name]
+			op.Tags, op.Bindings, op.Security = nil, nil, nil
+			op.Traits = append(op.Traits, spec3.Reference{Ref: "#/components/operationTraits/" + traitName})
+			doc.Operations[name] = op
+		}
+		traits = append(traits, traitName)
+	}
+	return traits
+}
+
+// operationTraitName names a hoisted trait after the tags, binding protocols
+// and security schemes it holds, e.g. "usersNatsUserPasswordTrait".
+func operationTraitName(op spec3.Operation) string {
+	var parts []string
+	for _, tag := range op.Tags {
+		parts = append(parts, tag.Name)
+	}
+	protocols := make([]string, 0, len(op.Bindings))
+	for protocol := range op.Bindings {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+	parts = append(parts, protocols...)
+	for _, ref := range op.Security {
+		parts = append(parts, ref.Ref[strings.LastIndex(ref.Ref, "/")+1:])
+	}
+	parts = append(parts, "trait")
+	words := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '.'
+	}, strings.Join(parts, "."))
+	return toChannelName(words)
+}
+
+// uniqueTraitName returns name, or a numbered variant when a trait already
+// uses it.
+func uniqueTraitName(traits map[string]spec3.OperationTrait, name string) string {
+	candidate := name
+	for i := 2; ; i++ {
+		if _, taken := traits[candidate]; !taken {
+			return candidate
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+}
@@ -0,0 +1,56 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func TestHoistOperationTraits(t *testing.T) {
+	natsQueue := func() map[string]interface{} {
+		return map[string]interface{}{"nats": map[string]interface{}{"queue": "workers"}}
+	}
+	security := []spec3.Reference{{Ref: "#/components/securitySchemes/userPassword"}}
+	doc := spec3.NewAsyncAPI()
+	doc.Operations = map[string]spec3.Operation{
+		"receiveA": {Tags: []spec3.Tag{{Name: "users"}}, Bindings: natsQueue(), Security: security},
+		"receiveB": {Tags: []spec3.Tag{{Name: "users"}}, Bindings: natsQueue(), Security: security},
+		"receiveC": {Tags: []spec3.Tag{{Name: "users"}}, Bindings: natsQueue(), Security: security, Traits: []spec3.Reference{{Ref: "#/components/operationTraits/audited"}}},
+		"receiveD": {Tags: []spec3.Tag{{Name: "orders"}}, Bindings: natsQueue()},
+		"receiveE": {Tags: []spec3.Tag{{Name: "orders"}}, Bindings: natsQueue()},
+		"sendF":    {},
+	}
+
+	traits := HoistOperationTraits(doc)
+	if want := []string{"usersNatsUserPasswordTrait"}; !reflect.DeepEqual(traits, want) {
+		t.Fatalf("HoistOperationTraits() = %v, want %v", traits, want)
+	}
+
+	wantTrait := spec3.OperationTrait{Tags: []spec3.Tag{{Name: "users"}}, Bindings: natsQueue(), Security: security}
+	if got := doc.Components.OperationTraits["usersNatsUserPasswordTrait"]; !reflect.DeepEqual(got, wantTrait) {
+		t.Errorf("trait = %+v, want %+v", got, wantTrait)
+	}
+
+	ref := spec3.Reference{Ref: "#/components/operationTraits/usersNatsUserPasswordTrait"}
+	for name, wantTraits := range map[string][]spec3.Reference{
+		"receiveA": {ref},
+		"receiveB": {ref},
+		"receiveC": {{Ref: "#/components/operationTraits/audited"}, ref},
+	} {
+		op := doc.Operations[name]
+		if op.Tags != nil || op.Bindings != nil || op.Security != nil {
+			t.Errorf("%s = %+v, want tags, bindings and security moved to the trait", name, op)
+		}
+		if !reflect.DeepEqual(op.Traits, wantTraits) {
+			t.Errorf("%s traits = %v, want %v", name, op.Traits, wantTraits)
+		}
+	}
+	if op := doc.Operations["receiveD"]; op.Traits != nil || len(op.Tags) != 1 {
+		t.Errorf("receiveD = %+v, want it unchanged below %d uses", op, minTraitUses)
+	}
+
+	if err := ValidOptimizations([]string{"traits", "schemas"}); err == nil {
+		t.Error("ValidOptimizations(schemas) error = nil, want error")
+	}
+}
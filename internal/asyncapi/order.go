@@ -0,0 +1,128 @@
+package asyncapi
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+)
+
+// Orders of the channels and operations in the written document.
+const (
+	// OrderAlpha sorts channels and operations by key, the default.
+	OrderAlpha = "alpha"
+	// OrderSource keeps channels and operations in the order they first
+	// appear in the code: by file, then by line.
+	OrderSource = "source"
+)
+
+// validOrder reports an error for an unknown document order.
+func validOrder(order string) error {
+	switch order {
+	case "", OrderAlpha, OrderSource:
+		return nil
+	}
+	return fmt.Errorf("unknown order %q (want %s or %s)", order, OrderAlpha, OrderSource)
+}
+
+// sourcePosition is the position of an annotation block: the index of its
+// file in parsing order and its line.
+type sourcePosition struct {
+	file int
+	line int
+}
+
+// sourceKey is a channel or operation key with the position it first
+// appeared at.
+type sourceKey struct {
+	key string
+	pos sourcePosition
+}
+
+// sourceOrder records the channel and operation keys in the order they first
+// appear in the code.
+type sourceOrder struct {
+	// pos is the position of the annotations being parsed.
+	pos sourcePosition
+	// files counts the parsed files, numbering them.
+	files int
+
+	channels   []sourceKey
+	operations []sourceKey
+	seen       map[string]bool
+}
+
+// startFile numbers the next parsed file.
+func (o *sourceOrder) startFile() {
+	o.files++
+	o.pos = sourcePosition{file: o.files}
+}
+
+// at sets the position of the comment group or table row being parsed.
+func (o *sourceOrder) at(c ast.Node, tc *TypeChecker) {
+	o.pos.line = 0
+	if tc != nil && tc.fset != nil {
+		o.pos.line = tc.fset.Position(c.Pos()).Line
+	}
+}
+
+// record adds the keys of channels and operations not seen before at the
+// current position. New keys of one annotation block are added by key.
+func (o *sourceOrder) record(channels, operations []string) {
+	if o.seen == nil {
+		o.seen = make(map[string]bool)
+	}
+	o.channels = o.add(o.channels, "channels/", channels)
+	o.operations = o.add(o.operations, "operations/", operations)
+}
+
+func (o *sourceOrder) add(keys []sourceKey, prefix string, names []string) []sourceKey {
+	sort.Strings(names)
+	for _, name := range names {
+		if o.seen[prefix+name] {
+			continue
+		}
+		o.seen[prefix+name] = true
+		keys = append(keys, sourceKey{key: name, pos: o.pos})
+	}
+	return keys
+}
+
+// sorted returns the keys ordered by position, keeping the recording order
+// of keys at the same position.
+func sorted(keys []sourceKey) []string {
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].pos.file != keys[j].pos.file {
+			return keys[i].pos.file < keys[j].pos.file
+		}
+		return keys[i].pos.line < keys[j].pos.line
+	})
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.key
+	}
+	return names
+}
+
+// recordSourceOrder records the channels and operations added by the
+// annotation block just parsed.
+func (p *Parser) recordSourceOrder() {
+	var channels, operations []string
+	for key := range p.asyncAPI.Channels {
+		if !p.order.seen["channels/"+key] {
+			channels = append(channels, key)
+		}
+	}
+	for key := range p.asyncAPI.Operations {
+		if !p.order.seen["operations/"+key] {
+			operations = append(operations, key)
+		}
+	}
+	p.order.record(channels, operations)
+}
+
+// applySourceOrder makes the document keep its channels and operations in
+// source order when written.
+func (p *Parser) applySourceOrder() {
+	p.asyncAPI.ChannelOrder = sorted(p.order.channels)
+	p.asyncAPI.OperationOrder = sorted(p.order.operations)
+}
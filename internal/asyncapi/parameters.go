@@ -0,0 +1,105 @@
+package asyncapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// parameterKeys are the keys of @parameter values.
+var parameterKeys = []string{"enum", "default", "description", "examples", "location"}
+
+// ParseParameter parses the metadata of a channel parameter,
+// "name enum=a,b default=a examples=a description=Text location=$message.header#/region".
+// Words without "=" continue the previous value, so the description may
+// contain spaces.
+func (operation *Operation) ParseParameter(value string) error {
+	name, rest, _ := strings.Cut(strings.TrimSpace(value), " ")
+	if name == "" {
+		return fmt.Errorf("invalid @parameter %q: missing parameter name", value)
+	}
+	values := parseKeyValues(rest)
+	for key := range values {
+		if !slices.Contains(parameterKeys, key) {
+			return fmt.Errorf("invalid @parameter %s: unknown key %q (want %s)", name, key, strings.Join(parameterKeys, ", "))
+		}
+	}
+
+	param := spec3.Parameter{
+		Description: values["description"],
+		Default:     values["default"],
+		Location:    values["location"],
+	}
+	if enum := values["enum"]; enum != "" {
+		param.Enum = splitList(enum)
+	}
+	if examples := values["examples"]; examples != "" {
+		param.Examples = splitList(examples)
+	}
+	if param.Location != "" && !strings.HasPrefix(param.Location, "$message.header#") && !strings.HasPrefix(param.Location, "$message.payload#") {
+		return fmt.Errorf("invalid @parameter %s location %q: must be a $message.header# or $message.payload# runtime expression", name, param.Location)
+	}
+	if param.Default != "" && len(param.Enum) > 0 && !slices.Contains(param.Enum, param.Default) {
+		return fmt.Errorf("invalid @parameter %s: default %q is not one of enum %s", name, param.Default, strings.Join(param.Enum, ","))
+	}
+
+	if operation.ParameterMetadata == nil {
+		operation.ParameterMetadata = make(map[string]spec3.Parameter)
+	}
+	operation.ParameterMetadata[name] = param
+	return nil
+}
+
+// splitList splits a comma-separated list, trimming its items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// createChannelParameters converts the parameters of an operation's channel
+// address to channel parameters, described by @parameter when given.
+func (p *Parser) createChannelParameters(operation *Operation) map[string]spec3.Parameter {
+	channelParams := make(map[string]spec3.Parameter)
+	for paramName, param := range operation.Parameters {
+		if meta, ok := operation.ParameterMetadata[paramName]; ok {
+			if meta.Description == "" {
+				meta.Description = getSchemaDescription(param.Schema)
+			}
+			channelParams[paramName] = meta
+			continue
+		}
+		channelParams[paramName] = spec3.Parameter{
+			Description: getSchemaDescription(param.Schema),
+		}
+	}
+	for paramName := range operation.ParameterMetadata {
+		if _, ok := operation.Parameters[paramName]; !ok {
+			p.warnings.warnf(warnAnnotation, "@parameter %s is not a parameter of channel %s", paramName, operation.Name)
+		}
+	}
+	return channelParams
+}
+
+// mergeChannelParameter returns the parameter of a channel shared by several
+// operations: one described with @parameter replaces the generated one, but
+// not the other way around.
+func mergeChannelParameter(existing, param spec3.Parameter, exists bool, name string) spec3.Parameter {
+	if exists && isGeneratedParameter(param, name) {
+		return existing
+	}
+	return param
+}
+
+// isGeneratedParameter reports whether param is the generated description of
+// the parameter name, without @parameter metadata.
+func isGeneratedParameter(param spec3.Parameter, name string) bool {
+	return param.Description == name && param.Default == "" && param.Location == "" &&
+		len(param.Enum) == 0 && len(param.Examples) == 0
+}
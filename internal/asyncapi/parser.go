@@ -2,275 +2,243 @@ package asyncapi
 
 import (
 	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/fedanant/asyncapi-doc/annotation"
 	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
-)
-
-const (
-	// Service-level annotations (camelCase).
-	titleAttr            = "@title"
-	urlAttr              = "@url"
-	hostAttr             = "@host"
-	versionAttr          = "@version"
-	termsOfServiceAttr   = "@termsofservice"
-	contactNameAttr      = "@contact.name"
-	contactURLAttr       = "@contact.url"
-	contactEmailAttr     = "@contact.email"
-	licenseNameAttr      = "@license.name"
-	licenseURLAttr       = "@license.url"
-	tagAttr              = "@tag"
-	externalDocsDescAttr = "@externaldocs.description"
-	externalDocsURLAttr  = "@externaldocs.url"
-
-	// Server annotations (camelCase in user code, lowercase for internal matching).
-	protocolAttr               = "@protocol"
-	protocolVersionAttr        = "@protocolversion"
-	pathnameAttr               = "@pathname"
-	serverNameAttr             = "@server.name"
-	serverTitleAttr            = "@server.title"
-	serverSummaryAttr          = "@server.summary"
-	serverDescriptionAttr      = "@server.description"
-	serverTagAttr              = "@server.tag"
-	serverExternalDocsDescAttr = "@server.externaldocs.description"
-	serverExternalDocsURLAttr  = "@server.externaldocs.url"
-	serverVariableAttr         = "@server.variable"
-	serverSecurityAttr         = "@server.security"
-	serverBindingAttr          = "@server.binding"
-
-	// Operation annotations (camelCase in user code, lowercase for internal matching).
-	typeAttr                      = "@type"
-	nameAttr                      = "@name"
-	descriptionAttr               = "@description"
-	summaryAttr                   = "@summary"
-	payloadAttr                   = "@payload"
-	responseAttr                  = "@response"
-	securityAttr                  = "@security"
-	operationTagAttr              = "@operation.tag"
-	operationExternalDocsDescAttr = "@operation.externaldocs.description"
-	operationExternalDocsURLAttr  = "@operation.externaldocs.url"
-	deprecatedAttr                = "@deprecated"
-	traitAttr                     = "@trait"
-
-	// Message annotations (camelCase in user code, lowercase for internal matching).
-	messageContentTypeAttr   = "@message.contenttype"
-	messageTitleAttr         = "@message.title"
-	messageNameAttr          = "@message.name"
-	messageTagAttr           = "@message.tag"
-	messageHeadersAttr       = "@message.headers"
-	messageCorrelationIDAttr = "@message.correlationid"
-	messageExamplesAttr      = "@message.examples"
-
-	// Channel annotations (camelCase).
-	channelTitleAttr       = "@channel.title"
-	channelDescriptionAttr = "@channel.description"
-	channelAddressAttr     = "@channel.address"
-
-	// Binding annotations (protocol-specific, camelCase in user code, lowercase for internal matching).
-	bindingNATSQueueAttr         = "@binding.nats.queue"
-	bindingNATSDeliverPolicyAttr = "@binding.nats.deliverpolicy"
-	bindingAMQPExchangeAttr      = "@binding.amqp.exchange"
-	bindingAMQPRoutingKeyAttr    = "@binding.amqp.routingkey"
-	bindingKafkaTopicAttr        = "@binding.kafka.topic"
-	bindingKafkaPartitionsAttr   = "@binding.kafka.partitions"
-	bindingKafkaReplicasAttr     = "@binding.kafka.replicas"
 )
 
 // Parser parses Go source comments and generates AsyncAPI 3.0 specifications.
+// Its methods may be called from multiple goroutines, e.g. to parse several
+// packages into one document in parallel; each call runs alone.
 type Parser struct {
+	// mu serializes the exported methods, which update the shared document,
+	// schema registry and warnings.
+	mu sync.Mutex
+
 	asyncAPI *spec3.AsyncAPI
+
+	// Headers declared once with @message.commonHeader and shared by all messages.
+	commonHeaders         map[string]interface{}
+	commonHeadersRequired []string
+
+	// Limits applied to every registered payload and header schema.
+	schemaLimits SchemaLimits
+
+	// Describe nullable schemas with the OpenAPI 3.0 "nullable: true".
+	openAPINullable bool
+
+	// Keep only explicit descriptions, without falling back to doc comments.
+	noDescriptionFallback bool
+
+	// Strategy naming the server described without @server.name.
+	serverNaming string
+
+	// Strategy naming the generated channel, operation and message keys.
+	naming naming
+
+	// Struct types whose literals register handlers, see RegistrationTable.
+	registrationTables []RegistrationTable
+
+	// Mode checking the subjects of broker calls against @name, if any.
+	verifySubjects string
+
+	// Clients whose calls become operations without annotations, and the
+	// calls found, parsed once all annotations are.
+	discover   []string
+	discovered []discoveredOperation
+
+	// bindingVersion of binding objects without one, by protocol.
+	bindingVersions map[string]string
+
+	// Order in which channels and operations first appear in the code.
+	order sourceOrder
+
+	// In merge mode, the service whose sources are being parsed and the
+	// services producing and consuming each channel and message.
+	service      string
+	serviceRoles serviceRoles
+
+	// Whether service-level annotations were parsed and, to tell where
+	// they belong when they were not, the directory of the package being
+	// parsed, the files with operation annotations and the files declaring
+	// func main.
+	hasServiceInfo bool
+	sourceDir      string
+	operationFiles []string
+	mainFiles      []string
+
+	// Component schema names reserved for the types emitted as $refs, by
+	// name and by type, so the $refs of each type resolve to its own schema.
+	refOwners map[string]string
+	refNames  map[string]string
+
+	// Named struct schemas inside the component schemas, shared by Finalize.
+	schemaUses []schemaUse
+
+	// Summaries and descriptions of shared messages that operations disagree
+	// on, by message name and field, left out of the messages.
+	messageTextConflicts map[string]bool
+
+	// Distinct warnings of the run, shared with the type checkers.
+	warnings *warningLog
 }
 
 // NewParser creates a new Parser with an initialized AsyncAPI 3.0 document.
 func NewParser() *Parser {
 	return &Parser{
-		asyncAPI: spec3.NewAsyncAPI(),
+		asyncAPI:        spec3.NewAsyncAPI(),
+		bindingVersions: bindingVersionsWith(nil),
+		warnings:        &warningLog{},
 	}
 }
 
 // ParseMain parses main function comments to extract API info and server configuration.
 // In AsyncAPI 3.0, servers use 'host' instead of 'url'.
+// Besides the single server described by @host/@protocol/@server.*, any number of
+// named servers can be declared with "@server.<name>.<field>" annotations.
 //
 //nolint:gocyclo // Complex parsing logic is intentionally centralized for maintainability
 func (p *Parser) ParseMain(comments []string) {
-	var protocol string
-	var protocolVersion string
-	var pathname string
-	var serverName string
-	var serverHost string
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hasServiceInfo = true
+	comments = joinDescriptions(comments)
+	var serverName, title string
 	var tags []spec3.Tag
 	var externalDocs *spec3.ExternalDocs
-	var serverTags []spec3.Tag
-	var serverExternalDocs *spec3.ExternalDocs
-	var serverTitle string
-	var serverSummary string
-	var serverDescription string
-	var serverVariables map[string]spec3.ServerVar
-	var serverSecurity []map[string][]string
-	var serverBindings map[string]interface{}
+	defaultServer := &serverBuilder{}
+	namedServers := make(map[string]*serverBuilder)
 
 	for i := range comments {
 		commentLine := comments[i]
-		attribute := strings.Split(commentLine, " ")[0]
-		attr := strings.ToLower(attribute)
+		// A multi-line description may have no text on its annotation line
+		attribute, _, _ := strings.Cut(strings.SplitN(commentLine, "\n", 2)[0], " ")
+		attr := annotation.Canonical(attribute)
 		value := strings.TrimSpace(commentLine[len(attribute):])
+
+		if field, ok := defaultServerFields[attr]; ok {
+			defaultServer.setField(field, value)
+			continue
+		}
+
 		switch attr {
-		case titleAttr:
+		case annotation.InfoMarker:
+			// Only marks the comment as service-level
+		case annotation.ID:
+			p.asyncAPI.ID = value
+		case annotation.Title:
 			p.asyncAPI.Info.Title = value
-			// Use title as default server name if not set
-			if serverName == "" {
-				serverName = strings.ReplaceAll(strings.ToLower(value), " ", "-")
-			}
-		case versionAttr:
+			title = value
+		case annotation.Version:
 			p.asyncAPI.Info.Version = value
-		case descriptionAttr:
+		case annotation.Description:
 			p.asyncAPI.Info.Description = value
-		case termsOfServiceAttr:
+		case annotation.TermsOfService:
 			p.asyncAPI.Info.TermsOfService = value
-		case contactNameAttr:
+		case annotation.ContactName:
 			if p.asyncAPI.Info.Contact == nil {
 				p.asyncAPI.Info.Contact = &spec3.Contact{}
 			}
 			p.asyncAPI.Info.Contact.Name = value
-		case contactEmailAttr:
+		case annotation.ContactEmail:
 			if p.asyncAPI.Info.Contact == nil {
 				p.asyncAPI.Info.Contact = &spec3.Contact{}
 			}
 			p.asyncAPI.Info.Contact.Email = value
-		case contactURLAttr:
+		case annotation.ContactURL:
 			if p.asyncAPI.Info.Contact == nil {
 				p.asyncAPI.Info.Contact = &spec3.Contact{}
 			}
 			p.asyncAPI.Info.Contact.URL = value
-		case licenseNameAttr:
+		case annotation.LicenseName:
 			if p.asyncAPI.Info.License == nil {
 				p.asyncAPI.Info.License = &spec3.License{}
 			}
 			p.asyncAPI.Info.License.Name = value
-		case licenseURLAttr:
+		case annotation.LicenseURL:
 			if p.asyncAPI.Info.License == nil {
 				p.asyncAPI.Info.License = &spec3.License{}
 			}
 			p.asyncAPI.Info.License.URL = value
-		case tagAttr:
-			// Parse tag in format: "name - description" or just "name"
-			tagParts := strings.SplitN(value, " - ", 2)
-			tag := spec3.Tag{Name: strings.TrimSpace(tagParts[0])}
-			if len(tagParts) > 1 {
-				tag.Description = strings.TrimSpace(tagParts[1])
-			}
-			tags = append(tags, tag)
-		case externalDocsDescAttr:
+		case annotation.Tag:
+			tags = append(tags, parseTag(value))
+		case annotation.ExternalDocsDescription:
 			if externalDocs == nil {
 				externalDocs = &spec3.ExternalDocs{}
 			}
 			externalDocs.Description = value
-		case externalDocsURLAttr:
+		case annotation.ExternalDocsURL:
 			if externalDocs == nil {
 				externalDocs = &spec3.ExternalDocs{}
 			}
 			externalDocs.URL = value
-		case protocolAttr:
-			protocol = value
-		case protocolVersionAttr:
-			protocolVersion = value
-		case pathnameAttr:
-			pathname = value
-		case serverTitleAttr:
-			serverTitle = value
-		case serverSummaryAttr:
-			serverSummary = value
-		case serverDescriptionAttr:
-			serverDescription = value
-		case serverNameAttr:
+		case annotation.ServerName:
 			serverName = value
-		case serverTagAttr:
-			// Parse tag in format: "name - description" or just "name"
-			tagParts := strings.SplitN(value, " - ", 2)
-			tag := spec3.Tag{Name: strings.TrimSpace(tagParts[0])}
-			if len(tagParts) > 1 {
-				tag.Description = strings.TrimSpace(tagParts[1])
-			}
-			serverTags = append(serverTags, tag)
-		case serverExternalDocsDescAttr:
-			if serverExternalDocs == nil {
-				serverExternalDocs = &spec3.ExternalDocs{}
+		case annotation.MessageCommonHeader:
+			if err := p.parseCommonHeader(value); err != nil {
+				p.warnings.warnf(warnAnnotation, "%v", err)
 			}
-			serverExternalDocs.Description = value
-		case serverExternalDocsURLAttr:
-			if serverExternalDocs == nil {
-				serverExternalDocs = &spec3.ExternalDocs{}
+		default:
+			if p.parseTraitAttr(attribute, value) {
+				continue
 			}
-			serverExternalDocs.URL = value
-		case serverVariableAttr:
-			// Parse variable in format: "name enum=val1,val2 default=val1 description=Variable description"
-			if serverVariables == nil {
-				serverVariables = make(map[string]spec3.ServerVar)
+			if _, ok := annotation.CutPrefix(attribute, annotation.SecuritySchemePrefix); ok {
+				if p.asyncAPI.Components.SecuritySchemes == nil {
+					p.asyncAPI.Components.SecuritySchemes = make(map[string]spec3.SecurityScheme)
+				}
+				parseSecuritySchemeAttr(attribute, value, p.asyncAPI.Components.SecuritySchemes)
+				continue
 			}
-			parseServerVariable(value, serverVariables)
-		case serverSecurityAttr:
-			// Parse security scheme names (comma-separated)
-			schemes := strings.Split(value, ",")
-			for _, scheme := range schemes {
-				trimmed := strings.TrimSpace(scheme)
-				if trimmed != "" {
-					serverSecurity = append(serverSecurity, map[string][]string{
-						trimmed: {},
-					})
+			if key, ok := extensionKey(attribute, "@"); ok {
+				if err := setExtension(&p.asyncAPI.Info.Extensions, key, value); err != nil {
+					p.warnings.warnf(warnAnnotation, "%v", err)
 				}
+				continue
 			}
-		case serverBindingAttr:
-			// Parse binding in format: "protocol.key value"
-			if serverBindings == nil {
-				serverBindings = make(map[string]interface{})
+			// Named server block: @server.<name>.<field>
+			if name, field, ok := namedServerAttr(attribute); ok {
+				builder := namedServers[name]
+				if builder == nil {
+					builder = &serverBuilder{}
+					namedServers[name] = builder
+				}
+				if key, ok := extensionKey(attribute, annotation.ServerPrefix+name+"."); ok {
+					if err := setExtension(&builder.server.Extensions, key, value); err != nil {
+						p.warnings.warnf(warnAnnotation, "%v", err)
+					}
+					continue
+				}
+				builder.setField(field, value)
+				continue
 			}
-			parseServerBinding(value, serverBindings)
-		case urlAttr, hostAttr:
-			// Store the host value, server will be created after all comments are parsed
-			// Strip protocol prefix from host if present (e.g., nats://localhost:4222 -> localhost:4222)
-			serverHost = value
-			if idx := strings.Index(serverHost, "://"); idx != -1 {
-				serverHost = serverHost[idx+3:]
+			if key, ok := extensionKey(attribute, annotation.ServerPrefix); ok {
+				if err := setExtension(&defaultServer.server.Extensions, key, value); err != nil {
+					p.warnings.warnf(warnAnnotation, "%v", err)
+				}
+				continue
 			}
 		}
 	}
 
-	// Create server after all attributes have been parsed
-	if serverHost != "" {
+	// Create servers after all attributes have been parsed
+	if server, ok := defaultServer.build(); ok {
 		if serverName == "" {
-			serverName = "default"
-		}
-
-		server := spec3.Server{
-			Host:            serverHost,
-			Protocol:        protocol,
-			ProtocolVersion: protocolVersion,
-			Pathname:        pathname,
-			Title:           serverTitle,
-			Summary:         serverSummary,
-			Description:     serverDescription,
-		}
-
-		if len(serverTags) > 0 {
-			server.Tags = serverTags
-		}
-		if serverExternalDocs != nil && serverExternalDocs.URL != "" {
-			server.ExternalDocs = serverExternalDocs
-		}
-		if len(serverVariables) > 0 {
-			server.Variables = serverVariables
-		}
-		if len(serverSecurity) > 0 {
-			server.Security = serverSecurity
-		}
-		if len(serverBindings) > 0 {
-			server.Bindings = serverBindings
+			serverName = defaultServerName(p.serverNaming, defaultServer.environment, title, server)
 		}
-
 		p.asyncAPI.Servers[serverName] = server
 	}
+	for name, builder := range namedServers {
+		if server, ok := builder.build(); ok {
+			p.asyncAPI.Servers[name] = server
+		}
+	}
 
 	// In AsyncAPI 3.0.0, tags and externalDocs are part of the Info object, not root level
 	if len(tags) > 0 {
@@ -283,15 +251,30 @@ func (p *Parser) ParseMain(comments []string) {
 
 // ParseOperation parses operation comments and processes them into AsyncAPI 3.0 structure.
 func (p *Parser) ParseOperation(comments []string, tc *TypeChecker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if tc != nil {
+		tc.warnings = p.warnings
+		tc.reserveComponent = func(typeName string) string { return p.reserveComponent(typeName, tc) }
+	}
+	comments = joinDescriptions(comments)
 	operation := NewOperation()
+	operation.Doc = docText(comments)
 	for i := range comments {
 		comment := comments[i]
 		if err := operation.ParseComment(comment, tc); err != nil {
-			// Log error but continue processing other comments
-			continue
+			// Report the error but continue processing other comments
+			p.warnings.warnf(warnAnnotation, "%v", err)
 		}
 	}
+	p.resolveSchemaFormats(operation, tc)
+	operation.applyAMQPChannelType()
+	operation.applyBindingVersions()
+	// Referenced types take their reserved names before payloads are registered
+	p.registerComponentRefs(tc)
 	p.proccessOperation(operation)
+	p.registerComponentRefs(tc)
+	p.recordSourceOrder()
 }
 
 // - Operations define actions (send/receive) with channel references.
@@ -300,79 +283,204 @@ func (p *Parser) proccessOperation(operation *Operation) {
 		return
 	}
 
-	channelName := toChannelName(operation.Name)
-	messageName := channelName + "Message"
+	channelName := p.channelKey(operation)
 
 	// Check if this is a request-reply pattern (has @response)
 	hasResponse := operation.MessageResponse != nil && operation.MessageResponse.MessageSample != nil
 	action, operationName := p.determineActionAndName(operation.TypeOperation, channelName, hasResponse)
-	channelParams := p.createChannelParameters(operation.Parameters)
-
-	// Create and register the message
-	p.createMessage(messageName, operation.Message, operation)
+	if operation.OperationID != "" {
+		operationName = operation.OperationID
+	}
+	channelParams := p.createChannelParameters(operation)
+	p.checkPayloadSubtypes(operation)
+
+	// Create and register the message, unless it is governed by an
+	// external catalog
+	var messageName string
+	var messageRef spec3.MessageRef
+	if operation.MessageRef != "" {
+		if operation.Message.MessageSample != nil {
+			p.warnings.warnf(warnAnnotation, "@payload on %s is ignored with @message.ref", operation.Name)
+		}
+		messageName = externalMessageName(operation.MessageRef)
+		messageRef = spec3.MessageRef{Ref: operation.MessageRef}
+	} else if operation.MessageName != "" {
+		messageName = p.createNamedMessage(operation.MessageName, operation.Message, operation)
+		messageRef = componentMessageRef(messageName)
+	} else {
+		messageName = p.createMessage(p.naming.messageName(channelName, operation.Message.TypeName), operation.Message, operation)
+		messageRef = componentMessageRef(messageName)
+	}
 
 	// Create and register the channel
-	p.createChannel(channelName, operation.Name, messageName, channelParams, operation)
+	p.createChannel(channelName, operation.Name, messageName, messageRef, channelParams, operation)
+	p.describeChannel(channelName, operation)
+	if len(operation.ChannelBindings) > 0 {
+		channel := p.asyncAPI.Channels[channelName]
+		channel.Bindings = mergeBindings(channel.Bindings, operation.ChannelBindings)
+		p.asyncAPI.Channels[channelName] = channel
+	}
 
 	// Create the operation
 	op := p.createOperation(action, channelName, messageName, operation)
+	if operation.MessageRef == "" {
+		p.recordService(action, channelName, messageName)
+	} else {
+		p.recordService(action, channelName)
+	}
+
+	// Additional payload types become extra messages on the same channel
+	for _, alt := range operation.AltMessages {
+		altName := p.createMessage(p.naming.altMessageName(channelName, alt.TypeName), alt, operation)
+		p.recordService(action, channelName, altName)
+		p.asyncAPI.Channels[channelName].Messages[altName] = componentMessageRef(altName)
+		op.Messages = append(op.Messages, spec3.Reference{
+			Ref: "#/channels/" + channelName + "/messages/" + altName,
+		})
+	}
 
 	// Handle request-reply pattern - automatically detected when @response is present
 	if operation.MessageResponse != nil && operation.MessageResponse.MessageSample != nil {
 		p.addReplyConfiguration(&op, channelName, operation, channelParams)
+	} else {
+		if len(operation.ResponseErrors) > 0 {
+			p.warnings.warnf(warnAnnotation, "@response.error on %s is ignored without @response", operation.Name)
+		}
+		if operation.ResponseAddress != nil {
+			p.warnings.warnf(warnAnnotation, "@response.address on %s is ignored without @response", operation.Name)
+		}
+	}
+
+	key := p.uniqueOperationName(operationName)
+	if operation.OperationID != "" && key != operationName {
+		p.warnings.warnf(warnAnnotation, "@operation.id %s is used by several operations; using %s", operationName, key)
 	}
+	p.asyncAPI.Operations[key] = op
+}
+
+// channelKey returns the key of the channel for the operation's address: the
+// @channel.key override when set, otherwise the camelCase form of the address.
+// Addresses that normalize to a key already used by a different address, such
+// as user.created and user-created, get a numbered key (userCreated2).
+func (p *Parser) channelKey(operation *Operation) string {
+	if operation.ChannelKey != "" {
+		if channel, exists := p.asyncAPI.Channels[operation.ChannelKey]; exists && channel.Address != operation.Name {
+			p.warnings.warnf(warnChannelKey, "@channel.key %s is used by both %q and %q", operation.ChannelKey, channel.Address, operation.Name)
+		}
+		return operation.ChannelKey
+	}
+
+	key := p.naming.channelKey(operation.Name)
+	candidate := key
+	for i := 2; ; i++ {
+		channel, exists := p.asyncAPI.Channels[candidate]
+		if !exists || channel.Address == operation.Name {
+			break
+		}
+		candidate = key + strconv.Itoa(i)
+	}
+	if candidate != key {
+		p.warnings.warnf(warnChannelKey, "channel key %s for %q is already used by %q; using %s (set @channel.key to choose a name)",
+			key, operation.Name, p.asyncAPI.Channels[key].Address, candidate)
+	}
+	return candidate
+}
 
-	p.asyncAPI.Operations[operationName] = op
+// uniqueOperationName suffixes the name with a number when several functions
+// declare the same action on the same channel.
+func (p *Parser) uniqueOperationName(name string) string {
+	if _, exists := p.asyncAPI.Operations[name]; !exists {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + strconv.Itoa(i)
+		if _, exists := p.asyncAPI.Operations[candidate]; !exists {
+			return candidate
+		}
+	}
 }
 
 // determineActionAndName returns the action and operation name based on operation type.
-// If hasResponse is true, it automatically treats the operation as a request-reply pattern.
+// If hasResponse is true, it automatically treats the operation as a request-reply pattern:
+// a publisher sends the request ("requestX") and a subscriber receives it and replies ("replyX").
 //
 //nolint:gocritic // Named returns would reduce readability here
 func (p *Parser) determineActionAndName(opType, channelName string, hasResponse bool) (spec3.OperationAction, string) {
-	// Capitalize first letter of channelName
-	capitalizedName := channelName
-	if len(channelName) > 0 {
-		caser := cases.Title(language.English)
-		// For camelCase strings, we need to uppercase the first letter manually
-		capitalizedName = strings.ToUpper(string(channelName[0])) + channelName[1:]
-		_ = caser // Keep import to satisfy linter
-	}
-
 	// If @response is present, this is a request-reply pattern
 	if hasResponse {
-		return spec3.ActionSend, "request" + capitalizedName
+		if opType == "pub" {
+			return spec3.ActionSend, p.naming.join("request", channelName)
+		}
+		return spec3.ActionReceive, p.naming.join("reply", channelName)
 	}
 
 	switch opType {
 	case "pub":
-		return spec3.ActionSend, "publish" + capitalizedName
+		return spec3.ActionSend, p.naming.join("publish", channelName)
 	case "sub":
-		return spec3.ActionReceive, "subscribe" + capitalizedName
+		return spec3.ActionReceive, p.naming.join("subscribe", channelName)
 	default:
-		return spec3.ActionReceive, "subscribe" + capitalizedName
+		return spec3.ActionReceive, p.naming.join("subscribe", channelName)
 	}
 }
 
-// createChannelParameters converts operation parameters to channel parameters.
-func (p *Parser) createChannelParameters(params map[string]ParameterInfo) map[string]spec3.Parameter {
-	channelParams := make(map[string]spec3.Parameter)
-	for paramName, param := range params {
-		channelParams[paramName] = spec3.Parameter{
-			Description: getSchemaDescription(param.Schema),
+// createMessage creates and registers a message in the components section and
+// returns its component name, which differs from messageName when an
+// equivalent message already exists or the name holds a different message.
+func (p *Parser) createMessage(messageName string, msgInfo *MessageInfo, operation *Operation) string {
+	return p.registerMessage(messageName, msgInfo.TypeName, p.buildMessage(messageName, msgInfo, operation))
+}
+
+// createNamedMessage registers a message under the name chosen with
+// @message.name. Unlike generated names, it is kept even when an equivalent
+// message exists under another name; only a different message already
+// holding the name makes it fall back to a numbered name (UserCreated2).
+func (p *Parser) createNamedMessage(messageName string, msgInfo *MessageInfo, operation *Operation) string {
+	message := p.buildMessage(messageName, msgInfo, operation)
+	candidate := messageName
+	for i := 2; ; i++ {
+		existing, taken := p.asyncAPI.Components.Messages[candidate]
+		if !taken {
+			break
+		}
+		if equivalentMessages(existing, message) {
+			p.mergeMessageText(candidate, message)
+			break
 		}
+		candidate = messageName + strconv.Itoa(i)
+	}
+	if candidate != messageName {
+		p.warnings.warnf(warnAnnotation, "@message.name %s is used by different messages; using %s", messageName, candidate)
 	}
-	return channelParams
+	if _, taken := p.asyncAPI.Components.Messages[candidate]; taken {
+		return candidate
+	}
+	message.Name = candidate
+	p.asyncAPI.Components.Messages[candidate] = message
+	return candidate
 }
 
-// createMessage creates and registers a message in the components section.
-func (p *Parser) createMessage(messageName string, msgInfo *MessageInfo, operation *Operation) {
+// buildMessage creates the message of msgInfo, registering its payload and
+// headers schemas.
+func (p *Parser) buildMessage(messageName string, msgInfo *MessageInfo, operation *Operation) spec3.Message {
 	message := spec3.Message{
 		Name:        messageName,
 		Summary:     msgInfo.Summary,
 		Description: msgInfo.Description,
 	}
 
+	// @message.summary and @message.description describe the @payload message
+	// instead of the shared @summary and @description
+	if msgInfo == operation.Message {
+		if operation.MessageSummary != "" {
+			message.Summary = operation.MessageSummary
+		}
+		if operation.MessageDescription != "" {
+			message.Description = operation.MessageDescription
+		}
+		message.Extensions = operation.MessageExtensions
+	}
+
 	// Add message metadata from operation annotations
 	if operation.MessageTitle != "" {
 		message.Title = operation.MessageTitle
@@ -391,55 +499,350 @@ func (p *Parser) createMessage(messageName string, msgInfo *MessageInfo, operati
 
 	// Handle message headers if specified
 	if operation.MessageHeaders != "" {
-		// Create a reference to the headers type in components/schemas
+		// Register the headers schema and reference it from components/schemas
+		headersName := operation.MessageHeaders
+		if operation.MessageHeadersSample != nil {
+			headersName = p.registerSchema(schemaNameForType(headersName), generateTypedSchema(operation.MessageHeadersSample))
+		}
 		message.Headers = map[string]interface{}{
-			"$ref": "#/components/schemas/" + operation.MessageHeaders,
+			"$ref": "#/components/schemas/" + headersName,
 		}
 	}
 
 	// Handle correlation ID if specified
 	if operation.MessageCorrelationID != "" {
-		message.CorrelationID = &spec3.CorrelationID{
-			Location: "$message.header#/" + operation.MessageCorrelationID,
-		}
+		message.CorrelationID = p.registerCorrelationID(parseCorrelationID(operation.MessageCorrelationID))
 	}
 
-	if msgInfo.MessageSample != nil {
-		schemaName := messageName + "Payload"
-		schema := GenerateJSONSchema(msgInfo.MessageSample)
-		p.asyncAPI.Components.Schemas[schemaName] = schema
+	switch {
+	case msgInfo == operation.Message && len(operation.PayloadSubtypes) > 0:
+		// The @payload.subtype variants make up the payload
+		message.Payload = p.subtypesPayload(messageName, operation)
+	case msgInfo.MessageSample != nil && operation.MessageProto != "" && (msgInfo.Protobuf || msgInfo == operation.Message):
+		// The .proto file describes the payload instead of a JSON Schema
+		message.Payload = map[string]interface{}{
+			"schemaFormat": protobufSchemaFormat,
+			"schema":       map[string]interface{}{"$ref": operation.MessageProto},
+		}
+		if message.ContentType == "" {
+			message.ContentType = protobufContentType
+		}
+	case msgInfo.Schema != nil:
+		// Schemas in another format, such as Avro, are embedded as they are
+		message.Payload = map[string]interface{}{
+			"schemaFormat": msgInfo.SchemaFormat,
+			"schema":       msgInfo.Schema,
+		}
+	case msgInfo.MessageSample != nil:
+		schemaName := schemaNameForType(msgInfo.TypeName)
+		if schemaName == "" {
+			schemaName = messageName + "Payload"
+		}
+		schema := generateTypedSchema(msgInfo.MessageSample)
+		if operation.MessageKey != "" && msgInfo == operation.Message {
+			p.checkMessageKey(messageName, operation.MessageKey, schema)
+		}
+		schemaName = p.registerSchema(schemaName, schema)
 		message.Payload = map[string]interface{}{
 			"$ref": "#/components/schemas/" + schemaName,
 		}
+		if msgInfo.SchemaFormat != "" {
+			// An explicit format of the generated JSON Schema, e.g. a draft
+			message.Payload = map[string]interface{}{
+				"schemaFormat": msgInfo.SchemaFormat,
+				"schema":       message.Payload,
+			}
+		}
+
+		for _, example := range msgInfo.Examples {
+			if example.Payload == nil {
+				continue
+			}
+			for _, problem := range validateExample(schema, example.Payload, "payload") {
+				p.warnings.warnf(warnExample, "example %q of message %s does not match its schema: %s", example.Name, messageName, problem)
+			}
+		}
 	}
 
-	p.asyncAPI.Components.Messages[messageName] = message
+	if len(operation.MessageTraits) > 0 {
+		message.Traits = traitRefs("messageTraits", operation.MessageTraits)
+	}
+
+	// Message bindings describe the messages of the operation's own channel
+	if len(operation.MessageBindings) > 0 && msgInfo != operation.MessageResponse && msgInfo.Error == nil {
+		message.Bindings = operation.MessageBindings
+	}
+
+	if len(msgInfo.Examples) > 0 {
+		message.Examples = msgInfo.Examples
+	}
+
+	message.Error = msgInfo.Error
+	if msgInfo == operation.Message {
+		message.PartitionKey = operation.MessageKey
+	}
+
+	return message
 }
 
-// createChannel creates and registers a channel.
-func (p *Parser) createChannel(channelName, address, messageName string, params map[string]spec3.Parameter, operation *Operation) {
-	channel := spec3.Channel{
-		Address: address,
-		Messages: map[string]spec3.MessageRef{
-			messageName: {
-				Ref: "#/components/messages/" + messageName,
-			},
-		},
+// registerMessage stores the message under the first free name and returns it.
+// A message equivalent to an existing one (same payload, headers and metadata)
+// reuses that component, so a Go type used by several operations is emitted once.
+func (p *Parser) registerMessage(name, typeName string, message spec3.Message) string {
+	existingNames := make([]string, 0, len(p.asyncAPI.Components.Messages))
+	for existingName := range p.asyncAPI.Components.Messages {
+		existingNames = append(existingNames, existingName)
 	}
+	sort.Strings(existingNames)
+	for _, existingName := range existingNames {
+		if equivalentMessages(p.asyncAPI.Components.Messages[existingName], message) {
+			p.mergeMessageText(existingName, message)
+			return existingName
+		}
+	}
+
+	// A different message already named after the channel falls back to a
+	// name that includes its Go type, e.g. "userCreatedUserAuditMessage".
+	base := p.naming.messageBase(name)
+	candidates := []string{name}
+	if typeName != "" && base != name && !strings.HasSuffix(name, p.naming.altMessageName("", typeName)) {
+		candidates = append(candidates, p.naming.altMessageName(base, typeName))
+	}
+	for i := 2; ; i++ {
+		for _, candidate := range candidates {
+			if _, taken := p.asyncAPI.Components.Messages[candidate]; !taken {
+				message.Name = candidate
+				p.asyncAPI.Components.Messages[candidate] = message
+				return candidate
+			}
+		}
+		if base == name {
+			// Messages named after their Go type are numbered as they are
+			candidates = []string{name + strconv.Itoa(i)}
+		} else {
+			candidates = []string{p.naming.join(base+strconv.Itoa(i), "Message")}
+		}
+	}
+}
+
+// equivalentMessages compares messages ignoring name, summary and description,
+// which come from the operations sharing the message and are reconciled by
+// mergeMessageText.
+func equivalentMessages(a, b spec3.Message) bool {
+	a.Name, a.Summary, a.Description = "", "", ""
+	b.Name, b.Summary, b.Description = "", "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+// mergeMessageText merges the summary and description of message into the
+// equivalent component message name. A text given by a single operation is
+// kept, while texts the operations sharing the message disagree on describe
+// none of them, so they are left out of the component, where
+// @message.summary and @message.description can set one; each operation
+// keeps its own.
+func (p *Parser) mergeMessageText(name string, message spec3.Message) {
+	shared := p.asyncAPI.Components.Messages[name]
+	shared.Summary = p.mergeText(name, "summary", shared.Summary, message.Summary)
+	shared.Description = p.mergeText(name, "description", shared.Description, message.Description)
+	p.asyncAPI.Components.Messages[name] = shared
+}
+
+// mergeText returns the field of the shared message name once text of
+// another operation is merged into its current value.
+func (p *Parser) mergeText(name, field, current, text string) string {
+	key := name + "/" + field
+	switch {
+	case text == "" || text == current || p.messageTextConflicts[key]:
+		return current
+	case current == "":
+		return text
+	}
+	if p.messageTextConflicts == nil {
+		p.messageTextConflicts = make(map[string]bool)
+	}
+	p.messageTextConflicts[key] = true
+	p.warnings.warnf(warnAnnotation, "message %s is shared by operations with different %s texts; leaving its %s out, set one with @message.%s", name, field, field, field)
+	return ""
+}
+
+// registerSchema stores the schema under name, or under a numbered variant
+// when name already holds a different schema, and returns the name used.
+func (p *Parser) registerSchema(name string, schema map[string]interface{}) string {
+	return p.registerTypeSchema(name, "", schema)
+}
+
+// registerTypeSchema registers the schema of the type identified by key, or
+// of no particular type when key is empty, as registerSchema does, skipping
+// the names reserved for other types emitted as $refs.
+func (p *Parser) registerTypeSchema(name, key string, schema map[string]interface{}) string {
+	truncated := p.schemaLimits.truncate(schema)
+	var uses []schemaUse
+	collectSchemaTypes(schema, -1, &uses)
+	candidate := name
+	for i := 2; ; i++ {
+		existing, taken := p.asyncAPI.Components.Schemas[candidate]
+		if taken && reflect.DeepEqual(existing, schema) {
+			return candidate
+		}
+		if owner := p.refOwners[candidate]; !taken && (owner == "" || owner == key) {
+			if truncated {
+				p.warnings.warnf(warnSchema, "schema %s exceeds the schema size limits; removed parts are marked %s", candidate, truncatedExtension)
+			}
+			p.asyncAPI.Components.Schemas[candidate] = schema
+			p.recordSchemaUses(candidate, schema, uses)
+			return candidate
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+}
+
+// recordSchemaUses adds the uses collected from the component schema name
+// to the uses of the document.
+func (p *Parser) recordSchemaUses(name string, schema map[string]interface{}, uses []schemaUse) {
+	offset := len(p.schemaUses)
+	for _, use := range uses {
+		if use.parent != -1 {
+			use.parent += offset
+		} else if reflect.ValueOf(use.schema).Pointer() == reflect.ValueOf(schema).Pointer() {
+			use.component = name
+		}
+		p.schemaUses = append(p.schemaUses, use)
+	}
+}
+
+// schemaNameForType derives a component schema name from a Go type name,
+// e.g. "events.OrderPlaced" -> "OrderPlaced", "[]Order" -> "OrderList",
+// "map[string]Order" -> "OrderMap" and "Page[events.Order]" -> "PageOrder".
+func schemaNameForType(typeName string) string {
+	suffix := ""
+	if strings.HasPrefix(typeName, "[]") {
+		typeName = typeName[2:]
+		suffix = "List"
+	}
+	typeName = strings.TrimPrefix(typeName, "*")
+	if strings.HasPrefix(typeName, "map[") {
+		if end := closingBracket(typeName, len("map")); end != -1 {
+			return schemaNameForType(typeName[end+1:]) + "Map" + suffix
+		}
+	}
+	if open := strings.Index(typeName, "["); open > 0 && strings.HasSuffix(typeName, "]") {
+		name := schemaNameForType(typeName[:open])
+		for _, arg := range splitTypeArgs(typeName[open+1 : len(typeName)-1]) {
+			name += schemaNameForType(arg)
+		}
+		return name + suffix
+	}
+	if idx := strings.LastIndex(typeName, "."); idx != -1 {
+		typeName = typeName[idx+1:]
+	}
+	if typeName == "" {
+		return ""
+	}
+	return strings.ToUpper(typeName[:1]) + typeName[1:] + suffix
+}
+
+// splitTypeArgs splits the type arguments of a generic instance name at the
+// commas outside nested brackets, e.g. "string,map[string]int".
+func splitTypeArgs(args string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range args {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(args[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(args[start:]))
+}
+
+// createChannel creates and registers a channel. When the channel already
+// exists for the same address (e.g. a publisher and a subscriber of one
+// subject), the message and parameters are merged into it.
+func (p *Parser) createChannel(channelName, address, messageName string, messageRef spec3.MessageRef, params map[string]spec3.Parameter, operation *Operation) {
+	channel, exists := p.asyncAPI.Channels[channelName]
+	if !exists || channel.Address != address {
+		channel = spec3.Channel{
+			Address:  address,
+			Messages: map[string]spec3.MessageRef{},
+		}
+	}
+	channel.Messages[messageName] = messageRef
 
 	// Add channel metadata from operation annotations
 	if operation.ChannelTitle != "" {
 		channel.Title = operation.ChannelTitle
 	}
 
+	if operation.ChannelSummary != "" {
+		channel.Summary = operation.ChannelSummary
+	}
+
 	if operation.ChannelDescription != "" {
 		channel.Description = operation.ChannelDescription
 	}
 
+	for _, tagName := range operation.ChannelTags {
+		if !slices.ContainsFunc(channel.Tags, func(tag spec3.Tag) bool { return tag.Name == tagName }) {
+			channel.Tags = append(channel.Tags, spec3.Tag{Name: tagName})
+		}
+	}
+
+	for _, serverName := range operation.ChannelServers {
+		ref := spec3.Reference{Ref: "#/servers/" + serverName}
+		if !slices.Contains(channel.Servers, ref) {
+			channel.Servers = append(channel.Servers, ref)
+		}
+	}
+
+	if operation.ChannelExternalDocs != nil && operation.ChannelExternalDocs.URL != "" {
+		channel.ExternalDocs = &spec3.ExternalDocs{
+			Description: operation.ChannelExternalDocs.Description,
+			URL:         operation.ChannelExternalDocs.URL,
+		}
+	}
+
+	channel.Extensions = mergeExtensions(channel.Extensions, operation.ChannelExtensions)
+
 	if len(params) > 0 {
-		channel.Parameters = params
+		if channel.Parameters == nil {
+			channel.Parameters = make(map[string]spec3.Parameter)
+		}
+		for name, param := range params {
+			existing, exists := channel.Parameters[name]
+			channel.Parameters[name] = mergeChannelParameter(existing, param, exists, name)
+		}
+	}
+
+	p.asyncAPI.Channels[channelName] = channel
+}
+
+// operationDescription returns the description of an operation, following
+// the fallback chain of Operation.Description unless fallbacks are disabled.
+func (p *Parser) operationDescription(operation *Operation) string {
+	if p.noDescriptionFallback {
+		if operation.OperationDescription != "" {
+			return operation.OperationDescription
+		}
+		return operation.Message.Description
 	}
+	return operation.Description()
+}
 
+// describeChannel gives a channel without @channel.description the
+// description of the first operation on it.
+func (p *Parser) describeChannel(channelName string, operation *Operation) {
+	channel := p.asyncAPI.Channels[channelName]
+	if channel.Description != "" || p.noDescriptionFallback {
+		return
+	}
+	channel.Description = operation.Description()
 	p.asyncAPI.Channels[channelName] = channel
 }
 
@@ -450,8 +853,8 @@ func (p *Parser) createOperation(action spec3.OperationAction, channelName, mess
 		Channel: spec3.Reference{
 			Ref: "#/channels/" + channelName,
 		},
-		Summary:     operation.Message.Summary,
-		Description: operation.Message.Description,
+		Summary:     operation.Summary(),
+		Description: p.operationDescription(operation),
 		Messages: []spec3.Reference{
 			{Ref: "#/channels/" + channelName + "/messages/" + messageName},
 		},
@@ -472,11 +875,9 @@ func (p *Parser) createOperation(action spec3.OperationAction, channelName, mess
 	}
 
 	if len(operation.Security) > 0 {
-		op.Security = make([]map[string][]string, len(operation.Security))
+		op.Security = make([]spec3.Reference, len(operation.Security))
 		for i, schemeName := range operation.Security {
-			op.Security[i] = map[string][]string{
-				schemeName: {},
-			}
+			op.Security[i] = securitySchemeRef(schemeName)
 		}
 	}
 
@@ -491,22 +892,42 @@ func (p *Parser) createOperation(action spec3.OperationAction, channelName, mess
 		op.Bindings = operation.Bindings
 	}
 
+	if len(operation.OperationTraits) > 0 {
+		op.Traits = traitRefs("operationTraits", operation.OperationTraits)
+	}
+
+	op.Timeout = operation.Timeout
+	op.QoS = operation.QoS
+	op.Extensions = operation.Extensions
+
 	return op
 }
 
 // addReplyConfiguration adds reply channel and message for request-reply pattern.
 func (p *Parser) addReplyConfiguration(op *spec3.Operation, channelName string, operation *Operation, channelParams map[string]spec3.Parameter) {
-	replyChannelName := channelName + "Reply"
-	replyMessageName := replyChannelName + "Message"
+	replyChannelName := p.naming.join(channelName, "Reply")
 
 	// Create and register reply message
-	p.createMessage(replyMessageName, operation.MessageResponse, operation)
+	replyMessageName := p.createMessage(p.naming.messageName(replyChannelName, operation.MessageResponse.TypeName), operation.MessageResponse, operation)
+
+	// Create and register reply channel. A reply address given at runtime,
+	// such as a NATS inbox, leaves the channel without an address.
+	replyAddress := operation.Name + "/reply"
+	if operation.ResponseAddress != nil {
+		replyAddress, channelParams = "", nil
+	}
+	p.createChannel(replyChannelName, replyAddress, replyMessageName, componentMessageRef(replyMessageName), channelParams, operation)
 
-	// Create and register reply channel
-	p.createChannel(replyChannelName, operation.Name+"/reply", replyMessageName, channelParams, operation)
+	// The requester receives the reply the replier sends
+	replyAction := spec3.ActionReceive
+	if op.Action == spec3.ActionReceive {
+		replyAction = spec3.ActionSend
+	}
+	p.recordService(replyAction, replyChannelName, replyMessageName)
 
 	// Set reply configuration on operation
 	op.Reply = &spec3.OperationReply{
+		Address: operation.ResponseAddress,
 		Channel: &spec3.Reference{
 			Ref: "#/channels/" + replyChannelName,
 		},
@@ -514,6 +935,43 @@ func (p *Parser) addReplyConfiguration(op *spec3.Operation, channelName string,
 			{Ref: "#/channels/" + replyChannelName + "/messages/" + replyMessageName},
 		},
 	}
+
+	// Error variants are additional messages on the reply channel
+	for _, errInfo := range operation.ResponseErrors {
+		errName := p.createMessage(p.naming.altMessageName(replyChannelName, errInfo.TypeName), errInfo, operation)
+		p.recordService(replyAction, replyChannelName, errName)
+		p.asyncAPI.Channels[replyChannelName].Messages[errName] = componentMessageRef(errName)
+		op.Reply.Messages = append(op.Reply.Messages, spec3.Reference{
+			Ref: "#/channels/" + replyChannelName + "/messages/" + errName,
+		})
+	}
+}
+
+// componentMessageRef references a message of components/messages.
+func componentMessageRef(messageName string) spec3.MessageRef {
+	return spec3.MessageRef{Ref: "#/components/messages/" + messageName}
+}
+
+// externalMessageName names the channel message of a @message.ref after the
+// last segment of the reference, without a .json, .yaml or .yml extension:
+// "https://catalog.example.com/messages/UserCreated.yaml" -> "UserCreated",
+// "catalog.yaml#/components/messages/order.created" -> "order.created".
+func externalMessageName(ref string) string {
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		if fragment := strings.Trim(ref[i+1:], "/"); fragment != "" {
+			ref = fragment
+		} else {
+			ref = ref[:i]
+		}
+	}
+	ref = strings.TrimRight(ref, "/")
+	name := path.Base(ref)
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		if base, ok := strings.CutSuffix(name, ext); ok {
+			return base
+		}
+	}
+	return name
 }
 
 // e.g., "user.created" -> "userCreated", "user.{id}.updated" -> "userIdUpdated".
@@ -558,8 +1016,62 @@ func getSchemaDescription(schema map[string]interface{}) string {
 	return ""
 }
 
+// Finalize applies settings that depend on the whole parsed source, such as
+// common headers declared in the main annotations. Call it after all files
+// have been parsed.
+func (p *Parser) Finalize() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.shareSchemas()
+	if p.openAPINullable {
+		for _, schema := range p.asyncAPI.Components.Schemas {
+			if schema, ok := schema.(map[string]interface{}); ok {
+				openAPINullable(schema)
+			}
+		}
+	}
+	p.applyCommonHeaders()
+	p.applyQoSBindings()
+	p.applyMessageKeys()
+	p.applyDefaultBindingVersions()
+	p.applyServiceRoles()
+}
+
+// applyQoSBindings moves @operation.qos into MQTT operation bindings when the
+// API is served over MQTT. Other protocols keep the x-qos extension.
+func (p *Parser) applyQoSBindings() {
+	mqtt := false
+	for _, server := range p.asyncAPI.Servers {
+		if protocol := strings.ToLower(server.Protocol); protocol == "mqtt" || protocol == "secure-mqtt" {
+			mqtt = true
+		}
+	}
+	if !mqtt {
+		return
+	}
+
+	for name, op := range p.asyncAPI.Operations {
+		if op.QoS == nil {
+			continue
+		}
+		if op.Bindings == nil {
+			op.Bindings = make(map[string]interface{})
+		}
+		binding, ok := op.Bindings["mqtt"].(map[string]interface{})
+		if !ok {
+			binding = make(map[string]interface{})
+			op.Bindings["mqtt"] = binding
+		}
+		binding["qos"] = *op.QoS
+		op.QoS = nil
+		p.asyncAPI.Operations[name] = op
+	}
+}
+
 // Validate checks that the parser has collected required API information.
 func (p *Parser) Validate() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if p.asyncAPI.Info.Title == "" {
 		return fmt.Errorf("missing required @title annotation in API comments")
 	}
@@ -574,6 +1086,8 @@ func (p *Parser) Validate() error {
 
 // MarshalYAML serializes the AsyncAPI 3.0 document to YAML format.
 func (p *Parser) MarshalYAML() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	return p.asyncAPI.MarshalYAML()
 }
 
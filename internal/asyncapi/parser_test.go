@@ -1,6 +1,13 @@
 package asyncapi
 
 import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
@@ -212,6 +219,220 @@ func TestParseMainWithInfoAnnotations(t *testing.T) {
 	}
 }
 
+func TestParseMainWithNamedServers(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@title Multi Server API",
+		"@version 1.0.0",
+		"@server.production.host nats://nats.example.com:4222",
+		"@server.production.protocol nats",
+		"@server.production.description Production cluster",
+		"@server.production.tag production - Production environment",
+		"@server.production.security userPassword",
+		"@server.staging.url nats://staging.example.com:4222",
+		"@server.staging.protocol nats",
+		"@server.staging.variable region enum=eu,us default=eu description=Staging region",
+		"@server.staging.externalDocs.url https://docs.example.com/staging",
+	})
+
+	if len(parser.asyncAPI.Servers) != 2 {
+		t.Fatalf("Expected 2 servers, got %d: %v", len(parser.asyncAPI.Servers), parser.asyncAPI.Servers)
+	}
+
+	production, ok := parser.asyncAPI.Servers["production"]
+	if !ok {
+		t.Fatal("production server missing")
+	}
+	if production.Host != "nats.example.com:4222" {
+		t.Errorf("production Host = %q, want %q", production.Host, "nats.example.com:4222")
+	}
+	if production.Description != "Production cluster" {
+		t.Errorf("production Description = %q, want %q", production.Description, "Production cluster")
+	}
+	if len(production.Tags) != 1 || production.Tags[0].Name != "production" {
+		t.Errorf("production Tags = %v", production.Tags)
+	}
+	if len(production.Security) != 1 {
+		t.Errorf("production Security = %v", production.Security)
+	}
+	if production.Variables != nil {
+		t.Errorf("production should not inherit staging variables, got %v", production.Variables)
+	}
+
+	staging, ok := parser.asyncAPI.Servers["staging"]
+	if !ok {
+		t.Fatal("staging server missing")
+	}
+	if staging.Host != "staging.example.com:4222" {
+		t.Errorf("staging Host = %q, want %q", staging.Host, "staging.example.com:4222")
+	}
+	if staging.Variables["region"].Default != "eu" {
+		t.Errorf("staging region default = %q, want %q", staging.Variables["region"].Default, "eu")
+	}
+	if staging.ExternalDocs == nil || staging.ExternalDocs.URL != "https://docs.example.com/staging" {
+		t.Errorf("staging ExternalDocs = %v", staging.ExternalDocs)
+	}
+}
+
+func TestParseMainWithDefaultAndNamedServers(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@title Mixed API",
+		"@version 1.0.0",
+		"@protocol nats",
+		"@host localhost:4222",
+		"@server.name local",
+		"@server.dev.host dev.example.com:4222",
+		"@server.dev.protocol nats",
+	})
+
+	if _, ok := parser.asyncAPI.Servers["local"]; !ok {
+		t.Error("local server missing")
+	}
+	if _, ok := parser.asyncAPI.Servers["dev"]; !ok {
+		t.Error("dev server missing")
+	}
+}
+
+func TestParseMainNamedServerWithExtensionPrefix(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@title Edge API",
+		"@version 1.0.0",
+		"@protocol nats",
+		"@host localhost:4222",
+		"@server.x-region us-east",
+		"@server.x-edge.host edge.example.com:4222",
+		"@server.x-edge.protocol nats",
+	})
+
+	edge, ok := parser.asyncAPI.Servers["x-edge"]
+	if !ok {
+		t.Fatalf("x-edge server missing, servers = %v", parser.asyncAPI.Servers)
+	}
+	if edge.Host != "edge.example.com:4222" {
+		t.Errorf("x-edge Host = %q, want %q", edge.Host, "edge.example.com:4222")
+	}
+	for name, server := range parser.asyncAPI.Servers {
+		if name == "x-edge" {
+			continue
+		}
+		if got := server.Extensions["x-region"]; got != "us-east" {
+			t.Errorf("%s x-region = %v, want us-east", name, got)
+		}
+		if _, ok := server.Extensions["x-edge.host"]; ok {
+			t.Errorf("%s has the x-edge server fields as an extension", name)
+		}
+	}
+}
+
+func TestParseMainServerPathname(t *testing.T) {
+	tests := []struct {
+		name         string
+		comments     []string
+		wantHost     string
+		wantPathname string
+	}{
+		{
+			name:         "url path",
+			comments:     []string{"@server.ws.url wss://events.example.com/stream", "@server.ws.protocol wss"},
+			wantHost:     "events.example.com",
+			wantPathname: "/stream",
+		},
+		{
+			name:         "explicit pathname wins",
+			comments:     []string{"@server.ws.pathname /v2", "@server.ws.url wss://events.example.com/stream", "@server.ws.protocol wss"},
+			wantHost:     "events.example.com",
+			wantPathname: "/v2",
+		},
+		{
+			name:         "amqp vhost",
+			comments:     []string{"@server.rabbit.url amqp://rabbit.example.com:5672/production"},
+			wantHost:     "rabbit.example.com:5672",
+			wantPathname: "/production",
+		},
+		{
+			name:         "amqp encoded vhost",
+			comments:     []string{"@server.rabbit.host rabbit.example.com:5672/%2Fproduction", "@server.rabbit.protocol amqp"},
+			wantHost:     "rabbit.example.com:5672",
+			wantPathname: "/production",
+		},
+		{
+			name:         "amqp default vhost",
+			comments:     []string{"@server.rabbit.url amqp://rabbit.example.com:5672/"},
+			wantHost:     "rabbit.example.com:5672",
+			wantPathname: "",
+		},
+		{
+			name:         "vhost annotation",
+			comments:     []string{"@server.rabbit.host rabbit.example.com:5672", "@server.rabbit.protocol amqp", "@server.rabbit.vhost staging"},
+			wantHost:     "rabbit.example.com:5672",
+			wantPathname: "/staging",
+		},
+		{
+			name:         "default server",
+			comments:     []string{"@server.name rabbit", "@protocol amqp", "@host rabbit.example.com", "@server.vhost /orders"},
+			wantHost:     "rabbit.example.com",
+			wantPathname: "/orders",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser()
+			parser.ParseMain(append([]string{"@title API", "@version 1.0.0"}, tt.comments...))
+			var server spec3.Server
+			for _, s := range parser.asyncAPI.Servers {
+				server = s
+			}
+			if server.Host != tt.wantHost {
+				t.Errorf("Host = %q, want %q", server.Host, tt.wantHost)
+			}
+			if server.Pathname != tt.wantPathname {
+				t.Errorf("Pathname = %q, want %q", server.Pathname, tt.wantPathname)
+			}
+		})
+	}
+}
+
+func TestParseMainDefaultServerName(t *testing.T) {
+	nats := []string{"@title NATS Message Service", "@version 1.0.0", "@protocol nats", "@host localhost:4222"}
+	tests := []struct {
+		name     string
+		naming   string
+		comments []string
+		want     string
+	}{
+		{"explicit name", "", append([]string{"@server.name production"}, nats...), "production"},
+		{"environment", "", append([]string{"@server.environment Staging EU"}, nats...), "staging-eu"},
+		{"protocol and host", "", nats, "nats-452e2b13"},
+		{"title strategy", ServerNamingTitle, nats, "nats-message-service"},
+		{"title strategy without title", ServerNamingTitle, []string{"@protocol nats", "@host localhost:4222"}, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser()
+			parser.serverNaming = tt.naming
+			parser.ParseMain(tt.comments)
+			if _, ok := parser.asyncAPI.Servers[tt.want]; !ok || len(parser.asyncAPI.Servers) != 1 {
+				t.Errorf("Servers = %v, want single server %q", parser.asyncAPI.Servers, tt.want)
+			}
+		})
+	}
+
+	if err := validServerNaming("hostname"); err == nil {
+		t.Errorf("validServerNaming(hostname) error = nil, want error")
+	}
+}
+
+func TestIsGeneralAPICommentWithNamedServer(t *testing.T) {
+	if !isGeneralAPIComment([]string{"@server.production.host localhost:4222"}) {
+		t.Error("Named server annotations should be treated as general API comments")
+	}
+	if isGeneralAPIComment([]string{"@name user.created"}) {
+		t.Error("Operation annotations should not be treated as general API comments")
+	}
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -323,8 +544,8 @@ func TestDetermineActionAndName(t *testing.T) {
 	}{
 		{"publish operation", "pub", "userCreated", false, spec3.ActionSend, "publishUserCreated"},
 		{"subscribe operation", "sub", "userUpdated", false, spec3.ActionReceive, "subscribeUserUpdated"},
-		{"request-reply with response", "sub", "getUser", true, spec3.ActionSend, "requestGetUser"},
-		{"request-reply overrides pub", "pub", "getUser", true, spec3.ActionSend, "requestGetUser"},
+		{"replier with response", "sub", "getUser", true, spec3.ActionReceive, "replyGetUser"},
+		{"requester with response", "pub", "getUser", true, spec3.ActionSend, "requestGetUser"},
 		{"unknown defaults to subscribe", "unknown", "someChannel", false, spec3.ActionReceive, "subscribeSomeChannel"},
 	}
 
@@ -361,7 +582,7 @@ func TestCreateChannelParameters(t *testing.T) {
 		},
 	}
 
-	result := parser.createChannelParameters(params)
+	result := parser.createChannelParameters(&Operation{Parameters: params})
 
 	if len(result) != 2 {
 		t.Errorf("Expected 2 parameters, got %d", len(result))
@@ -376,6 +597,49 @@ func TestCreateChannelParameters(t *testing.T) {
 	}
 }
 
+func TestProcessOperationWithParameterMetadata(t *testing.T) {
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type sub",
+		"@name orders.{region}.{orderId}.placed",
+		"@parameter region enum=eu,us default=eu examples=eu description=Region of the store location=$message.header#/region",
+		"@parameter tenant description=Not in the address",
+	}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name orders.{region}.{orderId}.placed"}, nil)
+
+	params := parser.asyncAPI.Channels["ordersRegionOrderIdPlaced"].Parameters
+	wantRegion := spec3.Parameter{
+		Description: "Region of the store",
+		Default:     "eu",
+		Enum:        []string{"eu", "us"},
+		Examples:    []string{"eu"},
+		Location:    "$message.header#/region",
+	}
+	if !reflect.DeepEqual(params["region"], wantRegion) {
+		t.Errorf("region = %+v, want %+v", params["region"], wantRegion)
+	}
+	if want := (spec3.Parameter{Description: "orderId"}); !reflect.DeepEqual(params["orderId"], want) {
+		t.Errorf("orderId = %+v, want %+v", params["orderId"], want)
+	}
+	if _, ok := params["tenant"]; ok {
+		t.Error("tenant parameter added, want it ignored")
+	}
+	if got := parser.warnings.list(); len(got) != 1 {
+		t.Errorf("warnings = %v, want 1 for tenant", got)
+	}
+
+	for _, comment := range []string{
+		"@parameter",
+		"@parameter region enum=eu,us default=asia",
+		"@parameter region location=header.region",
+		"@parameter region format=uuid",
+	} {
+		if err := NewOperation().ParseComment(comment, nil); err == nil {
+			t.Errorf("ParseComment(%q) error = nil, want error", comment)
+		}
+	}
+}
+
 func TestCreateMessage(t *testing.T) {
 	parser := NewParser()
 
@@ -423,6 +687,30 @@ func TestCreateMessage(t *testing.T) {
 	}
 }
 
+func TestCreateMessageWithExamples(t *testing.T) {
+	parser := NewParser()
+	operation := NewOperation()
+	operation.Message.MessageSample = Msg{Data: struct {
+		UserID string `json:"userId"`
+	}{}}
+	if err := operation.ParseMessageExample(`created {"payload": {"userId": "u-1"}, "headers": {"trace-id": "t-1"}}`); err != nil {
+		t.Fatalf("ParseMessageExample() error = %v", err)
+	}
+
+	parser.createMessage("userCreatedMessage", operation.Message, operation)
+
+	msg := parser.asyncAPI.Components.Messages["userCreatedMessage"]
+	if len(msg.Examples) != 1 {
+		t.Fatalf("Examples = %v, want 1 example", msg.Examples)
+	}
+	if msg.Examples[0].Name != "created" {
+		t.Errorf("Example name = %q, want %q", msg.Examples[0].Name, "created")
+	}
+	if msg.Examples[0].Headers["trace-id"] != "t-1" {
+		t.Errorf("Example headers = %v", msg.Examples[0].Headers)
+	}
+}
+
 func TestCreateChannel(t *testing.T) {
 	parser := NewParser()
 
@@ -432,10 +720,11 @@ func TestCreateChannel(t *testing.T) {
 
 	operation := &Operation{
 		ChannelTitle:       "User Created Channel",
+		ChannelSummary:     "User creation",
 		ChannelDescription: "Channel for user creation events",
 	}
 
-	parser.createChannel("userCreated", "user.created", "userCreatedMessage", params, operation)
+	parser.createChannel("userCreated", "user.created", "userCreatedMessage", componentMessageRef("userCreatedMessage"), params, operation)
 
 	channel, exists := parser.asyncAPI.Channels["userCreated"]
 	if !exists {
@@ -458,6 +747,10 @@ func TestCreateChannel(t *testing.T) {
 		t.Errorf("Title = %q, want %q", channel.Title, "User Created Channel")
 	}
 
+	if channel.Summary != "User creation" {
+		t.Errorf("Summary = %q, want %q", channel.Summary, "User creation")
+	}
+
 	if channel.Description != "Channel for user creation events" {
 		t.Errorf("Description = %q, want %q", channel.Description, "Channel for user creation events")
 	}
@@ -523,3 +816,1279 @@ func TestCreateOperation(t *testing.T) {
 		t.Errorf("Expected 1 message reference, got %d", len(op.Messages))
 	}
 }
+
+func TestCreateMessageRegistersHeadersSchema(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderHeaders struct {
+	TraceID  string ` + "`json:\"trace-id\"`" + `
+	TenantID string ` + "`json:\"tenant-id,omitempty\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	operation := NewOperation()
+	if err := operation.ParseComment("@message.headers OrderHeaders", tc); err != nil {
+		t.Fatalf("ParseComment() error = %v", err)
+	}
+
+	parser := NewParser()
+	parser.createMessage("orderCreatedMessage", operation.Message, operation)
+
+	msg := parser.asyncAPI.Components.Messages["orderCreatedMessage"]
+	headersRef, ok := msg.Headers.(map[string]interface{})
+	if !ok || headersRef["$ref"] != "#/components/schemas/OrderHeaders" {
+		t.Fatalf("Headers = %v, want $ref to OrderHeaders", msg.Headers)
+	}
+
+	schema, ok := parser.asyncAPI.Components.Schemas["OrderHeaders"].(map[string]interface{})
+	if !ok {
+		t.Fatal("OrderHeaders schema was not registered")
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Properties is not a map")
+	}
+	for _, name := range []string{"trace-id", "tenant-id"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("header %q missing from schema: %v", name, properties)
+		}
+	}
+}
+
+func TestProcessOperationWithMultiplePayloads(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderCreated struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type OrderShipped struct {
+	ID      string ` + "`json:\"id\"`" + `
+	Carrier string ` + "`json:\"carrier\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type sub",
+		"@name order.events",
+		"@payload OrderCreated",
+		"@payload.alt OrderShipped",
+	}, tc)
+
+	channel, ok := parser.asyncAPI.Channels["orderEvents"]
+	if !ok {
+		t.Fatal("orderEvents channel missing")
+	}
+	if len(channel.Messages) != 2 {
+		t.Fatalf("channel Messages = %v, want 2 messages", channel.Messages)
+	}
+	if _, ok := channel.Messages["orderEventsOrderShippedMessage"]; !ok {
+		t.Errorf("channel Messages = %v, want orderEventsOrderShippedMessage", channel.Messages)
+	}
+
+	op := parser.asyncAPI.Operations["subscribeOrderEvents"]
+	if len(op.Messages) != 2 {
+		t.Errorf("operation Messages = %v, want 2 references", op.Messages)
+	}
+
+	if _, ok := parser.asyncAPI.Components.Schemas["OrderShipped"]; !ok {
+		t.Error("payload schema for the alternative message was not registered")
+	}
+}
+
+func TestProcessOperationWithPayloadSubtypes(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderEvent struct {
+	Type    string ` + "`json:\"type\"`" + `
+	OrderID string ` + "`json:\"orderId\"`" + `
+}
+
+type UserEvent struct {
+	Type   string ` + "`json:\"type\"`" + `
+	UserID string ` + "`json:\"userId\"`" + `
+}
+
+type AuditEvent struct {
+	Actor string ` + "`json:\"actor\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type sub",
+		"@name events",
+		"@payload.discriminator type",
+		"@payload.subtype order:OrderEvent user:UserEvent",
+		"@payload.subtype audit:AuditEvent",
+	}, tc)
+
+	message := parser.asyncAPI.Components.Messages["eventsMessage"]
+	if want := map[string]interface{}{"$ref": "#/components/schemas/eventsMessagePayload"}; !reflect.DeepEqual(message.Payload, want) {
+		t.Fatalf("Payload = %v, want %v", message.Payload, want)
+	}
+	payload, _ := parser.asyncAPI.Components.Schemas["eventsMessagePayload"].(map[string]interface{})
+	if payload["discriminator"] != "type" {
+		t.Errorf("discriminator = %v, want type", payload["discriminator"])
+	}
+	subtypeOf := func(name, value string) interface{} {
+		return map[string]interface{}{
+			"allOf": []interface{}{
+				map[string]interface{}{"$ref": "#/components/schemas/" + name},
+				map[string]interface{}{
+					"properties": map[string]interface{}{"type": map[string]interface{}{"const": value}},
+				},
+			},
+		}
+	}
+	wantOneOf := []interface{}{
+		subtypeOf("OrderEvent", "order"),
+		subtypeOf("UserEvent", "user"),
+		map[string]interface{}{"$ref": "#/components/schemas/AuditEvent"},
+	}
+	if !reflect.DeepEqual(payload["oneOf"], wantOneOf) {
+		t.Errorf("oneOf = %v, want %v", payload["oneOf"], wantOneOf)
+	}
+
+	// The subtype schemas stay those of their types, shared with payloads
+	parser.ParseOperation([]string{"@type pub", "@name orders", "@payload OrderEvent"}, tc)
+	for _, name := range []string{"OrderEvent", "UserEvent"} {
+		schema, _ := parser.asyncAPI.Components.Schemas[name].(map[string]interface{})
+		properties, _ := schema["properties"].(map[string]interface{})
+		if want := map[string]interface{}{"type": "string"}; !reflect.DeepEqual(properties["type"], want) {
+			t.Errorf("%s type = %v, want %v", name, properties["type"], want)
+		}
+	}
+	if _, ok := parser.asyncAPI.Components.Schemas["OrderEvent2"]; ok {
+		t.Error("OrderEvent payload registered a second schema, want the subtype schema reused")
+	}
+	if want := map[string]interface{}{"$ref": "#/components/schemas/OrderEvent"}; !reflect.DeepEqual(parser.asyncAPI.Components.Messages["ordersMessage"].Payload, want) {
+		t.Errorf("orders Payload = %v, want %v", parser.asyncAPI.Components.Messages["ordersMessage"].Payload, want)
+	}
+	// AuditEvent has no type property to hold its value.
+	if got := parser.warnings.list(); len(got) != 1 {
+		t.Errorf("warnings = %v, want 1", got)
+	}
+
+	op := NewOperation()
+	for _, value := range []string{"order", "order:", ":OrderEvent"} {
+		if err := op.ParsePayloadSubtypes(value, tc); err == nil {
+			t.Errorf("ParsePayloadSubtypes(%q) error = nil, want error", value)
+		}
+	}
+	if err := op.ParsePayloadSubtypes("order:OrderEvent, order:UserEvent", tc); err == nil {
+		t.Error("ParsePayloadSubtypes() with a repeated value error = nil, want error")
+	}
+}
+
+func TestAltMessageName(t *testing.T) {
+	tests := []struct {
+		typeName string
+		want     string
+	}{
+		{"OrderShipped", "orderEventsOrderShippedMessage"},
+		{"events.OrderShipped", "orderEventsOrderShippedMessage"},
+		{"[]orderShipped", "orderEventsOrderShippedMessage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			if got := naming(NamingCamelCase).altMessageName("orderEvents", tt.typeName); got != tt.want {
+				t.Errorf("altMessageName(%q) = %q, want %q", tt.typeName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessOperationMergesSharedAddress(t *testing.T) {
+	src := `
+package testpkg
+
+type UserCreated struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type UserAudit struct {
+	Actor string ` + "`json:\"actor\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name user.created", "@payload UserCreated"}, tc)
+	parser.ParseOperation([]string{"@type sub", "@name user.created", "@payload UserCreated"}, tc)
+	parser.ParseOperation([]string{"@type sub", "@name user.created", "@payload UserAudit"}, tc)
+
+	channel := parser.asyncAPI.Channels["userCreated"]
+	if len(channel.Messages) != 2 {
+		t.Fatalf("channel Messages = %v, want shared and UserAudit messages", channel.Messages)
+	}
+	if _, ok := channel.Messages["userCreatedMessage"]; !ok {
+		t.Error("shared userCreatedMessage missing from channel")
+	}
+	if _, ok := channel.Messages["userCreatedUserAuditMessage"]; !ok {
+		t.Error("userCreatedUserAuditMessage missing from channel")
+	}
+
+	wantOps := map[string]string{
+		"publishUserCreated":    "#/channels/userCreated/messages/userCreatedMessage",
+		"subscribeUserCreated":  "#/channels/userCreated/messages/userCreatedMessage",
+		"subscribeUserCreated2": "#/channels/userCreated/messages/userCreatedUserAuditMessage",
+	}
+	if len(parser.asyncAPI.Operations) != len(wantOps) {
+		t.Fatalf("Operations = %v, want %d operations", parser.asyncAPI.Operations, len(wantOps))
+	}
+	for name, wantRef := range wantOps {
+		op, ok := parser.asyncAPI.Operations[name]
+		if !ok {
+			t.Errorf("operation %s missing", name)
+			continue
+		}
+		if op.Channel.Ref != "#/channels/userCreated" {
+			t.Errorf("%s channel = %q, want %q", name, op.Channel.Ref, "#/channels/userCreated")
+		}
+		if len(op.Messages) != 1 || op.Messages[0].Ref != wantRef {
+			t.Errorf("%s messages = %v, want %s", name, op.Messages, wantRef)
+		}
+	}
+}
+
+func TestProcessOperationChannelKeyCollisions(t *testing.T) {
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name user.created"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name user-created"}, nil)
+	parser.ParseOperation([]string{"@type sub", "@name user-created"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name user_created", "@channel.key userCreatedLegacy"}, nil)
+
+	wantAddresses := map[string]string{
+		"userCreated":       "user.created",
+		"userCreated2":      "user-created",
+		"userCreatedLegacy": "user_created",
+	}
+	if len(parser.asyncAPI.Channels) != len(wantAddresses) {
+		t.Fatalf("Channels = %v, want %d channels", parser.asyncAPI.Channels, len(wantAddresses))
+	}
+	for key, address := range wantAddresses {
+		if got := parser.asyncAPI.Channels[key].Address; got != address {
+			t.Errorf("channel %s address = %q, want %q", key, got, address)
+		}
+	}
+
+	for name, channel := range map[string]string{
+		"publishUserCreated":       "#/channels/userCreated",
+		"publishUserCreated2":      "#/channels/userCreated2",
+		"subscribeUserCreated2":    "#/channels/userCreated2",
+		"publishUserCreatedLegacy": "#/channels/userCreatedLegacy",
+	} {
+		op, ok := parser.asyncAPI.Operations[name]
+		if !ok {
+			t.Errorf("operation %s missing", name)
+			continue
+		}
+		if op.Channel.Ref != channel {
+			t.Errorf("%s channel = %q, want %q", name, op.Channel.Ref, channel)
+		}
+	}
+}
+
+func TestProcessOperationCustomIdentifiers(t *testing.T) {
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name user.created", "@operation.id emitUser", "@message.name UserCreated"}, nil)
+	parser.ParseOperation([]string{"@type sub", "@name user.created", "@operation.id emitUser"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name audit.user", "@message.name UserCreated"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name audit.order", "@message.name UserCreated", "@message.title Order"}, nil)
+
+	for name, channel := range map[string]string{
+		"emitUser":          "#/channels/userCreated",
+		"emitUser2":         "#/channels/userCreated",
+		"publishAuditUser":  "#/channels/auditUser",
+		"publishAuditOrder": "#/channels/auditOrder",
+	} {
+		op, ok := parser.asyncAPI.Operations[name]
+		if !ok {
+			t.Errorf("operation %s missing", name)
+			continue
+		}
+		if op.Channel.Ref != channel {
+			t.Errorf("%s channel = %q, want %q", name, op.Channel.Ref, channel)
+		}
+	}
+
+	for channel, message := range map[string]string{
+		"userCreated": "UserCreated",
+		"auditUser":   "UserCreated",
+		"auditOrder":  "UserCreated2",
+	} {
+		ref, ok := parser.asyncAPI.Channels[channel].Messages[message]
+		if !ok || ref.Ref != "#/components/messages/"+message {
+			t.Errorf("channel %s messages = %v, want %s", channel, parser.asyncAPI.Channels[channel].Messages, message)
+		}
+	}
+	if got := parser.asyncAPI.Components.Messages["UserCreated"].Name; got != "UserCreated" {
+		t.Errorf("UserCreated name = %q, want %q", got, "UserCreated")
+	}
+
+	for _, comment := range []string{"@operation.id emit user", "@message.name user/created"} {
+		if err := NewOperation().ParseComment(comment, nil); err == nil {
+			t.Errorf("ParseComment(%q) error = nil, want error", comment)
+		}
+	}
+}
+
+func TestProcessOperationSummaryAndDescription(t *testing.T) {
+	tests := []struct {
+		name                    string
+		comments                []string
+		wantOpSummary, wantOp   string
+		wantMsgSummary, wantMsg string
+	}{
+		{
+			name:           "shared",
+			comments:       []string{"@summary Get user", "@description Looks up a user"},
+			wantOpSummary:  "Get user",
+			wantOp:         "Looks up a user",
+			wantMsgSummary: "Get user",
+			wantMsg:        "Looks up a user",
+		},
+		{
+			name: "separate",
+			comments: []string{
+				"@operation.summary Request user details", "@operation.description Asks the user service for a user",
+				"@message.summary User lookup request payload", "@message.description Identifies the user to look up",
+			},
+			wantOpSummary:  "Request user details",
+			wantOp:         "Asks the user service for a user",
+			wantMsgSummary: "User lookup request payload",
+			wantMsg:        "Identifies the user to look up",
+		},
+		{
+			name:           "overrides",
+			comments:       []string{"@summary Get user", "@description Looks up a user", "@message.summary User lookup request payload", "@operation.description Asks for a user"},
+			wantOpSummary:  "Get user",
+			wantOp:         "Asks for a user",
+			wantMsgSummary: "User lookup request payload",
+			wantMsg:        "Looks up a user",
+		},
+		{
+			name:           "message only",
+			comments:       []string{"@message.summary User lookup request payload", "@message.description Identifies the user"},
+			wantMsgSummary: "User lookup request payload",
+			wantMsg:        "Identifies the user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser()
+			parser.ParseOperation(append([]string{"@type pub", "@name user.get"}, tt.comments...), nil)
+
+			op := parser.asyncAPI.Operations["publishUserGet"]
+			if op.Summary != tt.wantOpSummary || op.Description != tt.wantOp {
+				t.Errorf("operation summary, description = %q, %q, want %q, %q", op.Summary, op.Description, tt.wantOpSummary, tt.wantOp)
+			}
+			msg := parser.asyncAPI.Components.Messages["userGetMessage"]
+			if msg.Summary != tt.wantMsgSummary || msg.Description != tt.wantMsg {
+				t.Errorf("message summary, description = %q, %q, want %q, %q", msg.Summary, msg.Description, tt.wantMsgSummary, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestProcessOperationChannelTagsAndExternalDocs(t *testing.T) {
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type pub", "@name user.created",
+		"@channel.tag users", "@channel.tag lifecycle",
+		"@channel.externalDocs.description User events guide",
+		"@channel.externalDocs.url https://docs.example.com/users",
+	}, nil)
+	parser.ParseOperation([]string{"@type sub", "@name user.created", "@channel.tag users", "@channel.tag audit"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name user.deleted", "@channel.externalDocs.description No URL"}, nil)
+
+	channel := parser.asyncAPI.Channels["userCreated"]
+	want := []spec3.Tag{{Name: "users"}, {Name: "lifecycle"}, {Name: "audit"}}
+	if !reflect.DeepEqual(channel.Tags, want) {
+		t.Errorf("channel Tags = %v, want %v", channel.Tags, want)
+	}
+	wantDocs := &spec3.ExternalDocs{Description: "User events guide", URL: "https://docs.example.com/users"}
+	if !reflect.DeepEqual(channel.ExternalDocs, wantDocs) {
+		t.Errorf("channel ExternalDocs = %v, want %v", channel.ExternalDocs, wantDocs)
+	}
+	if docs := parser.asyncAPI.Channels["userDeleted"].ExternalDocs; docs != nil {
+		t.Errorf("channel without URL ExternalDocs = %v, want nil", docs)
+	}
+}
+
+func TestProcessOperationChannelServers(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@server.production.host nats://nats.example.com:4222",
+		"@server.production.protocol nats",
+		"@server.internal.host nats://nats.internal:4222",
+		"@server.internal.protocol nats",
+	})
+	parser.ParseOperation([]string{"@type pub", "@name audit.user", "@channel.server internal"}, nil)
+	parser.ParseOperation([]string{"@type sub", "@name audit.user", "@channel.server internal, staging"}, nil)
+	parser.ParseOperation([]string{"@type pub", "@name user.created"}, nil)
+
+	want := []spec3.Reference{{Ref: "#/servers/internal"}, {Ref: "#/servers/staging"}}
+	if got := parser.asyncAPI.Channels["auditUser"].Servers; !reflect.DeepEqual(got, want) {
+		t.Errorf("auditUser Servers = %v, want %v", got, want)
+	}
+	if got := parser.asyncAPI.Channels["userCreated"].Servers; got != nil {
+		t.Errorf("userCreated Servers = %v, want nil (all servers)", got)
+	}
+	if got := parser.undefinedChannelServers(); !reflect.DeepEqual(got, []string{"staging"}) {
+		t.Errorf("undefinedChannelServers() = %v, want [staging]", got)
+	}
+}
+
+func TestParseExtensions(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@title Orders",
+		"@x-team payments",
+		"@host nats://localhost:4222",
+		"@protocol nats",
+		"@server.x-region eu-west-1",
+		"@server.backup.host nats://backup:4222",
+		"@server.backup.protocol nats",
+		"@server.backup.x-Tier 2",
+	})
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name order.placed",
+		"@x-event-owner orders@example.com",
+		`@channel.x-slo {"latency": "100ms", "availability": 99.9}`,
+		"@message.x-pii false",
+		"@x-producers billing",
+	}, nil)
+
+	if got := parser.asyncAPI.Info.Extensions["x-team"]; got != "payments" {
+		t.Errorf("info x-team = %v, want payments", got)
+	}
+	for name, server := range parser.asyncAPI.Servers {
+		var want map[string]interface{}
+		if name == "backup" {
+			want = map[string]interface{}{"x-Tier": float64(2)}
+		} else {
+			want = map[string]interface{}{"x-region": "eu-west-1"}
+		}
+		if !reflect.DeepEqual(server.Extensions, want) {
+			t.Errorf("server %s Extensions = %v, want %v", name, server.Extensions, want)
+		}
+	}
+
+	op := parser.asyncAPI.Operations["publishOrderPlaced"]
+	if want := map[string]interface{}{"x-event-owner": "orders@example.com"}; !reflect.DeepEqual(op.Extensions, want) {
+		t.Errorf("operation Extensions = %v, want %v", op.Extensions, want)
+	}
+	slo := map[string]interface{}{"latency": "100ms", "availability": 99.9}
+	if got := parser.asyncAPI.Channels["orderPlaced"].Extensions["x-slo"]; !reflect.DeepEqual(got, slo) {
+		t.Errorf("channel x-slo = %v, want %v", got, slo)
+	}
+	for name, message := range parser.asyncAPI.Components.Messages {
+		if got := message.Extensions["x-pii"]; got != false {
+			t.Errorf("message %s x-pii = %v, want false", name, got)
+		}
+	}
+	if n := len(parser.warnings.list()); n != 1 {
+		t.Errorf("warnings = %d, want 1 for the generated x-producers", n)
+	}
+}
+
+func TestProcessOperationWithResponseErrors(t *testing.T) {
+	src := `
+package testpkg
+
+type GetUserRequest struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type GetUserResponse struct {
+	Email string ` + "`json:\"email\"`" + `
+}
+
+type ErrorPayload struct {
+	Code    int    ` + "`json:\"code\"`" + `
+	Message string ` + "`json:\"message\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name user.get",
+		"@payload GetUserRequest",
+		"@response GetUserResponse",
+		"@response.error ErrorPayload user does not exist",
+	}, tc)
+
+	op := parser.asyncAPI.Operations["requestUserGet"]
+	if op.Reply == nil {
+		t.Fatal("Reply should be configured")
+	}
+	if len(op.Reply.Messages) != 2 {
+		t.Fatalf("reply Messages = %v, want response and error", op.Reply.Messages)
+	}
+	if op.Reply.Messages[1].Ref != "#/channels/userGetReply/messages/userGetReplyErrorPayloadMessage" {
+		t.Errorf("error reply ref = %q", op.Reply.Messages[1].Ref)
+	}
+
+	if _, ok := parser.asyncAPI.Channels["userGetReply"].Messages["userGetReplyErrorPayloadMessage"]; !ok {
+		t.Error("error message missing from reply channel")
+	}
+
+	msg := parser.asyncAPI.Components.Messages["userGetReplyErrorPayloadMessage"]
+	if msg.Error == nil {
+		t.Fatal("error message should carry x-error metadata")
+	}
+	if msg.Error.Type != "ErrorPayload" || msg.Error.Condition != "user does not exist" {
+		t.Errorf("x-error = %+v, want type ErrorPayload with condition", msg.Error)
+	}
+	if reply := parser.asyncAPI.Components.Messages["userGetReplyMessage"]; reply.Error != nil {
+		t.Errorf("happy-path reply should not be marked as error, got %+v", reply.Error)
+	}
+}
+
+func TestProcessOperationWithResponseAddress(t *testing.T) {
+	src := `
+package testpkg
+
+type GetUserRequest struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type GetUserResponse struct {
+	Email string ` + "`json:\"email\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name user.{id}.get",
+		"@param id string User id",
+		"@payload GetUserRequest",
+		"@response GetUserResponse",
+		"@response.address $message.header#/replyTo - Inbox of the requester",
+	}, tc)
+
+	op := parser.asyncAPI.Operations["requestUserIdGet"]
+	if op.Reply == nil {
+		t.Fatal("Reply should be configured")
+	}
+	want := &spec3.OperationReplyAddress{Location: "$message.header#/replyTo", Description: "Inbox of the requester"}
+	if !reflect.DeepEqual(op.Reply.Address, want) {
+		t.Errorf("reply address = %+v, want %+v", op.Reply.Address, want)
+	}
+	channel := parser.asyncAPI.Channels["userIdGetReply"]
+	if channel.Address != "" || channel.Parameters != nil {
+		t.Errorf("reply channel = %+v, want no address or parameters", channel)
+	}
+	if _, ok := channel.Messages["userIdGetReplyMessage"]; !ok {
+		t.Errorf("reply channel messages = %v, want userIdGetReplyMessage", channel.Messages)
+	}
+
+	for _, value := range []string{"replyTo", "$message.body#/replyTo"} {
+		if err := NewOperation().ParseResponseAddress(value); err == nil {
+			t.Errorf("ParseResponseAddress(%q) error = nil, want error", value)
+		}
+	}
+}
+
+func TestProcessOperationRequesterAndReplier(t *testing.T) {
+	src := `
+package testpkg
+
+type GetUserRequest struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type GetUserResponse struct {
+	Email string ` + "`json:\"email\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.service = "gateway"
+	parser.ParseOperation([]string{"@type pub", "@name user.get", "@payload GetUserRequest", "@response GetUserResponse"}, tc)
+	parser.service = "users"
+	parser.ParseOperation([]string{"@type sub", "@name user.get", "@payload GetUserRequest", "@response GetUserResponse"}, tc)
+	parser.applyServiceRoles()
+
+	for name, action := range map[string]spec3.OperationAction{
+		"requestUserGet": spec3.ActionSend,
+		"replyUserGet":   spec3.ActionReceive,
+	} {
+		op, ok := parser.asyncAPI.Operations[name]
+		if !ok {
+			t.Errorf("operation %s missing, have %v", name, parser.asyncAPI.Operations)
+			continue
+		}
+		if op.Action != action {
+			t.Errorf("%s action = %s, want %s", name, op.Action, action)
+		}
+		if op.Reply == nil || op.Reply.Channel == nil || op.Reply.Channel.Ref != "#/channels/userGetReply" {
+			t.Errorf("%s reply = %+v, want the userGetReply channel", name, op.Reply)
+		}
+	}
+	if len(parser.asyncAPI.Operations) != 2 {
+		t.Errorf("operations = %v, want requestUserGet and replyUserGet", parser.asyncAPI.Operations)
+	}
+
+	reply := parser.asyncAPI.Channels["userGetReply"]
+	if !reflect.DeepEqual(reply.Producers, []string{"users"}) || !reflect.DeepEqual(reply.Consumers, []string{"gateway"}) {
+		t.Errorf("reply channel producers = %v, consumers = %v, want users and gateway", reply.Producers, reply.Consumers)
+	}
+}
+
+func TestProcessOperationDeduplicatesByGoType(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderPlacedEvent struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name order.placed", "@summary Order placed", "@payload OrderPlacedEvent"}, tc)
+	parser.ParseOperation([]string{"@type sub", "@name order.audit", "@summary Order audit", "@payload OrderPlacedEvent"}, tc)
+	parser.ParseOperation([]string{"@type sub", "@name order.archive", "@message.contentType application/avro", "@payload OrderPlacedEvent"}, tc)
+
+	if len(parser.asyncAPI.Components.Schemas) != 1 {
+		t.Errorf("Schemas = %v, want only OrderPlacedEvent", parser.asyncAPI.Components.Schemas)
+	}
+	if _, ok := parser.asyncAPI.Components.Schemas["OrderPlacedEvent"]; !ok {
+		t.Error("schema should be keyed by the Go type name")
+	}
+
+	if len(parser.asyncAPI.Components.Messages) != 2 {
+		t.Fatalf("Messages = %v, want shared message plus the one with a different content type", parser.asyncAPI.Components.Messages)
+	}
+	audit := parser.asyncAPI.Channels["orderAudit"]
+	if ref := audit.Messages["orderPlacedMessage"].Ref; ref != "#/components/messages/orderPlacedMessage" {
+		t.Errorf("orderAudit message ref = %q, want shared orderPlacedMessage", ref)
+	}
+	op := parser.asyncAPI.Operations["subscribeOrderAudit"]
+	if len(op.Messages) != 1 || op.Messages[0].Ref != "#/channels/orderAudit/messages/orderPlacedMessage" {
+		t.Errorf("subscribeOrderAudit messages = %v", op.Messages)
+	}
+	if _, ok := parser.asyncAPI.Components.Messages["orderArchiveMessage"]; !ok {
+		t.Error("message with different metadata should not be shared")
+	}
+
+	if summary := parser.asyncAPI.Components.Messages["orderPlacedMessage"].Summary; summary != "" {
+		t.Errorf("shared message Summary = %q, want none as the operations disagree", summary)
+	}
+	if summary := parser.asyncAPI.Operations["subscribeOrderAudit"].Summary; summary != "Order audit" {
+		t.Errorf("subscribeOrderAudit Summary = %q, want Order audit", summary)
+	}
+	warnings := parser.warnings.list()
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "message orderPlacedMessage is shared by operations with different summary texts") {
+		t.Errorf("warnings = %+v, want the summary conflict of orderPlacedMessage", warnings)
+	}
+	parser.ParseOperation([]string{"@type sub", "@name order.replay", "@summary Order placed", "@payload OrderPlacedEvent"}, tc)
+	if summary := parser.asyncAPI.Components.Messages["orderPlacedMessage"].Summary; summary != "" {
+		t.Errorf("shared message Summary = %q after a third operation, want none", summary)
+	}
+}
+
+func TestProcessOperationMergesSharedMessageText(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderPlacedEvent struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name order.placed", "@payload OrderPlacedEvent"}, tc)
+	parser.ParseOperation([]string{"@type sub", "@name order.audit", "@summary Order audit", "@payload OrderPlacedEvent",
+		"@message.summary Order placed", "@message.description Sent once per order"}, tc)
+
+	message := parser.asyncAPI.Components.Messages["orderPlacedMessage"]
+	if message.Summary != "Order placed" || message.Description != "Sent once per order" {
+		t.Errorf("shared message = %q, %q, want the texts of the operation that sets them", message.Summary, message.Description)
+	}
+	if warnings := parser.warnings.list(); len(warnings) != 0 {
+		t.Errorf("warnings = %+v, want none", warnings)
+	}
+}
+
+func TestSchemaNameForType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"OrderPlaced", "OrderPlaced"},
+		{"events.OrderPlaced", "OrderPlaced"},
+		{"[]Order", "OrderList"},
+		{"*Order", "Order"},
+		{"string", "String"},
+		{"map[string]Order", "OrderMap"},
+		{"Page[events.Order]", "PageOrder"},
+		{"[]Pair[string,[]Order]", "PairStringOrderListList"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := schemaNameForType(tt.input); got != tt.want {
+				t.Errorf("schemaNameForType(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinalizeQoS(t *testing.T) {
+	tests := []struct {
+		protocol    string
+		wantBinding bool
+	}{
+		{"mqtt", true},
+		{"nats", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			parser := NewParser()
+			parser.ParseMain([]string{
+				"@title Telemetry API",
+				"@version 1.0.0",
+				"@host broker.example.com:1883",
+				"@protocol " + tt.protocol,
+			})
+			parser.ParseOperation([]string{
+				"@type pub",
+				"@name sensors.temperature",
+				"@operation.timeout 5s",
+				"@operation.qos 1",
+			}, nil)
+			parser.Finalize()
+
+			op := parser.asyncAPI.Operations["publishSensorsTemperature"]
+			if op.Timeout != "5s" {
+				t.Errorf("Timeout = %q, want %q", op.Timeout, "5s")
+			}
+
+			mqtt, hasBinding := op.Bindings["mqtt"].(map[string]interface{})
+			if hasBinding != tt.wantBinding {
+				t.Fatalf("mqtt binding present = %v, want %v", hasBinding, tt.wantBinding)
+			}
+			if tt.wantBinding {
+				if mqtt["qos"] != 1 {
+					t.Errorf("mqtt qos = %v, want 1", mqtt["qos"])
+				}
+				if op.QoS != nil {
+					t.Errorf("x-qos should be dropped when the MQTT binding is used, got %d", *op.QoS)
+				}
+			} else if op.QoS == nil || *op.QoS != 1 {
+				t.Errorf("x-qos = %v, want 1", op.QoS)
+			}
+		})
+	}
+}
+
+func TestParseOperationRecursiveTypes(t *testing.T) {
+	src := `
+package testpkg
+
+type Category struct {
+	Name     string     ` + "`json:\"name\"`" + `
+	Parent   *Category  ` + "`json:\"parent,omitempty\"`" + `
+	Children []Category ` + "`json:\"children\"`" + `
+}
+
+type Tree struct {
+	Root Node ` + "`json:\"root\"`" + `
+}
+
+type Node struct {
+	Label    string          ` + "`json:\"label\"`" + `
+	Children map[string]Node ` + "`json:\"children\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name category.updated", "@payload Category"}, tc)
+	parser.ParseOperation([]string{"@type pub", "@name tree.updated", "@payload Tree"}, tc)
+
+	schemas := parser.asyncAPI.Components.Schemas
+	category, ok := schemas["Category"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("schemas = %v, want Category", schemas)
+	}
+	properties := category["properties"].(map[string]interface{})
+	categoryRef := map[string]interface{}{"$ref": "#/components/schemas/Category"}
+	if want := nullableSchema(categoryRef); !reflect.DeepEqual(properties["parent"], want) {
+		t.Errorf("parent = %v, want %v", properties["parent"], want)
+	}
+	children := properties["children"].(map[string]interface{})
+	if !reflect.DeepEqual(children["items"], categoryRef) {
+		t.Errorf("children items = %v, want %v", children["items"], categoryRef)
+	}
+
+	// Node is only reachable through Tree, so it is hoisted into components.
+	node, ok := schemas["Node"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("schemas = %v, want hoisted Node", schemas)
+	}
+	nodeChildren := node["properties"].(map[string]interface{})["children"].(map[string]interface{})
+	nodeRef := map[string]interface{}{"$ref": "#/components/schemas/Node"}
+	if !reflect.DeepEqual(nodeChildren["additionalProperties"], nodeRef) {
+		t.Errorf("Node children values = %v, want %v", nodeChildren["additionalProperties"], nodeRef)
+	}
+	root := schemas["Tree"].(map[string]interface{})["properties"].(map[string]interface{})["root"].(map[string]interface{})
+	if _, ok := root["properties"]; !ok {
+		t.Errorf("Tree root = %v, want inlined Node", root)
+	}
+}
+
+func TestParseOperationRefNested(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderPlaced struct {
+	OrderID  string      ` + "`json:\"orderId\"`" + `
+	Items    []OrderItem ` + "`json:\"items\"`" + `
+	Shipping *Address    ` + "`json:\"shipping,omitempty\"`" + `
+}
+
+type OrderItem struct {
+	SKU     string  ` + "`json:\"sku\"`" + `
+	Address Address ` + "`json:\"address\"`" + `
+}
+
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+	tc.refNested = true
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name order.placed", "@payload OrderPlaced"}, tc)
+
+	schemas := parser.asyncAPI.Components.Schemas
+	for _, name := range []string{"OrderPlaced", "OrderItem", "Address"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("schemas = %v, want %s", schemas, name)
+		}
+	}
+
+	properties := schemas["OrderPlaced"].(map[string]interface{})["properties"].(map[string]interface{})
+	items := properties["items"].(map[string]interface{})
+	if want := map[string]interface{}{"$ref": "#/components/schemas/OrderItem"}; !reflect.DeepEqual(items["items"], want) {
+		t.Errorf("items = %v, want %v", items["items"], want)
+	}
+	if want := nullableSchema(map[string]interface{}{"$ref": "#/components/schemas/Address"}); !reflect.DeepEqual(properties["shipping"], want) {
+		t.Errorf("shipping = %v, want %v", properties["shipping"], want)
+	}
+	itemProperties := schemas["OrderItem"].(map[string]interface{})["properties"].(map[string]interface{})
+	if want := map[string]interface{}{"$ref": "#/components/schemas/Address"}; !reflect.DeepEqual(itemProperties["address"], want) {
+		t.Errorf("OrderItem address = %v, want %v", itemProperties["address"], want)
+	}
+}
+
+func TestParseOperationMessageRef(t *testing.T) {
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name user.created",
+		"@message.ref https://catalog.example.com/messages/UserCreated.yaml",
+	}, nil)
+	parser.ParseOperation([]string{
+		"@type sub",
+		"@name user.created",
+		"@message.ref https://catalog.example.com/messages/UserCreated.yaml",
+	}, nil)
+
+	channel := parser.asyncAPI.Channels["userCreated"]
+	want := map[string]spec3.MessageRef{
+		"UserCreated": {Ref: "https://catalog.example.com/messages/UserCreated.yaml"},
+	}
+	if !reflect.DeepEqual(channel.Messages, want) {
+		t.Errorf("channel Messages = %v, want %v", channel.Messages, want)
+	}
+	wantOp := []spec3.Reference{{Ref: "#/channels/userCreated/messages/UserCreated"}}
+	for _, name := range []string{"publishUserCreated", "subscribeUserCreated"} {
+		if got := parser.asyncAPI.Operations[name].Messages; !reflect.DeepEqual(got, wantOp) {
+			t.Errorf("%s Messages = %v, want %v", name, got, wantOp)
+		}
+	}
+	if len(parser.asyncAPI.Components.Messages) != 0 {
+		t.Errorf("Components.Messages = %v, want none", parser.asyncAPI.Components.Messages)
+	}
+}
+
+func TestExternalMessageName(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"https://catalog.example.com/messages/UserCreated.yaml", "UserCreated"},
+		{"catalog.yaml#/components/messages/OrderPlaced", "OrderPlaced"},
+		{"https://catalog.example.com/messages/OrderShipped/", "OrderShipped"},
+		{"schemas/order.json#", "order"},
+		{"catalog.yaml#/components/messages/order.created", "order.created"},
+		{"https://catalog.example.com/messages/order.created", "order.created"},
+		{"https://catalog.example.com/messages/order.created.yml", "order.created"},
+	}
+	for _, tt := range tests {
+		if got := externalMessageName(tt.ref); got != tt.want {
+			t.Errorf("externalMessageName(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestParseOperationDescriptionFallback(t *testing.T) {
+	src := `
+package testpkg
+
+// OrderPlaced is published when a customer places an order.
+type OrderPlaced struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	operations := [][]string{
+		{"@type pub", "@name order.explicit", "@payload OrderPlaced", "@description Explicit description", "@channel.description Orders channel"},
+		{"PublishOrder publishes an order", "to the order stream.", "@type pub", "@name order.doc", "@payload OrderPlaced"},
+		{"@type pub", "@name order.type", "@payload OrderPlaced"},
+	}
+	tests := []struct {
+		channel         string
+		noFallback      bool
+		wantOperation   string
+		wantChannel     string
+		wantMessageDesc string
+	}{
+		{"orderExplicit", false, "Explicit description", "Orders channel", "Explicit description"},
+		{"orderDoc", false, "PublishOrder publishes an order to the order stream.", "PublishOrder publishes an order to the order stream.", ""},
+		{"orderType", false, "OrderPlaced is published when a customer places an order.", "OrderPlaced is published when a customer places an order.", ""},
+		{"orderDoc", true, "", "", ""},
+		{"orderType", true, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/noFallback=%v", tt.channel, tt.noFallback), func(t *testing.T) {
+			parser := NewParser()
+			parser.noDescriptionFallback = tt.noFallback
+			for _, comments := range operations {
+				parser.ParseOperation(comments, tc)
+			}
+			if got := parser.asyncAPI.Operations["publish"+strings.ToUpper(tt.channel[:1])+tt.channel[1:]].Description; got != tt.wantOperation {
+				t.Errorf("operation Description = %q, want %q", got, tt.wantOperation)
+			}
+			if got := parser.asyncAPI.Channels[tt.channel].Description; got != tt.wantChannel {
+				t.Errorf("channel Description = %q, want %q", got, tt.wantChannel)
+			}
+			if got := parser.asyncAPI.Components.Messages[tt.channel+"Message"].Description; got != tt.wantMessageDesc {
+				t.Errorf("message Description = %q, want %q", got, tt.wantMessageDesc)
+			}
+		})
+	}
+}
+
+func TestParseMultiLineDescriptions(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@title Orders",
+		"@description",
+		"Order events of the **shop**.",
+		"",
+		"- placed",
+		"- shipped",
+		"@version 1.0.0",
+	})
+	if want := "Order events of the **shop**.\n\n- placed\n- shipped"; parser.asyncAPI.Info.Description != want {
+		t.Errorf("Info.Description = %q, want %q", parser.asyncAPI.Info.Description, want)
+	}
+	if parser.asyncAPI.Info.Version != "1.0.0" {
+		t.Errorf("Info.Version = %q, want 1.0.0 after the description", parser.asyncAPI.Info.Version)
+	}
+
+	parser.ParseOperation([]string{
+		"PublishOrder publishes an order.",
+		"@type pub",
+		"@name order.placed",
+		"@operation.description Publishes an order",
+		"once it is paid.",
+		"@channel.description Orders:",
+		"",
+		"    {\"id\": \"1\"}",
+		"",
+		"@message.description One order.",
+	}, nil)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"operation", parser.asyncAPI.Operations["publishOrderPlaced"].Description, "Publishes an order\nonce it is paid."},
+		{"channel", parser.asyncAPI.Channels["orderPlaced"].Description, "Orders:\n\n    {\"id\": \"1\"}"},
+		{"message", parser.asyncAPI.Components.Messages["orderPlacedMessage"].Description, "One order."},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s description = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	got := joinDescriptions([]string{"Prose.", "@type pub", "not a description", "@description A", "B"})
+	want := []string{"Prose.", "@type pub", "not a description", "@description A\nB"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("joinDescriptions() = %q, want %q", got, want)
+	}
+}
+
+func TestFinalizeSharesSchemas(t *testing.T) {
+	src := `
+package testpkg
+
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+
+// User is an account holder.
+type User struct {
+	Name    string  ` + "`json:\"name\"`" + `
+	Address Address ` + "`json:\"address\"`" + `
+}
+
+type GetUserRequest struct {
+	// Requester asks for the lookup.
+	Requester User ` + "`json:\"requester\"`" + `
+	Address   Address ` + "`json:\"address\"`" + `
+}
+
+type GetUserReply struct {
+	User  *User  ` + "`json:\"user\"`" + `
+	Users []User ` + "`json:\"users\"`" + `
+}
+
+type Audit struct {
+	Actor User ` + "`json:\"actor\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name user.get", "@payload GetUserRequest", "@response GetUserReply"}, tc)
+	parser.Finalize()
+
+	schemas := parser.asyncAPI.Components.Schemas
+	userRef := map[string]interface{}{"$ref": "#/components/schemas/User"}
+	request := schemas["GetUserRequest"].(map[string]interface{})["properties"].(map[string]interface{})
+	if want := map[string]interface{}{"$ref": "#/components/schemas/User", "description": "Requester asks for the lookup."}; !reflect.DeepEqual(request["requester"], want) {
+		t.Errorf("requester = %v, want %v", request["requester"], want)
+	}
+	reply := schemas["GetUserReply"].(map[string]interface{})["properties"].(map[string]interface{})
+	if want := nullableSchema(map[string]interface{}{"$ref": "#/components/schemas/User"}); !reflect.DeepEqual(reply["user"], want) {
+		t.Errorf("user = %v, want %v", reply["user"], want)
+	}
+	if items := reply["users"].(map[string]interface{})["items"]; !reflect.DeepEqual(items, userRef) {
+		t.Errorf("users items = %v, want %v", items, userRef)
+	}
+
+	user, ok := schemas["User"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("schemas = %v, want shared User", schemas)
+	}
+	if user["description"] != "User is an account holder." {
+		t.Errorf("User description = %v, want type doc", user["description"])
+	}
+	// Address occurs in User once and in the request once
+	addressRef := map[string]interface{}{"$ref": "#/components/schemas/Address"}
+	if address := user["properties"].(map[string]interface{})["address"]; !reflect.DeepEqual(address, addressRef) {
+		t.Errorf("User address = %v, want %v", address, addressRef)
+	}
+	if !reflect.DeepEqual(request["address"], addressRef) {
+		t.Errorf("request address = %v, want %v", request["address"], addressRef)
+	}
+
+	// A type nested once stays inline, and an existing component is reused
+	single := NewParser()
+	single.ParseOperation([]string{"@type pub", "@name audit", "@payload Audit"}, tc)
+	single.ParseOperation([]string{"@type pub", "@name user.created", "@payload User"}, tc)
+	single.Finalize()
+	actor := single.asyncAPI.Components.Schemas["Audit"].(map[string]interface{})["properties"].(map[string]interface{})["actor"]
+	if !reflect.DeepEqual(actor, userRef) {
+		t.Errorf("actor = %v, want %v", actor, userRef)
+	}
+	address := single.asyncAPI.Components.Schemas["User"].(map[string]interface{})["properties"].(map[string]interface{})["address"]
+	if _, ok := address.(map[string]interface{})["properties"]; !ok {
+		t.Errorf("User address = %v, want inline Address", address)
+	}
+	if _, ok := single.asyncAPI.Components.Schemas["Address"]; ok {
+		t.Errorf("schemas has Address, want it inline in User only")
+	}
+}
+
+func TestParserConcurrentParseOperation(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderPlaced struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	const n = 20
+	parser := NewParser()
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parser.ParseOperation([]string{
+				"@type pub",
+				fmt.Sprintf("@name orders.region%d.placed", i),
+				"@payload OrderPlaced",
+				"@response Missing",
+			}, tc)
+		}()
+	}
+	wg.Wait()
+	parser.Finalize()
+
+	if got := len(parser.asyncAPI.Operations); got != n {
+		t.Errorf("len(Operations) = %d, want %d", got, n)
+	}
+	if got := len(parser.asyncAPI.Components.Schemas); got != 2 {
+		t.Errorf("len(Components.Schemas) = %d, want the shared OrderPlaced and Missing schemas", got)
+	}
+	if got := parser.warnings.list(); len(got) != 1 || got[0].Count != n {
+		t.Errorf("warnings = %+v, want one warning counted %d times", got, n)
+	}
+}
@@ -0,0 +1,154 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Protobuf-generated Go types are described by their protojson mapping, the
+// JSON form of the messages: fields use their JSON names, are all optional,
+// 64-bit integers are strings and enums are their value names. With
+// @message.proto the message instead references its .proto definition.
+const (
+	// protobufSchemaFormat is the AsyncAPI schema format of .proto payloads.
+	protobufSchemaFormat = "application/vnd.google.protobuf;version=3"
+	// protobufContentType is the default content type of messages that
+	// reference a .proto file.
+	protobufContentType = "application/x-protobuf"
+)
+
+// protobufWellKnownTypes holds the protojson schemas of the well-known types,
+// which encode differently from their fields.
+var protobufWellKnownTypes = map[string]map[string]interface{}{
+	"timestamppb.Timestamp":  {"type": "string", "format": "date-time"},
+	"durationpb.Duration":    {"type": "string", "pattern": `^-?[0-9]+(\.[0-9]{1,9})?s$`},
+	"fieldmaskpb.FieldMask":  {"type": "string"},
+	"structpb.Struct":        {"type": "object"},
+	"structpb.Value":         {},
+	"structpb.ListValue":     {"type": "array"},
+	"emptypb.Empty":          {"type": "object"},
+	"anypb.Any":              {"type": "object", "properties": map[string]interface{}{"@type": map[string]interface{}{"type": "string"}}},
+	"wrapperspb.StringValue": {"type": "string"},
+	"wrapperspb.BytesValue":  {"type": "string", "contentEncoding": "base64"},
+	"wrapperspb.BoolValue":   {"type": "boolean"},
+	"wrapperspb.Int32Value":  {"type": "integer", "format": "int32"},
+	"wrapperspb.UInt32Value": {"type": "integer", "format": "uint32"},
+	"wrapperspb.Int64Value":  {"type": "string", "format": "int64"},
+	"wrapperspb.UInt64Value": {"type": "string", "format": "uint64"},
+	"wrapperspb.FloatValue":  {"type": "number", "format": "float"},
+	"wrapperspb.DoubleValue": {"type": "number", "format": "double"},
+}
+
+// isProtoMessage reports whether the named type is a protobuf-generated
+// message, which implements proto.Message through ProtoMessage().
+func (tc *TypeChecker) isProtoMessage(typeName string) bool {
+	if tc == nil || tc.pkg == nil {
+		return false
+	}
+	obj := tc.lookupType(strings.TrimLeft(typeName, "[]*"))
+	if obj == nil {
+		return false
+	}
+	methods := types.NewMethodSet(types.NewPointer(obj.Type()))
+	return methods.Lookup(obj.Pkg(), "ProtoMessage") != nil
+}
+
+// protoEnumNames returns the value names of a protobuf-generated enum, read
+// from the "<Enum>_name" map generated next to it, or nil for other types.
+func (tc *TypeChecker) protoEnumNames(obj *types.TypeName) []interface{} {
+	if obj.Pkg() == nil {
+		return nil
+	}
+	nameMap, ok := obj.Pkg().Scope().Lookup(obj.Name() + "_name").(*types.Var)
+	if !ok || types.TypeString(nameMap.Type(), nil) != "map[int32]string" {
+		return nil
+	}
+
+	for _, f := range tc.files[obj.Pkg()] {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR || gen.Pos() > nameMap.Pos() || gen.End() < nameMap.Pos() {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok || len(valueSpec.Names) != 1 || valueSpec.Names[0].Pos() != nameMap.Pos() || len(valueSpec.Values) != 1 {
+					continue
+				}
+				lit, ok := valueSpec.Values[0].(*ast.CompositeLit)
+				if !ok {
+					return nil
+				}
+				var names []interface{}
+				for _, elt := range lit.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					value, ok := kv.Value.(*ast.BasicLit)
+					if !ok || value.Kind != token.STRING {
+						continue
+					}
+					if name, err := strconv.Unquote(value.Value); err == nil {
+						names = append(names, name)
+					}
+				}
+				return names
+			}
+		}
+	}
+	return nil
+}
+
+// protoField adapts a field of a protobuf-generated struct to its protojson
+// form. It reports false for fields protojson does not encode by name, such
+// as the interface holding a oneof, whose members are separate types.
+func protoField(field *FieldInfo) bool {
+	tag := reflect.StructTag(field.Tag)
+	if _, ok := tag.Lookup("protobuf_oneof"); ok {
+		return false
+	}
+	protoTag, ok := tag.Lookup("protobuf")
+	if !ok {
+		return true
+	}
+
+	// protobuf:"varint,1,opt,name=order_id,json=orderId,proto3"
+	jsonName := ""
+	for _, option := range strings.Split(protoTag, ",") {
+		if name, ok := strings.CutPrefix(option, "name="); ok && jsonName == "" {
+			jsonName = name
+		}
+		if name, ok := strings.CutPrefix(option, "json="); ok {
+			jsonName = name
+		}
+	}
+	if jsonName == "" {
+		return true
+	}
+	field.JSONTag = jsonName
+
+	// Every proto3 field may be omitted, and protojson writes 64-bit
+	// integers as strings
+	tagValue := `json:"` + jsonName + `,omitempty"`
+	switch strings.TrimLeft(field.Type, "*") {
+	case "int64", "uint64":
+		tagValue += ` format:"` + strings.TrimLeft(field.Type, "*") + `"`
+		field.Type = "string"
+	case "[]int64", "[]uint64":
+		field.Type, field.ElemType = "[]string", "string"
+	case "[]uint8", "[]byte":
+		// bytes fields are base64 strings
+		tagValue += ` format:"byte"`
+		field.Type, field.ElemType, field.IsArray = "string", "", false
+	}
+	if description, ok := tag.Lookup("description"); ok {
+		tagValue += ` description:` + strconv.Quote(description)
+	}
+	field.Tag = tagValue
+	return true
+}
@@ -0,0 +1,128 @@
+package asyncapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+const protobufTestSource = `
+package orderspb
+
+type Status int32
+
+const (
+	Status_STATUS_UNSPECIFIED Status = 0
+	Status_STATUS_OPEN        Status = 1
+)
+
+var (
+	Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "STATUS_OPEN",
+	}
+)
+
+type isOrder_Payment interface{ isOrder_Payment() }
+
+type Order struct {
+	state         struct{}
+	OrderId       string          ` + "`protobuf:\"bytes,1,opt,name=order_id,json=orderId,proto3\" json:\"order_id,omitempty\"`" + `
+	Quantity      int64           ` + "`protobuf:\"varint,2,opt,name=quantity,proto3\" json:\"quantity,omitempty\"`" + `
+	Status        Status          ` + "`protobuf:\"varint,3,opt,name=status,proto3,enum=orders.Status\" json:\"status,omitempty\"`" + `
+	Signature     []byte          ` + "`protobuf:\"bytes,4,opt,name=signature,proto3\" json:\"signature,omitempty\"`" + `
+	Item          *Item           ` + "`protobuf:\"bytes,5,opt,name=item,proto3\" json:\"item,omitempty\"`" + `
+	Payment       isOrder_Payment ` + "`protobuf_oneof:\"payment\"`" + `
+}
+
+func (*Order) ProtoMessage() {}
+
+type Item struct {
+	Sku string ` + "`protobuf:\"bytes,1,opt,name=sku,proto3\" json:\"sku,omitempty\"`" + `
+}
+
+func (*Item) ProtoMessage() {}
+`
+
+func newProtobufTestChecker(t *testing.T) *TypeChecker {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "orders.pb.go", protobufTestSource, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "orderspb")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+	return tc
+}
+
+func TestGenerateJSONSchema_Protobuf(t *testing.T) {
+	tc := newProtobufTestChecker(t)
+	schema := GenerateJSONSchema(GetByNameType("Order", tc))
+	properties := schema["properties"].(map[string]interface{})
+
+	tests := []struct {
+		name string
+		want interface{}
+	}{
+		{"orderId", map[string]interface{}{"type": "string"}},
+		{"quantity", map[string]interface{}{"type": "string", "format": "int64"}},
+		{"status", map[string]interface{}{
+			"type":  "string",
+			"title": "Status",
+			"enum":  []interface{}{"STATUS_UNSPECIFIED", "STATUS_OPEN"},
+		}},
+		{"signature", map[string]interface{}{"type": "string", "format": "byte"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(properties[tt.name], tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.name, properties[tt.name], tt.want)
+		}
+	}
+	if _, ok := properties["item"]; !ok {
+		t.Errorf("properties = %v, want item", properties)
+	}
+	if _, ok := properties["Payment"]; ok {
+		t.Errorf("properties = %v, want no oneof interface", properties)
+	}
+	if required, ok := schema["required"]; ok {
+		t.Errorf("required = %v, want none for proto3 fields", required)
+	}
+}
+
+func TestProtobufWellKnownTypes(t *testing.T) {
+	tc := newProtobufTestChecker(t)
+	got := generateSchemaForType(tc.getReflectTypeFromString("timestamppb.Timestamp"))
+	if want := map[string]interface{}{"type": "string", "format": "date-time"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("timestamppb.Timestamp = %v, want %v", got, want)
+	}
+}
+
+func TestParseOperationProtobuf(t *testing.T) {
+	tc := newProtobufTestChecker(t)
+
+	parser := NewParser()
+	parser.ParseOperation([]string{"@type pub", "@name order.created", "@payload Order"}, tc)
+	parser.ParseOperation([]string{"@type pub", "@name order.placed", "@payload Order", "@message.proto ./proto/orders.proto"}, tc)
+
+	created := parser.asyncAPI.Components.Messages["orderCreatedMessage"]
+	if want := map[string]interface{}{"$ref": "#/components/schemas/Order"}; !reflect.DeepEqual(created.Payload, want) {
+		t.Errorf("JSON Schema payload = %v, want %v", created.Payload, want)
+	}
+
+	placed := parser.asyncAPI.Components.Messages["orderPlacedMessage"]
+	want := map[string]interface{}{
+		"schemaFormat": protobufSchemaFormat,
+		"schema":       map[string]interface{}{"$ref": "./proto/orders.proto"},
+	}
+	if !reflect.DeepEqual(placed.Payload, want) {
+		t.Errorf(".proto payload = %v, want %v", placed.Payload, want)
+	}
+	if placed.ContentType != protobufContentType {
+		t.Errorf("ContentType = %q, want %q", placed.ContentType, protobufContentType)
+	}
+}
@@ -0,0 +1,202 @@
+package asyncapi
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// redactedKeys are removed from schemas of classified fields in public output.
+var redactedKeys = []string{"description", "example", "examples", "default", "const"}
+
+// Redact strips example values and descriptions from every schema marked with
+// one of the given classifications, e.g. "pii". A schema is marked when it
+// carries `x-<class>: true` or `x-classification: <class>` (see the xext struct tag).
+// The values of classified fields are also removed from message examples.
+// The document is modified in place.
+func Redact(doc *spec3.AsyncAPI, classes []string) {
+	if doc.Components == nil || len(classes) == 0 {
+		return
+	}
+
+	normalized := make([]string, 0, len(classes))
+	for _, class := range classes {
+		if class = strings.ToLower(strings.TrimSpace(class)); class != "" {
+			normalized = append(normalized, class)
+		}
+	}
+
+	for _, schema := range doc.Components.Schemas {
+		redactSchema(schema, normalized)
+	}
+	for name, message := range doc.Components.Messages {
+		redactSchema(message.Payload, normalized)
+		redactSchema(message.Headers, normalized)
+		if len(message.Examples) > 0 {
+			message.Examples = redactExamples(doc, message, normalized)
+			doc.Components.Messages[name] = message
+		}
+	}
+}
+
+// redactExamples returns copies of the examples of message without the
+// payload and header values of classified fields. The examples may be shared
+// with other messages, so they are not modified in place.
+func redactExamples(doc *spec3.AsyncAPI, message spec3.Message, classes []string) []spec3.MessageExample {
+	payload := payloadSchema(message.Payload)
+	examples := make([]spec3.MessageExample, len(message.Examples))
+	for i, example := range message.Examples {
+		example.Payload = redactValue(doc, payload, example.Payload, classes)
+		if headers, ok := redactValue(doc, message.Headers, example.Headers, classes).(map[string]interface{}); ok {
+			example.Headers = headers
+		}
+		examples[i] = example
+	}
+	return examples
+}
+
+// payloadSchema returns the schema of a payload, unwrapping a multi-format
+// schema object.
+func payloadSchema(payload interface{}) interface{} {
+	if wrapped, ok := payload.(map[string]interface{}); ok {
+		if _, ok := wrapped["schemaFormat"]; ok {
+			return wrapped["schema"]
+		}
+	}
+	return payload
+}
+
+// redactValue returns a copy of the example value without the object members
+// whose property schema is classified.
+func redactValue(doc *spec3.AsyncAPI, schema, value interface{}, classes []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, member := range v {
+			properties := propertySchemas(doc, schema, key)
+			if slices.ContainsFunc(properties, func(property map[string]interface{}) bool {
+				return isClassified(property, classes)
+			}) {
+				continue
+			}
+			for _, property := range properties {
+				member = redactValue(doc, property, member, classes)
+			}
+			redacted[key] = member
+		}
+		return redacted
+	case []interface{}:
+		items := subschemas(doc, schema, "items")
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			for _, itemSchema := range items {
+				item = redactValue(doc, itemSchema, item, classes)
+			}
+			redacted[i] = item
+		}
+		return redacted
+	}
+	return value
+}
+
+// propertySchemas returns the schemas a member named key of an object
+// matching schema may be described by: its property, or the additional
+// properties of a map, in schema or any of its allOf, oneOf and anyOf
+// alternatives.
+func propertySchemas(doc *spec3.AsyncAPI, schema interface{}, key string) []map[string]interface{} {
+	var properties []map[string]interface{}
+	for _, object := range subschemas(doc, schema, "") {
+		if named, ok := object["properties"].(map[string]interface{}); ok {
+			if property := resolveSchemaRef(doc, named[key]); property != nil {
+				properties = append(properties, property)
+				continue
+			}
+		}
+		if additional := resolveSchemaRef(doc, object["additionalProperties"]); additional != nil {
+			properties = append(properties, additional)
+		}
+	}
+	return properties
+}
+
+// subschemas returns schema and its allOf, oneOf and anyOf alternatives, with
+// their references resolved, or their keyword subschemas when keyword is set.
+func subschemas(doc *spec3.AsyncAPI, schema interface{}, keyword string) []map[string]interface{} {
+	var schemas []map[string]interface{}
+	var collect func(node interface{}, depth int)
+	collect = func(node interface{}, depth int) {
+		object := resolveSchemaRef(doc, node)
+		if object == nil || depth > maxRedactDepth {
+			return
+		}
+		if keyword == "" {
+			schemas = append(schemas, object)
+		} else if sub := resolveSchemaRef(doc, object[keyword]); sub != nil {
+			schemas = append(schemas, sub)
+		}
+		for _, combinator := range []string{"allOf", "oneOf", "anyOf"} {
+			alternatives, _ := object[combinator].([]interface{})
+			for _, alternative := range alternatives {
+				collect(alternative, depth+1)
+			}
+		}
+	}
+	collect(schema, 0)
+	return schemas
+}
+
+// maxRedactDepth bounds the nesting of schema combinators followed when
+// redacting examples, against self-referencing schemas.
+const maxRedactDepth = 16
+
+// resolveSchemaRef returns the schema object node is or refers to among the
+// component schemas, or nil.
+func resolveSchemaRef(doc *spec3.AsyncAPI, node interface{}) map[string]interface{} {
+	for range maxRedactDepth {
+		schema, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+		if !ok {
+			return nil
+		}
+		node = doc.Components.Schemas[name]
+	}
+	return nil
+}
+
+func redactSchema(node interface{}, classes []string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if isClassified(v, classes) {
+			for _, key := range redactedKeys {
+				delete(v, key)
+			}
+		}
+		for _, child := range v {
+			redactSchema(child, classes)
+		}
+	case []interface{}:
+		for _, child := range v {
+			redactSchema(child, classes)
+		}
+	}
+}
+
+func isClassified(schema map[string]interface{}, classes []string) bool {
+	for _, class := range classes {
+		if flag, ok := schema["x-"+class].(bool); ok && flag {
+			return true
+		}
+		if classification, ok := schema["x-classification"].(string); ok && strings.EqualFold(classification, class) {
+			return true
+		}
+	}
+	return false
+}
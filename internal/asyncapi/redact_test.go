@@ -0,0 +1,135 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func TestRedact(t *testing.T) {
+	type Address struct {
+		Street string `json:"street" description:"Street name" example:"Main St" xext:"classification=pii"`
+	}
+	type Customer struct {
+		Email   string  `json:"email" description:"Contact email" example:"jane@example.com" xext:"pii=true"`
+		Country string  `json:"country" description:"Country code" example:"DE"`
+		Address Address `json:"address"`
+	}
+
+	doc := spec3.NewAsyncAPI()
+	doc.Components.Schemas["customer"] = GenerateJSONSchema(Customer{})
+
+	Redact(doc, []string{"pii"})
+
+	schema, ok := doc.Components.Schemas["customer"].(map[string]interface{})
+	if !ok {
+		t.Fatal("customer schema missing")
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Properties is not a map")
+	}
+
+	email, ok := properties["email"].(map[string]interface{})
+	if !ok {
+		t.Fatal("email property missing")
+	}
+	if _, has := email["example"]; has {
+		t.Error("example should be redacted from pii field")
+	}
+	if _, has := email["description"]; has {
+		t.Error("description should be redacted from pii field")
+	}
+	if email["type"] != "string" {
+		t.Errorf("type should be kept, got %v", email["type"])
+	}
+
+	country, ok := properties["country"].(map[string]interface{})
+	if !ok {
+		t.Fatal("country property missing")
+	}
+	if country["example"] != "DE" {
+		t.Errorf("example should be kept on unclassified field, got %v", country["example"])
+	}
+
+	address, ok := properties["address"].(map[string]interface{})
+	if !ok {
+		t.Fatal("address property missing")
+	}
+	street, ok := address["properties"].(map[string]interface{})["street"].(map[string]interface{})
+	if !ok {
+		t.Fatal("street property missing")
+	}
+	if _, has := street["example"]; has {
+		t.Error("example should be redacted from nested classified field")
+	}
+}
+
+func TestRedactWithoutClasses(t *testing.T) {
+	type Customer struct {
+		Email string `json:"email" example:"jane@example.com" xext:"pii=true"`
+	}
+
+	doc := spec3.NewAsyncAPI()
+	doc.Components.Schemas["customer"] = GenerateJSONSchema(Customer{})
+
+	Redact(doc, nil)
+
+	schema := doc.Components.Schemas["customer"].(map[string]interface{})
+	email := schema["properties"].(map[string]interface{})["email"].(map[string]interface{})
+	if email["example"] != "jane@example.com" {
+		t.Errorf("example should be kept when no classes are redacted, got %v", email["example"])
+	}
+}
+
+func TestRedactMessageExamples(t *testing.T) {
+	type Address struct {
+		Street string `json:"street" xext:"classification=pii"`
+		City   string `json:"city"`
+	}
+	type Customer struct {
+		Email     string    `json:"email" xext:"pii=true"`
+		Country   string    `json:"country"`
+		Addresses []Address `json:"addresses"`
+	}
+	type Headers struct {
+		SessionID string `json:"sessionId" xext:"pii=true"`
+		TraceID   string `json:"traceId"`
+	}
+
+	doc := spec3.NewAsyncAPI()
+	doc.Components.Schemas["customer"] = GenerateJSONSchema(Customer{})
+	examples := []spec3.MessageExample{{
+		Name:    "signup",
+		Headers: map[string]interface{}{"sessionId": "s-123", "traceId": "t-1"},
+		Payload: map[string]interface{}{
+			"email":     "jane@example.com",
+			"country":   "DE",
+			"addresses": []interface{}{map[string]interface{}{"street": "Main St", "city": "Berlin"}},
+		},
+	}}
+	doc.Components.Messages["customerCreated"] = spec3.Message{
+		Payload:  map[string]interface{}{"$ref": "#/components/schemas/customer"},
+		Headers:  GenerateJSONSchema(Headers{}),
+		Examples: examples,
+	}
+
+	Redact(doc, []string{"pii"})
+
+	example := doc.Components.Messages["customerCreated"].Examples[0]
+	want := spec3.MessageExample{
+		Name:    "signup",
+		Headers: map[string]interface{}{"traceId": "t-1"},
+		Payload: map[string]interface{}{
+			"country":   "DE",
+			"addresses": []interface{}{map[string]interface{}{"city": "Berlin"}},
+		},
+	}
+	if !reflect.DeepEqual(example, want) {
+		t.Errorf("redacted example = %#v, want %#v", example, want)
+	}
+	if _, has := examples[0].Payload.(map[string]interface{})["email"]; !has {
+		t.Error("examples shared with other messages should not be modified")
+	}
+}
@@ -0,0 +1,44 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// redisBindingKey is the binding key of the Redis Streams properties. The
+// AsyncAPI Redis bindings define no properties yet, so they are written as
+// an x-redis extension of the bindings object.
+const redisBindingKey = "x-redis"
+
+// ParseBindingRedis parses a Redis Streams binding property: the stream and
+// its maximum length go to the channel binding, the consumer group and
+// consumer name to the operation binding.
+func (operation *Operation) ParseBindingRedis(key, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fmt.Errorf("missing value for @binding.redis.%s", key)
+	}
+	switch strings.ToLower(key) {
+	case "stream":
+		operation.setBinding(channelBinding, redisBindingKey, "stream", value)
+	case "maxlen":
+		// "~1000" trims the stream approximately, as XADD MAXLEN ~ does
+		approximate := strings.HasPrefix(value, "~")
+		maxLen, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(value, "~")))
+		if err != nil || maxLen < 0 {
+			return fmt.Errorf("invalid @binding.redis.maxLen %q: must be a non-negative integer", value)
+		}
+		operation.setBinding(channelBinding, redisBindingKey, "maxLen", maxLen)
+		if approximate {
+			operation.setBinding(channelBinding, redisBindingKey, "approximateTrimming", true)
+		}
+	case "consumergroup":
+		operation.setBinding(operationBinding, redisBindingKey, "consumerGroup", value)
+	case "consumer":
+		operation.setBinding(operationBinding, redisBindingKey, "consumer", value)
+	default:
+		return fmt.Errorf("unknown Redis binding @binding.redis.%s", key)
+	}
+	return nil
+}
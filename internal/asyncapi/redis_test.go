@@ -0,0 +1,60 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBindingRedis(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		wantErr bool
+	}{
+		{"stream", "@binding.redis.stream orders", false},
+		{"max length", "@binding.redis.maxLen 10000", false},
+		{"approximate max length", "@binding.redis.maxlen ~10000", false},
+		{"invalid max length", "@binding.redis.maxLen many", true},
+		{"consumer group", "@binding.Redis.consumerGroup billing", false},
+		{"missing value", "@binding.redis.stream", true},
+		{"unknown", "@binding.redis.db 2", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := NewOperation()
+			err := operation.ParseComment(tt.comment, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseOperationRedisBindings(t *testing.T) {
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type pub",
+		"@name orders.placed",
+		"@payload string",
+		"@binding.redis.stream orders",
+		"@binding.redis.maxLen ~10000",
+	}, nil)
+	parser.ParseOperation([]string{
+		"@type sub",
+		"@name orders.placed",
+		"@payload string",
+		"@binding.redis.consumerGroup billing",
+		"@binding.redis.consumer billing-1",
+	}, nil)
+
+	channel := parser.asyncAPI.Channels["ordersPlaced"].Bindings[redisBindingKey]
+	want := map[string]interface{}{"stream": "orders", "maxLen": 10000, "approximateTrimming": true}
+	if !reflect.DeepEqual(channel, want) {
+		t.Errorf("channel binding = %v, want %v", channel, want)
+	}
+	operation := parser.asyncAPI.Operations["subscribeOrdersPlaced"].Bindings[redisBindingKey]
+	want = map[string]interface{}{"consumerGroup": "billing", "consumer": "billing-1"}
+	if !reflect.DeepEqual(operation, want) {
+		t.Errorf("operation binding = %v, want %v", operation, want)
+	}
+}
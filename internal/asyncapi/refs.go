@@ -0,0 +1,200 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// literalSchema is the field type of the marker structs that stand in for a
+// type whose schema is known before reflection: a $ref to a component schema
+// (self-referencing types, or any nested struct in ref-nested mode) or a
+// schema from the type mappings. The marker's field tag holds the schema.
+type literalSchema struct{}
+
+// literalSchemaType returns a marker struct type that generates schema.
+func literalSchemaType(schema map[string]interface{}) reflect.Type {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return reflect.TypeOf((*interface{})(nil)).Elem()
+	}
+	return reflect.StructOf([]reflect.StructField{{
+		Name: "Schema",
+		Type: reflect.TypeOf(literalSchema{}),
+		Tag:  reflect.StructTag(`schema:` + strconv.Quote(string(data))),
+	}})
+}
+
+// schemaDoc is the type of the marker field that carries the component name,
+// the doc comment and, when enabled, the Go type of a struct built from type
+// information, in its component, description and gotype tags.
+type schemaDoc struct{}
+
+// schemaDocField names the marker field added by appendSchemaDoc.
+const schemaDocField = "AsyncAPISchemaDoc"
+
+// appendSchemaDoc adds the marker field describing the struct to fields,
+// unless a field already uses its name.
+func appendSchemaDoc(fields []reflect.StructField, component, doc, goType string) []reflect.StructField {
+	for _, field := range fields {
+		if field.Name == schemaDocField {
+			return fields
+		}
+	}
+	return append(fields, reflect.StructField{
+		Name: schemaDocField,
+		Type: reflect.TypeOf(schemaDoc{}),
+		Tag: reflect.StructTag(`component:` + strconv.Quote(component) + ` description:` + strconv.Quote(doc) +
+			` gotype:` + strconv.Quote(goType)),
+	})
+}
+
+// literalSchemaOf returns a copy of the schema of a marker struct created by
+// literalSchemaType.
+func literalSchemaOf(typ reflect.Type) (map[string]interface{}, bool) {
+	if typ.Kind() != reflect.Struct || typ.NumField() != 1 || typ.Field(0).Type != reflect.TypeOf(literalSchema{}) {
+		return nil, false
+	}
+	schema := map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(typ.Field(0).Tag.Get("schema")))
+	decoder.UseNumber()
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, false
+	}
+	normalizeNumbers(schema)
+	return schema, true
+}
+
+// normalizeNumbers replaces the json.Numbers of a decoded value with int64
+// for integers and float64 otherwise, as the schema generator emits them.
+func normalizeNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			val[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = normalizeNumbers(item)
+		}
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// schemaRefType returns a marker struct type whose schema is a $ref to the
+// named component schema.
+func schemaRefType(name string) reflect.Type {
+	return literalSchemaType(map[string]interface{}{
+		"$ref": "#/components/schemas/" + name,
+	})
+}
+
+// reserveComponent returns the component schema name of the type emitted as
+// a $ref, reserving a free one the first time: a name not reserved for
+// another type nor taken by another schema, so types of different packages
+// sharing a name, or a payload registered under it, keep their own schemas.
+func (p *Parser) reserveComponent(typeName string, tc *TypeChecker) string {
+	key := tc.componentKey(typeName)
+	if name, ok := p.refNames[key]; ok {
+		return name
+	}
+	if p.refNames == nil {
+		p.refNames = make(map[string]string)
+		p.refOwners = make(map[string]string)
+	}
+
+	base := schemaNameForType(typeName)
+	name := base
+	for i := 2; ; i++ {
+		if _, reserved := p.refOwners[name]; !reserved && p.componentNameFree(name, typeName, tc) {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	p.refNames[key] = name
+	p.refOwners[name] = key
+	return name
+}
+
+// componentNameFree reports whether name is free for the component schema
+// of typeName: not taken, or taken by the same schema, such as the payload of
+// the type. The schema of a type being expanded cannot be generated yet; a
+// schema already registered under its name belongs to another type, as a
+// self-referencing type reserves its name whenever it is expanded.
+func (p *Parser) componentNameFree(name, typeName string, tc *TypeChecker) bool {
+	existing, taken := p.asyncAPI.Components.Schemas[name]
+	if !taken {
+		return true
+	}
+	if info := tc.ExtractTypeInfo(typeName); info != nil && tc.expanding[types.TypeString(info.named, nil)] {
+		return false
+	}
+	// Compared as registerSchema stores it
+	schema := tc.componentSchema(typeName)
+	p.schemaLimits.truncate(schema)
+	var uses []schemaUse
+	collectSchemaTypes(schema, -1, &uses)
+	return reflect.DeepEqual(existing, schema)
+}
+
+// componentKey identifies the type typeName refers to across packages.
+func (tc *TypeChecker) componentKey(typeName string) string {
+	if named, ok := tc.instances[typeName]; ok {
+		return types.TypeString(named, nil)
+	}
+	if obj := tc.lookupType(typeName); obj != nil {
+		return types.TypeString(obj.Type(), nil)
+	}
+	return typeName
+}
+
+// componentSchema generates the component schema of a type emitted as a
+// $ref.
+func (tc *TypeChecker) componentSchema(typeName string) map[string]interface{} {
+	if schema, ok := tc.namedPrimitiveSchema(typeName); ok {
+		return schema
+	}
+	return generateTypedSchema(Msg{Data: GetByNameType(typeName, tc)})
+}
+
+// registerComponentRefs registers the component schemas referenced by the
+// payloads generated so far under their reserved names, so their $refs
+// resolve. Generating one schema may reference further types, so this
+// repeats until none remain.
+func (p *Parser) registerComponentRefs(tc *TypeChecker) {
+	if tc == nil {
+		return
+	}
+	done := make(map[string]bool)
+	for {
+		names := make([]string, 0, len(tc.componentRefs))
+		for name := range tc.componentRefs {
+			if !done[name] {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			done[name] = true
+			typeName := tc.componentRefs[name]
+			if registered := p.registerTypeSchema(name, p.refOwners[name], tc.componentSchema(typeName)); registered != name {
+				p.warnings.warnf(warnSchema, "type %s is registered as %s because another schema is named %s; its $refs point to %s",
+					typeName, registered, name, name)
+			}
+		}
+	}
+}
@@ -1,16 +1,32 @@
 package asyncapi
 
 import (
+	"encoding"
+	"encoding/json"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
 // GenerateJSONSchema converts a struct instance to a JSON Schema definition.
 // This creates a proper schema with type, properties, etc. instead of example values.
 // It unwraps Msg and MsgResponse wrapper types to return only the inner payload schema.
 func GenerateJSONSchema(v interface{}) map[string]interface{} {
+	schema := generateTypedSchema(v)
+	collectSchemaTypes(schema, -1, nil)
+	return schema
+}
+
+// generateTypedSchema is GenerateJSONSchema keeping the markers of the named
+// struct types, which registerSchema records to share them across the
+// document.
+func generateTypedSchema(v interface{}) map[string]interface{} {
 	if v == nil {
 		return map[string]interface{}{
 			"type": "object",
@@ -90,6 +106,12 @@ func generateSchemaForValue(val reflect.Value) map[string]interface{} {
 		typ = val.Type()
 	}
 
+	if isMarshaler(typ) {
+		return map[string]interface{}{
+			"type": "string",
+		}
+	}
+
 	//nolint:exhaustive // Only handling common types; default case handles others
 	switch typ.Kind() {
 	case reflect.Struct:
@@ -133,8 +155,14 @@ func generateObjectSchema(val reflect.Value) map[string]interface{} {
 		}
 	}
 
+	// Referenced and mapped types carry their schema
+	if schema, ok := literalSchemaOf(typ); ok {
+		return schema
+	}
+
 	properties := make(map[string]interface{})
 	required := []string{}
+	description, component, goType := "", "", ""
 
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
@@ -145,13 +173,21 @@ func generateObjectSchema(val reflect.Value) map[string]interface{} {
 			continue
 		}
 
-		// Get JSON tag name
+		// The doc comment of the type describes the whole object
+		if field.Type == reflect.TypeOf(schemaDoc{}) {
+			description, component, goType = field.Tag.Get("description"), field.Tag.Get("component"), field.Tag.Get("gotype")
+			continue
+		}
+
+		// Get JSON tag name. Types built from parsed sources tag the
+		// untagged fields they document (see TypeChecker.structFields)
 		jsonTag := field.Tag.Get("json")
 		if jsonTag == "" || jsonTag == "-" {
 			continue
 		}
 
-		// Parse JSON tag (e.g., "fieldName,omitempty")
+		// Parse JSON tag (e.g., "fieldName,omitempty"); like encoding/json,
+		// a tag without a name uses the Go field name
 		jsonName := jsonTag
 		isRequired := true
 		if idx := strings.Index(jsonTag, ","); idx != -1 {
@@ -161,13 +197,29 @@ func generateObjectSchema(val reflect.Value) map[string]interface{} {
 				isRequired = false
 			}
 		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		// Pointer fields may be null, so they are optional by default
+		isPointer := field.Type.Kind() == reflect.Ptr
+		if isPointer {
+			isRequired = false
+		}
 
 		// Generate schema for field
-		fieldSchema := generateSchemaForValue(fieldVal)
+		fieldSchema := generateFieldSchema(typ, fieldVal)
+		if marker, ok := fieldSchema[schemaTypeKey].(*schemaType); ok {
+			// Keep the schema of the type apart from the field's tags
+			marker.base = copySchema(fieldSchema)
+		}
 
 		// Apply struct field tags
 		applyFieldTags(fieldSchema, field)
 
+		if isPointer {
+			fieldSchema = nullableSchema(fieldSchema)
+		}
 		properties[jsonName] = fieldSchema
 
 		// Check for explicit required tag
@@ -188,10 +240,36 @@ func generateObjectSchema(val reflect.Value) map[string]interface{} {
 	if len(required) > 0 {
 		schema["required"] = required
 	}
+	if description != "" {
+		schema["description"] = description
+	}
+	if goType != "" {
+		schema[goTypeExtension] = goType
+	}
+	if component != "" {
+		schema[schemaTypeKey] = &schemaType{name: component}
+	}
 
 	return schema
 }
 
+// generateFieldSchema generates the schema of a field of the struct
+// type parent, describing nil pointers by their element type. A nil pointer
+// back to parent itself, as in a linked list, stays an untyped object so the
+// generation terminates.
+func generateFieldSchema(parent reflect.Type, fieldVal reflect.Value) map[string]interface{} {
+	if fieldVal.Kind() != reflect.Ptr || !fieldVal.IsNil() {
+		return generateSchemaForValue(fieldVal)
+	}
+	elem := fieldVal.Type().Elem()
+	if elem == parent {
+		return map[string]interface{}{
+			"type": "object",
+		}
+	}
+	return generateSchemaForValue(reflect.New(elem).Elem())
+}
+
 // applyFieldTags applies struct field tags to the field schema.
 //
 //nolint:gocritic // Passing by value is acceptable for this use case
@@ -215,6 +293,52 @@ func applyFieldTags(schema map[string]interface{}, field reflect.StructField) {
 	if validate := field.Tag.Get("validate"); validate != "" {
 		applyValidationRules(schema, validate)
 	}
+
+	// Apply specification extensions tag
+	if xext := field.Tag.Get("xext"); xext != "" {
+		applyExtensions(schema, xext)
+	}
+}
+
+// applyExtensions emits "x-" specification extensions from a tag such as
+// `xext:"pii=true,classification=confidential"`. A key without a value is
+// treated as a boolean flag.
+func applyExtensions(schema map[string]interface{}, xext string) {
+	for _, pair := range strings.Split(xext, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		key := strings.TrimPrefix(strings.TrimSpace(parts[0]), "x-")
+		if key == "" {
+			continue
+		}
+
+		if len(parts) == 1 {
+			schema["x-"+key] = true
+			continue
+		}
+		schema["x-"+key] = parseScalar(strings.TrimSpace(parts[1]))
+	}
+}
+
+// parseScalar converts a tag value to a boolean or number when possible.
+func parseScalar(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if val, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return val
+	}
+	if val, err := strconv.ParseFloat(value, 64); err == nil {
+		return val
+	}
+	return value
 }
 
 // parseExampleValue converts the example string to the appropriate type.
@@ -336,10 +460,8 @@ func applyValidationRules(schema map[string]interface{}, validate string) {
 		// Enum validations
 		case "oneof", "oneOf":
 			if value != "" {
-				enumValues := strings.Split(value, "|")
 				var typedEnums []interface{}
-				for _, v := range enumValues {
-					v = strings.TrimSpace(v)
+				for _, v := range oneofValues(value) {
 					typedEnums = append(typedEnums, convertToType(v, schemaType))
 				}
 				if len(typedEnums) > 0 {
@@ -486,6 +608,33 @@ func applyValidationRules(schema map[string]interface{}, validate string) {
 	}
 }
 
+// oneofValues splits the value of a oneof rule into its values. Values are
+// separated by spaces, as in go-playground/validator, or by "|", and a value
+// containing spaces is quoted with single quotes: oneof='New York' Paris.
+func oneofValues(value string) []string {
+	var values []string
+	for value != "" {
+		value = strings.TrimLeft(value, " \t|")
+		if value == "" {
+			break
+		}
+		if value[0] == '\'' {
+			if end := strings.IndexByte(value[1:], '\''); end >= 0 {
+				values = append(values, value[1:end+1])
+				value = value[end+2:]
+				continue
+			}
+		}
+		end := strings.IndexAny(value, " \t|")
+		if end < 0 {
+			end = len(value)
+		}
+		values = append(values, value[:end])
+		value = value[end:]
+	}
+	return values
+}
+
 // convertToType converts a string value to the appropriate type based on schema type.
 func convertToType(value, schemaType string) interface{} {
 	switch schemaType {
@@ -539,13 +688,35 @@ func generateArraySchema(val reflect.Value) map[string]interface{} {
 	}
 }
 
-func generateMapSchema(_ reflect.Value) map[string]interface{} {
-	return map[string]interface{}{
-		"type": "object",
-		"additionalProperties": map[string]interface{}{
-			"type": "object",
-		},
+// generateMapSchema describes a map as an object whose values all follow the
+// value type's schema. JSON object keys are always strings, so integer keys
+// are described with a propertyNames pattern.
+func generateMapSchema(val reflect.Value) map[string]interface{} {
+	typ := val.Type()
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": generateSchemaForType(typ.Elem()),
+	}
+
+	//nolint:exhaustive // Only integer keys need a hint; string keys are the default
+	switch typ.Key().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema["propertyNames"] = map[string]interface{}{"pattern": "^-?[0-9]+$"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["propertyNames"] = map[string]interface{}{"pattern": "^[0-9]+$"}
+	}
+	return schema
+}
+
+// isMarshaler reports whether typ encodes itself through json.Marshaler or
+// encoding.TextMarshaler, so its fields say nothing about its JSON form.
+// time.Time keeps its date-time schema and json.RawMessage holds arbitrary JSON.
+func isMarshaler(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Interface || typ == reflect.TypeOf(time.Time{}) || typ == reflect.TypeOf(json.RawMessage{}) {
+		return false
 	}
+	ptr := reflect.PointerTo(typ)
+	return ptr.Implements(jsonMarshalerType) || ptr.Implements(textMarshalerType)
 }
 
 func generateSchemaForType(typ reflect.Type) map[string]interface{} {
@@ -554,6 +725,12 @@ func generateSchemaForType(typ reflect.Type) map[string]interface{} {
 		typ = typ.Elem()
 	}
 
+	if isMarshaler(typ) {
+		return map[string]interface{}{
+			"type": "string",
+		}
+	}
+
 	//nolint:exhaustive // Only handling common types; default case handles others
 	switch typ.Kind() {
 	case reflect.String:
@@ -583,6 +760,13 @@ func generateSchemaForType(typ reflect.Type) map[string]interface{} {
 		// Create a zero value and generate schema
 		zeroVal := reflect.New(typ).Elem()
 		return generateObjectSchema(zeroVal)
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": generateSchemaForType(typ.Elem()),
+		}
+	case reflect.Map:
+		return generateMapSchema(reflect.New(typ).Elem())
 	default:
 		return map[string]interface{}{
 			"type": "object",
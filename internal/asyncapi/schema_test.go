@@ -1,7 +1,12 @@
 package asyncapi
 
 import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 )
@@ -222,6 +227,78 @@ func TestGenerateJSONSchema_NilPointer(t *testing.T) {
 	}
 }
 
+func TestGenerateJSONSchema_PointerFields(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Node struct {
+		Name     string   `json:"name"`
+		Note     *string  `json:"note" validate:"max=10"`
+		Count    *int     `json:"count" required:"true"`
+		Shipping *Address `json:"shipping"`
+		Next     *Node    `json:"next"`
+	}
+
+	schema := GenerateJSONSchema(Node{})
+	properties := schema["properties"].(map[string]interface{})
+
+	tests := []struct {
+		name string
+		want map[string]interface{}
+	}{
+		{"note", map[string]interface{}{"type": []interface{}{"string", "null"}, "maxLength": int64(10)}},
+		{"count", map[string]interface{}{"type": []interface{}{"integer", "null"}}},
+		{"shipping", map[string]interface{}{
+			"type":       []interface{}{"object", "null"},
+			"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+			"required":   []string{"city"},
+		}},
+		{"next", map[string]interface{}{"type": []interface{}{"object", "null"}}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(properties[tt.name], tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, properties[tt.name], tt.want)
+		}
+	}
+	if want := []string{"name", "count"}; !reflect.DeepEqual(schema["required"], want) {
+		t.Errorf("required = %v, want %v", schema["required"], want)
+	}
+}
+
+func TestOpenAPINullable(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"status": nullableSchema(map[string]interface{}{"type": "string", "enum": []interface{}{"open"}}),
+			"owner":  nullableSchema(map[string]interface{}{"$ref": "#/components/schemas/User"}),
+			"tags": map[string]interface{}{
+				"type":  "array",
+				"items": nullableSchema(map[string]interface{}{"type": "integer"}),
+			},
+		},
+	}
+	openAPINullable(schema)
+
+	properties := schema["properties"].(map[string]interface{})
+	tests := []struct {
+		name string
+		got  interface{}
+		want map[string]interface{}
+	}{
+		{"status", properties["status"], map[string]interface{}{"type": "string", "enum": []interface{}{"open"}, "nullable": true}},
+		{"owner", properties["owner"], map[string]interface{}{
+			"allOf":    []interface{}{map[string]interface{}{"$ref": "#/components/schemas/User"}},
+			"nullable": true,
+		}},
+		{"tags items", properties["tags"].(map[string]interface{})["items"], map[string]interface{}{"type": "integer", "nullable": true}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
 func TestGenerateJSONSchema_MsgWrapper(t *testing.T) {
 	type UserEvent struct {
 		UserID string `json:"userId"`
@@ -436,3 +513,685 @@ func TestGenerateMapSchema(t *testing.T) {
 		t.Error("Map schema should have additionalProperties")
 	}
 }
+
+func TestGenerateMapSchemaTypedValues(t *testing.T) {
+	type Item struct {
+		SKU string `json:"sku"`
+	}
+
+	tests := []struct {
+		name          string
+		input         interface{}
+		wantValue     map[string]interface{}
+		wantKeyFormat string
+	}{
+		{"string values", map[string]string{}, map[string]interface{}{"type": "string"}, ""},
+		{"integer keys", map[int]bool{}, map[string]interface{}{"type": "boolean"}, "^-?[0-9]+$"},
+		{"unsigned keys", map[uint32]float64{}, map[string]interface{}{"type": "number"}, "^[0-9]+$"},
+		{"slice values", map[string][]string{}, map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}, ""},
+		{"struct values", map[string]Item{}, map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"sku": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"sku"},
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := GenerateJSONSchema(tt.input)
+			if !reflect.DeepEqual(schema["additionalProperties"], tt.wantValue) {
+				t.Errorf("additionalProperties = %v, want %v", schema["additionalProperties"], tt.wantValue)
+			}
+			propertyNames, _ := schema["propertyNames"].(map[string]interface{})
+			if tt.wantKeyFormat == "" {
+				if propertyNames != nil {
+					t.Errorf("propertyNames = %v, want none", propertyNames)
+				}
+			} else if propertyNames["pattern"] != tt.wantKeyFormat {
+				t.Errorf("propertyNames = %v, want pattern %q", propertyNames, tt.wantKeyFormat)
+			}
+		})
+	}
+}
+
+func TestGenerateJSONSchema_TypeCheckerMaps(t *testing.T) {
+	src := `
+package testpkg
+
+type Region string
+
+type Stock struct {
+	Count int ` + "`json:\"count\"`" + `
+}
+
+type Inventory struct {
+	ByRegion map[Region]Stock    ` + "`json:\"byRegion\"`" + `
+	Labels   map[string][]string ` + "`json:\"labels\"`" + `
+	Shards   []map[int]Stock     ` + "`json:\"shards\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	schema := GenerateJSONSchema(GetByNameType("Inventory", tc))
+	properties := schema["properties"].(map[string]interface{})
+
+	stock := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"count": map[string]interface{}{"type": "integer"},
+		},
+		"required": []string{"count"},
+	}
+	byRegion := properties["byRegion"].(map[string]interface{})
+	if !reflect.DeepEqual(byRegion["additionalProperties"], stock) {
+		t.Errorf("byRegion values = %v, want Stock schema", byRegion["additionalProperties"])
+	}
+	labels := properties["labels"].(map[string]interface{})
+	if values := labels["additionalProperties"].(map[string]interface{}); values["type"] != "array" {
+		t.Errorf("labels values = %v, want array", values)
+	}
+	shards := properties["shards"].(map[string]interface{})
+	items := shards["items"].(map[string]interface{})
+	if !reflect.DeepEqual(items["additionalProperties"], stock) || items["propertyNames"] == nil {
+		t.Errorf("shards items = %v, want Stock values with integer keys", items)
+	}
+}
+
+func TestGenerateJSONSchema_TypeCheckerComposites(t *testing.T) {
+	src := `
+package testpkg
+
+type Stock struct {
+	Count int ` + "`json:\"count\"`" + `
+}
+
+type Page[T any] struct {
+	Items []T ` + "`json:\"items\"`" + `
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K ` + "`json:\"key\"`" + `
+	Value V ` + "`json:\"value\"`" + `
+}
+
+type Node[T any] struct {
+	Value    T         ` + "`json:\"value\"`" + `
+	Children []Node[T] ` + "`json:\"children\"`" + `
+}
+
+type Inventory struct {
+	Matrix  [][]int                        ` + "`json:\"matrix\"`" + `
+	Grid    [3][2]string                   ` + "`json:\"grid\"`" + `
+	Stocks  []*Stock                       ` + "`json:\"stocks\"`" + `
+	Nested  map[string]map[string][]Stock  ` + "`json:\"nested\"`" + `
+	Batches [][]map[string]*Stock          ` + "`json:\"batches\"`" + `
+	Page    Page[Stock]                    ` + "`json:\"page\"`" + `
+	Pages   map[string][]Page[Stock]       ` + "`json:\"pages\"`" + `
+	Pair    Pair[string, []Stock]          ` + "`json:\"pair\"`" + `
+	Deep    Page[Page[int]]                ` + "`json:\"deep\"`" + `
+	Tree    Node[int]                      ` + "`json:\"tree\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	schema := GenerateJSONSchema(GetByNameType("Inventory", tc))
+	properties := schema["properties"].(map[string]interface{})
+
+	stock := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"count": map[string]interface{}{"type": "integer"},
+		},
+		"required": []string{"count"},
+	}
+	array := func(items interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "array", "items": items}
+	}
+	object := func(values interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "object", "additionalProperties": values}
+	}
+	page := func(items interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"items": array(items)},
+			"required":   []string{"items"},
+		}
+	}
+	tests := []struct {
+		property string
+		want     map[string]interface{}
+	}{
+		{"matrix", array(array(map[string]interface{}{"type": "integer"}))},
+		{"grid", array(array(map[string]interface{}{"type": "string"}))},
+		{"stocks", array(stock)},
+		{"nested", object(object(array(stock)))},
+		{"batches", array(array(object(stock)))},
+		{"page", page(stock)},
+		{"pages", object(array(page(stock)))},
+		{"pair", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"key":   map[string]interface{}{"type": "string"},
+				"value": array(stock),
+			},
+			"required": []string{"key", "value"},
+		}},
+		{"deep", page(page(map[string]interface{}{"type": "integer"}))},
+		{"tree", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"value":    map[string]interface{}{"type": "integer"},
+				"children": array(map[string]interface{}{"$ref": "#/components/schemas/NodeInt"}),
+			},
+			"required": []string{"value", "children"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			if got := properties[tt.property]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.property, got, tt.want)
+			}
+		})
+	}
+	if got := tc.componentRefs["NodeInt"]; got != "Node[int]" {
+		t.Errorf("componentRefs[NodeInt] = %q, want %q", got, "Node[int]")
+	}
+}
+
+func TestGenerateJSONSchema_Extensions(t *testing.T) {
+	type Customer struct {
+		Email string `json:"email" xext:"pii=true,classification=confidential"`
+		Score int    `json:"score" xext:"x-retention-days=30,internal"`
+		Name  string `json:"name"`
+	}
+
+	schema := GenerateJSONSchema(Customer{})
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Properties is not a map")
+	}
+
+	email, ok := properties["email"].(map[string]interface{})
+	if !ok {
+		t.Fatal("email property missing")
+	}
+	if email["x-pii"] != true {
+		t.Errorf("x-pii = %v, want true", email["x-pii"])
+	}
+	if email["x-classification"] != "confidential" {
+		t.Errorf("x-classification = %v, want 'confidential'", email["x-classification"])
+	}
+
+	score, ok := properties["score"].(map[string]interface{})
+	if !ok {
+		t.Fatal("score property missing")
+	}
+	if score["x-retention-days"] != int64(30) {
+		t.Errorf("x-retention-days = %v, want 30", score["x-retention-days"])
+	}
+	if score["x-internal"] != true {
+		t.Errorf("x-internal = %v, want true", score["x-internal"])
+	}
+
+	name, ok := properties["name"].(map[string]interface{})
+	if !ok {
+		t.Fatal("name property missing")
+	}
+	for key := range name {
+		if len(key) > 2 && key[:2] == "x-" {
+			t.Errorf("Unexpected extension %q on untagged field", key)
+		}
+	}
+}
+
+func TestGenerateJSONSchema_TypeCheckerKeepsFieldTags(t *testing.T) {
+	src := `
+package testpkg
+
+type Customer struct {
+	Email string ` + "`json:\"email,omitempty\" description:\"Contact email\" xext:\"pii=true\"`" + `
+	ID    string ` + "`json:\"id\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	schema := GenerateJSONSchema(GetByNameType("Customer", tc))
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Properties is not a map")
+	}
+
+	email, ok := properties["email"].(map[string]interface{})
+	if !ok {
+		t.Fatal("email property missing")
+	}
+	if email["description"] != "Contact email" {
+		t.Errorf("description = %v, want 'Contact email'", email["description"])
+	}
+	if email["x-pii"] != true {
+		t.Errorf("x-pii = %v, want true", email["x-pii"])
+	}
+
+	required, ok := schema["required"].([]string)
+	if !ok || len(required) != 1 || required[0] != "id" {
+		t.Errorf("required = %v, want [id]", schema["required"])
+	}
+}
+
+func TestGenerateJSONSchema_TypeCheckerUntaggedFields(t *testing.T) {
+	src := `
+package testpkg
+
+type Audit struct {
+	Actor string ` + "`json:\"actor\"`" + `
+	ID    string
+}
+
+type base struct {
+	Tenant string
+}
+
+type Loop struct {
+	*Loop
+	Name string
+}
+
+type Order struct {
+	ID     string
+	Note   string ` + "`json:\",omitempty\"`" + `
+	Total  int    ` + "`json:\"total\"`" + `
+	Hidden string ` + "`json:\"-\"`" + `
+	Audit
+	*base
+	Loop Loop ` + "`json:\"loop\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+
+	tests := []struct {
+		mode         string
+		wantProps    []string
+		wantRequired []string
+	}{
+		{"", []string{"loop", "total"}, []string{"total", "loop"}},
+		{UntaggedFieldsInclude, []string{"ID", "Note", "Tenant", "actor", "loop", "total"}, []string{"ID", "total", "actor", "Tenant", "loop"}},
+		{UntaggedFieldsSkip, []string{"loop", "total"}, []string{"total", "loop"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+			if err != nil {
+				t.Fatalf("Failed to create type checker: %v", err)
+			}
+			tc.untaggedFields = tt.mode
+
+			schema := GenerateJSONSchema(GetByNameType("Order", tc))
+			properties, _ := schema["properties"].(map[string]interface{})
+			var props []string
+			for name := range properties {
+				props = append(props, name)
+			}
+			sort.Strings(props)
+			if !reflect.DeepEqual(props, tt.wantProps) {
+				t.Errorf("properties = %v, want %v", props, tt.wantProps)
+			}
+			if required, _ := schema["required"].([]string); !reflect.DeepEqual(required, tt.wantRequired) {
+				t.Errorf("required = %v, want %v", required, tt.wantRequired)
+			}
+		})
+	}
+}
+
+func TestGenerateJSONSchema_TypeMappings(t *testing.T) {
+	src := `
+package testpkg
+
+type ID [16]byte
+
+type Money struct {
+	units int64
+}
+
+type Invoice struct {
+	ID     ID               ` + "`json:\"id\"`" + `
+	Total  Money            ` + "`json:\"total\" description:\"Invoice total\"`" + `
+	Lines  []Money          ` + "`json:\"lines\"`" + `
+	Totals map[string]Money ` + "`json:\"totals\"`" + `
+	Parent *ID              ` + "`json:\"parent,omitempty\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+	tc.typeMappings = map[string]map[string]interface{}{
+		"testpkg.ID": {"type": "string", "format": "uuid"},
+		"Money":      {"type": "string", "format": "decimal"},
+	}
+
+	schema := GenerateJSONSchema(GetByNameType("Invoice", tc))
+	properties := schema["properties"].(map[string]interface{})
+
+	uuid := map[string]interface{}{"type": "string", "format": "uuid"}
+	money := map[string]interface{}{"type": "string", "format": "decimal"}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"id", properties["id"], uuid},
+		{"parent", properties["parent"], map[string]interface{}{"type": []interface{}{"string", "null"}, "format": "uuid"}},
+		{"total", properties["total"], map[string]interface{}{"type": "string", "format": "decimal", "description": "Invoice total"}},
+		{"lines", properties["lines"].(map[string]interface{})["items"], money},
+		{"totals", properties["totals"].(map[string]interface{})["additionalProperties"], money},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+type testOrderID struct {
+	raw [16]byte
+}
+
+func (id testOrderID) MarshalText() ([]byte, error) { return []byte("id"), nil }
+
+type testStatus int
+
+func (s *testStatus) MarshalJSON() ([]byte, error) { return []byte(`"open"`), nil }
+
+func TestGenerateJSONSchema_Marshalers(t *testing.T) {
+	type order struct {
+		ID      testOrderID     `json:"id"`
+		Status  testStatus      `json:"status"`
+		Related []testOrderID   `json:"related"`
+		Raw     json.RawMessage `json:"raw"`
+	}
+
+	properties := GenerateJSONSchema(order{})["properties"].(map[string]interface{})
+	str := map[string]interface{}{"type": "string"}
+	if !reflect.DeepEqual(properties["id"], str) {
+		t.Errorf("id = %v, want %v", properties["id"], str)
+	}
+	if !reflect.DeepEqual(properties["status"], str) {
+		t.Errorf("status = %v, want %v", properties["status"], str)
+	}
+	if items := properties["related"].(map[string]interface{})["items"]; !reflect.DeepEqual(items, str) {
+		t.Errorf("related items = %v, want %v", items, str)
+	}
+	if raw := properties["raw"].(map[string]interface{}); raw["type"] == "string" {
+		t.Errorf("raw = %v, want a non-string schema", raw)
+	}
+}
+
+func TestGenerateJSONSchema_TypeCheckerMarshalers(t *testing.T) {
+	src := `
+package testpkg
+
+type OrderID struct {
+	raw [16]byte
+}
+
+func (id OrderID) MarshalText() ([]byte, error) { return nil, nil }
+
+type Status int
+
+func (s *Status) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type Priority int
+
+func (p Priority) MarshalJSON() string { return "" }
+
+type Order struct {
+	ID       OrderID   ` + "`json:\"id\"`" + `
+	Status   *Status   ` + "`json:\"status\"`" + `
+	Related  []OrderID ` + "`json:\"related\"`" + `
+	Priority Priority  ` + "`json:\"priority\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	properties := GenerateJSONSchema(GetByNameType("Order", tc))["properties"].(map[string]interface{})
+	str := map[string]interface{}{"type": "string"}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"id", properties["id"], str},
+		{"status", properties["status"], map[string]interface{}{"type": []interface{}{"string", "null"}}},
+		{"related items", properties["related"].(map[string]interface{})["items"], str},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	// MarshalJSON with the wrong signature does not implement json.Marshaler
+	if priority := properties["priority"].(map[string]interface{}); priority["type"] == "string" {
+		t.Errorf("priority = %v, want a non-string schema", priority)
+	}
+}
+
+func TestGenerateJSONSchema_NamedPrimitives(t *testing.T) {
+	src := `
+package testpkg
+
+// UserID identifies a user.
+// @format uuid
+// @example 0b8e2a52-8d1f-4c1e-9a59-3f2d7a0c9b1e
+type UserID string
+
+// Status is the lifecycle state of an order.
+// @validate min=4
+type Status string
+
+const (
+	StatusOpen   Status = "open"
+	StatusClosed Status = "closed"
+)
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityHigh
+)
+
+type Label = string
+
+type Order struct {
+	UserID   UserID   ` + "`json:\"userId\"`" + `
+	Status   Status   ` + "`json:\"status\" description:\"Current state\"`" + `
+	History  []Status ` + "`json:\"history\"`" + `
+	Priority Priority ` + "`json:\"priority\"`" + `
+	Label    Label    ` + "`json:\"label\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	properties := GenerateJSONSchema(GetByNameType("Order", tc))["properties"].(map[string]interface{})
+	status := map[string]interface{}{
+		"type":        "string",
+		"title":       "Status",
+		"enum":        []interface{}{"open", "closed"},
+		"minLength":   int64(4),
+		"description": "Status is the lifecycle state of an order.",
+	}
+	tests := []struct {
+		name string
+		got  interface{}
+		want map[string]interface{}
+	}{
+		{"userId", properties["userId"], map[string]interface{}{
+			"type":        "string",
+			"title":       "UserID",
+			"format":      "uuid",
+			"example":     "0b8e2a52-8d1f-4c1e-9a59-3f2d7a0c9b1e",
+			"description": "UserID identifies a user.",
+		}},
+		{"status", properties["status"], map[string]interface{}{
+			"type":        "string",
+			"title":       "Status",
+			"enum":        []interface{}{"open", "closed"},
+			"minLength":   int64(4),
+			"description": "Current state",
+		}},
+		{"history items", properties["history"].(map[string]interface{})["items"], status},
+		{"priority", properties["priority"], map[string]interface{}{
+			"type":  "integer",
+			"title": "Priority",
+			"enum":  []interface{}{int64(0), int64(1)},
+		}},
+		{"label", properties["label"], map[string]interface{}{"type": "string", "title": "Label"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestGenerateJSONSchema_DocComments(t *testing.T) {
+	src := `
+package testpkg
+
+// Order is placed by a customer
+// at checkout.
+//
+// Orders are immutable.
+type Order struct {
+	// ID is the order number.
+	ID string ` + "`json:\"id\"`" + `
+	// Ignored in favor of the tag.
+	Total float64 ` + "`json:\"total\" description:\"Total in USD\"`" + `
+	Shipping Address ` + "`json:\"shipping\"`" + `
+	// Billing is where the invoice goes.
+	Billing Address ` + "`json:\"billing\"`" + `
+	Note string ` + "`json:\"note\"`" + ` // Trailing comments are not doc comments.
+}
+
+// Address is a postal address.
+// @validate required
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	schema := GenerateJSONSchema(GetByNameType("Order", tc))
+	properties := schema["properties"].(map[string]interface{})
+	description := func(name string) interface{} {
+		return properties[name].(map[string]interface{})["description"]
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"order", schema["description"], "Order is placed by a customer at checkout.\n\nOrders are immutable."},
+		{"id", description("id"), "ID is the order number."},
+		{"total", description("total"), "Total in USD"},
+		{"shipping", description("shipping"), "Address is a postal address."},
+		{"billing", description("billing"), "Billing is where the invoice goes."},
+		{"note", description("note"), nil},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s description = %#v, want %#v", tt.name, tt.got, tt.want)
+		}
+	}
+	if _, ok := properties[schemaDocField]; ok || len(properties) != 5 {
+		t.Errorf("properties = %v, want the five fields only", properties)
+	}
+}
+
+func TestApplyValidationRulesOneof(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate string
+		typ      string
+		want     []interface{}
+	}{
+		{"spaces", "oneof=UPS FedEx USPS DHL", "string", []interface{}{"UPS", "FedEx", "USPS", "DHL"}},
+		{"quoted", "required,oneof='New York' Paris", "string", []interface{}{"New York", "Paris"}},
+		{"pipes", "oneof=new|paid", "string", []interface{}{"new", "paid"}},
+		{"integers", "oneof=1 2 3", "integer", []interface{}{int64(1), int64(2), int64(3)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := map[string]interface{}{"type": tt.typ}
+			applyValidationRules(schema, tt.validate)
+			if !reflect.DeepEqual(schema["enum"], tt.want) {
+				t.Errorf("enum = %#v, want %#v", schema["enum"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package asyncapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// securitySchemeRef returns a reference to a scheme in components/securitySchemes.
+func securitySchemeRef(name string) spec3.Reference {
+	return spec3.Reference{Ref: "#/components/securitySchemes/" + name}
+}
+
+// parseSecuritySchemeAttr parses a security scheme annotation into schemes.
+// Supported forms (attribute keeps its original case):
+//
+//	@securityScheme.<name> type=<type> [description=...] [in=...] [name=...] [scheme=...] [bearerFormat=...] [openIdConnectUrl=...] [scopes=a,b]
+//	@securityScheme.<name>.flow.<flow> [authorizationUrl=...] [tokenUrl=...] [refreshUrl=...]
+//	@securityScheme.<name>.flow.<flow>.scope <scope> [description]
+func parseSecuritySchemeAttr(attribute, value string, schemes map[string]spec3.SecurityScheme) bool {
+	rest, ok := annotation.CutPrefix(attribute, annotation.SecuritySchemePrefix)
+	if !ok {
+		return false
+	}
+
+	parts := strings.Split(rest, ".")
+	name := parts[0]
+	if name == "" {
+		return false
+	}
+	scheme := schemes[name]
+
+	switch {
+	case len(parts) == 1:
+		applySecuritySchemeFields(&scheme, parseKeyValues(value))
+	case len(parts) == 3 && strings.EqualFold(parts[1], "flow"):
+		flow := securitySchemeFlow(&scheme, parts[2])
+		if flow == nil {
+			return false
+		}
+		fields := parseKeyValues(value)
+		if v, ok := fields["authorizationurl"]; ok {
+			flow.AuthorizationURL = v
+		}
+		if v, ok := fields["tokenurl"]; ok {
+			flow.TokenURL = v
+		}
+		if v, ok := fields["refreshurl"]; ok {
+			flow.RefreshURL = v
+		}
+	case len(parts) == 4 && strings.EqualFold(parts[1], "flow") && strings.EqualFold(parts[3], "scope"):
+		flow := securitySchemeFlow(&scheme, parts[2])
+		if flow == nil {
+			return false
+		}
+		scopeParts := strings.SplitN(value, " ", 2)
+		if scopeParts[0] == "" {
+			return false
+		}
+		if flow.AvailableScopes == nil {
+			flow.AvailableScopes = make(map[string]string)
+		}
+		description := ""
+		if len(scopeParts) > 1 {
+			description = strings.TrimSpace(scopeParts[1])
+		}
+		flow.AvailableScopes[scopeParts[0]] = description
+	default:
+		return false
+	}
+
+	schemes[name] = scheme
+	return true
+}
+
+func applySecuritySchemeFields(scheme *spec3.SecurityScheme, fields map[string]string) {
+	for key, v := range fields {
+		switch key {
+		case "type":
+			scheme.Type = v
+		case "description":
+			scheme.Description = v
+		case "in":
+			scheme.In = v
+		case "name":
+			scheme.Name = v
+		case "scheme":
+			scheme.Scheme = v
+		case "bearerformat":
+			scheme.BearerFormat = v
+		case "openidconnecturl":
+			scheme.OpenIDConnectURL = v
+		case "scopes":
+			scheme.Scopes = nil
+			for _, scope := range strings.Split(v, ",") {
+				if scope = strings.TrimSpace(scope); scope != "" {
+					scheme.Scopes = append(scheme.Scopes, scope)
+				}
+			}
+		}
+	}
+}
+
+// securitySchemeFlow returns the OAuth flow of the given kind, creating it if needed.
+func securitySchemeFlow(scheme *spec3.SecurityScheme, kind string) *spec3.OAuthFlow {
+	if scheme.Flows == nil {
+		scheme.Flows = &spec3.OAuthFlows{}
+	}
+
+	var flow **spec3.OAuthFlow
+	switch strings.ToLower(kind) {
+	case "implicit":
+		flow = &scheme.Flows.Implicit
+	case "password":
+		flow = &scheme.Flows.Password
+	case "clientcredentials":
+		flow = &scheme.Flows.ClientCredentials
+	case "authorizationcode":
+		flow = &scheme.Flows.AuthorizationCode
+	default:
+		return nil
+	}
+
+	if *flow == nil {
+		*flow = &spec3.OAuthFlow{}
+	}
+	return *flow
+}
+
+// parseKeyValues parses "key=value" pairs separated by spaces. Words without
+// "=" continue the previous value, so "description=Broker user credentials"
+// keeps its spaces. Keys are lowercased.
+func parseKeyValues(value string) map[string]string {
+	result := make(map[string]string)
+	lastKey := ""
+	for _, word := range strings.Fields(value) {
+		if idx := strings.Index(word, "="); idx > 0 {
+			lastKey = strings.ToLower(word[:idx])
+			result[lastKey] = word[idx+1:]
+			continue
+		}
+		if lastKey != "" {
+			result[lastKey] += " " + word
+		}
+	}
+	return result
+}
+
+// undefinedSecuritySchemes returns scheme names referenced by servers,
+// operations or operation traits that are not declared in components/securitySchemes.
+func (p *Parser) undefinedSecuritySchemes() []string {
+	missing := make(map[string]bool)
+	check := func(refs []spec3.Reference) {
+		for _, ref := range refs {
+			name := strings.TrimPrefix(ref.Ref, "#/components/securitySchemes/")
+			if _, ok := p.asyncAPI.Components.SecuritySchemes[name]; !ok {
+				missing[name] = true
+			}
+		}
+	}
+
+	for _, server := range p.asyncAPI.Servers {
+		check(server.Security)
+	}
+	for _, op := range p.asyncAPI.Operations {
+		check(op.Security)
+	}
+	for _, trait := range p.asyncAPI.Components.OperationTraits {
+		check(trait.Security)
+	}
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,124 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func TestParseMainWithSecuritySchemes(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@title Secure API",
+		"@version 1.0.0",
+		"@host nats://localhost:4222",
+		"@protocol nats",
+		"@server.security userPassword",
+		"@securityScheme.userPassword type=userPassword description=Broker user credentials",
+		"@securityScheme.apiKey type=httpApiKey name=X-API-Key in=header",
+		"@securityScheme.oauth type=oauth2 description=Identity provider",
+		"@securityScheme.oauth.flow.clientCredentials tokenUrl=https://auth.example.com/token",
+		"@securityScheme.oauth.flow.clientCredentials.scope orders:read Read orders",
+		"@securityScheme.oauth.flow.clientCredentials.scope orders:write Create and update orders",
+	})
+
+	schemes := parser.asyncAPI.Components.SecuritySchemes
+	if len(schemes) != 3 {
+		t.Fatalf("Expected 3 security schemes, got %d: %v", len(schemes), schemes)
+	}
+
+	userPassword := schemes["userPassword"]
+	if userPassword.Type != "userPassword" {
+		t.Errorf("userPassword Type = %q, want %q", userPassword.Type, "userPassword")
+	}
+	if userPassword.Description != "Broker user credentials" {
+		t.Errorf("userPassword Description = %q, want %q", userPassword.Description, "Broker user credentials")
+	}
+
+	apiKey := schemes["apiKey"]
+	if apiKey.Name != "X-API-Key" || apiKey.In != "header" {
+		t.Errorf("apiKey = %+v, want name X-API-Key in header", apiKey)
+	}
+
+	oauth := schemes["oauth"]
+	if oauth.Flows == nil || oauth.Flows.ClientCredentials == nil {
+		t.Fatalf("oauth client credentials flow missing: %+v", oauth)
+	}
+	flow := oauth.Flows.ClientCredentials
+	if flow.TokenURL != "https://auth.example.com/token" {
+		t.Errorf("TokenURL = %q, want %q", flow.TokenURL, "https://auth.example.com/token")
+	}
+	wantScopes := map[string]string{
+		"orders:read":  "Read orders",
+		"orders:write": "Create and update orders",
+	}
+	if !reflect.DeepEqual(flow.AvailableScopes, wantScopes) {
+		t.Errorf("AvailableScopes = %v, want %v", flow.AvailableScopes, wantScopes)
+	}
+
+	var server spec3.Server
+	for _, s := range parser.asyncAPI.Servers {
+		server = s
+	}
+	if len(server.Security) != 1 || server.Security[0].Ref != "#/components/securitySchemes/userPassword" {
+		t.Errorf("server Security = %v, want $ref to userPassword", server.Security)
+	}
+	if missing := parser.undefinedSecuritySchemes(); len(missing) != 0 {
+		t.Errorf("undefinedSecuritySchemes() = %v, want none", missing)
+	}
+}
+
+func TestUndefinedSecuritySchemes(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@title Secure API",
+		"@version 1.0.0",
+		"@host nats://localhost:4222",
+		"@protocol nats",
+		"@server.security userPassword, cert",
+		"@securityScheme.userPassword type=userPassword",
+	})
+
+	missing := parser.undefinedSecuritySchemes()
+	if !reflect.DeepEqual(missing, []string{"cert"}) {
+		t.Errorf("undefinedSecuritySchemes() = %v, want [cert]", missing)
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{
+			name:  "single pair",
+			input: "type=userPassword",
+			want:  map[string]string{"type": "userPassword"},
+		},
+		{
+			name:  "value with spaces",
+			input: "type=httpApiKey description=Key sent by clients name=X-Key",
+			want:  map[string]string{"type": "httpApiKey", "description": "Key sent by clients", "name": "X-Key"},
+		},
+		{
+			name:  "keys are lowercased",
+			input: "bearerFormat=JWT",
+			want:  map[string]string{"bearerformat": "JWT"},
+		},
+		{
+			name:  "leading words ignored",
+			input: "stray type=plain",
+			want:  map[string]string{"type": "plain"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseKeyValues(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKeyValues(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
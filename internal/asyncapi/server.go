@@ -0,0 +1,268 @@
+package asyncapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// defaultServerFields maps the single-server annotations to server fields.
+var defaultServerFields = map[string]string{
+	annotation.URL:                           "host",
+	annotation.Host:                          "host",
+	annotation.Protocol:                      "protocol",
+	annotation.ProtocolVersion:               "protocolversion",
+	annotation.Pathname:                      "pathname",
+	annotation.ServerPathname:                "pathname",
+	annotation.ServerVhost:                   "vhost",
+	annotation.ServerEnvironment:             "environment",
+	annotation.ServerTitle:                   "title",
+	annotation.ServerSummary:                 "summary",
+	annotation.ServerDescription:             "description",
+	annotation.ServerTag:                     "tag",
+	annotation.ServerExternalDocsDescription: "externaldocs.description",
+	annotation.ServerExternalDocsURL:         "externaldocs.url",
+	annotation.ServerVariable:                "variable",
+	annotation.ServerSecurity:                "security",
+	annotation.ServerBinding:                 "binding",
+}
+
+// serverBuilder accumulates server annotations until all comment lines are parsed.
+type serverBuilder struct {
+	server       spec3.Server
+	externalDocs *spec3.ExternalDocs
+	// scheme and path of the host URL, e.g. "amqp" and "/production".
+	scheme, urlPath string
+	// vhost is the AMQP virtual host annotated with vhost.
+	vhost string
+	// environment names the server when it has no @server.name.
+	environment string
+}
+
+// setField applies a server annotation. The field is the lowercase annotation
+// name without the "@" or "@server.<name>." prefix.
+func (b *serverBuilder) setField(field, value string) bool {
+	switch field {
+	case "host", "url":
+		// Strip protocol prefix from host if present (e.g., nats://localhost:4222 -> localhost:4222)
+		b.server.Host, b.scheme = value, ""
+		if idx := strings.Index(value, "://"); idx != -1 {
+			b.server.Host, b.scheme = value[idx+3:], strings.ToLower(value[:idx])
+		}
+		// The path of a URL is the server's pathname, not part of its host
+		b.urlPath = ""
+		if idx := strings.Index(b.server.Host, "/"); idx != -1 {
+			b.server.Host, b.urlPath = b.server.Host[:idx], b.server.Host[idx:]
+		}
+	case "protocol":
+		b.server.Protocol = value
+	case "protocolversion":
+		b.server.ProtocolVersion = value
+	case "pathname":
+		b.server.Pathname = value
+	case "vhost":
+		b.vhost = value
+	case "environment":
+		b.environment = value
+	case "title":
+		b.server.Title = value
+	case "summary":
+		b.server.Summary = value
+	case "description":
+		b.server.Description = value
+	case "tag":
+		b.server.Tags = append(b.server.Tags, parseTag(value))
+	case "externaldocs.description":
+		if b.externalDocs == nil {
+			b.externalDocs = &spec3.ExternalDocs{}
+		}
+		b.externalDocs.Description = value
+	case "externaldocs.url":
+		if b.externalDocs == nil {
+			b.externalDocs = &spec3.ExternalDocs{}
+		}
+		b.externalDocs.URL = value
+	case "variable":
+		// Parse variable in format: "name enum=val1,val2 default=val1 description=Variable description"
+		if b.server.Variables == nil {
+			b.server.Variables = make(map[string]spec3.ServerVar)
+		}
+		parseServerVariable(value, b.server.Variables)
+	case "security":
+		// Parse security scheme names (comma-separated)
+		for _, scheme := range strings.Split(value, ",") {
+			trimmed := strings.TrimSpace(scheme)
+			if trimmed != "" {
+				b.server.Security = append(b.server.Security, securitySchemeRef(trimmed))
+			}
+		}
+	case "binding":
+		// Parse binding in format: "protocol.key value"
+		if b.server.Bindings == nil {
+			b.server.Bindings = make(map[string]interface{})
+		}
+		parseServerBinding(value, b.server.Bindings)
+	default:
+		return false
+	}
+	return true
+}
+
+// build returns the server, or false when no host was annotated.
+func (b *serverBuilder) build() (spec3.Server, bool) {
+	if b.server.Host == "" {
+		return spec3.Server{}, false
+	}
+
+	server := b.server
+	if b.externalDocs != nil && b.externalDocs.URL != "" {
+		server.ExternalDocs = b.externalDocs
+	}
+	if server.Pathname == "" {
+		server.Pathname = b.pathname()
+	}
+	return server, true
+}
+
+// pathname returns the pathname implied by the vhost annotation or the host
+// URL. On AMQP servers the URL path is the percent-encoded virtual host, so
+// both "amqp://host/production" and "amqp://host/%2Fproduction" describe the
+// "/production" vhost.
+func (b *serverBuilder) pathname() string {
+	if b.vhost != "" {
+		return vhostPathname(b.vhost)
+	}
+	if b.urlPath == "" || b.urlPath == "/" {
+		return ""
+	}
+	if !isAMQPServer(b.server.Protocol, b.scheme) {
+		return b.urlPath
+	}
+	vhost, err := url.PathUnescape(b.urlPath[1:])
+	if err != nil {
+		vhost = b.urlPath[1:]
+	}
+	return vhostPathname(vhost)
+}
+
+// vhostPathname returns the server pathname of an AMQP virtual host.
+func vhostPathname(vhost string) string {
+	return "/" + strings.TrimPrefix(vhost, "/")
+}
+
+// isAMQPServer reports whether a server with the protocol, or the URL
+// scheme when no protocol is annotated, is an AMQP broker.
+func isAMQPServer(protocol, scheme string) bool {
+	if protocol == "" {
+		protocol = scheme
+	}
+	switch strings.ToLower(protocol) {
+	case "amqp", "amqps":
+		return true
+	}
+	return false
+}
+
+// Strategies naming the server described by @host/@protocol when it has no
+// @server.name.
+const (
+	// ServerNamingAuto names the server after its @server.environment, or
+	// after its protocol and a hash of its address, which stays stable when
+	// documents of several services are merged.
+	ServerNamingAuto = "auto"
+	// ServerNamingTitle names the server after the API @title, as in
+	// "nats-message-service".
+	ServerNamingTitle = "title"
+)
+
+// validServerNaming reports an error for an unknown server naming strategy.
+func validServerNaming(strategy string) error {
+	switch strategy {
+	case "", ServerNamingAuto, ServerNamingTitle:
+		return nil
+	}
+	return fmt.Errorf("unknown server naming strategy %q (want %s or %s)", strategy, ServerNamingAuto, ServerNamingTitle)
+}
+
+// defaultServerName names the server without @server.name following the
+// strategy.
+func defaultServerName(strategy, environment, title string, server spec3.Server) string {
+	if strategy == ServerNamingTitle {
+		if title == "" {
+			return "default"
+		}
+		return strings.ReplaceAll(strings.ToLower(title), " ", "-")
+	}
+	if environment != "" {
+		return strings.ReplaceAll(strings.ToLower(environment), " ", "-")
+	}
+	protocol := strings.ToLower(server.Protocol)
+	if protocol == "" {
+		protocol = "server"
+	}
+	sum := sha256.Sum256([]byte(server.Protocol + "://" + server.Host + server.Pathname))
+	return protocol + "-" + hex.EncodeToString(sum[:4])
+}
+
+// namedServerAttr splits a "@server.<name>.<field>" annotation. The server
+// name keeps its original case while the field is lowercased.
+//
+//nolint:gocritic // Named returns would reduce readability here
+func namedServerAttr(attribute string) (string, string, bool) {
+	return namedAttr(attribute, annotation.ServerPrefix)
+}
+
+// namedAttr splits a "<prefix><name>.<field>" annotation, where prefix is
+// matched case-insensitively. The name keeps its original case while the
+// field is lowercased.
+//
+//nolint:gocritic // Named returns would reduce readability here
+func namedAttr(attribute, prefix string) (string, string, bool) {
+	rest, ok := annotation.CutPrefix(attribute, prefix)
+	if !ok {
+		return "", "", false
+	}
+
+	idx := strings.Index(rest, ".")
+	if idx <= 0 || idx == len(rest)-1 {
+		return "", "", false
+	}
+	return rest[:idx], strings.ToLower(rest[idx+1:]), true
+}
+
+// parseTag parses a tag in format: "name - description" or just "name".
+func parseTag(value string) spec3.Tag {
+	tagParts := strings.SplitN(value, " - ", 2)
+	tag := spec3.Tag{Name: strings.TrimSpace(tagParts[0])}
+	if len(tagParts) > 1 {
+		tag.Description = strings.TrimSpace(tagParts[1])
+	}
+	return tag
+}
+
+// undefinedChannelServers returns the server names channels are restricted
+// to with @channel.server that are not declared in servers.
+func (p *Parser) undefinedChannelServers() []string {
+	missing := make(map[string]bool)
+	for _, channel := range p.asyncAPI.Channels {
+		for _, ref := range channel.Servers {
+			name := strings.TrimPrefix(ref.Ref, "#/servers/")
+			if _, ok := p.asyncAPI.Servers[name]; !ok {
+				missing[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,115 @@
+package asyncapi
+
+import (
+	"errors"
+	"go/ast"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// isOperationComment reports whether the lines of a comment annotate an
+// operation.
+func isOperationComment(comments []string) bool {
+	for _, line := range comments {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && (strings.EqualFold(fields[0], annotation.Type) || strings.EqualFold(fields[0], annotation.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceDocFiles are the files whose package doc comment holds the
+// service-level annotations of packages without func main, such as shared
+// libraries, even without the keys isGeneralAPIComment looks for.
+var serviceDocFiles = []string{"doc.go", "asyncapi.go"}
+
+// isServiceDocComment reports whether c is the annotated package doc comment
+// of one of the serviceDocFiles, and does not annotate an operation.
+func isServiceDocComment(name string, f *ast.File, c *ast.CommentGroup, comments []string) bool {
+	if f.Doc != c || !slices.Contains(serviceDocFiles, filepath.Base(name)) || isOperationComment(comments) {
+		return false
+	}
+	return slices.ContainsFunc(comments, func(line string) bool {
+		return strings.HasPrefix(strings.TrimSpace(line), "@")
+	})
+}
+
+// declaresMain reports whether f is a file of package main declaring func main.
+func declaresMain(f *ast.File) bool {
+	if f.Name.Name != "main" {
+		return false
+	}
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+// recordOperationFile notes that f annotates operations.
+func (p *Parser) recordOperationFile(f file) {
+	name := filepath.Join(p.sourceDir, f.name)
+	if !slices.Contains(p.operationFiles, name) {
+		p.operationFiles = append(p.operationFiles, name)
+	}
+}
+
+// missingServiceInfoError explains that operations were annotated without
+// the service-level annotations, listing where the operations are and
+// suggesting where the service-level block belongs.
+func (p *Parser) missingServiceInfoError() error {
+	var b strings.Builder
+	b.WriteString("validation failed: no service-level annotations (@title, @version, @host and @protocol) were found, but operations are annotated in:")
+	for _, name := range p.operationFiles {
+		b.WriteString("\n  " + name)
+	}
+	if len(p.mainFiles) > 0 {
+		b.WriteString("\nadd them to the doc comment of func main in " + p.mainFiles[0])
+	} else {
+		b.WriteString("\nadd them to the package doc comment of a doc.go file, or to a comment of its own marked " + annotation.InfoMarker)
+	}
+	b.WriteString(", or set info and servers in the -config file")
+	return errors.New(b.String())
+}
+
+// applyConfiguredInfo fills the info fields the annotations left empty from
+// info, and adds the servers the annotations do not declare.
+func (p *Parser) applyConfiguredInfo(info *spec3.Info, servers map[string]spec3.Server) {
+	if info != nil {
+		current := &p.asyncAPI.Info
+		fillString(&current.Title, info.Title)
+		fillString(&current.Version, info.Version)
+		fillString(&current.Description, info.Description)
+		fillString(&current.TermsOfService, info.TermsOfService)
+		if current.Contact == nil {
+			current.Contact = info.Contact
+		}
+		if current.License == nil {
+			current.License = info.License
+		}
+		if len(current.Tags) == 0 {
+			current.Tags = info.Tags
+		}
+		if current.ExternalDocs == nil {
+			current.ExternalDocs = info.ExternalDocs
+		}
+	}
+	for name, server := range servers {
+		if _, ok := p.asyncAPI.Servers[name]; !ok {
+			p.asyncAPI.Servers[name] = server
+		}
+	}
+}
+
+// fillString sets *field to value when it is empty.
+func fillString(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
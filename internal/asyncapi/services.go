@@ -0,0 +1,80 @@
+package asyncapi
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// serviceRoles records, in merge mode, the services sending and receiving
+// each channel and message, keyed "channels/<key>" or "messages/<name>".
+type serviceRoles struct {
+	producers map[string]map[string]bool
+	consumers map[string]map[string]bool
+}
+
+// serviceName names the service of a merged source directory after the
+// directory, e.g. "orders" for "./services/orders/...".
+func serviceName(root string) string {
+	name := filepath.Base(filepath.Clean(root))
+	if name == "." || name == string(filepath.Separator) {
+		if abs, err := filepath.Abs(root); err == nil {
+			name = filepath.Base(abs)
+		}
+	}
+	return name
+}
+
+// recordService records the service being parsed as a producer of the
+// channel and messages when the action is send, as a consumer otherwise.
+func (p *Parser) recordService(action spec3.OperationAction, channelName string, messageNames ...string) {
+	if p.service == "" {
+		return
+	}
+	roles := &p.serviceRoles.consumers
+	if action == spec3.ActionSend {
+		roles = &p.serviceRoles.producers
+	}
+	if *roles == nil {
+		*roles = make(map[string]map[string]bool)
+	}
+	keys := []string{"channels/" + channelName}
+	for _, name := range messageNames {
+		keys = append(keys, "messages/"+name)
+	}
+	for _, key := range keys {
+		if (*roles)[key] == nil {
+			(*roles)[key] = make(map[string]bool)
+		}
+		(*roles)[key][p.service] = true
+	}
+}
+
+// applyServiceRoles lists the recorded producers and consumers of each
+// channel and component message as x-producers and x-consumers.
+func (p *Parser) applyServiceRoles() {
+	for key, channel := range p.asyncAPI.Channels {
+		channel.Producers = sortedServices(p.serviceRoles.producers["channels/"+key])
+		channel.Consumers = sortedServices(p.serviceRoles.consumers["channels/"+key])
+		p.asyncAPI.Channels[key] = channel
+	}
+	for name, message := range p.asyncAPI.Components.Messages {
+		message.Producers = sortedServices(p.serviceRoles.producers["messages/"+name])
+		message.Consumers = sortedServices(p.serviceRoles.consumers["messages/"+name])
+		p.asyncAPI.Components.Messages[name] = message
+	}
+}
+
+// sortedServices returns the names of a set of services in order.
+func sortedServices(services map[string]bool) []string {
+	if len(services) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
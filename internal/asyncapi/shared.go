@@ -0,0 +1,186 @@
+package asyncapi
+
+import (
+	"reflect"
+	"sort"
+)
+
+// schemaTypeKey holds the marker of the named struct type an object schema
+// describes, from generation until registerSchema records and removes it.
+const schemaTypeKey = "x-asyncapi-doc-type"
+
+// goTypeExtension names the Go type of a struct schema when Options.GoTypes
+// is set.
+const goTypeExtension = "x-go-type"
+
+// schemaType marks the schema of a named struct type.
+type schemaType struct {
+	// name is the component schema name of the type.
+	name string
+	// base is the schema of the type itself when the marked schema is a
+	// field schema, which field tags and nullability may have changed.
+	base map[string]interface{}
+}
+
+// schemaUse is a marked schema inside a registered component schema.
+type schemaUse struct {
+	typ    *schemaType
+	schema map[string]interface{}
+	// parent is the index of the nearest enclosing use, or -1.
+	parent int
+	// component is the name of the component schema when the use is the
+	// component itself, "" for nested uses.
+	component string
+}
+
+// base returns the schema of the type of the use.
+func (use schemaUse) base() map[string]interface{} {
+	if use.typ.base != nil {
+		return use.typ.base
+	}
+	return copySchema(use.schema)
+}
+
+// copySchema returns a shallow copy of schema without its type marker.
+func copySchema(schema map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if key != schemaTypeKey {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// collectSchemaTypes removes the type markers from schema, appending the
+// marked schemas to uses in document order when uses is not nil.
+func collectSchemaTypes(node interface{}, parent int, uses *[]schemaUse) {
+	switch val := node.(type) {
+	case map[string]interface{}:
+		if marker, ok := val[schemaTypeKey].(*schemaType); ok {
+			delete(val, schemaTypeKey)
+			if uses != nil {
+				*uses = append(*uses, schemaUse{typ: marker, schema: val, parent: parent})
+				parent = len(*uses) - 1
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectSchemaTypes(val[key], parent, uses)
+		}
+	case []interface{}:
+		for _, item := range val {
+			collectSchemaTypes(item, parent, uses)
+		}
+	}
+}
+
+// shareSchemas makes each struct type appear once in the document: a type
+// whose schema occurs more than once across the component schemas, such as a
+// type nested in both the request and the reply of an operation, is
+// registered under components/schemas and every occurrence becomes a $ref.
+// Field descriptions and nullability stay on the referencing schema.
+func (p *Parser) shareSchemas() {
+	uses := p.schemaUses
+	if len(uses) == 0 {
+		return
+	}
+
+	// Uses of the same type with the same schema form a group
+	bases := make([]map[string]interface{}, len(uses))
+	group := make([]int, len(uses))
+	var heads []int
+	for i, use := range uses {
+		bases[i] = use.base()
+		group[i] = -1
+		for g, head := range heads {
+			if uses[head].typ.name == use.typ.name && reflect.DeepEqual(bases[head], bases[i]) {
+				group[i] = g
+				break
+			}
+		}
+		if group[i] == -1 {
+			heads = append(heads, i)
+			group[i] = len(heads) - 1
+		}
+	}
+
+	// A group is shared when it occurs more than once outside the copies
+	// replaced by a $ref. Replacing an outer type can leave a nested type
+	// with a single occurrence, so the groups are settled from the outside in.
+	shared := make([]bool, len(heads))
+	canonical := make([]int, len(heads))
+	for iteration := 0; iteration <= len(heads); iteration++ {
+		for g := range canonical {
+			canonical[g] = -1
+		}
+		for i, use := range uses {
+			if use.component != "" && canonical[group[i]] == -1 {
+				canonical[group[i]] = i
+			}
+		}
+		live := make([]bool, len(uses))
+		counts := make([]int, len(heads))
+		for i, use := range uses {
+			parent := use.parent
+			live[i] = parent == -1 || (live[parent] && (!shared[group[parent]] || canonical[group[parent]] == parent))
+			if !live[i] {
+				continue
+			}
+			counts[group[i]]++
+			if canonical[group[i]] == -1 {
+				canonical[group[i]] = i
+			}
+		}
+		changed := false
+		for g := range shared {
+			if isShared := counts[g] > 1; isShared != shared[g] {
+				shared[g] = isShared
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	names := make([]string, len(heads))
+	for g := range heads {
+		if !shared[g] {
+			continue
+		}
+		use := uses[canonical[g]]
+		if use.component != "" {
+			names[g] = use.component
+			continue
+		}
+		names[g] = p.registerSchema(use.typ.name, bases[canonical[g]])
+	}
+
+	for i, use := range uses {
+		g := group[i]
+		if !shared[g] || use.component != "" {
+			continue
+		}
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + names[g]}
+		for key, value := range use.schema {
+			if base, ok := bases[i][key]; key != "type" && (!ok || !reflect.DeepEqual(base, value)) {
+				ref[key] = value
+			}
+		}
+		_, nullable := nullableType(use.schema)
+		for key := range use.schema {
+			delete(use.schema, key)
+		}
+		if nullable {
+			ref = nullableSchema(ref)
+		}
+		for key, value := range ref {
+			use.schema[key] = value
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package spec3
+
+import "gopkg.in/yaml.v3"
+
+// copyComments carries the comments of src, a previous version of the
+// document, over to the matching nodes of dst. Mapping entries are matched by
+// key and sequence items by their name, for lists such as tags, or else by
+// position. Comments of entries that no longer exist are dropped.
+func copyComments(dst, src *yaml.Node) {
+	if dst.Kind != src.Kind {
+		return
+	}
+	copyNodeComments(dst, src)
+	switch dst.Kind {
+	case yaml.DocumentNode:
+		if len(dst.Content) > 0 && len(src.Content) > 0 {
+			copyComments(dst.Content[0], src.Content[0])
+		}
+	case yaml.MappingNode:
+		previous := make(map[string]int, len(src.Content)/2)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			previous[src.Content[i].Value] = i
+		}
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			j, ok := previous[dst.Content[i].Value]
+			if !ok {
+				continue
+			}
+			copyNodeComments(dst.Content[i], src.Content[j])
+			copyComments(dst.Content[i+1], src.Content[j+1])
+		}
+	case yaml.SequenceNode:
+		named := make(map[string]*yaml.Node)
+		for _, item := range src.Content {
+			if name := itemName(item); name != "" {
+				named[name] = item
+			}
+		}
+		for i, item := range dst.Content {
+			if previous, ok := named[itemName(item)]; ok {
+				copyComments(item, previous)
+			} else if itemName(item) == "" && i < len(src.Content) {
+				copyComments(item, src.Content[i])
+			}
+		}
+	case yaml.ScalarNode, yaml.AliasNode:
+	}
+}
+
+// copyNodeComments copies the comments set on src to dst.
+func copyNodeComments(dst, src *yaml.Node) {
+	if src.HeadComment != "" {
+		dst.HeadComment = src.HeadComment
+	}
+	if src.LineComment != "" {
+		dst.LineComment = src.LineComment
+	}
+	if src.FootComment != "" {
+		dst.FootComment = src.FootComment
+	}
+}
+
+// itemName returns the name of a sequence item: the value of a scalar, or the
+// name entry of a mapping, or "" for other items.
+func itemName(item *yaml.Node) string {
+	switch item.Kind {
+	case yaml.ScalarNode:
+		return item.Value
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" && item.Content[i+1].Kind == yaml.ScalarNode {
+				return item.Content[i+1].Value
+			}
+		}
+	case yaml.DocumentNode, yaml.SequenceNode, yaml.AliasNode:
+	}
+	return ""
+}
@@ -0,0 +1,291 @@
+package spec3
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultYAMLIndent is the indentation of the YAML encoder.
+const defaultYAMLIndent = 4
+
+// YAMLOptions configures how MarshalYAMLWith writes the document, so the
+// output can follow the formatting rules of the repository it is written to.
+type YAMLOptions struct {
+	// Indent is the number of spaces per nesting level, 4 when zero.
+	Indent int
+	// LineWidth is the preferred maximum line length. Longer string values
+	// are folded onto continuation lines at spaces; 0 keeps each value on
+	// one line.
+	LineWidth int
+	// FlowMaxKeys writes mappings of at most this many scalar values in flow
+	// style, e.g. {type: string, format: uuid}; 0 writes every mapping in
+	// block style.
+	FlowMaxKeys int
+	// Previous is a previous version of the YAML document, e.g. the file
+	// being regenerated, whose comments are kept on the entries that still
+	// exist.
+	Previous []byte
+}
+
+// MarshalYAMLWith serializes the AsyncAPI document to YAML with the given
+// formatting options.
+func (a *AsyncAPI) MarshalYAMLWith(opts YAMLOptions) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(a); err != nil {
+		return nil, err
+	}
+	literalDescriptions(&node)
+	a.orderKeys(&node)
+	if len(opts.Previous) > 0 {
+		var previous yaml.Node
+		if err := yaml.Unmarshal(opts.Previous, &previous); err != nil {
+			return nil, fmt.Errorf("parse previous document: %w", err)
+		}
+		// Comments at the top of a file belong to the document node
+		root := node
+		node = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&root}}
+		copyComments(&node, &previous)
+	}
+	indent := opts.Indent
+	if indent <= 0 {
+		indent = defaultYAMLIndent
+	}
+	if opts.FlowMaxKeys > 0 {
+		flowLayout{maxKeys: opts.FlowMaxKeys, width: opts.LineWidth, indent: indent}.apply(&node, 0, 0)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	if opts.LineWidth > 0 {
+		return wrapLines(buf.Bytes(), opts.LineWidth, indent), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// flowLayout switches the mappings of at most maxKeys single-line scalar
+// values to flow style when, with a line width set, they fit on their line.
+// The document root and its sections stay in block style.
+type flowLayout struct {
+	maxKeys int
+	width   int
+	indent  int
+}
+
+// apply lays out the entries of a block node starting at column col.
+func (f flowLayout) apply(node *yaml.Node, col, depth int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			f.apply(child, col, depth)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			f.child(node.Content[i+1], col+len(key)+2, col+f.indent, depth+1)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			f.child(item, col+2, col+2, depth+1)
+		}
+	case yaml.ScalarNode, yaml.AliasNode:
+	}
+}
+
+// child lays out a value whose flow form would start at column lineStart and
+// whose block form starts at column blockCol.
+func (f flowLayout) child(node *yaml.Node, lineStart, blockCol, depth int) {
+	if node.Kind == yaml.MappingNode && depth > 1 {
+		if length, ok := f.flowLength(node); ok && (f.width == 0 || lineStart+length <= f.width) {
+			node.Style = yaml.FlowStyle
+			return
+		}
+	}
+	f.apply(node, blockCol, depth)
+}
+
+// flowLength estimates the length of a mapping written in flow style, and
+// reports whether it can be written so.
+func (f flowLayout) flowLength(node *yaml.Node) (int, bool) {
+	if len(node.Content) == 0 || len(node.Content)/2 > f.maxKeys {
+		return 0, false
+	}
+	length := 2
+	for i, child := range node.Content {
+		if child.Kind != yaml.ScalarNode || child.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || strings.Contains(child.Value, "\n") ||
+			child.HeadComment != "" || child.LineComment != "" || child.FootComment != "" {
+			return 0, false
+		}
+		length += len(child.Value)
+		if strings.ContainsAny(child.Value, ":#,[]{}'\"") {
+			length += 2
+		}
+		if i%2 == 0 {
+			length += 2 // ": "
+		} else if i < len(node.Content)-1 {
+			length += 2 // ", "
+		}
+	}
+	return length, true
+}
+
+// wrapLines folds the string values of lines longer than width onto
+// continuation lines, which YAML joins back with single spaces. Only plain
+// and quoted single-line values are folded; block scalars, flow collections
+// and keys are left as they are. A comment kept after a value stays on the
+// last line of the value.
+func wrapLines(data []byte, width, indent int) []byte {
+	lines := strings.Split(string(data), "\n")
+	out := make([]string, 0, len(lines))
+	blockIndent := -1
+	for _, line := range lines {
+		lineIndent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent >= 0 {
+			// Lines of a block scalar are more indented than its key
+			if strings.TrimSpace(line) == "" || lineIndent > blockIndent {
+				out = append(out, line)
+				continue
+			}
+			blockIndent = -1
+		}
+
+		keyStart, valueStart, continuation, ok := splitValue(line, indent)
+		if !ok || strings.HasPrefix(line[lineIndent:], "#") {
+			out = append(out, line)
+			continue
+		}
+		value := line[valueStart:]
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockIndent = keyStart
+			out = append(out, line)
+			continue
+		}
+		value, comment := splitComment(value)
+		if len(line) <= width || !foldable(value) {
+			out = append(out, line)
+			continue
+		}
+		folded := foldValue(line[:valueStart], value, width, continuation)
+		folded[len(folded)-1] += comment
+		out = append(out, folded...)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// splitValue returns where the key and the value of a block line start and
+// the indentation of continuation lines, which must be deeper than the key or
+// sequence entry holding the value. ok is false for lines without a value.
+func splitValue(line string, indent int) (keyStart, valueStart, continuation int, ok bool) {
+	i := len(line) - len(strings.TrimLeft(line, " "))
+	for strings.HasPrefix(line[i:], "- ") {
+		i += 2
+	}
+	keyStart = i
+	rest := line[i:]
+	if rest == "" || rest == "-" || rest[0] == '{' || rest[0] == '[' {
+		return 0, 0, 0, false
+	}
+
+	// Quoted keys may contain ": ", plain keys cannot
+	keyEnd := -1
+	switch rest[0] {
+	case '"', '\'':
+		if end := closingQuote(rest); end > 0 && strings.HasPrefix(rest[end+1:], ": ") {
+			keyEnd = end + 1
+		}
+	default:
+		keyEnd = strings.Index(rest, ": ")
+	}
+	if keyEnd < 0 {
+		if strings.HasSuffix(rest, ":") {
+			return 0, 0, 0, false
+		}
+		// A sequence entry holding a scalar
+		return keyStart - 2, keyStart, keyStart, keyStart > 0
+	}
+	return keyStart, keyStart + keyEnd + 2, keyStart + indent, true
+}
+
+// closingQuote returns the index of the quote closing the quoted scalar that
+// starts s, or -1.
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// splitComment splits the comment written after a value, with the spaces
+// before it, from the value.
+func splitComment(value string) (string, string) {
+	if value == "" {
+		return value, ""
+	}
+	end := -1
+	switch value[0] {
+	case '"', '\'':
+		if quoteEnd := closingQuote(value); quoteEnd > 0 {
+			end = strings.Index(value[quoteEnd+1:], " #")
+			if end >= 0 {
+				end += quoteEnd + 1
+			}
+		}
+	default:
+		end = strings.Index(value, " #")
+	}
+	if end < 0 {
+		return value, ""
+	}
+	return value[:end], value[end:]
+}
+
+// foldable reports whether a value is a single-line plain or quoted scalar.
+func foldable(value string) bool {
+	if value == "" || strings.ContainsAny(value[:1], "{[&*!%@`") {
+		return false
+	}
+	if value[0] == '"' || value[0] == '\'' {
+		return closingQuote(value) == len(value)-1
+	}
+	return true
+}
+
+// foldValue breaks value at single spaces so each line fits width where
+// possible. A break is never put before a word a plain scalar could not
+// continue with, nor after an escaping backslash of a double-quoted one.
+func foldValue(prefix, value string, width, continuation int) []string {
+	words := strings.Split(value, " ")
+	var lines []string
+	current := prefix + words[0]
+	pad := strings.Repeat(" ", continuation)
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		previous := words[i-1]
+		canBreak := word != "" && previous != "" &&
+			!strings.ContainsAny(word[:1], "#-:?") &&
+			!strings.HasSuffix(previous, "\\")
+		if canBreak && len(current)+1+len(word) > width {
+			lines = append(lines, current)
+			current = pad + word
+			continue
+		}
+		current += " " + word
+	}
+	return append(lines, current)
+}
@@ -0,0 +1,155 @@
+package spec3
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshalYAMLWith(t *testing.T) {
+	doc := NewAsyncAPI()
+	doc.Info.Title = "Orders"
+	doc.Info.Version = "1.0.0"
+	doc.Info.Description = "Order events published by the checkout service whenever an order is placed, paid or shipped."
+	doc.Info.Tags = []Tag{{Name: "orders", Description: "Events of the order lifecycle: placement, payment and shipping, see #42 - for details."}}
+	doc.Channels["orderPlaced"] = Channel{
+		Address:     "order.placed",
+		Description: "First line of a literal description that is much longer than the configured width.\nSecond line.",
+		Parameters:  map[string]Parameter{"region": {Description: "Region", Enum: []string{"eu", "us"}}},
+	}
+	doc.Components.Schemas["Order"] = map[string]interface{}{
+		"type":        "object",
+		"description": `Quoted "value": with a colon and a \backslash that needs double quotes to be written at all`,
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{"type": "string", "format": "uuid"},
+		},
+		"examples": []interface{}{"a plain example string in a sequence that is long enough to be folded twice over"},
+	}
+
+	plain, err := doc.MarshalYAML()
+	if err != nil {
+		t.Fatalf("MarshalYAML() error = %v", err)
+	}
+	opts := YAMLOptions{Indent: 2, LineWidth: 40, FlowMaxKeys: 2}
+	formatted, err := doc.MarshalYAMLWith(opts)
+	if err != nil {
+		t.Fatalf("MarshalYAMLWith() error = %v", err)
+	}
+	got := string(formatted)
+
+	for _, want := range []string{
+		"  title: Orders\n",
+		"id: {format: uuid, type: string}",
+		"region:\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("MarshalYAMLWith() missing %q\n%s", want, got)
+		}
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if len(line) > opts.LineWidth && strings.Contains(strings.TrimSpace(line), " ") && !strings.HasPrefix(strings.TrimSpace(line), "First line") {
+			t.Errorf("line %q is longer than %d", line, opts.LineWidth)
+		}
+	}
+
+	var want, reparsed interface{}
+	if err := yaml.Unmarshal(plain, &want); err != nil {
+		t.Fatalf("Unmarshal(plain) error = %v", err)
+	}
+	if err := yaml.Unmarshal(formatted, &reparsed); err != nil {
+		t.Fatalf("Unmarshal(formatted) error = %v\n%s", err, got)
+	}
+	if !reflect.DeepEqual(reparsed, want) {
+		t.Errorf("formatted document = %v, want %v\n%s", reparsed, want, got)
+	}
+}
+
+func TestMarshalYAMLWithPrevious(t *testing.T) {
+	doc := NewAsyncAPI()
+	doc.Info.Title = "Orders"
+	doc.Info.Version = "1.1.0"
+	doc.Info.Tags = []Tag{{Name: "billing"}, {Name: "orders"}}
+	doc.Channels["orderPlaced"] = Channel{Address: "order.placed"}
+
+	previous := `# Reviewed by the platform team: do not rename channels without a migration note.
+
+asyncapi: 3.0.0
+# Owned by checkout
+info:
+    title: Orders # public name
+    version: 1.0.0
+    tags:
+        - name: orders # keep first
+channels:
+    # Deprecated, remove in 2.0
+    orderCancelled:
+        address: order.cancelled
+    orderPlaced:
+        # Partitioned by order id
+        address: order.placed
+`
+	got, err := doc.MarshalYAMLWith(YAMLOptions{LineWidth: 40, Previous: []byte(previous)})
+	if err != nil {
+		t.Fatalf("MarshalYAMLWith() error = %v", err)
+	}
+	for _, want := range []string{
+		"# Reviewed by the platform team: do not rename channels without a migration note.\n\nasyncapi: 3.0.0\n",
+		"# Owned by checkout\ninfo:\n",
+		"title: Orders # public name\n",
+		"version: 1.1.0\n",
+		"- name: orders # keep first\n",
+		"    # Partitioned by order id\n        address: order.placed\n",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("MarshalYAMLWith() missing %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(string(got), "Deprecated") {
+		t.Errorf("MarshalYAMLWith() kept the comment of a removed channel\n%s", got)
+	}
+
+	if _, err := doc.MarshalYAMLWith(YAMLOptions{Previous: []byte("info: [")}); err == nil {
+		t.Error("MarshalYAMLWith() with an invalid previous document succeeded, want error")
+	}
+}
+
+func TestMarshalYAMLWithPreviousWrapsCommentedLines(t *testing.T) {
+	doc := NewAsyncAPI()
+	doc.Info.Title = "Orders"
+	doc.Info.Version = "1.0.0"
+	doc.Info.Description = "Order events published by the checkout service whenever an order is placed"
+	doc.Channels["orderPlaced"] = Channel{Address: "order.placed", Title: `Placed: orders accepted by "checkout" and waiting for payment`}
+
+	previous := `asyncapi: 3.0.0
+info:
+    title: Orders
+    version: 1.0.0
+    description: Order events # reviewed by the platform team # twice
+channels:
+    orderPlaced:
+        address: order.placed
+        title: Placed # shown in the catalog
+`
+	got, err := doc.MarshalYAMLWith(YAMLOptions{LineWidth: 40, Previous: []byte(previous)})
+	if err != nil {
+		t.Fatalf("MarshalYAMLWith() error = %v", err)
+	}
+	for _, want := range []string{"placed # reviewed by the platform team # twice\n", "payment' # shown in the catalog\n"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("MarshalYAMLWith() missing %q\n%s", want, got)
+		}
+	}
+
+	reparsed, err := Parse(got)
+	if err != nil {
+		t.Fatalf("Parse() error = %v\n%s", err, got)
+	}
+	if reparsed.Info.Description != doc.Info.Description {
+		t.Errorf("description = %q, want %q\n%s", reparsed.Info.Description, doc.Info.Description, got)
+	}
+	if title := reparsed.Channels["orderPlaced"].Title; title != doc.Channels["orderPlaced"].Title {
+		t.Errorf("channel title = %q, want %q\n%s", title, doc.Channels["orderPlaced"].Title, got)
+	}
+}
@@ -0,0 +1,190 @@
+package spec3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// orderKeys reorders the channels and operations of the encoded document
+// following ChannelOrder and OperationOrder.
+func (a *AsyncAPI) orderKeys(node *yaml.Node) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch node.Content[i].Value {
+		case "channels":
+			orderMapping(node.Content[i+1], a.ChannelOrder)
+		case "operations":
+			orderMapping(node.Content[i+1], a.OperationOrder)
+		}
+	}
+}
+
+// orderMapping moves the listed keys of a mapping node to its start, in the
+// listed order. The other keys keep their relative order after them.
+func orderMapping(node *yaml.Node, order []string) {
+	if node.Kind != yaml.MappingNode || len(order) == 0 {
+		return
+	}
+	pairs := make(map[string][]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs[node.Content[i].Value] = node.Content[i : i+2]
+	}
+	content := make([]*yaml.Node, 0, len(node.Content))
+	listed := make(map[string]bool, len(order))
+	for _, key := range order {
+		if pair, ok := pairs[key]; ok && !listed[key] {
+			listed[key] = true
+			content = append(content, pair...)
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !listed[node.Content[i].Value] {
+			content = append(content, node.Content[i:i+2]...)
+		}
+	}
+	node.Content = content
+}
+
+// marshalOrderedJSON writes the document as indented JSON with its channels
+// and operations in order. encoding/json always sorts map keys and cannot
+// inline the Extensions maps, so the JSON is written from the ordered YAML
+// node of the document instead.
+func (a *AsyncAPI) marshalOrderedJSON() ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(a); err != nil {
+		return nil, err
+	}
+	a.orderKeys(&node)
+
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, &node); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// hasExtensions reports whether the info, a server, channel, operation or
+// component message of the document has specification extensions.
+func (a *AsyncAPI) hasExtensions() bool {
+	if len(a.Info.Extensions) > 0 {
+		return true
+	}
+	for _, server := range a.Servers {
+		if len(server.Extensions) > 0 {
+			return true
+		}
+	}
+	for _, channel := range a.Channels {
+		if len(channel.Extensions) > 0 {
+			return true
+		}
+	}
+	for _, operation := range a.Operations {
+		if len(operation.Extensions) > 0 {
+			return true
+		}
+	}
+	if a.Components == nil {
+		return false
+	}
+	for _, message := range a.Components.Messages {
+		if len(message.Extensions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONNode writes a YAML node encoded from Go values as JSON.
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSONNode(buf, node.Content[0])
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSONNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONNode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		return writeJSONScalar(buf, node)
+	case yaml.AliasNode:
+		return writeJSONNode(buf, node.Alias)
+	default:
+		return fmt.Errorf("unexpected YAML node kind %d", node.Kind)
+	}
+	return nil
+}
+
+// writeJSONScalar writes a scalar node as the JSON value of its tag.
+func writeJSONScalar(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Tag {
+	case "!!null":
+		buf.WriteString("null")
+		return nil
+	case "!!bool":
+		value, err := strconv.ParseBool(node.Value)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatBool(value))
+		return nil
+	case "!!int", "!!float":
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		return nil
+	}
+	data, err := json.Marshal(node.Value)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
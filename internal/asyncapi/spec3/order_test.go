@@ -0,0 +1,106 @@
+package spec3
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalOrderedKeys(t *testing.T) {
+	doc := NewAsyncAPI()
+	doc.Info.Title = "Orders"
+	for _, key := range []string{"orderPlaced", "orderShipped", "auditLog"} {
+		doc.Channels[key] = Channel{Address: key}
+		doc.Operations["publish"+key] = Operation{Action: ActionSend, Channel: Reference{Ref: "#/channels/" + key}}
+	}
+	doc.Components.Schemas["Order"] = map[string]interface{}{"type": "object", "maxItems": 3, "x-ratio": 0.5, "x-flag": true}
+	doc.ChannelOrder = []string{"orderShipped", "orderPlaced"}
+	doc.OperationOrder = []string{"publishorderShipped", "publishorderPlaced", "publishauditLog"}
+
+	yamlData, err := doc.MarshalYAML()
+	if err != nil {
+		t.Fatalf("MarshalYAML() error = %v", err)
+	}
+	jsonData, err := doc.MarshalJSONIndent()
+	if err != nil {
+		t.Fatalf("MarshalJSONIndent() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		keys []string
+	}{
+		{"yaml channels", string(yamlData), []string{"    orderShipped:", "    orderPlaced:", "    auditLog:"}},
+		{"yaml operations", string(yamlData), []string{"publishorderShipped:", "publishorderPlaced:", "publishauditLog:"}},
+		{"json channels", string(jsonData), []string{`"orderShipped": {`, `"orderPlaced": {`, `"auditLog": {`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := -1
+			for _, key := range tt.keys {
+				i := strings.Index(tt.data, key)
+				if i <= last {
+					t.Fatalf("%q at %d, want after %d\n%s", key, i, last, tt.data)
+				}
+				last = i
+			}
+		})
+	}
+
+	var got, want map[string]interface{}
+	if err := json.Unmarshal(jsonData, &got); err != nil {
+		t.Fatalf("ordered JSON is invalid: %v", err)
+	}
+	doc.ChannelOrder, doc.OperationOrder = nil, nil
+	plain, err := doc.MarshalJSONIndent()
+	if err != nil {
+		t.Fatalf("MarshalJSONIndent() error = %v", err)
+	}
+	if err := json.Unmarshal(plain, &want); err != nil {
+		t.Fatalf("JSON is invalid: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ordered JSON = %v, want %v", got, want)
+	}
+}
+
+func TestMarshalExtensions(t *testing.T) {
+	doc := NewAsyncAPI()
+	doc.Info.Title = "Orders"
+	doc.Info.Extensions = map[string]interface{}{"x-team": "payments"}
+	doc.Channels["orderPlaced"] = Channel{
+		Address:    "order.placed",
+		Extensions: map[string]interface{}{"x-slo": map[string]interface{}{"latency": "100ms"}},
+	}
+
+	yamlData, err := doc.MarshalYAML()
+	if err != nil {
+		t.Fatalf("MarshalYAML() error = %v", err)
+	}
+	for _, want := range []string{"    x-team: payments\n", "        x-slo:\n            latency: 100ms\n"} {
+		if !strings.Contains(string(yamlData), want) {
+			t.Errorf("MarshalYAML() missing %q\n%s", want, yamlData)
+		}
+	}
+
+	jsonData, err := doc.MarshalJSONIndent()
+	if err != nil {
+		t.Fatalf("MarshalJSONIndent() error = %v", err)
+	}
+	var got struct {
+		Info     map[string]interface{}            `json:"info"`
+		Channels map[string]map[string]interface{} `json:"channels"`
+	}
+	if err := json.Unmarshal(jsonData, &got); err != nil {
+		t.Fatalf("MarshalJSONIndent() is invalid: %v", err)
+	}
+	if got.Info["x-team"] != "payments" {
+		t.Errorf("info x-team = %v, want payments", got.Info["x-team"])
+	}
+	want := map[string]interface{}{"latency": "100ms"}
+	if slo := got.Channels["orderPlaced"]["x-slo"]; !reflect.DeepEqual(slo, want) {
+		t.Errorf("channel x-slo = %v, want %v", slo, want)
+	}
+}
@@ -3,7 +3,11 @@
 // Reference: https://www.asyncapi.com/docs/reference/specification/v3.0.0
 package spec3
 
-import "gopkg.in/yaml.v3"
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
 
 // AsyncAPI represents the root object of an AsyncAPI 3.0.0 document.
 // Note: In AsyncAPI 3.0.0, tags and externalDocs are now part of the Info object, not at the root level.
@@ -16,6 +20,12 @@ type AsyncAPI struct {
 	Channels           map[string]Channel   `json:"channels,omitempty" yaml:"channels,omitempty"`
 	Operations         map[string]Operation `json:"operations,omitempty" yaml:"operations,omitempty"`
 	Components         *Components          `json:"components,omitempty" yaml:"components,omitempty"`
+
+	// ChannelOrder and OperationOrder list the keys of Channels and
+	// Operations in the order to write them; unlisted keys follow sorted.
+	// Without them both maps are written sorted by key.
+	ChannelOrder   []string `json:"-" yaml:"-"`
+	OperationOrder []string `json:"-" yaml:"-"`
 }
 
 // NewAsyncAPI creates a new AsyncAPI 3.0.0 document with default values.
@@ -34,14 +44,15 @@ func NewAsyncAPI() *AsyncAPI {
 
 // Info provides metadata about the API.
 type Info struct {
-	Title          string        `json:"title" yaml:"title"`
-	Version        string        `json:"version" yaml:"version"`
-	Description    string        `json:"description,omitempty" yaml:"description,omitempty"`
-	TermsOfService string        `json:"termsOfService,omitempty" yaml:"termsOfService,omitempty"`
-	Contact        *Contact      `json:"contact,omitempty" yaml:"contact,omitempty"`
-	License        *License      `json:"license,omitempty" yaml:"license,omitempty"`
-	Tags           []Tag         `json:"tags,omitempty" yaml:"tags,omitempty"`
-	ExternalDocs   *ExternalDocs `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	Title          string                 `json:"title" yaml:"title"`
+	Version        string                 `json:"version" yaml:"version"`
+	Description    string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	TermsOfService string                 `json:"termsOfService,omitempty" yaml:"termsOfService,omitempty"`
+	Contact        *Contact               `json:"contact,omitempty" yaml:"contact,omitempty"`
+	License        *License               `json:"license,omitempty" yaml:"license,omitempty"`
+	Tags           []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ExternalDocs   *ExternalDocs          `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	Extensions     map[string]interface{} `json:"-" yaml:",inline"`
 }
 
 // Contact information for the exposed API.
@@ -68,10 +79,11 @@ type Server struct {
 	Title           string                 `json:"title,omitempty" yaml:"title,omitempty"`
 	Summary         string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
 	Variables       map[string]ServerVar   `json:"variables,omitempty" yaml:"variables,omitempty"`
-	Security        []map[string][]string  `json:"security,omitempty" yaml:"security,omitempty"`
+	Security        []Reference            `json:"security,omitempty" yaml:"security,omitempty"`
 	Tags            []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
 	ExternalDocs    *ExternalDocs          `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
 	Bindings        map[string]interface{} `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+	Extensions      map[string]interface{} `json:"-" yaml:",inline"`
 }
 
 // ServerVar represents a server variable for server URL template substitution.
@@ -98,15 +110,19 @@ type ExternalDocs struct {
 // Channel represents a channel in AsyncAPI 3.0.
 // In 3.0, channels are separate from operations and only define the address and messages.
 type Channel struct {
-	Address     string                 `json:"address,omitempty" yaml:"address,omitempty"`
-	Title       string                 `json:"title,omitempty" yaml:"title,omitempty"`
-	Summary     string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
-	Description string                 `json:"description,omitempty" yaml:"description,omitempty"`
-	Messages    map[string]MessageRef  `json:"messages,omitempty" yaml:"messages,omitempty"`
-	Parameters  map[string]Parameter   `json:"parameters,omitempty" yaml:"parameters,omitempty"`
-	Servers     []Reference            `json:"servers,omitempty" yaml:"servers,omitempty"`
-	Tags        []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
-	Bindings    map[string]interface{} `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+	Address      string                 `json:"address,omitempty" yaml:"address,omitempty"`
+	Title        string                 `json:"title,omitempty" yaml:"title,omitempty"`
+	Summary      string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Messages     map[string]MessageRef  `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Parameters   map[string]Parameter   `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Servers      []Reference            `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Tags         []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	Bindings     map[string]interface{} `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+	Producers    []string               `json:"x-producers,omitempty" yaml:"x-producers,omitempty"`
+	Consumers    []string               `json:"x-consumers,omitempty" yaml:"x-consumers,omitempty"`
+	Extensions   map[string]interface{} `json:"-" yaml:",inline"`
 }
 
 // Parameter represents a channel parameter.
@@ -132,9 +148,12 @@ type Operation struct {
 	Traits       []Reference            `json:"traits,omitempty" yaml:"traits,omitempty"`
 	Tags         []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
 	Bindings     map[string]interface{} `json:"bindings,omitempty" yaml:"bindings,omitempty"`
-	Security     []map[string][]string  `json:"security,omitempty" yaml:"security,omitempty"`
+	Security     []Reference            `json:"security,omitempty" yaml:"security,omitempty"`
 	ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
 	Deprecated   bool                   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Timeout      string                 `json:"x-timeout,omitempty" yaml:"x-timeout,omitempty"`
+	QoS          *int                   `json:"x-qos,omitempty" yaml:"x-qos,omitempty"`
+	Extensions   map[string]interface{} `json:"-" yaml:",inline"`
 }
 
 // OperationAction represents the action type of an operation.
@@ -173,6 +192,26 @@ type Message struct {
 	Tags          []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
 	Bindings      map[string]interface{} `json:"bindings,omitempty" yaml:"bindings,omitempty"`
 	Traits        []Reference            `json:"traits,omitempty" yaml:"traits,omitempty"`
+	Examples      []MessageExample       `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Error         *MessageError          `json:"x-error,omitempty" yaml:"x-error,omitempty"`
+	PartitionKey  string                 `json:"x-partition-key,omitempty" yaml:"x-partition-key,omitempty"`
+	Producers     []string               `json:"x-producers,omitempty" yaml:"x-producers,omitempty"`
+	Consumers     []string               `json:"x-consumers,omitempty" yaml:"x-consumers,omitempty"`
+	Extensions    map[string]interface{} `json:"-" yaml:",inline"`
+}
+
+// MessageError marks a reply message as an error variant (x-error extension).
+type MessageError struct {
+	Type      string `json:"type,omitempty" yaml:"type,omitempty"`
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+}
+
+// MessageExample represents an example of a message payload and headers.
+type MessageExample struct {
+	Name    string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	Summary string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Headers map[string]interface{} `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Payload interface{}            `json:"payload,omitempty" yaml:"payload,omitempty"`
 }
 
 // MessageRef can be either a direct Message or a Reference.
@@ -181,10 +220,12 @@ type MessageRef struct {
 	Message *Message `json:"-" yaml:"-"`
 }
 
-// CorrelationID specifies an identifier for message correlation.
+// CorrelationID specifies an identifier for message correlation. Ref, when
+// set, references a definition under components/correlationIds instead.
 type CorrelationID struct {
+	Ref         string `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 	Description string `json:"description,omitempty" yaml:"description,omitempty"`
-	Location    string `json:"location" yaml:"location"`
+	Location    string `json:"location,omitempty" yaml:"location,omitempty"`
 }
 
 // Reference represents a $ref to another object.
@@ -243,12 +284,13 @@ type OAuthFlow struct {
 
 // OperationTrait represents an operation trait for reuse.
 type OperationTrait struct {
-	Title       string                 `json:"title,omitempty" yaml:"title,omitempty"`
-	Summary     string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
-	Description string                 `json:"description,omitempty" yaml:"description,omitempty"`
-	Tags        []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
-	Bindings    map[string]interface{} `json:"bindings,omitempty" yaml:"bindings,omitempty"`
-	Security    []map[string][]string  `json:"security,omitempty" yaml:"security,omitempty"`
+	Title        string                 `json:"title,omitempty" yaml:"title,omitempty"`
+	Summary      string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags         []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Bindings     map[string]interface{} `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+	Security     []Reference            `json:"security,omitempty" yaml:"security,omitempty"`
+	ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
 }
 
 // MessageTrait represents a message trait for reuse.
@@ -262,9 +304,32 @@ type MessageTrait struct {
 	CorrelationID *CorrelationID         `json:"correlationId,omitempty" yaml:"correlationId,omitempty"`
 	Tags          []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
 	Bindings      map[string]interface{} `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+	ExternalDocs  *ExternalDocs          `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
 }
 
-// MarshalYAML serializes the AsyncAPI document to YAML format.
+// MarshalYAML serializes the AsyncAPI document to YAML format. Multi-line
+// descriptions are written as literal block scalars.
 func (a *AsyncAPI) MarshalYAML() ([]byte, error) {
-	return yaml.Marshal(a)
+	return a.MarshalYAMLWith(YAMLOptions{})
+}
+
+// MarshalJSONIndent serializes the AsyncAPI document to indented JSON format.
+func (a *AsyncAPI) MarshalJSONIndent() ([]byte, error) {
+	if len(a.ChannelOrder) > 0 || len(a.OperationOrder) > 0 || a.hasExtensions() {
+		return a.marshalOrderedJSON()
+	}
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Parse reads an AsyncAPI document from YAML (or JSON, which is a subset of YAML).
+func Parse(data []byte) (*AsyncAPI, error) {
+	doc := &AsyncAPI{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
 }
@@ -0,0 +1,34 @@
+package spec3
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// literalDescriptions switches multi-line description values in the encoded
+// document to literal block scalars (|), so they are written as readable text
+// instead of quoted strings with escaped newlines. The YAML encoder only picks
+// the literal style on its own when no line has trailing whitespace and the
+// text uses plain \n line endings, so both are normalized first.
+func literalDescriptions(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "description" && value.Kind == yaml.ScalarNode && value.Tag == "!!str" {
+				text := strings.ReplaceAll(value.Value, "\r\n", "\n")
+				if strings.Contains(text, "\n") {
+					lines := strings.Split(text, "\n")
+					for j, line := range lines {
+						lines[j] = strings.TrimRight(line, " \t")
+					}
+					value.Value = strings.Join(lines, "\n")
+					value.Style = yaml.LiteralStyle
+				}
+			}
+		}
+	}
+	for _, child := range node.Content {
+		literalDescriptions(child)
+	}
+}
@@ -0,0 +1,39 @@
+package spec3
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarshalYAMLMultiLineDescriptions(t *testing.T) {
+	doc := NewAsyncAPI()
+	doc.Info.Title = "Orders"
+	doc.Info.Description = "Order events.  \r\n\r\nSee the **runbook** for details."
+	doc.Components.Schemas["Order"] = map[string]interface{}{
+		"type":        "object",
+		"description": "An order.\nCreated at checkout.",
+	}
+
+	data, err := doc.MarshalYAML()
+	if err != nil {
+		t.Fatalf("MarshalYAML() error = %v", err)
+	}
+	got := string(data)
+
+	for _, want := range []string{
+		"    description: |-\n        Order events.\n\n        See the **runbook** for details.\n",
+		"            description: |-\n                An order.\n                Created at checkout.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("MarshalYAML() missing %q\n%s", want, got)
+		}
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := "Order events.\n\nSee the **runbook** for details."; parsed.Info.Description != want {
+		t.Errorf("Info.Description = %q, want %q", parsed.Info.Description, want)
+	}
+}
@@ -0,0 +1,256 @@
+package asyncapi
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// Modes of Options.VerifySubjects.
+const (
+	// VerifySubjectsWarn reports broker calls whose subject does not match
+	// the @name of their operation as warnings.
+	VerifySubjectsWarn = "warn"
+	// VerifySubjectsError fails the generation on them.
+	VerifySubjectsError = "error"
+)
+
+// ErrSubjectMismatch is returned in the VerifySubjectsError mode when a
+// broker call uses a subject other than the @name of its operation.
+var ErrSubjectMismatch = errors.New("broker calls do not match their @name")
+
+// validVerifySubjects reports an error for an unknown verification mode.
+func validVerifySubjects(mode string) error {
+	switch mode {
+	case "", VerifySubjectsWarn, VerifySubjectsError:
+		return nil
+	}
+	return fmt.Errorf("unknown subject verification %q (want %s or %s)", mode, VerifySubjectsWarn, VerifySubjectsError)
+}
+
+// Import paths of the broker clients whose calls are verified.
+var (
+	natsPackages      = []string{"github.com/nats-io/nats.go"}
+	jetStreamPackages = []string{"github.com/nats-io/nats.go/jetstream"}
+	kafkaGoPackages   = []string{"github.com/segmentio/kafka-go"}
+	saramaPackages    = []string{"github.com/IBM/sarama", "github.com/Shopify/sarama"}
+	confluentPackages = []string{"github.com/confluentinc/confluent-kafka-go/kafka", "github.com/confluentinc/confluent-kafka-go/v2/kafka"}
+	amqpPackages      = []string{"github.com/rabbitmq/amqp091-go", "github.com/streadway/amqp"}
+)
+
+// brokerCall is a client method taking the subject, topic or queue of the
+// messages it sends or receives.
+type brokerCall struct {
+	packages []string
+	name     string
+	// send tells calls of "pub" operations from those of "sub" ones.
+	send bool
+	// args are the indexes of the arguments naming the channel; the call
+	// matches when any of them does, e.g. the exchange or the routing key
+	// of an AMQP publish.
+	args []int
+}
+
+var brokerCalls = []brokerCall{
+	{natsPackages, "Publish", true, []int{0}},
+	{natsPackages, "Request", true, []int{0}},
+	{natsPackages, "RequestWithContext", true, []int{1}},
+	{natsPackages, "Subscribe", false, []int{0}},
+	{natsPackages, "SubscribeSync", false, []int{0}},
+	{natsPackages, "ChanSubscribe", false, []int{0}},
+	{natsPackages, "QueueSubscribe", false, []int{0}},
+	{natsPackages, "QueueSubscribeSync", false, []int{0}},
+	{natsPackages, "ChanQueueSubscribe", false, []int{0}},
+	{natsPackages, "PullSubscribe", false, []int{0}},
+	{jetStreamPackages, "Publish", true, []int{1}},
+	{jetStreamPackages, "PublishAsync", true, []int{0}},
+	{saramaPackages, "ConsumePartition", false, []int{0}},
+	{confluentPackages, "Subscribe", false, []int{0}},
+	{amqpPackages, "Publish", true, []int{0, 1}},
+	{amqpPackages, "PublishWithContext", true, []int{1, 2}},
+	{amqpPackages, "Consume", false, []int{0}},
+	{amqpPackages, "ConsumeWithContext", false, []int{1}},
+}
+
+// brokerField is a struct field naming the topic of the messages sent or
+// received, as in kafka.Message{Topic: "orders"}.
+type brokerField struct {
+	packages []string
+	typeName string
+	field    string
+	send     bool
+}
+
+var brokerFields = []brokerField{
+	{kafkaGoPackages, "Message", "Topic", true},
+	{kafkaGoPackages, "Writer", "Topic", true},
+	{kafkaGoPackages, "ReaderConfig", "Topic", false},
+	{saramaPackages, "ProducerMessage", "Topic", true},
+}
+
+// verifyFunctionSubjects checks the broker calls in the body of the function
+// documented by the operation comment c against its @name.
+func (p *Parser) verifyFunctionSubjects(f file, c *ast.CommentGroup, comments []string, tc *TypeChecker) {
+	if p.verifySubjects == "" || tc == nil || tc.info == nil {
+		return
+	}
+	if fn := documentedFunc(f, c); fn != nil && fn.Body != nil {
+		p.verifyCallSubjects(f, fn.Body, comments, tc)
+	}
+}
+
+// verifyCallSubjects warns about the broker calls below node, of the kind of
+// the operation annotated by comments, whose constant subjects do not match
+// its @name. Subjects computed at run time are not checked.
+func (p *Parser) verifyCallSubjects(f file, node ast.Node, comments []string, tc *TypeChecker) {
+	if p.verifySubjects == "" || tc == nil || tc.info == nil {
+		return
+	}
+	var name string
+	send := false
+	for _, line := range comments {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch {
+		case strings.EqualFold(fields[0], annotation.Name):
+			resolved, err := tc.resolveConst(fields[1])
+			if err != nil {
+				return
+			}
+			name = resolved
+		case strings.EqualFold(fields[0], annotation.Type):
+			send = fields[1] == "pub"
+		}
+	}
+	if name == "" {
+		return
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		callee, subjects := brokerSubjects(n, send, tc)
+		if len(subjects) == 0 || slices.ContainsFunc(subjects, func(subject string) bool { return subjectMatches(name, subject) }) {
+			return true
+		}
+		p.warnings.warnf(warnSubjectMismatch, "subject %q of %s at %s does not match %s %s",
+			subjects[len(subjects)-1], callee, commentLocation(f, n, tc), annotation.Name, name)
+		return true
+	})
+}
+
+// brokerSubjects returns the name of the broker call or field n and the
+// constant subjects it names, when it is a call of the given kind.
+func brokerSubjects(n ast.Node, send bool, tc *TypeChecker) (string, []string) {
+	switch n := n.(type) {
+	case *ast.CallExpr:
+		sel, ok := n.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return "", nil
+		}
+		fn, ok := tc.info.Uses[sel.Sel].(*types.Func)
+		if !ok || fn.Pkg() == nil {
+			return "", nil
+		}
+		for _, call := range brokerCalls {
+			if call.send != send || call.name != fn.Name() || !slices.Contains(call.packages, fn.Pkg().Path()) {
+				continue
+			}
+			var subjects []string
+			for _, i := range call.args {
+				if i >= len(n.Args) {
+					continue
+				}
+				if subject, ok := constantString(n.Args[i], tc); ok && subject != "" {
+					subjects = append(subjects, subject)
+				}
+			}
+			return fn.Pkg().Name() + "." + fn.Name(), subjects
+		}
+	case *ast.CompositeLit:
+		named, ok := derefType(tc.info.TypeOf(n)).(*types.Named)
+		if !ok || named.Obj().Pkg() == nil {
+			return "", nil
+		}
+		for _, field := range brokerFields {
+			if field.send != send || field.typeName != named.Obj().Name() || !slices.Contains(field.packages, named.Obj().Pkg().Path()) {
+				continue
+			}
+			for _, elt := range n.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != field.field {
+					continue
+				}
+				if subject, ok := constantString(kv.Value, tc); ok && subject != "" {
+					return named.Obj().Pkg().Name() + "." + field.typeName + "." + field.field, []string{subject}
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// derefType returns the element type of pointers, and other types as is.
+func derefType(typ types.Type) types.Type {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return typ
+}
+
+// subjectMatches reports whether subject, the constant argument of a broker
+// call, is a channel of the @name pattern. Compared token by token, a
+// parameter such as {id} or the NATS wildcard * of @name matches any token,
+// including a * of the subject, and a trailing > any remaining tokens.
+func subjectMatches(name, subject string) bool {
+	names, subjects := strings.Split(name, "."), strings.Split(subject, ".")
+	for i, token := range names {
+		if i >= len(subjects) {
+			return false
+		}
+		if token == ">" {
+			return true
+		}
+		if token != "*" && !tokenMatches(token, subjects[i]) {
+			return false
+		}
+	}
+	return len(names) == len(subjects)
+}
+
+// tokenMatches reports whether a token of a subject matches a token of @name
+// that may hold parameters, e.g. "orders-{region}".
+func tokenMatches(pattern, token string) bool {
+	if !strings.Contains(pattern, "{") {
+		return pattern == token
+	}
+	parts := paramsPattern.Split(pattern, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".+") + "$").MatchString(token)
+}
+
+// subjectMismatchError returns ErrSubjectMismatch with the mismatches among
+// warnings, or nil when there are none.
+func subjectMismatchError(warnings []Warning) error {
+	var mismatches []string
+	for _, warning := range warnings {
+		if warning.Kind == warnSubjectMismatch {
+			mismatches = append(mismatches, warning.Location+": "+warning.Message)
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w:\n  %s", ErrSubjectMismatch, strings.Join(mismatches, "\n  "))
+}
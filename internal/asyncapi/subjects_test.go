@@ -0,0 +1,148 @@
+package asyncapi
+
+import (
+	"errors"
+	"testing"
+)
+
+// subjectSources is a module calling a stand-in for the NATS client.
+var subjectSources = map[string]string{
+	"go.mod": `module example.com/svc
+
+go 1.21
+
+require github.com/nats-io/nats.go v1.0.0
+
+replace github.com/nats-io/nats.go => ./stub/nats
+`,
+	"stub/nats/go.mod": "module github.com/nats-io/nats.go\n\ngo 1.21\n",
+	"stub/nats/nats.go": `package nats
+
+type Conn struct{}
+
+type Msg struct{}
+
+func (c *Conn) Publish(subj string, data []byte) error { return nil }
+
+func (c *Conn) Subscribe(subj string, cb func(*Msg)) (*Msg, error) { return nil, nil }
+`,
+	"main.go": `// @title Orders API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+import "github.com/nats-io/nats.go"
+
+const SubjectShipped = "order.shipped"
+
+type Order struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// @type pub
+// @name order.created
+// @payload Order
+func PublishCreated(nc *nats.Conn, data []byte, subject string) {
+	nc.Publish("order.created", data)
+	nc.Publish("order.cancelled", data)
+	nc.Subscribe("order.audit", nil)
+	nc.Publish(subject, data)
+}
+
+// @type sub
+// @name order.{id}.updated
+// @payload Order
+func SubscribeUpdated(nc *nats.Conn) {
+	nc.Subscribe("order.*.updated", nil)
+}
+
+// @type pub
+// @name const:SubjectShipped
+// @payload Order
+func PublishShipped(nc *nats.Conn, data []byte) {
+	nc.Publish("order.shiped", data)
+}
+
+func main() {
+	var nc *nats.Conn
+	//asyncapi:publish order.paid Order
+	nc.Publish("order.payed", nil)
+}
+`,
+}
+
+func TestParseFSVerifySubjects(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, subjectSources)
+
+	var report Report
+	if _, err := ParseFS(DirFS(root), Options{VerifySubjects: VerifySubjectsWarn, Report: &report}); err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	var got []string
+	for _, warning := range report.Warnings {
+		if warning.Kind == warnSubjectMismatch {
+			got = append(got, warning.Message)
+		}
+	}
+	want := []string{
+		`subject "order.cancelled" of nats.Publish at main.go:20 does not match @name order.created`,
+		`subject "order.shiped" of nats.Publish at main.go:36 does not match @name order.shipped`,
+		`subject "order.payed" of nats.Publish at main.go:42 does not match @name order.paid`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("mismatches = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("mismatch %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	_, err := ParseFS(DirFS(root), Options{VerifySubjects: VerifySubjectsError})
+	if !errors.Is(err, ErrSubjectMismatch) {
+		t.Errorf("ParseFS(error mode) error = %v, want ErrSubjectMismatch", err)
+	}
+
+	report = Report{}
+	if _, err := ParseFS(DirFS(root), Options{Report: &report}); err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	for _, warning := range report.Warnings {
+		if warning.Kind == warnSubjectMismatch {
+			t.Errorf("warning %q without VerifySubjects", warning.Message)
+		}
+	}
+
+	if _, err := ParseFS(DirFS(root), Options{VerifySubjects: "strict"}); err == nil {
+		t.Error("ParseFS(unknown mode) error = nil, want an error")
+	}
+	if _, err := BuildIndex(root, Options{VerifySubjects: VerifySubjectsError}); !errors.Is(err, ErrSubjectMismatch) {
+		t.Errorf("BuildIndex(error mode) error = %v, want ErrSubjectMismatch", err)
+	}
+}
+
+func TestSubjectMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		want    bool
+	}{
+		{"order.created", "order.created", true},
+		{"order.created", "order.cancelled", false},
+		{"order.{id}.updated", "order.42.updated", true},
+		{"order.{id}.updated", "order.*.updated", true},
+		{"order.created", "order.*", false},
+		{"order.*", "order.created", true},
+		{"order.>", "order.created.eu", true},
+		{"order.created", "order.created.eu", false},
+		{"orders-{region}", "orders-eu", true},
+		{"orders-{region}", "payments-eu", false},
+	}
+	for _, tt := range tests {
+		if got := subjectMatches(tt.name, tt.subject); got != tt.want {
+			t.Errorf("subjectMatches(%q, %q) = %v, want %v", tt.name, tt.subject, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// PayloadSubtype is a variant of a discriminated payload, declared with
+// @payload.subtype.
+type PayloadSubtype struct {
+	Value         string // value of the discriminator property, e.g. "order"
+	TypeName      string // Go type of the variant, e.g. "OrderEvent"
+	MessageSample interface{}
+}
+
+// ParsePayloadSubtypes parses the "value:Type" pairs of @payload.subtype,
+// e.g. "order:OrderEvent user:UserEvent". The pairs may be separated by
+// spaces or commas and the annotation repeated.
+func (operation *Operation) ParsePayloadSubtypes(value string, tc *TypeChecker) error {
+	pairs := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(pairs) == 0 {
+		return fmt.Errorf("invalid %s %q: want value:Type pairs", annotation.PayloadSubtype, value)
+	}
+	for _, pair := range pairs {
+		discriminatorValue, typeName, ok := strings.Cut(pair, ":")
+		if !ok || discriminatorValue == "" || typeName == "" {
+			return fmt.Errorf("invalid %s %q: want value:Type", annotation.PayloadSubtype, pair)
+		}
+		for _, subtype := range operation.PayloadSubtypes {
+			if subtype.Value == discriminatorValue {
+				return fmt.Errorf("invalid %s %q: value %s is already mapped to %s", annotation.PayloadSubtype, pair, discriminatorValue, subtype.TypeName)
+			}
+		}
+		typeSpec := GetByNameType(typeName, tc)
+		if typeSpec == nil {
+			return fmt.Errorf("payload subtype type not found: %s", typeName)
+		}
+		operation.PayloadSubtypes = append(operation.PayloadSubtypes, PayloadSubtype{
+			Value:         discriminatorValue,
+			TypeName:      typeName,
+			MessageSample: Msg{Data: typeSpec},
+		})
+	}
+	return nil
+}
+
+// checkPayloadSubtypes warns about @payload.discriminator and
+// @payload.subtype annotations that do not go together.
+func (p *Parser) checkPayloadSubtypes(operation *Operation) {
+	switch {
+	case operation.PayloadDiscriminator != "" && len(operation.PayloadSubtypes) == 0:
+		p.warnings.warnf(warnAnnotation, "%s on %s is ignored without %s", annotation.PayloadDiscriminator, operation.Name, annotation.PayloadSubtype)
+	case operation.PayloadDiscriminator == "" && len(operation.PayloadSubtypes) > 0:
+		p.warnings.warnf(warnAnnotation, "%s on %s is ignored without %s", annotation.PayloadSubtype, operation.Name, annotation.PayloadDiscriminator)
+		operation.PayloadSubtypes = nil
+	case len(operation.PayloadSubtypes) > 0 && operation.Message.MessageSample != nil:
+		p.warnings.warnf(warnAnnotation, "%s on %s is ignored with %s; the subtypes are the payload", annotation.Payload, operation.Name, annotation.PayloadSubtype)
+	}
+}
+
+// subtypesPayload registers the schemas of the payload subtypes of an
+// operation and returns the payload schema choosing between them. AsyncAPI
+// discriminators only name the property, so each alternative pins the value
+// mapped to its subtype with an allOf of the subtype schema, left as the type
+// is documented elsewhere, and a const of that property.
+func (p *Parser) subtypesPayload(messageName string, operation *Operation) map[string]interface{} {
+	oneOf := make([]interface{}, 0, len(operation.PayloadSubtypes))
+	for _, subtype := range operation.PayloadSubtypes {
+		schema := generateTypedSchema(subtype.MessageSample)
+		properties, _ := schema["properties"].(map[string]interface{})
+		_, hasDiscriminator := properties[operation.PayloadDiscriminator].(map[string]interface{})
+		name := p.registerSchema(schemaNameForType(subtype.TypeName), schema)
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+		if !hasDiscriminator {
+			p.warnings.warnf(warnAnnotation, "%s %s of %s has no %s property", annotation.PayloadSubtype, subtype.TypeName, operation.Name, operation.PayloadDiscriminator)
+			oneOf = append(oneOf, ref)
+			continue
+		}
+		oneOf = append(oneOf, map[string]interface{}{
+			"allOf": []interface{}{
+				ref,
+				map[string]interface{}{
+					"properties": map[string]interface{}{
+						operation.PayloadDiscriminator: map[string]interface{}{"const": subtype.Value},
+					},
+				},
+			},
+		})
+	}
+	name := p.registerSchema(messageName+"Payload", map[string]interface{}{
+		"discriminator": operation.PayloadDiscriminator,
+		"oneOf":         oneOf,
+	})
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
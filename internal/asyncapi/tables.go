@@ -0,0 +1,167 @@
+package asyncapi
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+)
+
+// Default fields of registration table rows.
+const (
+	defaultTableSubjectField = "Subject"
+	defaultTablePayloadField = "Payload"
+)
+
+// RegistrationTable describes a struct type whose literals register message
+// handlers, such as the rows of
+//
+//	var handlers = []Handler{
+//		{Subject: "user.created", Fn: onUserCreated, Payload: UserCreatedEvent{}},
+//	}
+//
+// Every literal of the type becomes an operation on the channel named by its
+// subject field, with the type of its payload field as the payload, so the
+// rows need no annotations of their own.
+type RegistrationTable struct {
+	// Type is the row struct type, by name ("Handler") or qualified by its
+	// package name ("bus.Handler") or import path.
+	Type string
+	// Subject is the field holding the channel name, a string constant;
+	// "Subject" by default.
+	Subject string
+	// Payload is the field holding a value of the payload type, e.g.
+	// UserCreatedEvent{}; "Payload" by default.
+	Payload string
+	// Action is the type of the operations: "sub" (the default) for tables
+	// of consumers or "pub" for tables of publishers.
+	Action string
+}
+
+// validRegistrationTables reports an error for a table without a type or with
+// an unknown action.
+func validRegistrationTables(tables []RegistrationTable) error {
+	for _, table := range tables {
+		if table.Type == "" {
+			return errors.New("registration table without a type")
+		}
+		switch table.Action {
+		case "", "pub", "sub":
+		default:
+			return fmt.Errorf("unknown action %q of registration table %s (want pub or sub)", table.Action, table.Type)
+		}
+	}
+	return nil
+}
+
+// matches reports whether named is the row type of the table.
+func (t RegistrationTable) matches(named *types.Named) bool {
+	obj := named.Obj()
+	if t.Type == obj.Name() {
+		return true
+	}
+	return obj.Pkg() != nil && (t.Type == obj.Pkg().Name()+"."+obj.Name() || t.Type == obj.Pkg().Path()+"."+obj.Name())
+}
+
+// fieldOr returns field, or def when it is not set.
+func fieldOr(field, def string) string {
+	if field == "" {
+		return def
+	}
+	return field
+}
+
+// parseRegistrationTables turns the rows of the registration tables of a file
+// into operations. Rows are recognized by their type, so it needs type
+// information.
+func parseRegistrationTables(p *Parser, f file, tc *TypeChecker) {
+	if len(p.registrationTables) == 0 || tc == nil || tc.info == nil {
+		return
+	}
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		table, row, ok := p.registrationTableOf(tc.info.TypeOf(lit))
+		if !ok {
+			return true
+		}
+		p.warnings.location = commentLocation(f, lit, tc)
+		p.order.at(lit, tc)
+
+		fields := rowFields(lit, row)
+		subjectField := fieldOr(table.Subject, defaultTableSubjectField)
+		subject, ok := constantString(fields[subjectField], tc)
+		if !ok {
+			p.warnings.warnf(warnTable, "%s row in %s is ignored: its %s is not a string constant", table.Type, f.name, subjectField)
+			return false
+		}
+		annotations := []string{annotation.Type + " " + fieldOr(table.Action, "sub"), annotation.Name + " " + subject}
+		payloadField := fieldOr(table.Payload, defaultTablePayloadField)
+		if expr := fields[payloadField]; expr != nil {
+			if payload := payloadTypeName(tc.info.TypeOf(expr), tc); payload != "" {
+				annotations = append(annotations, annotation.Payload+" "+payload)
+			}
+		}
+		if !hasAnnotation(annotations, annotation.Payload) {
+			p.warnings.warnf(warnTable, "%s row for %s in %s has no payload type in its %s field", table.Type, subject, f.name, payloadField)
+		}
+		p.recordOperationFile(f)
+		p.ParseOperation(annotations, tc)
+		return false
+	})
+}
+
+// registrationTableOf returns the registration table whose rows have type
+// typ, with the struct of the row.
+func (p *Parser) registrationTableOf(typ types.Type) (RegistrationTable, *types.Struct, bool) {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return RegistrationTable{}, nil, false
+	}
+	row, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return RegistrationTable{}, nil, false
+	}
+	for _, table := range p.registrationTables {
+		if table.matches(named) {
+			return table, row, true
+		}
+	}
+	return RegistrationTable{}, nil, false
+}
+
+// rowFields maps the fields set by a row literal, keyed or positional, to
+// their values.
+func rowFields(lit *ast.CompositeLit, row *types.Struct) map[string]ast.Expr {
+	fields := make(map[string]ast.Expr, len(lit.Elts))
+	for i, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				fields[key.Name] = kv.Value
+			}
+			continue
+		}
+		if i < row.NumFields() {
+			fields[row.Field(i).Name()] = elt
+		}
+	}
+	return fields
+}
+
+// constantString returns the value of a string constant expression, such as a
+// literal or a named constant.
+func constantString(expr ast.Expr, tc *TypeChecker) (string, bool) {
+	if expr == nil {
+		return "", false
+	}
+	value := tc.info.Types[expr].Value
+	if value == nil || value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(value), true
+}
@@ -0,0 +1,97 @@
+package asyncapi
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseFSRegistrationTables(t *testing.T) {
+	src := `// @title Users API
+// @version 1.0.0
+// @protocol nats
+// @url nats://localhost:4222
+package main
+
+const SubjectUserDeleted = "user.deleted"
+
+type UserCreatedEvent struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type UserDeletedEvent struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Handler struct {
+	Subject string
+	Fn      func([]byte) error
+	Payload any
+}
+
+type Route struct {
+	Topic string
+	Body  any
+}
+
+func onUserCreated([]byte) error { return nil }
+
+var handlers = []Handler{
+	{Subject: "user.created", Fn: onUserCreated, Payload: UserCreatedEvent{}},
+	{SubjectUserDeleted, onUserCreated, &UserDeletedEvent{}},
+	{Subject: "user.unknown", Fn: onUserCreated},
+}
+
+var routes = map[string]Route{
+	"audit": {Topic: "audit.user", Body: UserCreatedEvent{}},
+}
+
+func register(subject string) Handler {
+	return Handler{Subject: subject}
+}
+
+func main() {}
+`
+	var report Report
+	fsys := fstest.MapFS{"main.go": {Data: []byte(src)}}
+	doc, err := ParseFS(fsys, Options{
+		RegistrationTables: []RegistrationTable{
+			{Type: "Handler"},
+			{Type: "main.Route", Subject: "Topic", Payload: "Body", Action: "pub"},
+		},
+		Report: &report,
+	})
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"subscribeUserCreated": "#/components/schemas/UserCreatedEvent",
+		"subscribeUserDeleted": "#/components/schemas/UserDeletedEvent",
+		"subscribeUserUnknown": "",
+		"publishAuditUser":     "#/components/schemas/UserCreatedEvent",
+	} {
+		op, ok := doc.Operations[name]
+		if !ok {
+			t.Errorf("operation %s missing, operations = %v", name, doc.Operations)
+			continue
+		}
+		ref := op.Messages[0].Ref
+		message := doc.Components.Messages[ref[strings.LastIndex(ref, "/")+1:]]
+		payload, _ := message.Payload.(map[string]interface{})
+		if got, _ := payload["$ref"].(string); got != want {
+			t.Errorf("%s payload $ref = %q, want %q", name, got, want)
+		}
+	}
+	if len(doc.Operations) != 4 {
+		t.Errorf("len(Operations) = %d, want 4", len(doc.Operations))
+	}
+	// The row without a payload and the literal with a variable subject.
+	if len(report.Warnings) != 2 {
+		t.Errorf("warnings = %v, want 2", report.Warnings)
+	}
+
+	if _, err := ParseFS(fsys, Options{RegistrationTables: []RegistrationTable{{Type: "Handler", Action: "reply"}}}); err == nil {
+		t.Error("ParseFS() with an unknown table action error = nil, want error")
+	}
+}
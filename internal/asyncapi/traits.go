@@ -0,0 +1,229 @@
+package asyncapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// commonHeadersTrait is the message trait holding headers declared with
+// @message.commonHeader. It is attached to every generated message.
+const commonHeadersTrait = "commonHeaders"
+
+// headerTypes lists the schema types accepted by @message.commonHeader.
+var headerTypes = map[string]bool{
+	"string":  true,
+	"integer": true,
+	"number":  true,
+	"boolean": true,
+}
+
+// parseCommonHeader adds a header applied by shared middleware, in format:
+// "name type [required] [description]", e.g. "trace-id string required Distributed trace ID".
+func (p *Parser) parseCommonHeader(value string) error {
+	parts := strings.Fields(value)
+	if len(parts) < 2 {
+		return fmt.Errorf("@message.commonHeader requires a name and a type, got %q", value)
+	}
+
+	name, headerType := parts[0], strings.ToLower(parts[1])
+	if !headerTypes[headerType] {
+		return fmt.Errorf("unsupported @message.commonHeader type %q for header %s", parts[1], name)
+	}
+
+	rest := parts[2:]
+	required := len(rest) > 0 && strings.EqualFold(rest[0], "required")
+	if required {
+		rest = rest[1:]
+	}
+
+	schema := map[string]interface{}{"type": headerType}
+	if len(rest) > 0 {
+		schema["description"] = strings.Join(rest, " ")
+	}
+
+	if p.commonHeaders == nil {
+		p.commonHeaders = make(map[string]interface{})
+	}
+	p.commonHeaders[name] = schema
+	if required {
+		p.commonHeadersRequired = append(p.commonHeadersRequired, name)
+	}
+	return nil
+}
+
+// applyCommonHeaders registers the common headers as a message trait and
+// references it from every message. It runs once all files are parsed, since
+// the main annotations may come after the operations.
+func (p *Parser) applyCommonHeaders() {
+	if len(p.commonHeaders) == 0 {
+		return
+	}
+
+	headers := map[string]interface{}{
+		"type":       "object",
+		"properties": p.commonHeaders,
+	}
+	if len(p.commonHeadersRequired) > 0 {
+		required := append([]string(nil), p.commonHeadersRequired...)
+		sort.Strings(required)
+		headers["required"] = required
+	}
+
+	if p.asyncAPI.Components.MessageTraits == nil {
+		p.asyncAPI.Components.MessageTraits = make(map[string]spec3.MessageTrait)
+	}
+	p.asyncAPI.Components.MessageTraits[commonHeadersTrait] = spec3.MessageTrait{Headers: headers}
+
+	ref := spec3.Reference{Ref: "#/components/messageTraits/" + commonHeadersTrait}
+	for name, message := range p.asyncAPI.Components.Messages {
+		message.Traits = append(message.Traits, ref)
+		p.asyncAPI.Components.Messages[name] = message
+	}
+}
+
+// parseTraitAttr parses an operation or message trait declaration into
+// components. It reports false when the attribute is not a trait declaration.
+func (p *Parser) parseTraitAttr(attribute, value string) bool {
+	if name, field, ok := namedAttr(attribute, annotation.OperationTraitPrefix); ok {
+		if p.asyncAPI.Components.OperationTraits == nil {
+			p.asyncAPI.Components.OperationTraits = make(map[string]spec3.OperationTrait)
+		}
+		trait := p.asyncAPI.Components.OperationTraits[name]
+		if !setOperationTraitField(&trait, field, value) {
+			return false
+		}
+		p.asyncAPI.Components.OperationTraits[name] = trait
+		return true
+	}
+
+	if name, field, ok := namedAttr(attribute, annotation.MessageTraitPrefix); ok {
+		if p.asyncAPI.Components.MessageTraits == nil {
+			p.asyncAPI.Components.MessageTraits = make(map[string]spec3.MessageTrait)
+		}
+		trait := p.asyncAPI.Components.MessageTraits[name]
+		if !setMessageTraitField(&trait, field, value) {
+			return false
+		}
+		p.asyncAPI.Components.MessageTraits[name] = trait
+		return true
+	}
+
+	return false
+}
+
+func setOperationTraitField(trait *spec3.OperationTrait, field, value string) bool {
+	switch field {
+	case "title":
+		trait.Title = value
+	case "summary":
+		trait.Summary = value
+	case "description":
+		trait.Description = value
+	case "tag":
+		trait.Tags = append(trait.Tags, parseTag(value))
+	case "security":
+		for _, scheme := range strings.Split(value, ",") {
+			if trimmed := strings.TrimSpace(scheme); trimmed != "" {
+				trait.Security = append(trait.Security, securitySchemeRef(trimmed))
+			}
+		}
+	case "externaldocs.description":
+		if trait.ExternalDocs == nil {
+			trait.ExternalDocs = &spec3.ExternalDocs{}
+		}
+		trait.ExternalDocs.Description = value
+	case "externaldocs.url":
+		if trait.ExternalDocs == nil {
+			trait.ExternalDocs = &spec3.ExternalDocs{}
+		}
+		trait.ExternalDocs.URL = value
+	case "binding":
+		if trait.Bindings == nil {
+			trait.Bindings = make(map[string]interface{})
+		}
+		parseServerBinding(value, trait.Bindings)
+	default:
+		return false
+	}
+	return true
+}
+
+func setMessageTraitField(trait *spec3.MessageTrait, field, value string) bool {
+	switch field {
+	case "name":
+		trait.Name = value
+	case "title":
+		trait.Title = value
+	case "summary":
+		trait.Summary = value
+	case "description":
+		trait.Description = value
+	case "contenttype":
+		trait.ContentType = value
+	case "tag":
+		trait.Tags = append(trait.Tags, parseTag(value))
+	case "correlationid":
+		correlationID := parseCorrelationID(value)
+		trait.CorrelationID = &correlationID
+	case "externaldocs.description":
+		if trait.ExternalDocs == nil {
+			trait.ExternalDocs = &spec3.ExternalDocs{}
+		}
+		trait.ExternalDocs.Description = value
+	case "externaldocs.url":
+		if trait.ExternalDocs == nil {
+			trait.ExternalDocs = &spec3.ExternalDocs{}
+		}
+		trait.ExternalDocs.URL = value
+	case "binding":
+		if trait.Bindings == nil {
+			trait.Bindings = make(map[string]interface{})
+		}
+		parseServerBinding(value, trait.Bindings)
+	default:
+		return false
+	}
+	return true
+}
+
+// traitRefs converts trait names to references into the given components section.
+func traitRefs(section string, names []string) []spec3.Reference {
+	refs := make([]spec3.Reference, len(names))
+	for i, name := range names {
+		refs[i] = spec3.Reference{Ref: "#/components/" + section + "/" + name}
+	}
+	return refs
+}
+
+// undefinedTraits returns trait references used by operations or messages
+// that have no matching declaration in components.
+func (p *Parser) undefinedTraits() []string {
+	missing := make(map[string]bool)
+	for _, op := range p.asyncAPI.Operations {
+		for _, ref := range op.Traits {
+			name := strings.TrimPrefix(ref.Ref, "#/components/operationTraits/")
+			if _, ok := p.asyncAPI.Components.OperationTraits[name]; !ok {
+				missing["operationTraits/"+name] = true
+			}
+		}
+	}
+	for _, message := range p.asyncAPI.Components.Messages {
+		for _, ref := range message.Traits {
+			name := strings.TrimPrefix(ref.Ref, "#/components/messageTraits/")
+			if _, ok := p.asyncAPI.Components.MessageTraits[name]; !ok {
+				missing["messageTraits/"+name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,141 @@
+package asyncapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommonHeadersTrait(t *testing.T) {
+	parser := NewParser()
+
+	operation := NewOperation()
+	operation.ParseName("order.created")
+	parser.proccessOperation(operation)
+
+	parser.ParseMain([]string{
+		"@title Orders API",
+		"@version 1.0.0",
+		"@message.commonHeader trace-id string required Distributed trace identifier",
+		"@message.commonHeader tenant-id string Tenant the message belongs to",
+	})
+	parser.Finalize()
+
+	trait, ok := parser.asyncAPI.Components.MessageTraits[commonHeadersTrait]
+	if !ok {
+		t.Fatal("commonHeaders message trait missing")
+	}
+	headers, ok := trait.Headers.(map[string]interface{})
+	if !ok {
+		t.Fatalf("trait Headers = %T, want schema map", trait.Headers)
+	}
+	properties := headers["properties"].(map[string]interface{})
+	traceID := properties["trace-id"].(map[string]interface{})
+	if traceID["type"] != "string" || traceID["description"] != "Distributed trace identifier" {
+		t.Errorf("trace-id schema = %v", traceID)
+	}
+	if _, ok := properties["tenant-id"]; !ok {
+		t.Error("tenant-id header missing")
+	}
+	if !reflect.DeepEqual(headers["required"], []string{"trace-id"}) {
+		t.Errorf("required = %v, want [trace-id]", headers["required"])
+	}
+
+	msg := parser.asyncAPI.Components.Messages["orderCreatedMessage"]
+	if len(msg.Traits) != 1 || msg.Traits[0].Ref != "#/components/messageTraits/commonHeaders" {
+		t.Errorf("message Traits = %v, want commonHeaders reference", msg.Traits)
+	}
+}
+
+func TestParseCommonHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing type", "trace-id"},
+		{"unsupported type", "trace-id uuid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser()
+			if err := parser.parseCommonHeader(tt.input); err == nil {
+				t.Errorf("parseCommonHeader(%q) expected error", tt.input)
+			}
+		})
+	}
+}
+
+func TestFinalizeWithoutCommonHeaders(t *testing.T) {
+	parser := NewParser()
+	operation := NewOperation()
+	operation.ParseName("order.created")
+	parser.proccessOperation(operation)
+	parser.Finalize()
+
+	if len(parser.asyncAPI.Components.MessageTraits) != 0 {
+		t.Errorf("MessageTraits = %v, want none", parser.asyncAPI.Components.MessageTraits)
+	}
+	if msg := parser.asyncAPI.Components.Messages["orderCreatedMessage"]; len(msg.Traits) != 0 {
+		t.Errorf("message Traits = %v, want none", msg.Traits)
+	}
+}
+
+func TestTraitDeclarationsAndReferences(t *testing.T) {
+	parser := NewParser()
+	parser.ParseMain([]string{
+		"@title Orders API",
+		"@version 1.0.0",
+		"@operationTrait.audited.tag audit - Operations recorded in the audit log",
+		"@operationTrait.audited.binding nats.queue audit-workers",
+		"@operationTrait.audited.security userPassword",
+		"@messageTrait.jsonEvent.contentType application/json",
+		"@messageTrait.jsonEvent.correlationId requestId",
+	})
+
+	opTrait, ok := parser.asyncAPI.Components.OperationTraits["audited"]
+	if !ok {
+		t.Fatal("audited operation trait missing")
+	}
+	if len(opTrait.Tags) != 1 || opTrait.Tags[0].Name != "audit" {
+		t.Errorf("audited Tags = %v", opTrait.Tags)
+	}
+	if _, ok := opTrait.Bindings["nats"]; !ok {
+		t.Errorf("audited Bindings = %v, want nats binding", opTrait.Bindings)
+	}
+	msgTrait, ok := parser.asyncAPI.Components.MessageTraits["jsonEvent"]
+	if !ok {
+		t.Fatal("jsonEvent message trait missing")
+	}
+	if msgTrait.ContentType != "application/json" {
+		t.Errorf("jsonEvent ContentType = %q, want %q", msgTrait.ContentType, "application/json")
+	}
+
+	operation := NewOperation()
+	for _, comment := range []string{
+		"@type pub",
+		"@name order.created",
+		"@operation.trait audited",
+		"@message.trait jsonEvent, missing",
+	} {
+		if err := operation.ParseComment(comment, nil); err != nil {
+			t.Fatalf("ParseComment(%q) error = %v", comment, err)
+		}
+	}
+	parser.proccessOperation(operation)
+
+	op := parser.asyncAPI.Operations["publishOrderCreated"]
+	if len(op.Traits) != 1 || op.Traits[0].Ref != "#/components/operationTraits/audited" {
+		t.Errorf("operation Traits = %v, want audited reference", op.Traits)
+	}
+	msg := parser.asyncAPI.Components.Messages["orderCreatedMessage"]
+	if len(msg.Traits) != 2 || msg.Traits[0].Ref != "#/components/messageTraits/jsonEvent" {
+		t.Errorf("message Traits = %v, want jsonEvent and missing references", msg.Traits)
+	}
+
+	if got := parser.undefinedTraits(); !reflect.DeepEqual(got, []string{"messageTraits/missing"}) {
+		t.Errorf("undefinedTraits() = %v, want [messageTraits/missing]", got)
+	}
+	if got := parser.undefinedSecuritySchemes(); !reflect.DeepEqual(got, []string{"userPassword"}) {
+		t.Errorf("undefinedSecuritySchemes() = %v, want [userPassword]", got)
+	}
+}
@@ -1,19 +1,80 @@
 package asyncapi
 
 import (
+	"fmt"
 	"go/ast"
 	"go/importer"
 	"go/token"
 	"go/types"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Modes documenting exported struct fields without a json tag.
+const (
+	// UntaggedFieldsInclude documents them under their Go name and promotes
+	// the fields of untagged embedded structs, as encoding/json writes them.
+	UntaggedFieldsInclude = "include"
+	// UntaggedFieldsSkip documents only fields named by a json tag; the
+	// default.
+	UntaggedFieldsSkip = "skip"
 )
 
+// validUntaggedFields reports an error for an unknown untagged fields mode.
+func validUntaggedFields(mode string) error {
+	switch mode {
+	case "", UntaggedFieldsInclude, UntaggedFieldsSkip:
+		return nil
+	}
+	return fmt.Errorf("unknown untagged fields mode %q (want %s or %s)", mode, UntaggedFieldsInclude, UntaggedFieldsSkip)
+}
+
 // TypeChecker wraps go/types functionality for extracting type information.
 type TypeChecker struct {
 	fset *token.FileSet
 	pkg  *types.Package
 	info *types.Info
+	// files holds the syntax of the checked package and, when loaded with
+	// go/packages, of its imports, for reading type doc comments.
+	files map[*types.Package][]*ast.File
+
+	// expanding holds the named types whose fields are being converted,
+	// keyed by types.TypeString, so a type reached again from its own fields
+	// becomes a $ref instead of recursing forever.
+	expanding map[string]bool
+	// instances maps the names extractFieldTypeInfo gives to instances of
+	// generic types, e.g. "Page[Order]", to the instantiated types.
+	instances map[string]*types.Named
+	// componentRefs maps the component schema name of each type emitted as a
+	// $ref to the type name to generate it from.
+	componentRefs map[string]string
+	// refNested emits every nested struct as a $ref to its own component
+	// schema instead of inlining it.
+	refNested bool
+	// untaggedFields is UntaggedFieldsInclude or UntaggedFieldsSkip, the
+	// default when empty, how exported fields without a json tag are
+	// documented.
+	untaggedFields string
+	// goTypes adds the x-go-type extension, the import path and name of the
+	// Go type, to the schemas of named structs.
+	goTypes bool
+	// typeMappings holds configured schemas for types, keyed by the type name
+	// as written in Go source ("uuid.UUID").
+	typeMappings map[string]map[string]interface{}
+	// warnings receives the warnings of type resolution, shared with the
+	// parser using the type checker.
+	warnings *warningLog
+	// reserveComponent returns the component schema name reserved for the
+	// type emitted as a $ref, set by the parser using the type checker so
+	// names are unique across packages.
+	reserveComponent func(typeName string) string
+	// typeErrors holds the errors of type-checking the package, which
+	// explain why a payload type could not be resolved.
+	typeErrors []error
 }
 
 // NewTypeChecker creates a new TypeChecker from parsed files.
@@ -24,37 +85,121 @@ func NewTypeChecker(fset *token.FileSet, files []*ast.File, pkgPath string) (*Ty
 		Uses:  make(map[*ast.Ident]types.Object),
 	}
 
+	// Type-check errors do not stop the generation: the package is still
+	// checked as far as possible and the errors are kept to explain the
+	// payload types that could not be resolved.
+	var typeErrors []error
 	config := &types.Config{
 		Importer: importer.Default(),
-		Error: func(_ error) {
-			// Ignore errors for now - we want to be lenient
+		Error: func(err error) {
+			typeErrors = append(typeErrors, err)
 		},
 	}
 
-	pkg, err := config.Check(pkgPath, fset, files, info)
-	_ = err // Intentionally ignored - we create a package even if type checking fails
+	pkg, _ := config.Check(pkgPath, fset, files, info)
 	if pkg == nil {
 		// If type checking fails, create an empty package
 		pkg = types.NewPackage(pkgPath, pkgPath)
 	}
 
 	return &TypeChecker{
-		fset: fset,
-		pkg:  pkg,
-		info: info,
+		fset:       fset,
+		pkg:        pkg,
+		info:       info,
+		files:      map[*types.Package][]*ast.File{pkg: files},
+		typeErrors: typeErrors,
 	}, nil
 }
 
-// ExtractTypeInfo extracts type information from a named type.
-func (tc *TypeChecker) ExtractTypeInfo(typeName string) *TypeInfo {
-	obj := tc.pkg.Scope().Lookup(typeName)
-	if obj == nil {
+// configure applies the options that shape the schemas of payload types.
+func (tc *TypeChecker) configure(opts Options) {
+	tc.refNested = opts.RefNested
+	tc.typeMappings = opts.TypeMappings
+	tc.goTypes = opts.GoTypes
+	tc.untaggedFields = opts.UntaggedFields
+}
+
+// newPackageTypeChecker wraps a package loaded with go/packages, whose
+// imports are fully type-checked.
+func newPackageTypeChecker(pkg *packages.Package) *TypeChecker {
+	files := make(map[*types.Package][]*ast.File)
+	packages.Visit([]*packages.Package{pkg}, nil, func(p *packages.Package) {
+		files[p.Types] = p.Syntax
+	})
+	typeErrors := make([]error, len(pkg.Errors))
+	for i, err := range pkg.Errors {
+		typeErrors[i] = err
+	}
+	return &TypeChecker{
+		fset:       pkg.Fset,
+		pkg:        pkg.Types,
+		info:       pkg.TypesInfo,
+		files:      files,
+		typeErrors: typeErrors,
+	}
+}
+
+// typeErrorFor returns the type-check error most likely to explain why the
+// named type could not be resolved: the first one mentioning the type, else
+// the first one of the package, or nil when the package checked cleanly.
+func (tc *TypeChecker) typeErrorFor(typeName string) error {
+	if tc == nil || len(tc.typeErrors) == 0 {
 		return nil
 	}
+	_, name, qualified := strings.Cut(typeName, ".")
+	if !qualified {
+		name = typeName
+	}
+	for _, err := range tc.typeErrors {
+		if strings.Contains(err.Error(), name) {
+			return err
+		}
+	}
+	return tc.typeErrors[0]
+}
 
-	named, ok := obj.Type().(*types.Named)
+// lookupType finds a type name declared in the checked package or, for
+// qualified names such as "events.OrderPlaced", in a package it imports
+// directly or indirectly.
+func (tc *TypeChecker) lookupType(typeName string) types.Object {
+	pkgName, name, qualified := strings.Cut(typeName, ".")
+	if !qualified || pkgName == tc.pkg.Name() {
+		if qualified {
+			typeName = name
+		}
+		return tc.pkg.Scope().Lookup(typeName)
+	}
+
+	seen := map[*types.Package]bool{tc.pkg: true}
+	queue := tc.pkg.Imports()
+	for len(queue) > 0 {
+		imported := queue[0]
+		queue = queue[1:]
+		if seen[imported] {
+			continue
+		}
+		seen[imported] = true
+		if imported.Name() == pkgName {
+			if obj := imported.Scope().Lookup(name); obj != nil {
+				return obj
+			}
+		}
+		queue = append(queue, imported.Imports()...)
+	}
+	return nil
+}
+
+// ExtractTypeInfo extracts type information from a named type.
+func (tc *TypeChecker) ExtractTypeInfo(typeName string) *TypeInfo {
+	named, ok := tc.instances[typeName]
 	if !ok {
-		return nil
+		obj := tc.lookupType(typeName)
+		if obj == nil {
+			return nil
+		}
+		if named, ok = obj.Type().(*types.Named); !ok {
+			return nil
+		}
 	}
 
 	underlying := named.Underlying()
@@ -63,32 +208,92 @@ func (tc *TypeChecker) ExtractTypeInfo(typeName string) *TypeInfo {
 		return nil
 	}
 
-	typeInfo := &TypeInfo{
+	return &TypeInfo{
 		Name:   typeName,
-		Fields: []FieldInfo{},
+		Doc:    docDescription(tc.typeDoc(named.Obj())),
+		Fields: tc.structFields(named.Obj(), structType, map[*types.TypeName]bool{named.Obj(): true}),
+		obj:    named.Obj(),
+		named:  named,
+	}
+}
+
+// structFields returns the fields of a struct type as encoding/json writes
+// them. Exported fields without a json tag are named after the Go field, and
+// the fields of embedded structs without a json name are promoted unless a
+// field of the outer struct has the same name. Unless untaggedFields is
+// UntaggedFieldsInclude, only fields named by a json tag are kept. visiting
+// holds the embedded types being expanded, to stop at embedding cycles.
+func (tc *TypeChecker) structFields(obj *types.TypeName, structType *types.Struct, visiting map[*types.TypeName]bool) []FieldInfo {
+	type entry struct {
+		field    FieldInfo
+		promoted bool
 	}
+	var entries []entry
+	own := make(map[string]bool)
+	docs := tc.fieldDocs(obj)
 
 	for i := 0; i < structType.NumFields(); i++ {
 		field := structType.Field(i)
+		tag := structType.Tag(i)
+
+		if field.Embedded() && tc.untaggedFields == UntaggedFieldsInclude && extractJSONTagFromReflect(tag) == "" {
+			if embedded, ok := embeddedStruct(field.Type()); ok && !visiting[embedded.Obj()] {
+				visiting[embedded.Obj()] = true
+				for _, promoted := range tc.structFields(embedded.Obj(), embedded.Underlying().(*types.Struct), visiting) {
+					entries = append(entries, entry{field: promoted, promoted: true})
+				}
+				delete(visiting, embedded.Obj())
+				continue
+			}
+		}
 		if !field.Exported() {
 			continue
 		}
 
 		fieldInfo := FieldInfo{
 			Name: field.Name(),
+			Doc:  docs[field.Pos()],
 		}
 
-		// Extract JSON tag
-		tag := structType.Tag(i)
+		// Extract JSON tag and keep the full tag for schema annotations
 		fieldInfo.JSONTag = extractJSONTagFromReflect(tag)
+		fieldInfo.Tag = tag
 
 		// Extract type information
 		fieldInfo.Type, fieldInfo.IsArray, fieldInfo.IsPtr, fieldInfo.ElemType = tc.extractFieldTypeInfo(field.Type())
+		if !protoField(&fieldInfo) {
+			continue
+		}
+		if tc.untaggedFields != UntaggedFieldsInclude && fieldInfo.JSONTag == "" {
+			continue
+		}
 
-		typeInfo.Fields = append(typeInfo.Fields, fieldInfo)
+		entries = append(entries, entry{field: fieldInfo})
+		own[fieldInfo.jsonName()] = true
 	}
 
-	return typeInfo
+	fields := make([]FieldInfo, 0, len(entries))
+	for _, e := range entries {
+		if e.promoted && own[e.field.jsonName()] {
+			continue
+		}
+		fields = append(fields, e.field)
+	}
+	return fields
+}
+
+// embeddedStruct returns the named struct type of an embedded field, which
+// may be a pointer to it.
+func embeddedStruct(typ types.Type) (*types.Named, bool) {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return nil, false
+	}
+	_, ok = named.Underlying().(*types.Struct)
+	return named, ok
 }
 
 // extractFieldTypeInfo extracts type information from a types.Type.
@@ -105,13 +310,41 @@ func (tc *TypeChecker) extractFieldTypeInfo(typ types.Type) (typeName string, is
 	case *types.Named:
 		// Handle named types like time.Time
 		obj := t.Obj()
+		name := obj.Name()
 		if obj.Pkg() != nil && obj.Pkg().Name() != tc.pkg.Name() {
-			return obj.Pkg().Name() + "." + obj.Name(), false, false, ""
+			name = obj.Pkg().Name() + "." + name
 		}
-		return obj.Name(), false, false, ""
+		if args := t.TypeArgs(); args.Len() > 0 {
+			// Instances of generic types are resolved from their own
+			// fields, where the type arguments replace the parameters
+			names := make([]string, args.Len())
+			for i := range names {
+				names[i], _, _, _ = tc.extractFieldTypeInfo(args.At(i))
+			}
+			name += "[" + strings.Join(names, ",") + "]"
+			if tc.instances == nil {
+				tc.instances = make(map[string]*types.Named)
+			}
+			tc.instances[name] = t
+		}
+		return name, false, false, ""
 	case *types.Array:
 		elemTypeName, _, _, _ := tc.extractFieldTypeInfo(t.Elem())
 		return "[]" + elemTypeName, true, false, elemTypeName
+	case *types.Alias:
+		// Aliases of primitives keep their name, like defined primitive types
+		if _, ok := types.Unalias(t).(*types.Basic); ok {
+			obj := t.Obj()
+			if obj.Pkg() != nil && obj.Pkg().Name() != tc.pkg.Name() {
+				return obj.Pkg().Name() + "." + obj.Name(), false, false, ""
+			}
+			return obj.Name(), false, false, ""
+		}
+		return tc.extractFieldTypeInfo(types.Unalias(t))
+	case *types.Map:
+		keyTypeName, _, _, _ := tc.extractFieldTypeInfo(t.Key().Underlying())
+		valueTypeName, _, _, _ := tc.extractFieldTypeInfo(t.Elem())
+		return "map[" + keyTypeName + "]" + valueTypeName, false, false, valueTypeName
 	}
 	return "interface{}", false, false, ""
 }
@@ -122,6 +355,15 @@ func (tc *TypeChecker) GetReflectType(typeInfo *TypeInfo) reflect.Type {
 		return reflect.TypeOf(struct{}{})
 	}
 
+	if typeInfo.named != nil {
+		if tc.expanding == nil {
+			tc.expanding = make(map[string]bool)
+		}
+		key := types.TypeString(typeInfo.named, nil)
+		tc.expanding[key] = true
+		defer delete(tc.expanding, key)
+	}
+
 	var fields []reflect.StructField
 
 	for _, field := range typeInfo.Fields {
@@ -134,12 +376,15 @@ func (tc *TypeChecker) GetReflectType(typeInfo *TypeInfo) reflect.Type {
 			jsonTag = field.Name
 		}
 
-		fieldType := tc.getReflectTypeFromString(field.Type, field.IsArray, field.ElemType)
+		fieldType := tc.getReflectTypeFromString(field.Type)
+		if field.IsPtr {
+			fieldType = reflect.PointerTo(fieldType)
+		}
 
 		structField := reflect.StructField{
 			Name: field.Name,
 			Type: fieldType,
-			Tag:  reflect.StructTag(`json:"` + jsonTag + `"`),
+			Tag:  buildStructTag(jsonTag, field.Tag, field.Doc),
 		}
 
 		fields = append(fields, structField)
@@ -148,27 +393,53 @@ func (tc *TypeChecker) GetReflectType(typeInfo *TypeInfo) reflect.Type {
 	if len(fields) == 0 {
 		return reflect.TypeOf(struct{}{})
 	}
+	if typeInfo.obj != nil {
+		goType := ""
+		if tc.goTypes && typeInfo.obj.Pkg() != nil {
+			goType = typeInfo.obj.Pkg().Path() + "." + typeInfo.obj.Name()
+		}
+		fields = appendSchemaDoc(fields, schemaNameForType(typeInfo.Name), typeInfo.Doc, goType)
+	}
 
 	return reflect.StructOf(fields)
 }
 
-// getReflectTypeFromString converts a type string to reflect.Type.
+// getReflectTypeFromString converts a type string, as written by
+// extractFieldTypeInfo, to a reflect.Type. Pointers, slices and maps are
+// resolved recursively, so compositions such as map[string][]*Order keep
+// the schema of their innermost type.
+func (tc *TypeChecker) getReflectTypeFromString(typeName string) reflect.Type {
+	switch {
+	case strings.HasPrefix(typeName, "*"):
+		// Fields add null to the schema of pointers; elements do not
+		return tc.getReflectTypeFromString(typeName[1:])
+	case strings.HasPrefix(typeName, "[]"):
+		return reflect.SliceOf(tc.getReflectTypeFromString(typeName[2:]))
+	case strings.HasPrefix(typeName, "map["):
+		// Maps keep their key type and resolve the value type like a field type
+		if end := closingBracket(typeName, len("map")); end != -1 {
+			keyType := tc.getReflectTypeFromString(typeName[len("map["):end])
+			if keyType.Kind() == reflect.Interface || !keyType.Comparable() {
+				keyType = reflect.TypeOf("")
+			}
+			return reflect.MapOf(keyType, tc.getReflectTypeFromString(typeName[end+1:]))
+		}
+	}
+	return tc.baseReflectType(typeName)
+}
+
+// baseReflectType converts the name of a type other than a pointer, slice
+// or map to a reflect.Type.
 //
 //nolint:gocyclo // Type mapping logic is intentionally centralized for maintainability
-func (tc *TypeChecker) getReflectTypeFromString(typeName string, isArray bool, elemType string) reflect.Type {
-	// Handle pointer types
-	if typeName != "" && typeName[0] == '*' {
-		typeName = typeName[1:]
-	}
+func (tc *TypeChecker) baseReflectType(typeName string) reflect.Type {
+	var baseType reflect.Type
 
-	// If it's an array type and starts with []
-	if len(typeName) > 2 && typeName[:2] == "[]" {
-		typeName = typeName[2:]
-		isArray = true
+	// Configured type mappings take precedence over the built-in types
+	if schema, ok := tc.mappedSchema(typeName); ok {
+		return literalSchemaType(schema)
 	}
 
-	var baseType reflect.Type
-
 	switch typeName {
 	case "string":
 		baseType = reflect.TypeOf("")
@@ -202,23 +473,122 @@ func (tc *TypeChecker) getReflectTypeFromString(typeName string, isArray bool, e
 		baseType = reflect.TypeOf(time.Time{})
 	default:
 		// Try to look up nested type
-		if elemType != "" {
-			nestedTypeInfo := tc.ExtractTypeInfo(elemType)
-			if nestedTypeInfo != nil {
-				baseType = tc.GetReflectType(nestedTypeInfo)
+		if schema, ok := protobufWellKnownTypes[typeName]; ok {
+			baseType = literalSchemaType(schema)
+			break
+		}
+		if tc.isMarshaler(typeName) {
+			baseType = literalSchemaType(map[string]interface{}{"type": "string"})
+			break
+		}
+		if schema, ok := tc.namedPrimitiveSchema(typeName); ok {
+			if tc.refNested {
+				baseType = tc.componentRef(typeName)
 			} else {
-				baseType = reflect.TypeOf((*interface{})(nil)).Elem()
+				baseType = literalSchemaType(schema)
 			}
-		} else {
+			break
+		}
+		nestedTypeInfo := tc.ExtractTypeInfo(typeName)
+		switch {
+		case nestedTypeInfo == nil:
 			baseType = reflect.TypeOf((*interface{})(nil)).Elem()
+		case tc.refNested || tc.expanding[types.TypeString(nestedTypeInfo.named, nil)]:
+			baseType = tc.componentRef(typeName)
+		default:
+			baseType = tc.GetReflectType(nestedTypeInfo)
 		}
 	}
+	return baseType
+}
 
-	if isArray {
-		return reflect.SliceOf(baseType)
+// isMarshaler reports whether the named type encodes itself through
+// json.Marshaler or encoding.TextMarshaler (custom IDs, enums, time wrappers),
+// so its fields say nothing about its JSON form. Such types are described as
+// strings; a type mapping can describe them as another primitive.
+func (tc *TypeChecker) isMarshaler(typeName string) bool {
+	obj := tc.lookupType(typeName)
+	if obj == nil {
+		return false
+	}
+	// json.RawMessage holds arbitrary JSON rather than a string
+	if obj.Pkg() != nil && obj.Pkg().Path() == "encoding/json" && obj.Name() == "RawMessage" {
+		return false
 	}
 
-	return baseType
+	methods := types.NewMethodSet(types.NewPointer(obj.Type()))
+	for _, name := range []string{"MarshalJSON", "MarshalText"} {
+		sel := methods.Lookup(obj.Pkg(), name)
+		if sel == nil {
+			continue
+		}
+		sig, ok := sel.Type().(*types.Signature)
+		if ok && sig.Params().Len() == 0 && sig.Results().Len() == 2 &&
+			types.TypeString(sig.Results().At(0).Type(), nil) == "[]byte" &&
+			types.TypeString(sig.Results().At(1).Type(), nil) == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// mappedSchema returns the configured schema of typeName. Types of the
+// checked package match both their bare and their qualified name.
+func (tc *TypeChecker) mappedSchema(typeName string) (map[string]interface{}, bool) {
+	if schema, ok := tc.typeMappings[typeName]; ok {
+		return schema, true
+	}
+	if !strings.Contains(typeName, ".") && tc.pkg != nil {
+		schema, ok := tc.typeMappings[tc.pkg.Name()+"."+typeName]
+		return schema, ok
+	}
+	return nil, false
+}
+
+// componentRef records typeName as a component schema and returns the marker
+// type that generates a $ref to it.
+func (tc *TypeChecker) componentRef(typeName string) reflect.Type {
+	name := schemaNameForType(typeName)
+	if tc.reserveComponent != nil {
+		name = tc.reserveComponent(typeName)
+	}
+	if tc.componentRefs == nil {
+		tc.componentRefs = make(map[string]string)
+	}
+	tc.componentRefs[name] = typeName
+	return schemaRefType(name)
+}
+
+// buildStructTag keeps the original field tag (format, example, validate, ...)
+// so schema generation sees the same annotations as the source struct. The
+// field's doc comment becomes its description unless the tag sets one.
+func buildStructTag(jsonName, original, doc string) reflect.StructTag {
+	tag := original
+	if _, ok := reflect.StructTag(original).Lookup("json"); !ok {
+		tag = strings.TrimSpace(`json:"` + jsonName + `" ` + original)
+	}
+	if _, ok := reflect.StructTag(original).Lookup("description"); !ok && doc != "" {
+		tag += ` description:` + strconv.Quote(doc)
+	}
+	return reflect.StructTag(tag)
+}
+
+// closingBracket returns the index of the "]" closing the "[" at open in s,
+// skipping nested pairs as in "map[[2]int]string", or -1.
+func closingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
 }
 
 // extractJSONTagFromReflect extracts JSON tag from a reflect-style tag string.
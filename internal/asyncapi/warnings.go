@@ -0,0 +1,124 @@
+package asyncapi
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Warning kinds group the warnings of a run in its summary.
+const (
+	warnTypeNotFound      = "type not found"
+	warnAnnotation        = "invalid annotation"
+	warnDirective         = "directive"
+	warnTable             = "registration table"
+	warnChannelKey        = "channel key"
+	warnSchema            = "schema"
+	warnExample           = "example"
+	warnUndefinedName     = "undefined reference"
+	warnUnknownAnnotation = "unknown annotation"
+	warnSubjectMismatch   = "subject mismatch"
+	warnDiscovery         = "discovery"
+)
+
+// Warning is a problem that did not stop the generation, reported once with
+// the number of times it occurred and where it was first seen.
+type Warning struct {
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	Count    int    `json:"count"`
+	Location string `json:"location,omitempty"`
+}
+
+// Report describes a generation run, e.g. for the JSON file written by
+// "generate -report".
+type Report struct {
+	Warnings []Warning `json:"warnings"`
+	// Ignored lists the annotated files that were not parsed.
+	Ignored []IgnoredFile `json:"ignored,omitempty"`
+}
+
+// warningLog logs each distinct warning once and counts its repeats, so a
+// type missing from hundreds of operations does not swamp the output.
+type warningLog struct {
+	warnings []*Warning
+	seen     map[string]*Warning
+	// occurrences holds every warning at its own location, repeats
+	// included, for the diagnostics of an editor.
+	occurrences []Warning
+
+	// location is the source position of the annotations being parsed.
+	location string
+}
+
+// warnf records a warning of the given kind. A nil log just prints it.
+func (w *warningLog) warnf(kind, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if w == nil {
+		log.Printf("Warning: %s", message)
+		return
+	}
+	w.occurrences = append(w.occurrences, Warning{Kind: kind, Message: message, Count: 1, Location: w.location})
+	if warning, ok := w.seen[message]; ok {
+		warning.Count++
+		return
+	}
+	if w.seen == nil {
+		w.seen = make(map[string]*Warning)
+	}
+	warning := &Warning{Kind: kind, Message: message, Count: 1, Location: w.location}
+	w.seen[message] = warning
+	w.warnings = append(w.warnings, warning)
+
+	if w.location != "" {
+		log.Printf("Warning: %s: %s", w.location, message)
+	} else {
+		log.Printf("Warning: %s", message)
+	}
+}
+
+// list returns the distinct warnings in the order they were first reported.
+func (w *warningLog) list() []Warning {
+	warnings := make([]Warning, len(w.warnings))
+	for i, warning := range w.warnings {
+		warnings[i] = *warning
+	}
+	return warnings
+}
+
+// summary groups the warnings by kind, with their counts and the location
+// each was first seen at, or returns "" when there were none.
+func (w *warningLog) summary() string {
+	if len(w.warnings) == 0 {
+		return ""
+	}
+
+	var kinds []string
+	byKind := make(map[string][]*Warning)
+	total := 0
+	for _, warning := range w.warnings {
+		if _, ok := byKind[warning.Kind]; !ok {
+			kinds = append(kinds, warning.Kind)
+		}
+		byKind[warning.Kind] = append(byKind[warning.Kind], warning)
+		total += warning.Count
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d warning(s), %d distinct:\n", total, len(w.warnings))
+	for _, kind := range kinds {
+		count := 0
+		for _, warning := range byKind[kind] {
+			count += warning.Count
+		}
+		fmt.Fprintf(&b, "  %s (%d)\n", kind, count)
+		for _, warning := range byKind[kind] {
+			fmt.Fprintf(&b, "    %dx %s", warning.Count, warning.Message)
+			if warning.Location != "" {
+				fmt.Fprintf(&b, " (first at %s)", warning.Location)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package asyncapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWarningLog(t *testing.T) {
+	w := &warningLog{}
+	w.location = "orders.go:12"
+	w.warnf(warnTypeNotFound, "type '%s' not found, using empty struct", "OrderPlaced")
+	w.location = "orders.go:30"
+	w.warnf(warnTypeNotFound, "type '%s' not found, using empty struct", "OrderPlaced")
+	w.warnf(warnTypeNotFound, "type '%s' not found, using empty struct", "OrderShipped")
+	w.location = ""
+	w.warnf(warnUndefinedName, "trait %q is referenced but not declared", "kafka")
+
+	want := []Warning{
+		{Kind: warnTypeNotFound, Message: "type 'OrderPlaced' not found, using empty struct", Count: 2, Location: "orders.go:12"},
+		{Kind: warnTypeNotFound, Message: "type 'OrderShipped' not found, using empty struct", Count: 1, Location: "orders.go:30"},
+		{Kind: warnUndefinedName, Message: `trait "kafka" is referenced but not declared`, Count: 1},
+	}
+	got := w.list()
+	if len(got) != len(want) {
+		t.Fatalf("list() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("list()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	summary := w.summary()
+	for _, line := range []string{
+		"4 warning(s), 3 distinct:",
+		"  type not found (3)",
+		"    2x type 'OrderPlaced' not found, using empty struct (first at orders.go:12)",
+		"  undefined reference (1)",
+		`    1x trait "kafka" is referenced but not declared`,
+	} {
+		if !strings.Contains(summary, line+"\n") {
+			t.Errorf("summary() missing %q\n%s", line, summary)
+		}
+	}
+
+	if summary := (&warningLog{}).summary(); summary != "" {
+		t.Errorf("summary() without warnings = %q, want empty", summary)
+	}
+}
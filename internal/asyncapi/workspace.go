@@ -0,0 +1,203 @@
+package asyncapi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// ErrNoAnnotations is returned by ParseWorkspace when no module holds
+// annotated operations.
+var ErrNoAnnotations = errors.New("no AsyncAPI annotations found")
+
+// unannotatedError is the validation error of sources holding neither
+// service-level nor operation annotations, which ParseWorkspace skips. Its
+// message is the one of any other validation error.
+type unannotatedError struct{ err error }
+
+func (e unannotatedError) Error() string { return "validation failed: " + e.err.Error() }
+func (e unannotatedError) Unwrap() error { return e.err }
+
+// workFileName is the name of the file declaring a Go workspace.
+const workFileName = "go.work"
+
+// Workspace is the documentation of the modules of a go.work workspace.
+type Workspace struct {
+	// File is the go.work file.
+	File string
+	// Modules are the modules holding annotations, in the order of the
+	// use directives of the go.work file.
+	Modules []WorkspaceModule
+	// Merged combines the documents of the modules, each one listed as the
+	// service producing and consuming its channels; only set with
+	// Options.Merge.
+	Merged *spec3.AsyncAPI
+}
+
+// WorkspaceModule is an annotated module of a Go workspace.
+type WorkspaceModule struct {
+	// Path is the module path, e.g. "github.com/acme/orders".
+	Path string
+	// Dir is the directory of the module.
+	Dir string
+	// Document is the AsyncAPI document generated from the module.
+	Document *spec3.AsyncAPI
+}
+
+// FindWorkFile returns the go.work file in dir or its closest parent, as
+// the go command finds it.
+func FindWorkFile(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory %s: %w", dir, err)
+	}
+	for current := abs; ; {
+		file := filepath.Join(current, workFileName)
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("no %s file found in %s or its parents", workFileName, abs)
+		}
+		current = parent
+	}
+}
+
+// ParseWorkspace generates one document per module of the go.work workspace
+// of dir. Each module is parsed with its sub-directories, except those of
+// other modules nested in it. Modules without annotations, or whose
+// annotations declare no operation, are skipped.
+func ParseWorkspace(dir string, opts Options) (*Workspace, error) {
+	workFile, err := FindWorkFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := workspaceModules(workFile)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := &Workspace{File: workFile}
+	var merged []sourcePackage
+	for _, module := range modules {
+		if opts.Verbose {
+			fmt.Printf("Parsing module %s (%s)\n", module.Path, module.Dir)
+		}
+		moduleOpts := opts
+		moduleOpts.Recursive = true
+		moduleOpts.Merge = false
+		moduleOpts.ExcludeDirs = strings.Join(append([]string{opts.ExcludeDirs}, nestedModules(module, modules)...), ",")
+		pkgs, ignored, err := loadFS(DirFS(module.Dir), moduleOpts)
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", module.Path, err)
+		}
+		// Nested modules are parsed on their own rather than ignored
+		ignored = slices.DeleteFunc(ignored, func(file IgnoredFile) bool {
+			return file.Reason == IgnoredExcluded && inNestedModule(file.File, module, modules)
+		})
+		doc, err := buildDocument(pkgs, ignored, moduleOpts)
+		if errors.As(err, new(unannotatedError)) || (err == nil && len(doc.Operations) == 0) {
+			if opts.Verbose {
+				fmt.Printf("Skipping module %s: no annotated operations\n", module.Path)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", module.Path, err)
+		}
+		module.Document = doc
+		workspace.Modules = append(workspace.Modules, module)
+		for _, pkg := range pkgs {
+			pkg.service = module.Path
+			merged = append(merged, pkg)
+		}
+	}
+	if len(workspace.Modules) == 0 {
+		return nil, fmt.Errorf("%w in the modules of %s", ErrNoAnnotations, workFile)
+	}
+
+	if opts.Merge {
+		workspace.Merged, err = buildDocument(merged, nil, opts)
+		if err != nil {
+			return nil, fmt.Errorf("merged workspace: %w", err)
+		}
+	}
+	return workspace, nil
+}
+
+// workspaceModules returns the modules used by the go.work file, named by
+// the module directive of their go.mod file.
+func workspaceModules(workFile string) ([]WorkspaceModule, error) {
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", workFile, err)
+	}
+	work, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", workFile, err)
+	}
+
+	modules := make([]WorkspaceModule, 0, len(work.Use))
+	for _, use := range work.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(workFile), dir)
+		}
+		goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read module %s: %w", use.Path, err)
+		}
+		path := modfile.ModulePath(goMod)
+		if path == "" {
+			return nil, fmt.Errorf("module %s: go.mod declares no module path", use.Path)
+		}
+		modules = append(modules, WorkspaceModule{Path: path, Dir: filepath.Clean(dir)})
+	}
+	return modules, nil
+}
+
+// nestedModules returns exclude patterns for the directories of the other
+// modules below the directory of module, which the go command treats as
+// separate modules too.
+func nestedModules(module WorkspaceModule, modules []WorkspaceModule) []string {
+	var patterns []string
+	for _, other := range modules {
+		rel, err := filepath.Rel(module.Dir, other.Dir)
+		if err != nil || rel == "." || outsideDir(rel) {
+			continue
+		}
+		patterns = append(patterns, filepath.ToSlash(rel)+"/**")
+	}
+	return patterns
+}
+
+// inNestedModule reports whether the file is in one of the other modules
+// below the directory of module.
+func inNestedModule(file string, module WorkspaceModule, modules []WorkspaceModule) bool {
+	for _, other := range modules {
+		if other.Dir == module.Dir {
+			continue
+		}
+		if rel, err := filepath.Rel(module.Dir, other.Dir); err != nil || outsideDir(rel) {
+			continue
+		}
+		if rel, err := filepath.Rel(other.Dir, file); err == nil && !outsideDir(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// outsideDir reports whether the relative path rel leads out of its base
+// directory. Names merely starting with "..", such as "..shared", do not.
+func outsideDir(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
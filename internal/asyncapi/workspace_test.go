@@ -0,0 +1,97 @@
+package asyncapi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWorkspaceModules(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.work":              "go 1.24\n\nuse (\n\t./orders\n\t./orders/tools\n\t./orders/..gen\n)\n\nuse ./users\n",
+		"orders/go.mod":        "module example.com/orders\n\ngo 1.24\n",
+		"orders/tools/go.mod":  "module example.com/orders/tools\n\ngo 1.24\n",
+		"orders/..gen/go.mod":  "module example.com/orders/gen\n\ngo 1.24\n",
+		"users/go.mod":         "module example.com/users\n\ngo 1.24\n",
+		"users/internal/empty": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	workFile, err := FindWorkFile(filepath.Join(root, "users", "internal"))
+	if err != nil {
+		t.Fatalf("FindWorkFile() error = %v", err)
+	}
+	if want := filepath.Join(root, "go.work"); workFile != want {
+		t.Errorf("FindWorkFile() = %s, want %s", workFile, want)
+	}
+
+	modules, err := workspaceModules(workFile)
+	if err != nil {
+		t.Fatalf("workspaceModules() error = %v", err)
+	}
+	want := []WorkspaceModule{
+		{Path: "example.com/orders", Dir: filepath.Join(root, "orders")},
+		{Path: "example.com/orders/tools", Dir: filepath.Join(root, "orders", "tools")},
+		{Path: "example.com/orders/gen", Dir: filepath.Join(root, "orders", "..gen")},
+		{Path: "example.com/users", Dir: filepath.Join(root, "users")},
+	}
+	if !reflect.DeepEqual(modules, want) {
+		t.Fatalf("workspaceModules() = %v, want %v", modules, want)
+	}
+
+	tests := []struct {
+		module WorkspaceModule
+		want   []string
+	}{
+		{modules[0], []string{"tools/**", "..gen/**"}},
+		{modules[1], nil},
+		{modules[2], nil},
+		{modules[3], nil},
+	}
+	for _, tt := range tests {
+		if got := nestedModules(tt.module, modules); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("nestedModules(%s) = %v, want %v", tt.module.Path, got, tt.want)
+		}
+	}
+
+	for file, want := range map[string]bool{
+		filepath.Join(root, "orders", "..gen", "gen.go"):   true,
+		filepath.Join(root, "orders", "tools", "tools.go"): true,
+		filepath.Join(root, "orders", "orders.go"):         false,
+	} {
+		if got := inNestedModule(file, modules[0], modules); got != want {
+			t.Errorf("inNestedModule(%s) = %v, want %v", file, got, want)
+		}
+	}
+
+	if _, err := FindWorkFile(t.TempDir()); err == nil {
+		t.Error("FindWorkFile() outside a workspace error = nil, want error")
+	}
+}
+
+func TestParseFSUnannotatedError(t *testing.T) {
+	fsys := fstest.MapFS{"main.go": {Data: []byte("package main\n\nfunc main() {}\n")}}
+	_, err := ParseFS(fsys, Options{})
+	if err == nil {
+		t.Fatal("ParseFS() error = nil, want a validation error")
+	}
+	if !strings.HasPrefix(err.Error(), "validation failed: ") {
+		t.Errorf("ParseFS() error = %q, want a validation error", err)
+	}
+	if errors.Is(err, ErrNoAnnotations) {
+		t.Errorf("ParseFS() error = %q, want ErrNoAnnotations only from ParseWorkspace", err)
+	}
+}
@@ -0,0 +1,33 @@
+package asyncapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseBindingWS parses a WebSocket channel binding property: the method of
+// the handshake request and the Go types of its query and headers, whose
+// schemas are embedded in the binding.
+func (operation *Operation) ParseBindingWS(key, value string, tc *TypeChecker) error {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(key) {
+	case "bindingversion":
+		operation.setBindingVersion("ws", value)
+	case "method":
+		method := strings.ToUpper(value)
+		if method != "GET" && method != "POST" {
+			return fmt.Errorf("invalid @binding.ws.method %q: must be GET or POST", value)
+		}
+		operation.setBinding(channelBinding, "ws", "method", method)
+	case "query", "headers":
+		name := strings.ToLower(key)
+		schema := GenerateJSONSchema(GetByNameType(value, tc))
+		if schema["type"] != "object" {
+			return fmt.Errorf("invalid @binding.ws.%s %q: must be a struct type", key, value)
+		}
+		operation.setBinding(channelBinding, "ws", name, schema)
+	default:
+		return fmt.Errorf("unknown WebSocket binding @binding.ws.%s", key)
+	}
+	return nil
+}
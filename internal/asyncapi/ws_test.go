@@ -0,0 +1,84 @@
+package asyncapi
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestParseBindingWS(t *testing.T) {
+	src := `
+package testpkg
+
+type StreamQuery struct {
+	Token  string ` + "`json:\"token\"`" + `
+	Cursor int    ` + "`json:\"cursor,omitempty\"`" + `
+}
+
+type StreamHeaders struct {
+	Origin string ` + "`json:\"Origin\"`" + `
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "test.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	tc, err := NewTypeChecker(fset, []*ast.File{file}, "testpkg")
+	if err != nil {
+		t.Fatalf("Failed to create type checker: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		comment string
+		key     string
+		wantErr bool
+	}{
+		{"method", "@binding.ws.method get", "method", false},
+		{"invalid method", "@binding.ws.method PUT", "", true},
+		{"query", "@binding.ws.query StreamQuery", "query", false},
+		{"headers case", "@binding.WS.headers StreamHeaders", "headers", false},
+		{"non-struct query", "@binding.ws.query string", "", true},
+		{"unknown", "@binding.ws.subprotocol graphql-ws", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := NewOperation()
+			err := operation.ParseComment(tt.comment, tc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			ws, _ := operation.ChannelBindings["ws"].(map[string]interface{})
+			if ws[tt.key] == nil {
+				t.Errorf("channel bindings = %v, want %s", operation.ChannelBindings, tt.key)
+			}
+		})
+	}
+
+	parser := NewParser()
+	parser.ParseOperation([]string{
+		"@type sub",
+		"@name stream",
+		"@payload string",
+		"@binding.ws.method GET",
+		"@binding.ws.query StreamQuery",
+		"@binding.ws.bindingVersion 0.1.0",
+	}, tc)
+	ws, _ := parser.asyncAPI.Channels["stream"].Bindings["ws"].(map[string]interface{})
+	if ws["method"] != "GET" || ws["bindingVersion"] != "0.1.0" {
+		t.Errorf("ws binding = %v, want GET method and bindingVersion", ws)
+	}
+	query, _ := ws["query"].(map[string]interface{})
+	properties, _ := query["properties"].(map[string]interface{})
+	if _, ok := properties["token"]; !ok || query["type"] != "object" {
+		t.Errorf("query = %v, want object schema with token", query)
+	}
+	if _, ok := query[schemaTypeKey]; ok {
+		t.Errorf("query = %v, want no type marker", query)
+	}
+}
@@ -0,0 +1,129 @@
+package codegen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+func testDoc() *spec3.AsyncAPI {
+	doc := spec3.NewAsyncAPI()
+	doc.Components.Schemas["Order"] = map[string]interface{}{
+		"type":        "object",
+		"description": "An order placed by a customer",
+		"properties": map[string]interface{}{
+			"orderId": map[string]interface{}{"type": "string"},
+			"status":  map[string]interface{}{"type": "string", "enum": []interface{}{"open", "shipped"}},
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"$ref": "#/components/schemas/Item"},
+			},
+			"labels": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "integer"},
+			},
+			"trace-id": map[string]interface{}{"type": "string"},
+			"note":     map[string]interface{}{"type": []interface{}{"string", "null"}},
+			"shipping": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"$ref": "#/components/schemas/Item"},
+					map[string]interface{}{"type": "null"},
+				},
+			},
+		},
+		"required": []string{"orderId"},
+	}
+	doc.Components.Schemas["Item"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"sku": map[string]interface{}{"type": "string"},
+		},
+	}
+	doc.Components.Schemas["String"] = map[string]interface{}{"type": "string"}
+	doc.Components.Schemas["EventsPayload"] = map[string]interface{}{
+		"discriminator": "kind",
+		"oneOf": []interface{}{
+			map[string]interface{}{
+				"allOf": []interface{}{
+					map[string]interface{}{"$ref": "#/components/schemas/Order"},
+					map[string]interface{}{
+						"properties": map[string]interface{}{"kind": map[string]interface{}{"const": "order"}},
+					},
+				},
+			},
+			map[string]interface{}{"$ref": "#/components/schemas/Item"},
+		},
+	}
+	return doc
+}
+
+func TestJSONSchemaFiles(t *testing.T) {
+	files, err := JSONSchemaFiles(testDoc())
+	if err != nil {
+		t.Fatalf("JSONSchemaFiles() error = %v", err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("JSONSchemaFiles() returned %d files, want 4", len(files))
+	}
+
+	var order map[string]interface{}
+	if err := json.Unmarshal(files["Order.schema.json"], &order); err != nil {
+		t.Fatalf("Order.schema.json is not valid JSON: %v", err)
+	}
+	if order["$schema"] != jsonSchemaDialect {
+		t.Errorf("$schema = %v, want %q", order["$schema"], jsonSchemaDialect)
+	}
+	if order["title"] != "Order" {
+		t.Errorf("title = %v, want %q", order["title"], "Order")
+	}
+	items := order["properties"].(map[string]interface{})["items"].(map[string]interface{})
+	if ref := items["items"].(map[string]interface{})["$ref"]; ref != "./Item.schema.json" {
+		t.Errorf("items $ref = %v, want %q", ref, "./Item.schema.json")
+	}
+}
+
+func TestTypeScript(t *testing.T) {
+	data, err := TypeScript(testDoc())
+	if err != nil {
+		t.Fatalf("TypeScript() error = %v", err)
+	}
+	got := string(data)
+
+	for _, want := range []string{
+		"export interface Item {\n  sku?: string;\n}",
+		"/** An order placed by a customer */\nexport interface Order {",
+		"  items?: Item[];",
+		"  labels?: Record<string, number>;",
+		"  orderId: string;",
+		`  status?: "open" | "shipped";`,
+		`  "trace-id"?: string;`,
+		"  note?: string | null;",
+		"  shipping?: Item | null;",
+		"export type String = string;",
+		"export type EventsPayload = Order & {\n  kind?: \"order\";\n} | Item;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("TypeScript() missing %q\n%s", want, got)
+		}
+	}
+}
+
+func TestTSIdentifier(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Order", "Order"},
+		{"orderEvents.Payload", "OrderEventsPayload"},
+		{"user-created", "UserCreated"},
+		{"2fa", "T2fa"},
+	}
+
+	for _, tt := range tests {
+		if got := tsIdentifier(tt.input); got != tt.want {
+			t.Errorf("tsIdentifier(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package codegen exports the payload schemas of an AsyncAPI 3.0 document in
+// formats consumer teams can use directly: standalone JSON Schema files and
+// TypeScript declarations.
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// jsonSchemaDialect is declared by every exported JSON Schema file.
+const jsonSchemaDialect = "http://json-schema.org/draft-07/schema#"
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+// JSONSchemaFileName returns the file name used for the named schema.
+func JSONSchemaFileName(name string) string {
+	return name + ".schema.json"
+}
+
+// JSONSchemaFiles returns one standalone JSON Schema document per entry in
+// components/schemas, keyed by file name. References to other components are
+// rewritten to relative file references so the files can be used on their own.
+func JSONSchemaFiles(doc *spec3.AsyncAPI) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, name := range schemaNames(doc) {
+		schema, ok := rewriteRefs(doc.Components.Schemas[name]).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("schema %s is not an object", name)
+		}
+		schema["$schema"] = jsonSchemaDialect
+		if _, hasTitle := schema["title"]; !hasTitle {
+			schema["title"] = name
+		}
+
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema %s: %w", name, err)
+		}
+		files[JSONSchemaFileName(name)] = append(data, '\n')
+	}
+	return files, nil
+}
+
+// rewriteRefs returns a copy of node with component references replaced by
+// references to the sibling schema files.
+func rewriteRefs(node interface{}) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" && strings.HasPrefix(ref, componentSchemaPrefix) {
+				out[key] = "./" + JSONSchemaFileName(strings.TrimPrefix(ref, componentSchemaPrefix))
+				continue
+			}
+			out[key] = rewriteRefs(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = rewriteRefs(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// schemaNames returns the component schema names in sorted order.
+func schemaNames(doc *spec3.AsyncAPI) []string {
+	if doc.Components == nil {
+		return nil
+	}
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
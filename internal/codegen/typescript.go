@@ -0,0 +1,249 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// TypeScriptFileName is the name of the declaration bundle written by TypeScript.
+const TypeScriptFileName = "index.d.ts"
+
+// TypeScript returns a declaration file with one exported type per entry in
+// components/schemas. Objects become interfaces; every other schema becomes a
+// type alias.
+func TypeScript(doc *spec3.AsyncAPI) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by asyncapi-doc. DO NOT EDIT.\n")
+
+	for _, name := range schemaNames(doc) {
+		schema, err := normalizeSchema(doc.Components.Schemas[name])
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+
+		buf.WriteString("\n")
+		writeDocComment(&buf, schema, "")
+		typeName := tsIdentifier(name)
+		if isObjectSchema(schema) {
+			fmt.Fprintf(&buf, "export interface %s ", typeName)
+			writeObjectType(&buf, schema, "")
+			buf.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&buf, "export type %s = %s;\n", typeName, tsType(schema, ""))
+	}
+	return buf.Bytes(), nil
+}
+
+// normalizeSchema round-trips a schema through JSON so that nested values
+// only use the generic map and slice types regardless of how they were built.
+func normalizeSchema(schema interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("not an object: %w", err)
+	}
+	return normalized, nil
+}
+
+func isObjectSchema(schema map[string]interface{}) bool {
+	_, hasProperties := schema["properties"].(map[string]interface{})
+	return schema["type"] == "object" && hasProperties
+}
+
+// tsType returns the TypeScript type expression for a schema.
+func tsType(schema map[string]interface{}, indent string) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return tsIdentifier(ref[strings.LastIndex(ref, "/")+1:])
+	}
+	if value, ok := schema["const"]; ok {
+		return tsLiteral(value)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		literals := make([]string, len(enum))
+		for i, value := range enum {
+			literals[i] = tsLiteral(value)
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	if types, ok := schema["type"].([]interface{}); ok {
+		members := make([]string, 0, len(types))
+		for _, typ := range types {
+			member := make(map[string]interface{}, len(schema))
+			for key, value := range schema {
+				member[key] = value
+			}
+			member["type"] = typ
+			members = append(members, tsType(member, indent))
+		}
+		return strings.Join(members, " | ")
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok && len(oneOf) > 0 {
+		members := make([]string, 0, len(oneOf))
+		for _, item := range oneOf {
+			member, _ := item.(map[string]interface{})
+			members = append(members, tsType(member, indent))
+		}
+		return strings.Join(members, " | ")
+	}
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok && len(allOf) > 0 {
+		// e.g. a discriminated subtype pinning its discriminator value
+		members := make([]string, 0, len(allOf))
+		for _, item := range allOf {
+			member, _ := item.(map[string]interface{})
+			members = append(members, tsType(member, indent))
+		}
+		return strings.Join(members, " & ")
+	}
+	if _, ok := schema["properties"].(map[string]interface{}); ok && schema["type"] == nil {
+		var buf bytes.Buffer
+		writeObjectType(&buf, schema, indent)
+		return buf.String()
+	}
+
+	switch schema["type"] {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "null":
+		return "null"
+	case "array":
+		items, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			return "unknown[]"
+		}
+		itemType := tsType(items, indent)
+		if strings.ContainsAny(itemType, " |{") {
+			return "Array<" + itemType + ">"
+		}
+		return itemType + "[]"
+	case "object":
+		if isObjectSchema(schema) {
+			var buf bytes.Buffer
+			writeObjectType(&buf, schema, indent)
+			return buf.String()
+		}
+		if values, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			return "Record<string, " + tsType(values, indent) + ">"
+		}
+		return "Record<string, unknown>"
+	default:
+		return "unknown"
+	}
+}
+
+// writeObjectType writes an object literal type with one member per property.
+// Properties not listed as required are optional.
+func writeObjectType(buf *bytes.Buffer, schema map[string]interface{}, indent string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	inner := indent + "  "
+	buf.WriteString("{\n")
+	for _, name := range names {
+		property, _ := properties[name].(map[string]interface{})
+		writeDocComment(buf, property, inner)
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(buf, "%s%s%s: %s;\n", inner, tsPropertyName(name), optional, tsType(property, inner))
+	}
+	buf.WriteString(indent + "}")
+}
+
+// writeDocComment writes the schema description as a JSDoc comment.
+func writeDocComment(buf *bytes.Buffer, schema map[string]interface{}, indent string) {
+	description, _ := schema["description"].(string)
+	if description == "" {
+		return
+	}
+	lines := strings.Split(strings.ReplaceAll(description, "*/", "*\\/"), "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(buf, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(buf, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(buf, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(buf, "%s */\n", indent)
+}
+
+func tsLiteral(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "unknown"
+	}
+	return string(data)
+}
+
+// tsPropertyName quotes property names that are not valid identifiers.
+func tsPropertyName(name string) string {
+	if isIdentifier(name) {
+		return name
+	}
+	return tsLiteral(name)
+}
+
+// tsIdentifier turns a schema name into a valid TypeScript type name.
+func tsIdentifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	id := b.String()
+	if id == "" || unicode.IsDigit(rune(id[0])) {
+		id = "T" + id
+	}
+	return id
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if unicode.IsLetter(r) || r == '_' || r == '$' || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
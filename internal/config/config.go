@@ -4,12 +4,70 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
 )
 
 // Config holds the application configuration.
 type Config struct {
 	DefaultTemplate string `json:"default_template"`
 	OutputDir       string `json:"output_dir"`
+	// TypeMappings maps Go type names as written in source ("uuid.UUID") to
+	// the JSON Schema used for them instead of a reflected one.
+	TypeMappings map[string]map[string]interface{} `json:"type_mappings"`
+	// ServerNaming is the strategy naming the server annotated without
+	// @server.name: "auto" (environment, then protocol and address hash) or
+	// "title".
+	ServerNaming string `json:"server_naming"`
+	// Naming is the strategy naming generated channel, operation and
+	// message keys: "camelCase", "snake_case", "kebab-case" or
+	// "go-type-name". The -naming flag takes precedence.
+	Naming string `json:"naming"`
+	// UntaggedFields is how exported struct fields without a json tag are
+	// documented: "skip" (the default) or "include" (under the Go field
+	// name, as encoding/json writes them). The -untagged-fields flag takes
+	// precedence.
+	UntaggedFields string `json:"untagged_fields"`
+	// BindingVersions overrides the bindingVersion written on binding
+	// objects per protocol, e.g. {"kafka": "0.4.0"}; "" omits it.
+	BindingVersions map[string]string `json:"binding_versions"`
+	// RegistrationTables lists the struct types whose literals register
+	// message handlers, so each row becomes an operation.
+	RegistrationTables []RegistrationTable `json:"registration_tables"`
+	// Info describes the service for sources without the service-level
+	// annotations, as the AsyncAPI info object; annotations take precedence.
+	Info *spec3.Info `json:"info"`
+	// Servers adds servers, as AsyncAPI server objects by name, to those
+	// declared with annotations.
+	Servers map[string]spec3.Server `json:"servers"`
+	// YAML holds the formatting of YAML output.
+	YAML YAMLConfig `json:"yaml"`
+}
+
+// RegistrationTable describes the rows of a handler registration table,
+// such as []Handler{{Subject: "user.created", Payload: UserCreated{}}}.
+type RegistrationTable struct {
+	// Type is the row struct type, e.g. "Handler" or "bus.Handler".
+	Type string `json:"type"`
+	// Subject is the field holding the channel name (default "Subject").
+	Subject string `json:"subject"`
+	// Payload is the field holding a payload value (default "Payload").
+	Payload string `json:"payload"`
+	// Action is "sub" (the default) for consumers or "pub" for publishers.
+	Action string `json:"action"`
+}
+
+// YAMLConfig sets how YAML output is formatted, so generated files pass the
+// formatting checks of the repository they are written to.
+type YAMLConfig struct {
+	// Indent is the number of spaces per nesting level (default 4).
+	Indent int `json:"indent"`
+	// LineWidth folds string values longer than this many characters onto
+	// continuation lines (default 0, no folding).
+	LineWidth int `json:"line_width"`
+	// FlowMaxKeys writes mappings of at most this many scalar values on one
+	// line in flow style, e.g. {type: string, format: uuid} (default 0, off).
+	FlowMaxKeys int `json:"flow_max_keys"`
 }
 
 // DefaultConfig returns the default configuration.
@@ -0,0 +1,370 @@
+// Package diff compares two AsyncAPI 3.0 documents and classifies the
+// differences as breaking or non-breaking for consumers of the contract.
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// Severity classifies the impact of a change on existing consumers.
+type Severity string
+
+const (
+	// Breaking changes can break existing producers or consumers.
+	Breaking Severity = "breaking"
+	// NonBreaking changes are backwards compatible.
+	NonBreaking Severity = "non-breaking"
+)
+
+// Change describes a single difference between two documents.
+type Change struct {
+	Severity Severity
+	Path     string
+	Message  string
+}
+
+// String formats the change as a single report line.
+func (c Change) String() string {
+	label := "NON-BREAKING"
+	if c.Severity == Breaking {
+		label = "BREAKING"
+	}
+	return fmt.Sprintf("%-12s %s: %s", label, c.Path, c.Message)
+}
+
+// Report holds all changes found between two documents.
+type Report struct {
+	Changes []Change
+}
+
+// HasBreaking reports whether at least one breaking change was found.
+func (r *Report) HasBreaking() bool {
+	for _, c := range r.Changes {
+		if c.Severity == Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Breaking returns only the breaking changes.
+func (r *Report) Breaking() []Change {
+	var result []Change
+	for _, c := range r.Changes {
+		if c.Severity == Breaking {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func (r *Report) add(severity Severity, path, format string, args ...interface{}) {
+	r.Changes = append(r.Changes, Change{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// maxRefDepth bounds $ref resolution so self-referencing schemas terminate.
+const maxRefDepth = 32
+
+// Compare compares oldDoc with newDoc at the channel, operation, message and
+// schema level.
+func Compare(oldDoc, newDoc *spec3.AsyncAPI) *Report {
+	r := &Report{}
+	compareChannels(r, oldDoc.Channels, newDoc.Channels)
+	compareOperations(r, oldDoc.Operations, newDoc.Operations)
+	compareMessages(r, oldDoc, newDoc)
+	return r
+}
+
+func compareChannels(r *Report, oldChannels, newChannels map[string]spec3.Channel) {
+	for _, name := range sortedKeys(oldChannels) {
+		oldCh := oldChannels[name]
+		path := "channels." + name
+		newCh, ok := newChannels[name]
+		if !ok {
+			r.add(Breaking, path, "channel removed")
+			continue
+		}
+		if oldCh.Address != newCh.Address {
+			r.add(Breaking, path+".address", "address changed from %q to %q", oldCh.Address, newCh.Address)
+		}
+		for _, msg := range sortedKeys(oldCh.Messages) {
+			if _, ok := newCh.Messages[msg]; !ok {
+				r.add(Breaking, path+".messages."+msg, "message removed from channel")
+			}
+		}
+		for _, msg := range sortedKeys(newCh.Messages) {
+			if _, ok := oldCh.Messages[msg]; !ok {
+				r.add(NonBreaking, path+".messages."+msg, "message added to channel")
+			}
+		}
+		for _, param := range sortedKeys(oldCh.Parameters) {
+			if _, ok := newCh.Parameters[param]; !ok {
+				r.add(Breaking, path+".parameters."+param, "parameter removed")
+			}
+		}
+		for _, param := range sortedKeys(newCh.Parameters) {
+			if _, ok := oldCh.Parameters[param]; !ok {
+				r.add(Breaking, path+".parameters."+param, "parameter added")
+			}
+		}
+	}
+	for _, name := range sortedKeys(newChannels) {
+		if _, ok := oldChannels[name]; !ok {
+			r.add(NonBreaking, "channels."+name, "channel added")
+		}
+	}
+}
+
+func compareOperations(r *Report, oldOps, newOps map[string]spec3.Operation) {
+	for _, name := range sortedKeys(oldOps) {
+		oldOp := oldOps[name]
+		path := "operations." + name
+		newOp, ok := newOps[name]
+		if !ok {
+			r.add(Breaking, path, "operation removed")
+			continue
+		}
+		if oldOp.Action != newOp.Action {
+			r.add(Breaking, path+".action", "action changed from %q to %q", oldOp.Action, newOp.Action)
+		}
+		if oldOp.Channel.Ref != newOp.Channel.Ref {
+			r.add(Breaking, path+".channel", "channel changed from %q to %q", oldOp.Channel.Ref, newOp.Channel.Ref)
+		}
+		if (oldOp.Reply == nil) != (newOp.Reply == nil) {
+			if newOp.Reply == nil {
+				r.add(Breaking, path+".reply", "reply removed")
+			} else {
+				r.add(Breaking, path+".reply", "reply added")
+			}
+		}
+		if !oldOp.Deprecated && newOp.Deprecated {
+			r.add(NonBreaking, path+".deprecated", "operation deprecated")
+		}
+	}
+	for _, name := range sortedKeys(newOps) {
+		if _, ok := oldOps[name]; !ok {
+			r.add(NonBreaking, "operations."+name, "operation added")
+		}
+	}
+}
+
+func compareMessages(r *Report, oldDoc, newDoc *spec3.AsyncAPI) {
+	oldMessages := componentMessages(oldDoc)
+	newMessages := componentMessages(newDoc)
+
+	for _, name := range sortedKeys(oldMessages) {
+		oldMsg := oldMessages[name]
+		path := "components.messages." + name
+		newMsg, ok := newMessages[name]
+		if !ok {
+			r.add(Breaking, path, "message removed")
+			continue
+		}
+		if oldMsg.ContentType != newMsg.ContentType {
+			r.add(Breaking, path+".contentType", "content type changed from %q to %q", oldMsg.ContentType, newMsg.ContentType)
+		}
+		c := &schemaComparer{report: r, oldDoc: oldDoc, newDoc: newDoc}
+		c.compare(path+".payload", asMap(oldMsg.Payload), asMap(newMsg.Payload), 0)
+		c.compare(path+".headers", asMap(oldMsg.Headers), asMap(newMsg.Headers), 0)
+	}
+	for _, name := range sortedKeys(newMessages) {
+		if _, ok := oldMessages[name]; !ok {
+			r.add(NonBreaking, "components.messages."+name, "message added")
+		}
+	}
+}
+
+func componentMessages(doc *spec3.AsyncAPI) map[string]spec3.Message {
+	if doc.Components == nil {
+		return nil
+	}
+	return doc.Components.Messages
+}
+
+// schemaComparer compares JSON schemas, resolving local $refs against the
+// components of the document each side belongs to.
+type schemaComparer struct {
+	report  *Report
+	oldDoc  *spec3.AsyncAPI
+	newDoc  *spec3.AsyncAPI
+	visited map[string]bool
+}
+
+//nolint:gocyclo // Schema comparison rules are intentionally kept together
+func (c *schemaComparer) compare(path string, oldSchema, newSchema map[string]interface{}, depth int) {
+	if depth > maxRefDepth {
+		return
+	}
+	if oldRef, newRef := refOf(oldSchema), refOf(newSchema); oldRef != "" || newRef != "" {
+		key := oldRef + "|" + newRef
+		if c.visited[key] {
+			return
+		}
+		if c.visited == nil {
+			c.visited = make(map[string]bool)
+		}
+		c.visited[key] = true
+	}
+	oldSchema = resolveRef(c.oldDoc, oldSchema)
+	newSchema = resolveRef(c.newDoc, newSchema)
+
+	switch {
+	case oldSchema == nil && newSchema == nil:
+		return
+	case oldSchema == nil:
+		c.report.add(Breaking, path, "schema added")
+		return
+	case newSchema == nil:
+		c.report.add(Breaking, path, "schema removed")
+		return
+	}
+
+	if oldType, newType := oldSchema["type"], newSchema["type"]; !reflect.DeepEqual(oldType, newType) {
+		c.report.add(Breaking, path+".type", "type changed from %v to %v", oldType, newType)
+		return
+	}
+	if oldFormat, newFormat := oldSchema["format"], newSchema["format"]; !reflect.DeepEqual(oldFormat, newFormat) {
+		c.report.add(Breaking, path+".format", "format changed from %v to %v", oldFormat, newFormat)
+	}
+
+	c.compareEnum(path, oldSchema["enum"], newSchema["enum"])
+
+	oldRequired := stringSet(oldSchema["required"])
+	newRequired := stringSet(newSchema["required"])
+	oldProps := asMap(oldSchema["properties"])
+	newProps := asMap(newSchema["properties"])
+
+	for _, name := range sortedKeys(oldProps) {
+		propPath := path + ".properties." + name
+		if _, ok := newProps[name]; !ok {
+			c.report.add(Breaking, propPath, "property removed")
+			continue
+		}
+		if !oldRequired[name] && newRequired[name] {
+			c.report.add(Breaking, propPath, "property became required")
+		}
+		if oldRequired[name] && !newRequired[name] {
+			c.report.add(NonBreaking, propPath, "property became optional")
+		}
+		c.compare(propPath, asMap(oldProps[name]), asMap(newProps[name]), depth+1)
+	}
+	for _, name := range sortedKeys(newProps) {
+		if _, ok := oldProps[name]; ok {
+			continue
+		}
+		if newRequired[name] {
+			c.report.add(Breaking, path+".properties."+name, "required property added")
+		} else {
+			c.report.add(NonBreaking, path+".properties."+name, "optional property added")
+		}
+	}
+
+	if oldSchema["items"] != nil || newSchema["items"] != nil {
+		c.compare(path+".items", asMap(oldSchema["items"]), asMap(newSchema["items"]), depth+1)
+	}
+	if oldAdditional, ok := oldSchema["additionalProperties"].(map[string]interface{}); ok {
+		c.compare(path+".additionalProperties", oldAdditional, asMap(newSchema["additionalProperties"]), depth+1)
+	}
+}
+
+func (c *schemaComparer) compareEnum(path string, oldEnum, newEnum interface{}) {
+	oldValues := enumSet(oldEnum)
+	newValues := enumSet(newEnum)
+	if oldValues == nil && newValues == nil {
+		return
+	}
+	if oldValues == nil {
+		c.report.add(Breaking, path+".enum", "enum constraint added")
+		return
+	}
+	if newValues == nil {
+		c.report.add(NonBreaking, path+".enum", "enum constraint removed")
+		return
+	}
+	for _, v := range sortedKeys(oldValues) {
+		if !newValues[v] {
+			c.report.add(Breaking, path+".enum", "enum value %s removed", v)
+		}
+	}
+	for _, v := range sortedKeys(newValues) {
+		if !oldValues[v] {
+			c.report.add(NonBreaking, path+".enum", "enum value %s added", v)
+		}
+	}
+}
+
+// resolveRef follows local "#/components/schemas/..." references.
+func resolveRef(doc *spec3.AsyncAPI, schema map[string]interface{}) map[string]interface{} {
+	for i := 0; i < maxRefDepth && schema != nil; i++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		const prefix = "#/components/schemas/"
+		if !strings.HasPrefix(ref, prefix) || doc.Components == nil {
+			return schema
+		}
+		schema = asMap(doc.Components.Schemas[strings.TrimPrefix(ref, prefix)])
+	}
+	return schema
+}
+
+func refOf(schema map[string]interface{}) string {
+	ref, _ := schema["$ref"].(string)
+	return ref
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return m
+}
+
+func stringSet(v interface{}) map[string]bool {
+	set := make(map[string]bool)
+	switch values := v.(type) {
+	case []interface{}:
+		for _, item := range values {
+			if s, ok := item.(string); ok {
+				set[s] = true
+			}
+		}
+	case []string:
+		for _, s := range values {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+func enumSet(v interface{}) map[string]bool {
+	values, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, item := range values {
+		set[fmt.Sprintf("%v", item)] = true
+	}
+	return set
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,184 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+const baseSpec = `
+asyncapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+channels:
+  userCreated:
+    address: user.created
+    messages:
+      userCreatedMessage:
+        $ref: '#/components/messages/userCreatedMessage'
+operations:
+  publishUserCreated:
+    action: send
+    channel:
+      $ref: '#/channels/userCreated'
+components:
+  messages:
+    userCreatedMessage:
+      contentType: application/json
+      payload:
+        $ref: '#/components/schemas/userCreatedMessagePayload'
+  schemas:
+    userCreatedMessagePayload:
+      type: object
+      properties:
+        userId:
+          type: string
+        status:
+          type: string
+          enum: [active, disabled]
+        nickname:
+          type: string
+      required: [userId]
+`
+
+func mustParse(t *testing.T, data string) *spec3.AsyncAPI {
+	t.Helper()
+	doc, err := spec3.Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	return doc
+}
+
+func TestCompareIdentical(t *testing.T) {
+	report := Compare(mustParse(t, baseSpec), mustParse(t, baseSpec))
+
+	if len(report.Changes) != 0 {
+		t.Errorf("Expected no changes, got %v", report.Changes)
+	}
+
+	if report.HasBreaking() {
+		t.Error("HasBreaking() should be false for identical documents")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name         string
+		old          string
+		new          string
+		wantPath     string
+		wantSeverity Severity
+	}{
+		{
+			name:         "channel removed",
+			new:          strings.Replace(baseSpec, "  userCreated:\n    address: user.created", "  userRenamed:\n    address: user.created", 1),
+			wantPath:     "channels.userCreated",
+			wantSeverity: Breaking,
+		},
+		{
+			name:         "channel added",
+			new:          strings.Replace(baseSpec, "channels:\n", "channels:\n  orderPlaced:\n    address: order.placed\n", 1),
+			wantPath:     "channels.orderPlaced",
+			wantSeverity: NonBreaking,
+		},
+		{
+			name:         "address changed",
+			new:          strings.Replace(baseSpec, "address: user.created", "address: user.registered", 1),
+			wantPath:     "channels.userCreated.address",
+			wantSeverity: Breaking,
+		},
+		{
+			name:         "action changed",
+			new:          strings.Replace(baseSpec, "action: send", "action: receive", 1),
+			wantPath:     "operations.publishUserCreated.action",
+			wantSeverity: Breaking,
+		},
+		{
+			name:         "property removed",
+			new:          strings.Replace(baseSpec, "        nickname:\n          type: string\n", "", 1),
+			wantPath:     "components.messages.userCreatedMessage.payload.properties.nickname",
+			wantSeverity: Breaking,
+		},
+		{
+			name:         "optional property added",
+			old:          strings.Replace(baseSpec, "        nickname:\n          type: string\n", "", 1),
+			wantPath:     "components.messages.userCreatedMessage.payload.properties.nickname",
+			wantSeverity: NonBreaking,
+		},
+		{
+			name:         "property became required",
+			new:          strings.Replace(baseSpec, "required: [userId]", "required: [userId, nickname]", 1),
+			wantPath:     "components.messages.userCreatedMessage.payload.properties.nickname",
+			wantSeverity: Breaking,
+		},
+		{
+			name:         "property type changed",
+			new:          strings.Replace(baseSpec, "        userId:\n          type: string", "        userId:\n          type: integer", 1),
+			wantPath:     "components.messages.userCreatedMessage.payload.properties.userId.type",
+			wantSeverity: Breaking,
+		},
+		{
+			name:         "enum value removed",
+			new:          strings.Replace(baseSpec, "enum: [active, disabled]", "enum: [active]", 1),
+			wantPath:     "components.messages.userCreatedMessage.payload.properties.status.enum",
+			wantSeverity: Breaking,
+		},
+		{
+			name:         "enum value added",
+			new:          strings.Replace(baseSpec, "enum: [active, disabled]", "enum: [active, disabled, banned]", 1),
+			wantPath:     "components.messages.userCreatedMessage.payload.properties.status.enum",
+			wantSeverity: NonBreaking,
+		},
+		{
+			name:         "content type changed",
+			new:          strings.Replace(baseSpec, "contentType: application/json", "contentType: application/avro", 1),
+			wantPath:     "components.messages.userCreatedMessage.contentType",
+			wantSeverity: Breaking,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldSpec, newSpec := tt.old, tt.new
+			if oldSpec == "" {
+				oldSpec = baseSpec
+			}
+			if newSpec == "" {
+				newSpec = baseSpec
+			}
+
+			report := Compare(mustParse(t, oldSpec), mustParse(t, newSpec))
+
+			var found *Change
+			for i := range report.Changes {
+				if report.Changes[i].Path == tt.wantPath {
+					found = &report.Changes[i]
+					break
+				}
+			}
+			if found == nil {
+				t.Fatalf("Expected change at %q, got %v", tt.wantPath, report.Changes)
+			}
+			if found.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %q, want %q (%s)", found.Severity, tt.wantSeverity, found.Message)
+			}
+			if report.HasBreaking() != (tt.wantSeverity == Breaking) {
+				t.Errorf("HasBreaking() = %v, changes: %v", report.HasBreaking(), report.Changes)
+			}
+		})
+	}
+}
+
+func TestCompareSelfReferencingSchema(t *testing.T) {
+	spec := strings.Replace(baseSpec, "        nickname:\n          type: string\n",
+		"        parent:\n          $ref: '#/components/schemas/userCreatedMessagePayload'\n", 1)
+
+	report := Compare(mustParse(t, spec), mustParse(t, spec))
+
+	if len(report.Changes) != 0 {
+		t.Errorf("Expected no changes, got %v", report.Changes)
+	}
+}
@@ -0,0 +1,179 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Function checks a value selected by a rule and returns the message of the
+// problem found, or "" when the value passes. present is false when the
+// field named by the rule is missing, value then being nil.
+type Function func(value interface{}, present bool, options map[string]interface{}) string
+
+// functions holds the built-in and registered rule functions by name.
+var functions = map[string]Function{
+	"truthy":      truthy,
+	"falsy":       falsy,
+	"defined":     defined,
+	"undefined":   undefined,
+	"pattern":     pattern,
+	"enumeration": enumeration,
+	"casing":      casing,
+}
+
+// optionValidators check the functionOptions of the built-in functions that
+// take options when the ruleset is loaded.
+var optionValidators = map[string]func(map[string]interface{}) error{
+	"pattern": func(options map[string]interface{}) error {
+		match, notMatch := stringOption(options, "match"), stringOption(options, "notMatch")
+		if match == "" && notMatch == "" {
+			return errors.New("match or notMatch is required")
+		}
+		for _, expr := range []string{match, notMatch} {
+			if _, err := regexp.Compile(expr); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	"enumeration": func(options map[string]interface{}) error {
+		if _, ok := options["values"].([]interface{}); !ok {
+			return errors.New("values must be a list")
+		}
+		return nil
+	},
+	"casing": func(options map[string]interface{}) error {
+		if _, ok := casings[stringOption(options, "type")]; !ok {
+			return fmt.Errorf("unknown casing type %q", stringOption(options, "type"))
+		}
+		return nil
+	},
+}
+
+// RegisterFunction makes a custom rule function available to rulesets
+// under name, replacing any function of that name. Call it before loading
+// rulesets, e.g. from an init function.
+func RegisterFunction(name string, fn Function) {
+	functions[name] = fn
+}
+
+// truthy fails for missing, false, zero and empty values.
+func truthy(value interface{}, present bool, _ map[string]interface{}) string {
+	if !present || isFalsy(value) {
+		return "must be present and not empty"
+	}
+	return ""
+}
+
+// falsy fails for values truthy accepts.
+func falsy(value interface{}, present bool, _ map[string]interface{}) string {
+	if present && !isFalsy(value) {
+		return "must be missing or empty"
+	}
+	return ""
+}
+
+// defined fails for missing values.
+func defined(_ interface{}, present bool, _ map[string]interface{}) string {
+	if !present {
+		return "must be defined"
+	}
+	return ""
+}
+
+// undefined fails for present values.
+func undefined(_ interface{}, present bool, _ map[string]interface{}) string {
+	if present {
+		return "must not be defined"
+	}
+	return ""
+}
+
+// pattern fails for strings not matching the match option or matching the
+// notMatch option. Other values pass.
+func pattern(value interface{}, _ bool, options map[string]interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	if match := stringOption(options, "match"); match != "" && !regexp.MustCompile(match).MatchString(s) {
+		return fmt.Sprintf("%q must match %s", s, match)
+	}
+	if notMatch := stringOption(options, "notMatch"); notMatch != "" && regexp.MustCompile(notMatch).MatchString(s) {
+		return fmt.Sprintf("%q must not match %s", s, notMatch)
+	}
+	return ""
+}
+
+// enumeration fails for present values not listed in the values option.
+func enumeration(value interface{}, present bool, options map[string]interface{}) string {
+	if !present {
+		return ""
+	}
+	values, _ := options["values"].([]interface{})
+	for _, allowed := range values {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return ""
+		}
+	}
+	names := make([]string, len(values))
+	for i, allowed := range values {
+		names[i] = fmt.Sprint(allowed)
+	}
+	return fmt.Sprintf("%v must be one of %s", value, strings.Join(names, ", "))
+}
+
+// casings are the patterns of the casing types.
+var casings = map[string]*regexp.Regexp{
+	"flat":   regexp.MustCompile(`^[a-z][a-z0-9]*$`),
+	"camel":  regexp.MustCompile(`^[a-z][a-z0-9]*([A-Z][a-z0-9]*)*$`),
+	"pascal": regexp.MustCompile(`^[A-Z][a-z0-9]*([A-Z][a-z0-9]*)*$`),
+	"kebab":  regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`),
+	"cobol":  regexp.MustCompile(`^[A-Z][A-Z0-9]*(-[A-Z0-9]+)*$`),
+	"snake":  regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	"macro":  regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`),
+}
+
+// casing fails for strings not written in the casing of the type option:
+// flat, camel, pascal, kebab, cobol, snake or macro.
+func casing(value interface{}, _ bool, options map[string]interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	kind := stringOption(options, "type")
+	if !casings[kind].MatchString(s) {
+		return fmt.Sprintf("%q must be %s case", s, kind)
+	}
+	return ""
+}
+
+// isFalsy reports whether value is nil, false, zero, blank or empty.
+func isFalsy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case string:
+		return strings.TrimFunc(v, unicode.IsSpace) == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// stringOption returns the string function option name, or "".
+func stringOption(options map[string]interface{}, name string) string {
+	s, _ := options[name].(string)
+	return s
+}
@@ -0,0 +1,208 @@
+// Package lint evaluates governance rules, such as naming conventions for
+// channels, mandatory extensions or forbidden protocols, against AsyncAPI
+// documents. Rules are written in a small YAML format modelled on Spectral
+// rulesets; custom rule functions can be registered from Go.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi/spec3"
+)
+
+// Severity classifies the result of a failed rule.
+type Severity string
+
+const (
+	// Error results fail "lint" and "generate -strict".
+	Error Severity = "error"
+	// Warn results are reported without failing.
+	Warn Severity = "warn"
+	// Info results are reported as hints.
+	Info Severity = "info"
+	// Off disables a rule.
+	Off Severity = "off"
+)
+
+// Result is a rule failed by a value of the document.
+type Result struct {
+	Rule     string
+	Severity Severity
+	Path     string
+	Message  string
+}
+
+// String formats the result as a single report line.
+func (r Result) String() string {
+	return fmt.Sprintf("%-5s %s: %s (%s)", r.Severity, r.Path, r.Message, r.Rule)
+}
+
+// HasErrors reports whether at least one result has the error severity.
+func HasErrors(results []Result) bool {
+	for _, result := range results {
+		if result.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckDocument evaluates the ruleset against a generated document.
+func (rs *Ruleset) CheckDocument(doc *spec3.AsyncAPI) ([]Result, error) {
+	data, err := doc.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return rs.CheckData(data)
+}
+
+// CheckData evaluates the ruleset against a YAML or JSON document. Unlike a
+// parsed document, the data keeps every extension and unknown field.
+func (rs *Ruleset) CheckData(data []byte) ([]Result, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	return rs.Check(doc), nil
+}
+
+// Check evaluates the ruleset against a decoded document, returning the
+// results ordered by rule name and path.
+func (rs *Ruleset) Check(doc interface{}) []Result {
+	var results []Result
+	for _, name := range rs.names() {
+		rule := rs.Rules[name]
+		if rule.Severity == Off {
+			continue
+		}
+		for _, target := range selectPath(doc, rule.given) {
+			for _, problem := range rule.check(target) {
+				if problem.path == "" {
+					problem.path = "$"
+				}
+				message := problem.message
+				if rule.Message != "" {
+					message = strings.NewReplacer("{{path}}", problem.path, "{{error}}", problem.message).Replace(rule.Message)
+				}
+				results = append(results, Result{
+					Rule:     name,
+					Severity: rule.Severity,
+					Path:     problem.path,
+					Message:  message,
+				})
+			}
+		}
+	}
+	return results
+}
+
+// names returns the rule names in order.
+func (rs *Ruleset) names() []string {
+	names := make([]string, 0, len(rs.Rules))
+	for name := range rs.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// node is a value of the document and its dotted path.
+type node struct {
+	path  string
+	value interface{}
+}
+
+// problem is a failed check of a value.
+type problem struct {
+	path    string
+	message string
+}
+
+// check applies the function of the rule to the field of target it names.
+func (r *Rule) check(target node) []problem {
+	fn := functions[r.Then.Function]
+	switch {
+	case r.Then.Field == "@key":
+		object, ok := target.value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		var problems []problem
+		for _, key := range sortedKeys(object) {
+			if message := fn(key, true, r.Then.FunctionOptions); message != "" {
+				problems = append(problems, problem{joinPath(target.path, key), message})
+			}
+		}
+		return problems
+	case r.Then.Field != "":
+		value, present := target.value, true
+		path := target.path
+		for _, name := range strings.Split(r.Then.Field, ".") {
+			path = joinPath(path, name)
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				value, present = nil, false
+				break
+			}
+			value, present = object[name]
+		}
+		if message := fn(value, present, r.Then.FunctionOptions); message != "" {
+			return []problem{{path, message}}
+		}
+	default:
+		if message := fn(target.value, true, r.Then.FunctionOptions); message != "" {
+			return []problem{{target.path, message}}
+		}
+	}
+	return nil
+}
+
+// selectPath returns the values of doc selected by a parsed given path.
+func selectPath(doc interface{}, segments []string) []node {
+	nodes := []node{{path: "", value: doc}}
+	for _, segment := range segments {
+		var next []node
+		for _, n := range nodes {
+			switch value := n.value.(type) {
+			case map[string]interface{}:
+				if segment == "*" {
+					for _, key := range sortedKeys(value) {
+						next = append(next, node{joinPath(n.path, key), value[key]})
+					}
+				} else if child, ok := value[segment]; ok {
+					next = append(next, node{joinPath(n.path, segment), child})
+				}
+			case []interface{}:
+				if segment == "*" {
+					for i, child := range value {
+						next = append(next, node{fmt.Sprintf("%s[%d]", n.path, i), child})
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// joinPath appends a key to a dotted path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,137 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDoc = `
+asyncapi: 3.0.0
+info:
+  title: Orders
+  version: 1.0.0
+servers:
+  production:
+    host: broker:4222
+    protocol: nats
+  legacy:
+    host: broker:80
+    protocol: http
+channels:
+  orderPlaced:
+    address: order.placed
+  Order_Shipped:
+    address: Order/Shipped
+operations:
+  publishOrderPlaced:
+    action: send
+    x-owner: checkout
+  onOrderShipped:
+    action: receive
+`
+
+const testRuleset = `
+rules:
+  channel-address:
+    description: Channel addresses are lowercase and dot-separated
+    severity: error
+    given: $.channels[*]
+    then:
+      field: address
+      function: pattern
+      functionOptions:
+        match: '^[a-z0-9]+(\.[a-z0-9]+)*$'
+  channel-keys:
+    given: $.channels
+    then:
+      field: '@key'
+      function: casing
+      functionOptions:
+        type: camel
+  operation-owner:
+    severity: error
+    message: 'operations need an owning team ({{error}})'
+    given: $.operations.*
+    then:
+      field: x-owner
+      function: truthy
+  allowed-protocols:
+    severity: info
+    given: "$['servers'][*].protocol"
+    then:
+      function: enumeration
+      functionOptions:
+        values: [nats, kafka]
+  disabled:
+    severity: off
+    given: $
+    then:
+      field: info.description
+      function: defined
+`
+
+func TestCheckData(t *testing.T) {
+	rs, err := ParseRuleset([]byte(testRuleset))
+	if err != nil {
+		t.Fatalf("ParseRuleset() error = %v", err)
+	}
+	results, err := rs.CheckData([]byte(testDoc))
+	if err != nil {
+		t.Fatalf("CheckData() error = %v", err)
+	}
+
+	want := []Result{
+		{"allowed-protocols", Info, "servers.legacy.protocol", "http must be one of nats, kafka"},
+		{"channel-address", Error, "channels.Order_Shipped.address", `"Order/Shipped" must match ^[a-z0-9]+(\.[a-z0-9]+)*$`},
+		{"channel-keys", Warn, "channels.Order_Shipped", `"Order_Shipped" must be camel case`},
+		{"operation-owner", Error, "operations.onOrderShipped.x-owner", "operations need an owning team (must be present and not empty)"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("CheckData() = %v, want %v", results, want)
+	}
+	if !HasErrors(results) {
+		t.Errorf("HasErrors() = false, want true")
+	}
+}
+
+func TestParseRulesetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		ruleset string
+		wantErr string
+	}{
+		{"severity", "rules:\n  r:\n    severity: fatal\n    given: $\n    then: {function: truthy}\n", "invalid severity"},
+		{"function", "rules:\n  r:\n    given: $\n    then: {function: spellcheck}\n", "unknown function"},
+		{"regexp", "rules:\n  r:\n    given: $\n    then: {function: pattern, functionOptions: {match: '('}}\n", "function pattern"},
+		{"casing", "rules:\n  r:\n    given: $\n    then: {function: casing, functionOptions: {type: title}}\n", "unknown casing"},
+		{"given", "rules:\n  r:\n    given: channels\n    then: {function: truthy}\n", "must start with $"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuleset([]byte(tt.ruleset))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRuleset() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterFunction(t *testing.T) {
+	RegisterFunction("noTodo", func(value interface{}, _ bool, _ map[string]interface{}) string {
+		if s, ok := value.(string); ok && strings.Contains(s, "TODO") {
+			return "must not contain TODO"
+		}
+		return ""
+	})
+	defer delete(functions, "noTodo")
+
+	rs, err := ParseRuleset([]byte("rules:\n  no-todo:\n    given: $.info\n    then: {field: title, function: noTodo}\n"))
+	if err != nil {
+		t.Fatalf("ParseRuleset() error = %v", err)
+	}
+	results := rs.Check(map[string]interface{}{"info": map[string]interface{}{"title": "TODO"}})
+	if len(results) != 1 || results[0].Path != "info.title" {
+		t.Errorf("Check() = %v, want one result at info.title", results)
+	}
+}
@@ -0,0 +1,143 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ruleset is a set of named rules, as read from a ruleset file:
+//
+//	rules:
+//	  channel-address-dots:
+//	    description: Channel addresses are lowercase and dot-separated
+//	    severity: error
+//	    given: $.channels[*]
+//	    then:
+//	      field: address
+//	      function: pattern
+//	      functionOptions:
+//	        match: '^[a-z0-9{}]+(\.[a-z0-9{}]+)*$'
+type Ruleset struct {
+	Rules map[string]*Rule `yaml:"rules"`
+}
+
+// Rule checks the values selected by Given with a function.
+type Rule struct {
+	Description string `yaml:"description"`
+	// Message replaces the message of the function; {{path}} and {{error}}
+	// are replaced by the path of the value and the function's message.
+	Message string `yaml:"message"`
+	// Severity of the results, Warn when empty.
+	Severity Severity `yaml:"severity"`
+	// Given selects the values to check with a JSONPath subset: "$", ".key"
+	// or "['key']" for a member and ".*" or "[*]" for every member.
+	Given string `yaml:"given"`
+	Then  Then   `yaml:"then"`
+
+	given []string
+}
+
+// Then describes the check of a rule.
+type Then struct {
+	// Field is the dotted path of the checked field within each given value,
+	// or "@key" to check the keys of a given object.
+	Field string `yaml:"field"`
+	// Function is a built-in or registered function name.
+	Function        string                 `yaml:"function"`
+	FunctionOptions map[string]interface{} `yaml:"functionOptions"`
+}
+
+// LoadRuleset reads and validates a ruleset file.
+func LoadRuleset(path string) (*Ruleset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ruleset: %w", err)
+	}
+	return ParseRuleset(data)
+}
+
+// ParseRuleset parses and validates a YAML ruleset.
+func ParseRuleset(data []byte) (*Ruleset, error) {
+	var rs Ruleset
+	if err := yaml.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("failed to parse ruleset: %w", err)
+	}
+	for _, name := range rs.names() {
+		rule := rs.Rules[name]
+		if rule == nil {
+			return nil, fmt.Errorf("rule %s: empty rule", name)
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	return &rs, nil
+}
+
+// compile validates the rule and parses its given path.
+func (r *Rule) compile() error {
+	switch r.Severity {
+	case "":
+		r.Severity = Warn
+	case Error, Warn, Info, Off:
+	default:
+		return fmt.Errorf("invalid severity %q (expected error, warn, info or off)", r.Severity)
+	}
+
+	if _, ok := functions[r.Then.Function]; !ok {
+		return fmt.Errorf("unknown function %q", r.Then.Function)
+	}
+	if validate, ok := optionValidators[r.Then.Function]; ok {
+		if err := validate(r.Then.FunctionOptions); err != nil {
+			return fmt.Errorf("function %s: %w", r.Then.Function, err)
+		}
+	}
+
+	given, err := parsePath(r.Given)
+	if err != nil {
+		return err
+	}
+	r.given = given
+	return nil
+}
+
+// parsePath splits a given path into member names, "*" standing for every
+// member.
+func parsePath(path string) ([]string, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !ok {
+		return nil, fmt.Errorf("invalid given %q: must start with $", path)
+	}
+	var segments []string
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "[*]"):
+			segments = append(segments, "*")
+			rest = rest[3:]
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid given %q: unterminated ['", path)
+			}
+			segments = append(segments, rest[2:end])
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid given %q: empty member name", path)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("invalid given %q at %q", path, rest)
+		}
+	}
+	return segments, nil
+}
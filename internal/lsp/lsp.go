@@ -0,0 +1,511 @@
+// Package lsp serves the annotations of Go sources to editors over a small
+// part of the Language Server Protocol: diagnostics of the package of each
+// open file, such as misspelled annotations and unresolved payload types, and
+// hover documentation of annotations.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/fedanant/asyncapi-doc/annotation"
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+)
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// LSP constants.
+const (
+	syncFull        = 1
+	severityWarning = 2
+	messageError    = 1
+	diagnosticOwner = "asyncapi-doc"
+)
+
+// Server answers the requests of one editor session.
+type Server struct {
+	in   *bufio.Reader
+	out  io.Writer
+	opts asyncapi.Options
+
+	// Debounce delays the diagnostics of edited files until typing pauses.
+	// Zero diagnoses every change right away.
+	Debounce time.Duration
+
+	mu sync.Mutex
+	// documents holds the text of the open files by path.
+	documents map[string]string
+	// published holds the files with diagnostics by package directory, to
+	// clear them once fixed.
+	published map[string]map[string]bool
+	timers    map[string]*time.Timer
+	shutdown  bool
+
+	writeMu    sync.Mutex
+	diagnoseMu sync.Mutex
+}
+
+// NewServer returns a server reading requests from in and writing responses
+// to out. opts configures the parser as for "generate".
+func NewServer(in io.Reader, out io.Writer, opts asyncapi.Options) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		opts:      opts,
+		documents: make(map[string]string),
+		published: make(map[string]map[string]bool),
+		timers:    make(map[string]*time.Timer),
+	}
+}
+
+// errExitWithoutShutdown is returned when the editor exits the server
+// without shutting it down first.
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Serve handles requests until the editor exits the server or closes its
+// input.
+func (s *Server) Serve() error {
+	defer s.stopTimers()
+	for {
+		body, err := s.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.respondError(nil, codeParseError, err.Error())
+			continue
+		}
+		if msg.Method == "exit" {
+			s.mu.Lock()
+			shutdown := s.shutdown
+			s.mu.Unlock()
+			if !shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+		s.handle(msg)
+	}
+}
+
+// message is a JSON-RPC request or notification. Notifications have no ID.
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type diagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+// handle dispatches a request or notification other than exit.
+func (s *Server) handle(msg message) {
+	switch msg.Method {
+	case "initialize":
+		s.respond(msg.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    syncFull,
+					"save":      true,
+				},
+				"hoverProvider": true,
+			},
+			"serverInfo": map[string]string{"name": diagnosticOwner},
+		})
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		s.respond(msg.ID, nil)
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if s.decode(msg, &params) {
+			s.open(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument   textDocumentIdentifier `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if s.decode(msg, &params) && len(params.ContentChanges) > 0 {
+			// Full synchronization sends the whole text in the last change
+			s.change(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didSave":
+		var params struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if s.decode(msg, &params) {
+			s.diagnose(filepath.Dir(uriPath(params.TextDocument.URI)))
+		}
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if s.decode(msg, &params) {
+			s.closeDocument(params.TextDocument.URI)
+		}
+	case "textDocument/hover":
+		var params struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+			Position     position               `json:"position"`
+		}
+		if s.decode(msg, &params) {
+			s.respond(msg.ID, s.hover(params.TextDocument.URI, params.Position))
+		}
+	default:
+		if msg.ID != nil {
+			s.respondError(msg.ID, codeMethodNotFound, "method not supported: "+msg.Method)
+		}
+	}
+}
+
+// decode unmarshals the parameters of msg, answering requests with invalid
+// ones with an error.
+func (s *Server) decode(msg message, params interface{}) bool {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		if msg.ID != nil {
+			s.respondError(msg.ID, codeInvalidParams, err.Error())
+		}
+		return false
+	}
+	return true
+}
+
+func (s *Server) open(uri, text string) {
+	path := uriPath(uri)
+	s.mu.Lock()
+	s.documents[path] = text
+	s.mu.Unlock()
+	s.diagnose(filepath.Dir(path))
+}
+
+func (s *Server) change(uri, text string) {
+	path := uriPath(uri)
+	dir := filepath.Dir(path)
+	s.mu.Lock()
+	s.documents[path] = text
+	if s.Debounce == 0 {
+		s.mu.Unlock()
+		s.diagnose(dir)
+		return
+	}
+	if timer, ok := s.timers[dir]; ok {
+		timer.Stop()
+	}
+	s.timers[dir] = time.AfterFunc(s.Debounce, func() { s.diagnose(dir) })
+	s.mu.Unlock()
+}
+
+func (s *Server) closeDocument(uri string) {
+	path := uriPath(uri)
+	s.mu.Lock()
+	delete(s.documents, path)
+	s.mu.Unlock()
+	// The file on disk may differ from the closed buffer
+	s.diagnose(filepath.Dir(path))
+}
+
+func (s *Server) stopTimers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, timer := range s.timers {
+		timer.Stop()
+	}
+}
+
+// diagnose publishes the diagnostics of the package in dir, parsed with the
+// open files in place of the ones on disk, and clears those of files that
+// no longer have any.
+func (s *Server) diagnose(dir string) {
+	s.diagnoseMu.Lock()
+	defer s.diagnoseMu.Unlock()
+
+	s.mu.Lock()
+	overlay := make(map[string][]byte, len(s.documents))
+	for path, text := range s.documents {
+		overlay[path] = []byte(text)
+	}
+	s.mu.Unlock()
+
+	found, err := asyncapi.Diagnose(dir, overlay, s.opts)
+	if err != nil {
+		s.notify("window/logMessage", map[string]interface{}{
+			"type":    messageError,
+			"message": fmt.Sprintf("failed to diagnose %s: %v", dir, err),
+		})
+		return
+	}
+
+	byFile := make(map[string][]diagnostic)
+	for _, d := range found {
+		lineText := s.line(d.File, d.Line-1)
+		byFile[d.File] = append(byFile[d.File], diagnostic{
+			Range: lspRange{
+				Start: position{Line: d.Line - 1, Character: utf16Column(lineText, d.Column-1)},
+				End:   position{Line: d.Line - 1, Character: utf16Column(lineText, d.EndColumn-1)},
+			},
+			Severity: severityWarning,
+			Code:     d.Kind,
+			Source:   diagnosticOwner,
+			Message:  d.Message,
+		})
+	}
+
+	s.mu.Lock()
+	previous := s.published[dir]
+	s.published[dir] = make(map[string]bool, len(byFile))
+	for file := range byFile {
+		s.published[dir][file] = true
+	}
+	s.mu.Unlock()
+
+	files := make([]string, 0, len(byFile)+len(previous))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	for file := range previous {
+		if _, ok := byFile[file]; !ok {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		diagnostics := byFile[file]
+		if diagnostics == nil {
+			diagnostics = []diagnostic{}
+		}
+		s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         pathURI(file),
+			"diagnostics": diagnostics,
+		})
+	}
+}
+
+// line returns the 0-based line of the open or saved file at path.
+func (s *Server) line(path string, line int) string {
+	s.mu.Lock()
+	text, ok := s.documents[path]
+	s.mu.Unlock()
+	if !ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return ""
+		}
+		text = string(data)
+	}
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	return lines[line]
+}
+
+// hover documents the annotation at pos of a line comment, or returns nil.
+func (s *Server) hover(uri string, pos position) interface{} {
+	lineText := s.line(uriPath(uri), pos.Line)
+	start, end, ok := annotationAt(lineText, byteColumn(lineText, pos.Character))
+	if !ok {
+		return nil
+	}
+	annotations := annotation.Lookup(lineText[start:end])
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	sections := make([]string, len(annotations))
+	for i, found := range annotations {
+		name := found.Name
+		if found.Prefix {
+			name += "*"
+		}
+		sections[i] = fmt.Sprintf("**%s** (%s)\n\n%s\n\n```go\n// %s\n```", name, found.Target, found.Description, found.Example)
+	}
+	return map[string]interface{}{
+		"contents": map[string]string{
+			"kind":  "markdown",
+			"value": strings.Join(sections, "\n\n---\n\n"),
+		},
+		"range": lspRange{
+			Start: position{Line: pos.Line, Character: utf16Column(lineText, start)},
+			End:   position{Line: pos.Line, Character: utf16Column(lineText, end)},
+		},
+	}
+}
+
+// annotationAt returns the byte range of the annotation name around column
+// col of a line comment.
+func annotationAt(line string, col int) (start, end int, ok bool) {
+	comment := strings.Index(line, "//")
+	if comment < 0 || col <= comment || col > len(line) {
+		return 0, 0, false
+	}
+	start, end = col, col
+	for start > comment && isAnnotationByte(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isAnnotationByte(line[end]) {
+		end++
+	}
+	// The cursor may rest on the @ itself
+	if start < len(line) && line[start] != '@' {
+		return 0, 0, false
+	}
+	return start, end, end > start+1
+}
+
+func isAnnotationByte(b byte) bool {
+	return b == '@' || b == '.' || b == ':' || b == '-' || b == '_' ||
+		('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+// utf16Column converts a byte column of line to the UTF-16 code units LSP
+// positions count.
+func utf16Column(line string, col int) int {
+	col = min(max(col, 0), len(line))
+	n := 0
+	for _, r := range line[:col] {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// byteColumn converts an LSP character position of line to a byte column.
+func byteColumn(line string, character int) int {
+	n := 0
+	for i, r := range line {
+		if n >= character {
+			return i
+		}
+		n += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// uriPath returns the path of a file URI.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	// file:///C:/dir on Windows
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// pathURI returns the file URI of path.
+func pathURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// read returns the body of the next message.
+func (s *Server) read() ([]byte, error) {
+	length := -1
+	for {
+		header, err := s.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		header = strings.TrimRight(header, "\r\n")
+		if header == "" {
+			break
+		}
+		name, value, ok := strings.Cut(header, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q: %w", value, err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message without Content-Length")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) respond(id json.RawMessage, result interface{}) {
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+func (s *Server) respondError(id json.RawMessage, code int, msg string) {
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "id": id, "error": responseError{Code: code, Message: msg}})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// write sends a message. Errors are dropped: the editor has gone away.
+func (s *Server) write(msg interface{}) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
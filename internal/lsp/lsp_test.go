@@ -0,0 +1,197 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fedanant/asyncapi-doc/internal/asyncapi"
+)
+
+// session frames the messages of an editor session.
+func session(t *testing.T, messages ...map[string]interface{}) *bytes.Buffer {
+	t.Helper()
+	var in bytes.Buffer
+	for _, msg := range messages {
+		msg["jsonrpc"] = "2.0"
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	return &in
+}
+
+type received struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+	Params struct {
+		URI         string       `json:"uri"`
+		Diagnostics []diagnostic `json:"diagnostics"`
+	} `json:"params"`
+}
+
+// replies parses the messages the server wrote.
+func replies(t *testing.T, out *bytes.Buffer) []received {
+	t.Helper()
+	s := &Server{in: bufio.NewReader(out)}
+	var msgs []received
+	for {
+		body, err := s.read()
+		if err != nil {
+			return msgs
+		}
+		var msg received
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestServe(t *testing.T) {
+	root := t.TempDir()
+	mainFile := filepath.Join(root, "main.go")
+	files := map[string]string{
+		"go.mod":  "module example.com/svc\n\ngo 1.21\n",
+		"main.go": "// @title Orders API\n// @version 1.0.0\npackage main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uri := pathURI(mainFile)
+	edited := "// @title Orders API\n// @version 1.0.0\npackage main\n\n// @type pub\n// @name order.placed\n// @paylod Order\nfunc Publish() {}\n\nfunc main() {}\n"
+
+	in := session(t,
+		map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{}},
+		map[string]interface{}{"method": "initialized", "params": map[string]interface{}{}},
+		map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "go", "version": 1, "text": files["main.go"]},
+		}},
+		map[string]interface{}{"method": "textDocument/didChange", "params": map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+			"contentChanges": []map[string]interface{}{{"text": edited}},
+		}},
+		map[string]interface{}{"id": 2, "method": "textDocument/hover", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"position":     map[string]interface{}{"line": 4, "character": 6},
+		}},
+		map[string]interface{}{"id": 3, "method": "textDocument/hover", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"position":     map[string]interface{}{"line": 7, "character": 6},
+		}},
+		map[string]interface{}{"method": "textDocument/didClose", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+		}},
+		map[string]interface{}{"id": 4, "method": "textDocument/definition", "params": map[string]interface{}{}},
+		map[string]interface{}{"id": 5, "method": "shutdown"},
+		map[string]interface{}{"method": "exit"},
+	)
+	var out bytes.Buffer
+	if err := NewServer(in, &out, asyncapi.Options{}).Serve(); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	msgs := replies(t, &out)
+	var methods []string
+	for _, msg := range msgs {
+		if msg.ID != nil {
+			methods = append(methods, fmt.Sprintf("response %d", *msg.ID))
+		} else {
+			methods = append(methods, msg.Method)
+		}
+	}
+	// The opened file has no problems, the edit adds one and closing the
+	// buffer clears it, as the file on disk has none
+	want := []string{"response 1", "textDocument/publishDiagnostics", "response 2", "response 3", "textDocument/publishDiagnostics", "response 4", "response 5"}
+	if strings.Join(methods, ",") != strings.Join(want, ",") {
+		t.Fatalf("messages = %v, want %v", methods, want)
+	}
+
+	published := msgs[1].Params
+	if published.URI != uri || len(published.Diagnostics) != 1 {
+		t.Fatalf("publishDiagnostics = %+v, want one diagnostic for %s", published, uri)
+	}
+	got := published.Diagnostics[0]
+	wantRange := lspRange{Start: position{Line: 6, Character: 3}, End: position{Line: 6, Character: 10}}
+	if got.Range != wantRange || got.Severity != severityWarning || got.Message != "unknown annotation @paylod, did you mean @payload?" {
+		t.Errorf("diagnostic = %+v, want @paylod at %+v", got, wantRange)
+	}
+
+	var hover struct {
+		Contents struct {
+			Kind  string `json:"kind"`
+			Value string `json:"value"`
+		} `json:"contents"`
+		Range lspRange `json:"range"`
+	}
+	if err := json.Unmarshal(msgs[2].Result, &hover); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(hover.Contents.Value, "**@type** (operation)") || hover.Range.Start.Character != 3 || hover.Range.End.Character != 8 {
+		t.Errorf("hover = %+v, want @type", hover)
+	}
+	if string(msgs[3].Result) != "null" {
+		t.Errorf("hover outside annotations = %s, want null", msgs[3].Result)
+	}
+
+	if cleared := msgs[4].Params; cleared.URI != uri || cleared.Diagnostics == nil || len(cleared.Diagnostics) != 0 {
+		t.Errorf("publishDiagnostics after close = %+v, want an empty list", cleared)
+	}
+	if msgs[5].Error == nil || msgs[5].Error.Code != codeMethodNotFound {
+		t.Errorf("definition error = %+v, want method not found", msgs[5].Error)
+	}
+}
+
+func TestServeExitWithoutShutdown(t *testing.T) {
+	in := session(t, map[string]interface{}{"method": "exit"})
+	if err := NewServer(in, &bytes.Buffer{}, asyncapi.Options{}).Serve(); err == nil {
+		t.Error("Serve() error = nil, want an error")
+	}
+}
+
+func TestAnnotationAt(t *testing.T) {
+	tests := []struct {
+		line string
+		col  int
+		want string
+	}{
+		{"// @binding.kafka.key string", 10, "@binding.kafka.key"},
+		{"// @type pub", 3, "@type"},
+		{"// @type pub", 8, "@type"},
+		{"// @type pub", 10, ""},
+		{"// @contact.email support@example.com", 30, ""},
+		{"func main() {} // @type", 4, ""},
+	}
+	for _, tt := range tests {
+		start, end, ok := annotationAt(tt.line, tt.col)
+		got := ""
+		if ok {
+			got = tt.line[start:end]
+		}
+		if got != tt.want {
+			t.Errorf("annotationAt(%q, %d) = %q, want %q", tt.line, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestUTF16Column(t *testing.T) {
+	line := "// héllo 😀 @type"
+	col := strings.Index(line, "@")
+	if got := utf16Column(line, col); got != 12 {
+		t.Errorf("utf16Column() = %d, want 12", got)
+	}
+	if got := byteColumn(line, 12); got != col {
+		t.Errorf("byteColumn() = %d, want %d", got, col)
+	}
+}
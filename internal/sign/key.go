@@ -0,0 +1,100 @@
+package sign
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PEM block types of the keys written by "cosign generate-key-pair".
+const (
+	sigstoreKeyType = "ENCRYPTED SIGSTORE PRIVATE KEY"
+	cosignKeyType   = "ENCRYPTED COSIGN PRIVATE KEY"
+)
+
+// encryptedKey is the JSON body of an encrypted cosign private key: a PKCS#8
+// key sealed with NaCl secretbox under a scrypt-derived key.
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadPrivateKey parses a PEM private key: an encrypted cosign key, which is
+// decrypted with password, or an unencrypted PKCS#8 or SEC 1 EC key.
+func LoadPrivateKey(data, password []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM private key found")
+	}
+
+	der := block.Bytes
+	switch block.Type {
+	case sigstoreKeyType, cosignKeyType:
+		var err error
+		if der, err = decrypt(block.Bytes, password); err != nil {
+			return nil, err
+		}
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(der)
+	case "PRIVATE KEY":
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// decrypt opens the secretbox of an encrypted cosign key.
+func decrypt(data, password []byte) ([]byte, error) {
+	var enc encryptedKey
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted key: %w", err)
+	}
+	if enc.KDF.Name != "scrypt" || enc.Cipher.Name != "nacl/secretbox" {
+		return nil, fmt.Errorf("unsupported key encryption %s with %s", enc.KDF.Name, enc.Cipher.Name)
+	}
+	if len(enc.Cipher.Nonce) != 24 {
+		return nil, errors.New("invalid encrypted key nonce")
+	}
+
+	secret, err := scrypt.Key(password, enc.KDF.Salt, enc.KDF.Params.N, enc.KDF.Params.R, enc.KDF.Params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	var key [32]byte
+	var nonce [24]byte
+	copy(key[:], secret)
+	copy(nonce[:], enc.Cipher.Nonce)
+
+	der, ok := secretbox.Open(nil, enc.Ciphertext, &nonce, &key)
+	if !ok {
+		return nil, errors.New("failed to decrypt private key: wrong password")
+	}
+	return der, nil
+}
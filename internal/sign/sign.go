@@ -0,0 +1,123 @@
+// Package sign produces detached signatures and in-toto attestations for
+// generated specifications in the formats verified by cosign
+// ("cosign verify-blob" and "cosign verify-blob-attestation").
+package sign
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// StatementType is the in-toto statement version of attestations.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType identifies the spec provenance predicate.
+	PredicateType = "https://github.com/fedanant/asyncapi-doc/provenance/v1"
+	// payloadType is the DSSE payload type of in-toto statements.
+	payloadType = "application/vnd.in-toto+json"
+)
+
+// Predicate describes the signed specification.
+type Predicate struct {
+	AsyncAPI  string `json:"asyncapi"`
+	ID        string `json:"id,omitempty"`
+	Title     string `json:"title"`
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+// Subject names an attested artifact and its digests.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Statement is an in-toto statement about a specification.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Envelope is a DSSE envelope carrying a signed statement.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a single DSSE signature.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Blob returns the base64-encoded signature of data, as written by
+// "cosign sign-blob --key".
+func Blob(signer crypto.Signer, data []byte) ([]byte, error) {
+	sig, err := signMessage(signer, data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sig)), nil
+}
+
+// Attestation returns a DSSE envelope holding an in-toto statement that
+// binds the predicate to the SHA-256 digest of data, stored under name.
+func Attestation(signer crypto.Signer, name string, data []byte, predicate Predicate) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	statement, err := json.Marshal(Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   name,
+			Digest: map[string]string{"sha256": hex.EncodeToString(digest[:])},
+		}},
+		PredicateType: PredicateType,
+		Predicate:     predicate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement: %w", err)
+	}
+
+	sig, err := signMessage(signer, pae(payloadType, statement))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(statement),
+		Signatures:  []Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	})
+}
+
+// pae is the DSSE pre-authentication encoding of a payload.
+func pae(typ string, payload []byte) []byte {
+	return []byte("DSSEv1 " + strconv.Itoa(len(typ)) + " " + typ + " " + strconv.Itoa(len(payload)) + " " + string(payload))
+}
+
+// signMessage signs the SHA-256 digest of msg, or msg itself for Ed25519
+// keys, which hash internally.
+func signMessage(signer crypto.Signer, msg []byte) ([]byte, error) {
+	var (
+		sig []byte
+		err error
+	)
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		sig, err = signer.Sign(rand.Reader, msg, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(msg)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return sig, nil
+}
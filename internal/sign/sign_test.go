@@ -0,0 +1,166 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+var spec = []byte("asyncapi: 3.0.0\ninfo:\n  title: Orders API\n  version: 1.0.0\n")
+
+func testKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestBlob(t *testing.T) {
+	key := testKey(t)
+	sig, err := Blob(key, spec)
+	if err != nil {
+		t.Fatalf("Blob() error = %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(sig))
+	if err != nil {
+		t.Fatalf("signature is not base64: %v", err)
+	}
+	digest := sha256.Sum256(spec)
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], raw) {
+		t.Error("signature does not verify against the spec digest")
+	}
+}
+
+func TestBlobEd25519(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := Blob(key, spec)
+	if err != nil {
+		t.Fatalf("Blob() error = %v", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(string(sig))
+	if !ed25519.Verify(pub, spec, raw) {
+		t.Error("signature does not verify against the spec")
+	}
+}
+
+func TestAttestation(t *testing.T) {
+	key := testKey(t)
+	predicate := Predicate{AsyncAPI: "3.0.0", Title: "Orders API", Version: "1.0.0", Generator: "asyncapi-doc"}
+	data, err := Attestation(key, "asyncapi.yaml", spec, predicate)
+	if err != nil {
+		t.Fatalf("Attestation() error = %v", err)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("envelope is not valid JSON: %v", err)
+	}
+	if envelope.PayloadType != payloadType {
+		t.Errorf("PayloadType = %q, want %q", envelope.PayloadType, payloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		t.Fatalf("payload is not base64: %v", err)
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		t.Fatalf("payload is not a statement: %v", err)
+	}
+	digest := sha256.Sum256(spec)
+	if got := statement.Subject[0].Digest["sha256"]; got != hex.EncodeToString(digest[:]) {
+		t.Errorf("subject sha256 = %q, want spec digest", got)
+	}
+	if statement.PredicateType != PredicateType || statement.Predicate != predicate {
+		t.Errorf("predicate = %s %+v, want %s %+v", statement.PredicateType, statement.Predicate, PredicateType, predicate)
+	}
+
+	sig, _ := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+	paeDigest := sha256.Sum256(pae(payloadType, payload))
+	if !ecdsa.VerifyASN1(&key.PublicKey, paeDigest[:], sig) {
+		t.Error("envelope signature does not verify")
+	}
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	key := testKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"pkcs8", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), false},
+		{"sec1", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}), false},
+		{"cosign", encryptKey(t, pkcs8, "secret"), false},
+		{"public key", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{0}}), true},
+		{"not pem", []byte("key"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := LoadPrivateKey(tt.data, []byte("secret"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !key.PublicKey.Equal(signer.Public()) {
+				t.Error("LoadPrivateKey() returned a different key")
+			}
+		})
+	}
+
+	if _, err := LoadPrivateKey(encryptKey(t, pkcs8, "secret"), []byte("wrong")); err == nil {
+		t.Error("LoadPrivateKey() with a wrong password succeeded")
+	}
+}
+
+// encryptKey seals a PKCS#8 key the way "cosign generate-key-pair" does.
+func encryptKey(t *testing.T, der []byte, password string) []byte {
+	t.Helper()
+	var enc encryptedKey
+	enc.KDF.Name = "scrypt"
+	enc.KDF.Params.N, enc.KDF.Params.R, enc.KDF.Params.P = 1024, 8, 1
+	enc.KDF.Salt = []byte("0123456789abcdef0123456789abcdef")
+	enc.Cipher.Name = "nacl/secretbox"
+	enc.Cipher.Nonce = []byte("0123456789abcdef01234567")
+
+	secret, err := scrypt.Key([]byte(password), enc.KDF.Salt, 1024, 8, 1, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key [32]byte
+	var nonce [24]byte
+	copy(key[:], secret)
+	copy(nonce[:], enc.Cipher.Nonce)
+	enc.Ciphertext = secretbox.Seal(nil, der, &nonce, &key)
+
+	data, err := json.Marshal(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: sigstoreKeyType, Bytes: data})
+}